@@ -0,0 +1,63 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Certificates defines the existing CAs to sign the cluster certs, instead of the self-signed CAs generated by KubeKey.
+type Certificates struct {
+	Kubernetes *CertificateAuthority `yaml:"kubernetes" json:"kubernetes,omitempty"`
+	FrontProxy *CertificateAuthority `yaml:"frontProxy" json:"frontProxy,omitempty"`
+	Etcd       *CertificateAuthority `yaml:"etcd" json:"etcd,omitempty"`
+}
+
+// CertificateAuthority is an existing CA, e.g. an intermediate CA issued by the corporate root CA.
+type CertificateAuthority struct {
+	// CertFile is the PEM file of the CA cert, followed by the chain up to the root CA.
+	CertFile string `yaml:"certFile" json:"certFile,omitempty"`
+	// KeyFile is the PEM file of the CA key. It must be empty when Signer is set.
+	KeyFile string `yaml:"keyFile" json:"keyFile,omitempty"`
+	// Signer signs the certs with the CA, so that the CA key is never on disk.
+	Signer *ExternalSigner `yaml:"signer" json:"signer,omitempty"`
+}
+
+// ExternalSigner is a local signer which signs a certificate signing request.
+// Either URL or Command should be set.
+type ExternalSigner struct {
+	// URL is the address of the HTTP signer, the request is posted to it.
+	URL string `yaml:"url" json:"url,omitempty"`
+	// Command is the exec signer, the request is written to its stdin and the response is read from its stdout.
+	Command []string `yaml:"command" json:"command,omitempty"`
+	// Timeout is the timeout in seconds of signing a cert. Default is 30.
+	Timeout int `yaml:"timeout" json:"timeout,omitempty"`
+}
+
+// Validate checks the CA named name.
+func (c *CertificateAuthority) Validate(name string) error {
+	if c.CertFile == "" {
+		return errors.Errorf("certificates.%s.certFile is required", name)
+	}
+	if (c.KeyFile == "") == (c.Signer == nil) {
+		return errors.Errorf("either certificates.%s.keyFile or certificates.%s.signer should be set", name, name)
+	}
+	if c.Signer != nil && (c.Signer.URL == "") == (len(c.Signer.Command) == 0) {
+		return errors.Errorf("either certificates.%s.signer.url or certificates.%s.signer.command should be set", name, name)
+	}
+	return nil
+}
@@ -43,6 +43,7 @@ type ClusterSpec struct {
 	Network              NetworkConfig        `yaml:"network" json:"network,omitempty"`
 	Storage              StorageConfig        `yaml:"storage" json:"storage,omitempty"`
 	Registry             RegistryConfig       `yaml:"registry" json:"registry,omitempty"`
+	Certificates         Certificates         `yaml:"certificates" json:"certificates,omitempty"`
 	Addons               []Addon              `yaml:"addons" json:"addons,omitempty"`
 	KubeSphere           KubeSphere           `json:"kubesphere,omitempty"`
 }
@@ -66,11 +67,29 @@ type HostCfg struct {
 	PrivateKeyPath  string `yaml:"privateKeyPath,omitempty" json:"privateKeyPath,omitempty"`
 	Arch            string `yaml:"arch,omitempty" json:"arch,omitempty"`
 	Timeout         *int64 `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	// HostKeyChecking is the mode to verify ssh host key. "yes" (default), "accept-new" or "no".
+	HostKeyChecking string `yaml:"hostKeyChecking,omitempty" json:"hostKeyChecking,omitempty"`
+	// KnownHosts is the known_hosts file to verify ssh host key. default is kubekey/known_hosts.
+	KnownHosts string `yaml:"knownHosts,omitempty" json:"knownHosts,omitempty"`
+	// HostKeyFingerprints is the pinned sha256 fingerprints of ssh host key. KnownHosts is not used when it's set.
+	HostKeyFingerprints []string `yaml:"hostKeyFingerprints,omitempty" json:"hostKeyFingerprints,omitempty"`
+	// Bastions are the jump hosts to connect the host through, in order.
+	Bastions []BastionCfg `yaml:"bastions,omitempty" json:"bastions,omitempty"`
 
 	// Labels defines the kubernetes labels for the node.
 	Labels map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
 }
 
+// BastionCfg defines a jump host for ssh connection. The credentials of the host are used when it has none.
+type BastionCfg struct {
+	Address        string `yaml:"address,omitempty" json:"address,omitempty"`
+	Port           int    `yaml:"port,omitempty" json:"port,omitempty"`
+	User           string `yaml:"user,omitempty" json:"user,omitempty"`
+	Password       string `yaml:"password,omitempty" json:"password,omitempty"`
+	PrivateKey     string `yaml:"privateKey,omitempty" json:"privateKey,omitempty"`
+	PrivateKeyPath string `yaml:"privateKeyPath,omitempty" json:"privateKeyPath,omitempty"`
+}
+
 // ControlPlaneEndpoint defines the control plane endpoint information for cluster.
 type ControlPlaneEndpoint struct {
 	InternalLoadbalancer string  `yaml:"internalLoadbalancer" json:"internalLoadbalancer,omitempty"`
@@ -209,6 +228,19 @@ func toHosts(cfg HostCfg) *KubeHost {
 	host.PrivateKeyPath = cfg.PrivateKeyPath
 	host.Arch = cfg.Arch
 	host.Timeout = *cfg.Timeout
+	host.HostKeyChecking = cfg.HostKeyChecking
+	host.KnownHosts = cfg.KnownHosts
+	host.HostKeyFingerprints = cfg.HostKeyFingerprints
+	for _, b := range cfg.Bastions {
+		host.Bastions = append(host.Bastions, connector.Bastion{
+			Address:        b.Address,
+			Port:           b.Port,
+			User:           b.User,
+			Password:       b.Password,
+			PrivateKey:     b.PrivateKey,
+			PrivateKeyPath: b.PrivateKeyPath,
+		})
+	}
 
 	kubeHost := &KubeHost{
 		BaseHost: host,
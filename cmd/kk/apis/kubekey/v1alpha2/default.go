@@ -176,6 +176,12 @@ func SetDefaultHostsCfg(cfg *ClusterSpec) []HostCfg {
 				host.PrivateKeyPath = strings.Replace(host.PrivateKeyPath, "~/", fmt.Sprintf("%s/", homeDir), 1)
 			}
 		}
+		for j := range host.Bastions {
+			if strings.HasPrefix(strings.TrimSpace(host.Bastions[j].PrivateKeyPath), "~/") {
+				homeDir, _ := util.Home()
+				host.Bastions[j].PrivateKeyPath = strings.Replace(host.Bastions[j].PrivateKeyPath, "~/", fmt.Sprintf("%s/", homeDir), 1)
+			}
+		}
 
 		if host.Arch == "" {
 			host.Arch = DefaultArch
@@ -17,6 +17,7 @@ limitations under the License.
 package cert
 
 import (
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/options"
@@ -26,8 +27,10 @@ import (
 )
 
 type CertRenewOptions struct {
-	CommonOptions  *options.CommonOptions
-	ClusterCfgFile string
+	CommonOptions    *options.CommonOptions
+	ClusterCfgFile   string
+	RotateCA         bool
+	FinishCARotation bool
 }
 
 func NewCertRenewOptions() *CertRenewOptions {
@@ -43,6 +46,7 @@ func NewCmdCertRenew() *cobra.Command {
 		Use:   "renew",
 		Short: "renew a cluster certs",
 		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Validate())
 			util.CheckErr(o.Run())
 		},
 	}
@@ -52,14 +56,25 @@ func NewCmdCertRenew() *cobra.Command {
 	return cmd
 }
 
+func (o *CertRenewOptions) Validate() error {
+	if o.RotateCA && o.FinishCARotation {
+		return errors.New("--rotate-ca and --finish-ca-rotation can not be used together")
+	}
+	return nil
+}
+
 func (o *CertRenewOptions) Run() error {
 	arg := common.Argument{
-		FilePath: o.ClusterCfgFile,
-		Debug:    o.CommonOptions.Verbose,
+		FilePath:         o.ClusterCfgFile,
+		Debug:            o.CommonOptions.Verbose,
+		RotateCA:         o.RotateCA,
+		FinishCARotation: o.FinishCARotation,
 	}
 	return pipelines.RenewCerts(arg)
 }
 
 func (o *CertRenewOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
+	cmd.Flags().BoolVarP(&o.RotateCA, "rotate-ca", "", false, "Start the rotation of cluster CAs, both the new and old CAs are trusted until the rotation is finished")
+	cmd.Flags().BoolVarP(&o.FinishCARotation, "finish-ca-rotation", "", false, "Finish the rotation of cluster CAs, the old CAs are no longer trusted")
 }
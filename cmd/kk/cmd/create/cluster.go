@@ -48,6 +48,9 @@ type CreateClusterOptions struct {
 	InstallPackages     bool
 	WithBuildx          bool
 	OnlyEtcd            bool
+	Resume              bool
+	FromModule          string
+	ToModule            string
 
 	localStorageChanged bool
 }
@@ -125,6 +128,9 @@ func (o *CreateClusterOptions) Run() error {
 		Artifact:            o.Artifact,
 		InstallPackages:     o.InstallPackages,
 		Namespace:           o.CommonOptions.Namespace,
+		Resume:              o.Resume,
+		FromModule:          o.FromModule,
+		ToModule:            o.ToModule,
 	}
 
 	if o.localStorageChanged {
@@ -148,6 +154,9 @@ func (o *CreateClusterOptions) AddFlags(cmd *cobra.Command) {
 		`The user defined command to download the necessary binary files. The first param '%s' is output path, the second param '%s', is the URL`)
 	cmd.Flags().StringVarP(&o.Artifact, "artifact", "a", "", "Path to a KubeKey artifact")
 	cmd.Flags().BoolVarP(&o.InstallPackages, "with-packages", "", false, "install operation system packages by artifact")
+	cmd.Flags().BoolVarP(&o.Resume, "resume", "", false, "Skip the modules completed by the last run whose inputs are not changed")
+	cmd.Flags().StringVarP(&o.FromModule, "from-module", "", "", "The name of the first module to run, e.g. JoinNodesModule")
+	cmd.Flags().StringVarP(&o.ToModule, "to-module", "", "", "The name of the last module to run")
 }
 
 func completionSetting(cmd *cobra.Command) (err error) {
@@ -0,0 +1,48 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/options"
+)
+
+type EtcdOptions struct {
+	CommonOptions *options.CommonOptions
+}
+
+func NewEtcdOptions() *EtcdOptions {
+	return &EtcdOptions{
+		CommonOptions: options.NewCommonOptions(),
+	}
+}
+
+// NewCmdEtcd creates a new etcd command
+func NewCmdEtcd() *cobra.Command {
+	o := NewEtcdOptions()
+	cmd := &cobra.Command{
+		Use:   "etcd",
+		Short: "Snapshot and restore the etcd cluster installed by KubeKey",
+	}
+
+	o.CommonOptions.AddCommonFlag(cmd)
+
+	cmd.AddCommand(NewCmdEtcdSnapshot())
+	cmd.AddCommand(NewCmdEtcdRestore())
+	return cmd
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/options"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/util"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/pipelines"
+)
+
+type EtcdSnapshotOptions struct {
+	CommonOptions  *options.CommonOptions
+	ClusterCfgFile string
+	Output         string
+}
+
+func NewEtcdSnapshotOptions() *EtcdSnapshotOptions {
+	return &EtcdSnapshotOptions{
+		CommonOptions: options.NewCommonOptions(),
+	}
+}
+
+// NewCmdEtcdSnapshot creates a new etcd snapshot command
+func NewCmdEtcdSnapshot() *cobra.Command {
+	o := NewEtcdSnapshotOptions()
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Save an etcd snapshot and fetch it to local",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Run())
+		},
+	}
+
+	o.CommonOptions.AddCommonFlag(cmd)
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func (o *EtcdSnapshotOptions) Run() error {
+	if o.Output == "" {
+		o.Output = fmt.Sprintf("etcd-snapshot-%s.db", time.Now().Format("20060102150405"))
+	}
+	arg := common.Argument{
+		FilePath:     o.ClusterCfgFile,
+		Debug:        o.CommonOptions.Verbose,
+		SnapshotPath: o.Output,
+	}
+	return pipelines.EtcdSnapshot(arg)
+}
+
+func (o *EtcdSnapshotOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "Path to save the snapshot, default is etcd-snapshot-<timestamp>.db in current directory")
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/completion"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/create"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/delete"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/etcd"
 	initOs "github.com/kubesphere/kubekey/v3/cmd/kk/cmd/init"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/options"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/cmd/plugin"
@@ -115,6 +116,7 @@ func NewKubeKeyCommand(o KubeKeyOptions) *cobra.Command {
 	cmds.AddCommand(add.NewCmdAdd())
 	cmds.AddCommand(upgrade.NewCmdUpgrade())
 	cmds.AddCommand(cert.NewCmdCerts())
+	cmds.AddCommand(etcd.NewCmdEtcd())
 	cmds.AddCommand(artifact.NewCmdArtifact())
 
 	cmds.AddCommand(plugin.NewCmdPlugin(o.IOStreams))
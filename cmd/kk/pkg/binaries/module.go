@@ -28,6 +28,11 @@ type NodeBinariesModule struct {
 	common.KubeModule
 }
 
+// IsStateful the binaries info is cached for the following modules, and the downloaded binaries are not downloaded again.
+func (n *NodeBinariesModule) IsStateful() bool {
+	return true
+}
+
 func (n *NodeBinariesModule) Init() {
 	n.Name = "NodeBinariesModule"
 	n.Desc = "Download installation binaries"
@@ -47,6 +52,11 @@ type K3sNodeBinariesModule struct {
 	common.KubeModule
 }
 
+// IsStateful the binaries info is cached for the following modules.
+func (k *K3sNodeBinariesModule) IsStateful() bool {
+	return true
+}
+
 func (k *K3sNodeBinariesModule) Init() {
 	k.Name = "K3sNodeBinariesModule"
 	k.Desc = "Download installation binaries"
@@ -66,6 +76,11 @@ type K8eNodeBinariesModule struct {
 	common.KubeModule
 }
 
+// IsStateful the binaries info is cached for the following modules.
+func (k *K8eNodeBinariesModule) IsStateful() bool {
+	return true
+}
+
 func (k *K8eNodeBinariesModule) Init() {
 	k.Name = "K8eNodeBinariesModule"
 	k.Desc = "Download installation binaries"
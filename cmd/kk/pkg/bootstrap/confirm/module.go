@@ -31,6 +31,11 @@ func (i *InstallConfirmModule) IsSkip() bool {
 	return i.Skip
 }
 
+// IsStateful a resumed installation is confirmed again.
+func (i *InstallConfirmModule) IsStateful() bool {
+	return true
+}
+
 func (i *InstallConfirmModule) Init() {
 	i.Name = "ConfirmModule"
 	i.Desc = "Display confirmation form"
@@ -94,6 +99,30 @@ func (d *DeleteNodeConfirmModule) Init() {
 	}
 }
 
+type ETCDRestoreConfirmModule struct {
+	common.KubeModule
+	Skip bool
+}
+
+func (e *ETCDRestoreConfirmModule) IsSkip() bool {
+	return e.Skip
+}
+
+func (e *ETCDRestoreConfirmModule) Init() {
+	e.Name = "ETCDRestoreConfirmModule"
+	e.Desc = "Display etcd restore confirmation form"
+
+	display := &task.LocalTask{
+		Name:   "ConfirmForm",
+		Desc:   "Display confirmation form",
+		Action: new(ETCDRestoreConfirm),
+	}
+
+	e.Tasks = []task.Interface{
+		display,
+	}
+}
+
 type UpgradeConfirmModule struct {
 	common.KubeModule
 	Skip bool
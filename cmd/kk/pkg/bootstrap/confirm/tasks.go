@@ -204,6 +204,30 @@ func (d *DeleteConfirm) Execute(runtime connector.Runtime) error {
 	return nil
 }
 
+type ETCDRestoreConfirm struct {
+	common.KubeAction
+}
+
+func (e *ETCDRestoreConfirm) Execute(runtime connector.Runtime) error {
+	fmt.Println("kube-apiserver and etcd will be stopped on all nodes, and the etcd data will be replaced by the snapshot.")
+	fmt.Println("The changes after the snapshot was taken will be lost. The old etcd data is kept in the data dir with a timestamp suffix.")
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Printf("Are you sure to restore etcd? [yes/no]: ")
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToLower(strings.TrimSpace(input)) {
+		case "yes", "y":
+			return nil
+		case "no", "n":
+			os.Exit(0)
+		}
+	}
+}
+
 type UpgradeConfirm struct {
 	common.KubeAction
 }
@@ -107,8 +107,8 @@ func (c *ClearNodeOSModule) Init() {
 	stopKubelet := &task.RemoteTask{
 		Name:     "StopKubelet",
 		Desc:     "Stop Kubelet",
-		Hosts:    c.Runtime.GetHostsByRole(common.Worker),
-		Prepare:  new(DeleteNode),
+		Hosts:    c.Runtime.GetHostsByRole(common.K8s),
+		Prepare:  new(common.OnlyDeleteNode),
 		Action:   new(StopKubelet),
 		Parallel: true,
 	}
@@ -116,17 +116,29 @@ func (c *ClearNodeOSModule) Init() {
 	resetNetworkConfig := &task.RemoteTask{
 		Name:     "ResetNetworkConfig",
 		Desc:     "Reset os network config",
-		Hosts:    c.Runtime.GetHostsByRole(common.Worker),
-		Prepare:  new(DeleteNode),
+		Hosts:    c.Runtime.GetHostsByRole(common.K8s),
+		Prepare:  new(common.OnlyDeleteNode),
 		Action:   new(ResetNetworkConfig),
 		Parallel: true,
 	}
 
+	uninstallETCD := &task.RemoteTask{
+		Name:  "UninstallETCD",
+		Desc:  "Uninstall etcd",
+		Hosts: c.Runtime.GetHostsByRole(common.ETCD),
+		Prepare: &prepare.PrepareCollection{
+			new(common.OnlyDeleteNode),
+			new(EtcdTypeIsKubeKey),
+		},
+		Action:   new(UninstallETCD),
+		Parallel: true,
+	}
+
 	removeFiles := &task.RemoteTask{
 		Name:     "RemoveFiles",
 		Desc:     "Remove node files",
-		Hosts:    c.Runtime.GetHostsByRole(common.Worker),
-		Prepare:  new(DeleteNode),
+		Hosts:    c.Runtime.GetAllHosts(),
+		Prepare:  new(common.OnlyDeleteNode),
 		Action:   new(RemoveNodeFiles),
 		Parallel: true,
 	}
@@ -134,17 +146,26 @@ func (c *ClearNodeOSModule) Init() {
 	daemonReload := &task.RemoteTask{
 		Name:     "DaemonReload",
 		Desc:     "Systemd daemon reload",
-		Hosts:    c.Runtime.GetHostsByRole(common.Worker),
-		Prepare:  new(DeleteNode),
+		Hosts:    c.Runtime.GetAllHosts(),
+		Prepare:  new(common.OnlyDeleteNode),
 		Action:   new(DaemonReload),
 		Parallel: true,
 	}
 
+	// the following modules, such as load balancer, are generated without the deleted node.
+	removeNodeRoles := &task.LocalTask{
+		Name:   "RemoveNodeRoles",
+		Desc:   "Remove the deleted node from cluster roles",
+		Action: new(RemoveNodeRoles),
+	}
+
 	c.Tasks = []task.Interface{
 		stopKubelet,
 		resetNetworkConfig,
+		uninstallETCD,
 		removeFiles,
 		daemonReload,
+		removeNodeRoles,
 	}
 }
 
@@ -218,6 +218,23 @@ func (s *UninstallETCD) Execute(runtime connector.Runtime) error {
 	return nil
 }
 
+type RemoveNodeRoles struct {
+	common.KubeAction
+}
+
+func (r *RemoveNodeRoles) Execute(runtime connector.Runtime) error {
+	nodeName, ok := r.PipelineCache.GetMustString(common.DstNode)
+	if !ok {
+		return errors.New("get the node to delete by pipeline cache failed")
+	}
+	for _, host := range runtime.GetAllHosts() {
+		if host.GetName() == nodeName {
+			runtime.RoleMapDelete(host)
+		}
+	}
+	return nil
+}
+
 type RemoveNodeFiles struct {
 	common.KubeAction
 }
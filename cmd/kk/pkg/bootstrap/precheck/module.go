@@ -29,6 +29,11 @@ type GreetingsModule struct {
 	module.BaseTaskModule
 }
 
+// IsStateful the connection of hosts is always checked.
+func (h *GreetingsModule) IsStateful() bool {
+	return true
+}
+
 func (h *GreetingsModule) Init() {
 	h.Name = "GreetingsModule"
 	h.Desc = "Greetings"
@@ -61,6 +66,11 @@ func (n *NodePreCheckModule) IsSkip() bool {
 	return n.Skip
 }
 
+// IsStateful the result of precheck is used by the confirm module.
+func (n *NodePreCheckModule) IsStateful() bool {
+	return true
+}
+
 func (n *NodePreCheckModule) Init() {
 	n.Name = "NodePreCheckModule"
 	n.Desc = "Do pre-check on cluster nodes"
@@ -18,14 +18,18 @@ package certs
 
 import (
 	"path/filepath"
+	"time"
 
 	versionutil "k8s.io/apimachinery/pkg/util/version"
 
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/certs/templates"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/action"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/task"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/util"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/etcd"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/kubernetes"
 )
 
@@ -45,8 +49,17 @@ func (c *CheckCertsModule) Init() {
 		Parallel: true,
 	}
 
+	checkNodeCerts := &task.RemoteTask{
+		Name:     "CheckNodeCerts",
+		Desc:     "Check kubelet client and etcd certs",
+		Hosts:    c.Runtime.GetAllHosts(),
+		Action:   new(ListNodeCerts),
+		Parallel: true,
+	}
+
 	c.Tasks = []task.Interface{
 		check,
+		checkNodeCerts,
 	}
 }
 
@@ -71,6 +84,11 @@ func (p *PrintClusterCertsModule) Init() {
 
 type RenewCertsModule struct {
 	common.KubeModule
+	Skip bool
+}
+
+func (r *RenewCertsModule) IsSkip() bool {
+	return r.Skip
 }
 
 func (r *RenewCertsModule) Init() {
@@ -101,6 +119,175 @@ func (r *RenewCertsModule) Init() {
 	}
 }
 
+// RotateCAModule rotates the kubernetes, front-proxy and KubeKey-managed etcd CAs in two phases. The rotation is
+// started with a bundle of the new and old CAs, and it's finished by removing the old CAs from the bundle.
+type RotateCAModule struct {
+	common.KubeModule
+	Skip   bool
+	Finish bool
+}
+
+func (r *RotateCAModule) IsSkip() bool {
+	return r.Skip
+}
+
+func (r *RotateCAModule) Init() {
+	r.Name = "RotateCAModule"
+	r.Desc = "Rotate cluster CAs"
+
+	etcdKubeKey := r.KubeConf.Cluster.Etcd.Type == kubekeyapiv1alpha2.KubeKey
+
+	fetchCA := &task.RemoteTask{
+		Name:     "FetchCA",
+		Desc:     "Fetch kubernetes CAs",
+		Hosts:    r.Runtime.GetHostsByRole(common.Master),
+		Prepare:  new(common.OnlyFirstMaster),
+		Action:   new(FetchCA),
+		Parallel: true,
+	}
+
+	fetchETCDCA := &task.RemoteTask{
+		Name:     "FetchETCDCA",
+		Desc:     "Fetch etcd CA",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Prepare:  new(etcd.FirstETCDNode),
+		Action:   &FetchCA{ETCD: true},
+		Parallel: true,
+	}
+
+	generateCABundle := &task.LocalTask{
+		Name:   "GenerateCABundle",
+		Desc:   "Generate CA bundle",
+		Action: &GenerateCABundle{Finish: r.Finish},
+	}
+
+	caHosts := make([]connector.Host, 0)
+	for _, host := range r.Runtime.GetAllHosts() {
+		if host.IsRole(common.K8s) || (etcdKubeKey && host.IsRole(common.ETCD)) {
+			caHosts = append(caHosts, host)
+		}
+	}
+	syncCA := &task.RemoteTask{
+		Name:     "SyncCA",
+		Desc:     "Synchronize CA bundle",
+		Hosts:    caHosts,
+		Action:   &SyncCA{BackupSuffix: time.Now().Format("20060102150405")},
+		Parallel: true,
+		Retry:    1,
+	}
+
+	updateKubeConfigCA := &task.RemoteTask{
+		Name:     "UpdateKubeConfigCA",
+		Desc:     "Update CA of kubeconfig",
+		Hosts:    r.Runtime.GetHostsByRole(common.K8s),
+		Action:   new(UpdateKubeConfigCA),
+		Parallel: true,
+	}
+
+	copyKubeConfig := &task.RemoteTask{
+		Name:     "CopyKubeConfig",
+		Desc:     "Copy admin.conf to ~/.kube/config",
+		Hosts:    r.Runtime.GetHostsByRole(common.Master),
+		Action:   new(kubernetes.CopyKubeConfigForControlPlane),
+		Parallel: true,
+		Retry:    2,
+	}
+
+	rollingRestartETCD := &task.RemoteTask{
+		Name:     "RollingRestartETCD",
+		Desc:     "Restart etcd one by one",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Action:   new(etcd.RollingRestartETCD),
+		Parallel: false,
+	}
+
+	restartControlPlane := &task.RemoteTask{
+		Name:     "RestartControlPlane",
+		Desc:     "Restart control plane one by one",
+		Hosts:    r.Runtime.GetHostsByRole(common.Master),
+		Action:   new(RestartControlPlane),
+		Parallel: false,
+	}
+
+	restartKubelet := &task.RemoteTask{
+		Name:     "RestartKubelet",
+		Desc:     "Restart kubelet",
+		Hosts:    r.Runtime.GetHostsByRole(common.K8s),
+		Prepare:  new(common.OnlyWorker),
+		Action:   new(RestartKubelet),
+		Parallel: true,
+	}
+
+	updateClusterInfo := &task.RemoteTask{
+		Name:     "UpdateClusterInfo",
+		Desc:     "Update CA of cluster-info",
+		Hosts:    r.Runtime.GetHostsByRole(common.Master),
+		Prepare:  new(common.OnlyFirstMaster),
+		Action:   new(UpdateClusterInfo),
+		Parallel: true,
+		Retry:    5,
+	}
+
+	renewKubeletClientCert := &task.RemoteTask{
+		Name:     "RenewKubeletClientCert",
+		Desc:     "Renew kubelet client cert with the new CA",
+		Hosts:    r.Runtime.GetHostsByRole(common.K8s),
+		Action:   new(RenewKubeletClientCert),
+		Parallel: true,
+	}
+
+	r.Tasks = []task.Interface{fetchCA}
+	if etcdKubeKey {
+		r.Tasks = append(r.Tasks, fetchETCDCA)
+	}
+	r.Tasks = append(r.Tasks,
+		generateCABundle,
+		syncCA,
+		updateKubeConfigCA,
+		copyKubeConfig,
+	)
+	if etcdKubeKey {
+		r.Tasks = append(r.Tasks, rollingRestartETCD)
+	}
+	r.Tasks = append(r.Tasks,
+		restartControlPlane,
+		restartKubelet,
+		updateClusterInfo,
+	)
+	// the kubelet client certs signed by the old CA must be replaced before the old CA is removed.
+	if !r.Finish {
+		r.Tasks = append(r.Tasks, renewKubeletClientCert)
+	}
+}
+
+// RestartSystemWorkloadsModule restarts the workloads in kube-system to load the new CA bundle.
+type RestartSystemWorkloadsModule struct {
+	common.KubeModule
+	Skip bool
+}
+
+func (r *RestartSystemWorkloadsModule) IsSkip() bool {
+	return r.Skip
+}
+
+func (r *RestartSystemWorkloadsModule) Init() {
+	r.Name = "RestartSystemWorkloadsModule"
+	r.Desc = "Restart the workloads in kube-system"
+
+	restart := &task.RemoteTask{
+		Name:     "RestartSystemWorkloads",
+		Desc:     "Restart the deployments and daemonsets in kube-system",
+		Hosts:    r.Runtime.GetHostsByRole(common.Master),
+		Prepare:  new(common.OnlyFirstMaster),
+		Action:   new(RestartSystemWorkloads),
+		Parallel: true,
+	}
+
+	r.Tasks = []task.Interface{
+		restart,
+	}
+}
+
 type AutoRenewCertsModule struct {
 	common.KubeModule
 	Skip bool
@@ -0,0 +1,398 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package certs
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/clientcmd"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/utils"
+	certsutil "github.com/kubesphere/kubekey/v3/cmd/kk/pkg/utils/certs"
+)
+
+// caFile is a CA cert and its key in the remote dir.
+type caFile struct {
+	Dir  string
+	Cert string
+	Key  string
+}
+
+var (
+	kubeCAs = []caFile{
+		{Dir: common.KubeCertDir, Cert: "ca.crt", Key: "ca.key"},
+		{Dir: common.KubeCertDir, Cert: "front-proxy-ca.crt", Key: "front-proxy-ca.key"},
+	}
+	etcdCA = caFile{Dir: common.ETCDCertDir, Cert: "ca.pem", Key: "ca-key.pem"}
+
+	kubeletKubeConfig = filepath.Join(common.KubeConfigDir, "kubelet.conf")
+)
+
+// rotateCADir returns the local dir of the CAs in rotation, name is kubernetes or etcd.
+func rotateCADir(runtime connector.Runtime, name string) string {
+	return filepath.Join(runtime.GetWorkDir(), "pki", "rotate-ca", name)
+}
+
+// FetchCA fetches the kubernetes CAs, or the etcd CA if ETCD is true.
+type FetchCA struct {
+	common.KubeAction
+	ETCD bool
+}
+
+func (f *FetchCA) Execute(runtime connector.Runtime) error {
+	dir := rotateCADir(runtime, "kubernetes")
+	cas := kubeCAs
+	if f.ETCD {
+		dir = rotateCADir(runtime, "etcd")
+		cas = []caFile{etcdCA}
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return errors.Wrapf(err, "create dir %s failed", dir)
+	}
+
+	for _, ca := range cas {
+		for _, name := range []string{ca.Cert, ca.Key} {
+			local := filepath.Join(dir, name)
+			if err := runtime.GetRunner().Fetch(local, filepath.Join(ca.Dir, name)); err != nil {
+				return errors.Wrapf(errors.WithStack(err), "fetch %s failed", filepath.Join(ca.Dir, name))
+			}
+			if err := os.Chmod(local, 0600); err != nil {
+				return errors.Wrapf(err, "chmod %s failed", local)
+			}
+		}
+	}
+	return nil
+}
+
+// GenerateCABundle generates the new CAs. When the rotation is started, the cert file of each CA is a bundle of
+// the new CA and the old one, and the key is the new one, so that the certs signed by both CAs are trusted.
+// When the rotation is finished, the old CA is removed from the bundle.
+type GenerateCABundle struct {
+	common.KubeAction
+	Finish bool
+}
+
+func (g *GenerateCABundle) Execute(runtime connector.Runtime) error {
+	if c := g.KubeConf.Cluster.Certificates; c.Kubernetes != nil || c.FrontProxy != nil || c.Etcd != nil {
+		return errors.New("the CAs provided in spec.certificates can not be rotated by KubeKey, please replace them in spec.certificates")
+	}
+	dir := rotateCADir(runtime, "kubernetes")
+	for _, ca := range kubeCAs {
+		if err := rotateCA(filepath.Join(dir, ca.Cert), filepath.Join(dir, ca.Key), g.Finish); err != nil {
+			return err
+		}
+	}
+	if g.KubeConf.Cluster.Etcd.Type == kubekeyapiv1alpha2.KubeKey {
+		dir := rotateCADir(runtime, "etcd")
+		if err := rotateCA(filepath.Join(dir, etcdCA.Cert), filepath.Join(dir, etcdCA.Key), g.Finish); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rotateCA starts or finishes the rotation of the CA in certPath and keyPath.
+func rotateCA(certPath, keyPath string, finish bool) error {
+	caCerts, err := certutil.CertsFromFile(certPath)
+	if err != nil {
+		return errors.Wrapf(err, "load CA %s failed", certPath)
+	}
+
+	if finish {
+		if len(caCerts) < 2 {
+			return errors.Errorf("CA %s is not in rotation, please start the rotation by --rotate-ca first", certPath)
+		}
+		key, err := keyutil.PrivateKeyFromFile(keyPath)
+		if err != nil {
+			return errors.Wrapf(err, "load CA key %s failed", keyPath)
+		}
+		if !certsutil.KeyMatchesCert(caCerts[0], key) {
+			return errors.Errorf("the first cert in %s does not match the key %s", certPath, keyPath)
+		}
+		return errors.Wrapf(certutil.WriteCert(certPath, certsutil.EncodeCertPEM(caCerts[0])), "write CA %s failed", certPath)
+	}
+
+	if len(caCerts) > 1 {
+		return errors.Errorf("CA %s is already in rotation, please finish the rotation by --finish-ca-rotation first", certPath)
+	}
+	oldCA := caCerts[0]
+	newCA, newKey, err := certsutil.NewCertificateAuthority(&certsutil.CertConfig{
+		Config: certutil.Config{
+			CommonName:   oldCA.Subject.CommonName,
+			Organization: oldCA.Subject.Organization,
+		},
+		PublicKeyAlgorithm: oldCA.PublicKeyAlgorithm,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "generate new CA for %s failed", certPath)
+	}
+	keyPEM, err := keyutil.MarshalPrivateKeyToPEM(newKey)
+	if err != nil {
+		return errors.Wrapf(err, "marshal new CA key for %s failed", certPath)
+	}
+	if err := keyutil.WriteKey(keyPath, keyPEM); err != nil {
+		return errors.Wrapf(err, "write CA key %s failed", keyPath)
+	}
+	bundle := append(certsutil.EncodeCertPEM(newCA), certsutil.EncodeCertPEM(oldCA)...)
+	return errors.Wrapf(certutil.WriteCert(certPath, bundle), "write CA %s failed", certPath)
+}
+
+// SyncCA backups the cert dirs with BackupSuffix, then synchronizes the CAs in rotation to the host.
+type SyncCA struct {
+	common.KubeAction
+	BackupSuffix string
+}
+
+func (s *SyncCA) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+
+	// local to remote
+	files := make(map[string]string)
+	dirs := make([]string, 0, 2)
+	if host.IsRole(common.K8s) {
+		dir := rotateCADir(runtime, "kubernetes")
+		files[filepath.Join(dir, "ca.crt")] = filepath.Join(common.KubeCertDir, "ca.crt")
+		if host.IsRole(common.Master) {
+			for _, ca := range kubeCAs {
+				files[filepath.Join(dir, ca.Cert)] = filepath.Join(ca.Dir, ca.Cert)
+				files[filepath.Join(dir, ca.Key)] = filepath.Join(ca.Dir, ca.Key)
+			}
+		}
+		dirs = append(dirs, common.KubeCertDir)
+	}
+	if s.KubeConf.Cluster.Etcd.Type == kubekeyapiv1alpha2.KubeKey && (host.IsRole(common.ETCD) || host.IsRole(common.Master)) {
+		dir := rotateCADir(runtime, "etcd")
+		files[filepath.Join(dir, etcdCA.Cert)] = filepath.Join(etcdCA.Dir, etcdCA.Cert)
+		files[filepath.Join(dir, etcdCA.Key)] = filepath.Join(etcdCA.Dir, etcdCA.Key)
+		dirs = append(dirs, common.ETCDCertDir)
+	}
+
+	for _, dir := range dirs {
+		backup := fmt.Sprintf("%s.bak-%s", dir, s.BackupSuffix)
+		if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("if [ ! -d %[2]s ]; then cp -a %[1]s %[2]s; fi", dir, backup), false); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "backup %s failed", dir)
+		}
+	}
+	for local, remote := range files {
+		if err := runtime.GetRunner().SudoScp(local, remote); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "sync %s failed", remote)
+		}
+	}
+	return nil
+}
+
+// UpdateKubeConfigCA updates the CA of the kubeconfig files on the host with /etc/kubernetes/pki/ca.crt.
+type UpdateKubeConfigCA struct {
+	common.KubeAction
+}
+
+func (u *UpdateKubeConfigCA) Execute(runtime connector.Runtime) error {
+	kubeConfigs := append([]string{"super-admin.conf", "kubelet.conf"}, kubeConfigList...)
+	for _, name := range kubeConfigs {
+		kubeConfig := filepath.Join(common.KubeConfigDir, name)
+		if exist, err := runtime.GetRunner().FileExist(kubeConfig); err != nil {
+			return err
+		} else if !exist {
+			continue
+		}
+
+		cluster, err := runtime.GetRunner().SudoCmd(
+			fmt.Sprintf("%s/kubectl config view --kubeconfig=%s -o jsonpath='{.clusters[0].name}'", common.BinDir, kubeConfig), false)
+		if err != nil {
+			return errors.Wrapf(errors.WithStack(err), "get cluster name of %s failed", kubeConfig)
+		}
+		setClusterCmd := fmt.Sprintf("%s/kubectl config set-cluster %s --certificate-authority=%s/ca.crt --embed-certs=true --kubeconfig=%s",
+			common.BinDir, strings.TrimSpace(cluster), common.KubeCertDir, kubeConfig)
+		if _, err := runtime.GetRunner().SudoCmd(setClusterCmd, false); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "update CA of %s failed", kubeConfig)
+		}
+	}
+	return nil
+}
+
+// UpdateClusterInfo updates the CA in the cluster-info ConfigMap, which is used by the joining nodes to discover the cluster.
+type UpdateClusterInfo struct {
+	common.KubeAction
+}
+
+func (u *UpdateClusterInfo) Execute(runtime connector.Runtime) error {
+	if err := utils.ResetTmpDir(runtime); err != nil {
+		return err
+	}
+
+	out, err := runtime.GetRunner().SudoCmd(
+		fmt.Sprintf("%s/kubectl -n kube-public get configmap cluster-info -o jsonpath='{.data.kubeconfig}'", common.BinDir), false)
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "get cluster-info failed")
+	}
+	config, err := clientcmd.Load([]byte(strings.ReplaceAll(out, "\r\n", "\n")))
+	if err != nil {
+		return errors.Wrap(err, "parse kubeconfig of cluster-info failed")
+	}
+
+	caBundle, err := os.ReadFile(filepath.Join(rotateCADir(runtime, "kubernetes"), "ca.crt"))
+	if err != nil {
+		return errors.Wrap(err, "read CA failed")
+	}
+	for _, cluster := range config.Clusters {
+		cluster.CertificateAuthorityData = caBundle
+	}
+	kubeConfig, err := clientcmd.Write(*config)
+	if err != nil {
+		return errors.Wrap(err, "encode kubeconfig of cluster-info failed")
+	}
+
+	// only the kubeconfig is applied, the signatures are updated by the bootstrap signer of kube-controller-manager.
+	configMap, err := json.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]string{"name": "cluster-info", "namespace": "kube-public"},
+		"data":       map[string]string{"kubeconfig": string(kubeConfig)},
+	})
+	if err != nil {
+		return errors.Wrap(err, "encode cluster-info failed")
+	}
+	local := filepath.Join(runtime.GetWorkDir(), "pki", "rotate-ca", "cluster-info.json")
+	if err := os.WriteFile(local, configMap, 0644); err != nil {
+		return errors.Wrapf(err, "write %s failed", local)
+	}
+	remote := filepath.Join(common.TmpDir, "cluster-info.json")
+	if err := runtime.GetRunner().Scp(local, remote); err != nil {
+		return errors.Wrap(errors.WithStack(err), "scp cluster-info failed")
+	}
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("%s/kubectl apply -f %s", common.BinDir, remote), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "update cluster-info failed")
+	}
+	return nil
+}
+
+// RenewKubeletClientCert signs the kubelet client cert with the new CA, and points kubelet.conf to it.
+type RenewKubeletClientCert struct {
+	common.KubeAction
+}
+
+func (r *RenewKubeletClientCert) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+
+	dir := rotateCADir(runtime, "kubernetes")
+	caCerts, err := certutil.CertsFromFile(filepath.Join(dir, "ca.crt"))
+	if err != nil {
+		return errors.Wrap(err, "load CA failed")
+	}
+	caKey, err := keyutil.PrivateKeyFromFile(filepath.Join(dir, "ca.key"))
+	if err != nil {
+		return errors.Wrap(err, "load CA key failed")
+	}
+	signer, ok := caKey.(crypto.Signer)
+	if !ok {
+		return errors.New("the CA key is not a signer")
+	}
+	cert, key, err := certsutil.NewCertAndKey(caCerts[0], signer, &certsutil.CertConfig{
+		Config: certutil.Config{
+			CommonName:   fmt.Sprintf("system:node:%s", host.GetName()),
+			Organization: []string{"system:nodes"},
+			Usages:       []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		},
+		PublicKeyAlgorithm: x509.ECDSA,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "sign kubelet client cert for %s failed", host.GetName())
+	}
+	keyPEM, err := keyutil.MarshalPrivateKeyToPEM(key)
+	if err != nil {
+		return errors.Wrap(err, "marshal kubelet client key failed")
+	}
+
+	fileName := fmt.Sprintf("kubelet-client-%s.pem", time.Now().Format("2006-01-02-15-04-05"))
+	local := filepath.Join(runtime.GetWorkDir(), host.GetName(), fileName)
+	if err := os.MkdirAll(filepath.Dir(local), 0700); err != nil {
+		return errors.Wrapf(err, "create dir %s failed", filepath.Dir(local))
+	}
+	if err := os.WriteFile(local, append(certsutil.EncodeCertPEM(cert), keyPEM...), 0600); err != nil {
+		return errors.Wrapf(err, "write %s failed", local)
+	}
+	remote := filepath.Join(filepath.Dir(kubeletClientCert), fileName)
+	if err := runtime.GetRunner().SudoScp(local, remote); err != nil {
+		return errors.Wrap(errors.WithStack(err), "sync kubelet client cert failed")
+	}
+
+	user, err := runtime.GetRunner().SudoCmd(
+		fmt.Sprintf("%s/kubectl config view --kubeconfig=%s -o jsonpath='{.users[0].name}'", common.BinDir, kubeletKubeConfig), false)
+	if err != nil {
+		return errors.Wrapf(errors.WithStack(err), "get user name of %s failed", kubeletKubeConfig)
+	}
+	cmds := []string{
+		fmt.Sprintf("chmod 600 %s", remote),
+		fmt.Sprintf("ln -sf %s %s", remote, kubeletClientCert),
+		// the client cert may be embedded in kubelet.conf by the old version of kubeadm.
+		fmt.Sprintf("%s/kubectl config set-credentials %s --client-certificate=%s --client-key=%s --kubeconfig=%s",
+			common.BinDir, strings.TrimSpace(user), kubeletClientCert, kubeletClientCert, kubeletKubeConfig),
+		"systemctl restart kubelet",
+	}
+	if _, err := runtime.GetRunner().SudoCmd(strings.Join(cmds, " && "), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "update kubelet client cert failed")
+	}
+	return nil
+}
+
+// RestartControlPlane restarts the control plane components and kubelet, and waits until kube-apiserver is ready.
+type RestartControlPlane struct {
+	common.KubeAction
+}
+
+func (r *RestartControlPlane) Execute(runtime connector.Runtime) error {
+	return restartControlPlane(runtime, r.KubeConf.Cluster.Kubernetes.ContainerManager)
+}
+
+type RestartKubelet struct {
+	common.KubeAction
+}
+
+func (r *RestartKubelet) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd("systemctl restart kubelet", false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "restart kubelet failed")
+	}
+	return nil
+}
+
+// RestartSystemWorkloads restarts the workloads in kube-system, so that they load the CA bundle of the service account again.
+type RestartSystemWorkloads struct {
+	common.KubeAction
+}
+
+func (r *RestartSystemWorkloads) Execute(runtime connector.Runtime) error {
+	for _, kind := range []string{"deployment", "daemonset"} {
+		cmd := fmt.Sprintf("%s/kubectl -n kube-system rollout restart %s", common.BinDir, kind)
+		if _, err := runtime.GetRunner().SudoCmd(cmd, false); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "restart %s in kube-system failed", kind)
+		}
+	}
+	return nil
+}
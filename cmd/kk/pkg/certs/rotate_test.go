@@ -0,0 +1,97 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package certs
+
+import (
+	"crypto/x509"
+	"path/filepath"
+	"testing"
+
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+
+	certsutil "github.com/kubesphere/kubekey/v3/cmd/kk/pkg/utils/certs"
+)
+
+func TestRotateCA(t *testing.T) {
+	dir := t.TempDir()
+	certPath, keyPath := filepath.Join(dir, "ca.crt"), filepath.Join(dir, "ca.key")
+
+	oldCA, oldKey, err := certsutil.NewCertificateAuthority(&certsutil.CertConfig{
+		Config:             certutil.Config{CommonName: "kubernetes"},
+		PublicKeyAlgorithm: x509.RSA,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM, err := keyutil.MarshalPrivateKeyToPEM(oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := keyutil.WriteKey(keyPath, keyPEM); err != nil {
+		t.Fatal(err)
+	}
+	if err := certutil.WriteCert(certPath, certsutil.EncodeCertPEM(oldCA)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := rotateCA(certPath, keyPath, true); err == nil {
+		t.Fatal("finish the rotation which is not started, want error")
+	}
+
+	if err := rotateCA(certPath, keyPath, false); err != nil {
+		t.Fatalf("start rotation: %v", err)
+	}
+	bundle := loadCerts(t, certPath)
+	if len(bundle) != 2 {
+		t.Fatalf("got %d certs in bundle, want 2", len(bundle))
+	}
+	if !bundle[1].Equal(oldCA) {
+		t.Error("the old CA is not the second cert of bundle")
+	}
+	if bundle[0].Subject.CommonName != oldCA.Subject.CommonName {
+		t.Errorf("got CommonName %s of the new CA, want %s", bundle[0].Subject.CommonName, oldCA.Subject.CommonName)
+	}
+	key, err := keyutil.PrivateKeyFromFile(keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !certsutil.KeyMatchesCert(bundle[0], key) {
+		t.Error("the key does not match the new CA")
+	}
+
+	if err := rotateCA(certPath, keyPath, false); err == nil {
+		t.Fatal("start the rotation which is in progress, want error")
+	}
+
+	if err := rotateCA(certPath, keyPath, true); err != nil {
+		t.Fatalf("finish rotation: %v", err)
+	}
+	finished := loadCerts(t, certPath)
+	if len(finished) != 1 || !finished[0].Equal(bundle[0]) {
+		t.Error("only the new CA should be left after the rotation is finished")
+	}
+}
+
+func loadCerts(t *testing.T, path string) []*x509.Certificate {
+	t.Helper()
+	certs, err := certutil.CertsFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certs
+}
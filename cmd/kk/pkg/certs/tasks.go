@@ -32,6 +32,7 @@ import (
 	clientcmdlatest "k8s.io/client-go/tools/clientcmd/api/latest"
 	certutil "k8s.io/client-go/util/cert"
 
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/certs/templates"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
@@ -70,6 +71,11 @@ var (
 	}
 )
 
+const (
+	kubeletClientCert = "/var/lib/kubelet/pki/kubelet-client-current.pem"
+	etcdCAName        = "etcd-ca"
+)
+
 type ListClusterCerts struct {
 	common.KubeAction
 }
@@ -134,6 +140,81 @@ func (l *ListClusterCerts) Execute(runtime connector.Runtime) error {
 	return nil
 }
 
+// ListNodeCerts lists the kubelet client cert and the certs of KubeKey-managed etcd on the host.
+type ListNodeCerts struct {
+	common.KubeAction
+}
+
+func (l *ListNodeCerts) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+
+	certificates := make([]*Certificate, 0)
+	caCertificates := make([]*CaCertificate, 0)
+	if v, ok := host.GetCache().Get(common.Certificate); ok {
+		certificates = v.([]*Certificate)
+	}
+	if v, ok := host.GetCache().Get(common.CaCertificate); ok {
+		caCertificates = v.([]*CaCertificate)
+	}
+
+	certPaths := make([]string, 0)
+	if host.IsRole(common.K8s) {
+		certPaths = append(certPaths, kubeletClientCert)
+	}
+	isETCDCertsHost := host.IsRole(common.ETCD) || host.IsRole(common.Master)
+	if l.KubeConf.Cluster.Etcd.Type == kubekeyapiv1alpha2.KubeKey && isETCDCertsHost {
+		if host.IsRole(common.ETCD) {
+			certPaths = append(certPaths,
+				filepath.Join(common.ETCDCertDir, fmt.Sprintf("admin-%s.pem", host.GetName())),
+				filepath.Join(common.ETCDCertDir, fmt.Sprintf("member-%s.pem", host.GetName())))
+		}
+		if host.IsRole(common.Master) {
+			certPaths = append(certPaths, filepath.Join(common.ETCDCertDir, fmt.Sprintf("node-%s.pem", host.GetName())))
+		}
+
+		caCertContext, err := readCertFile(runtime, filepath.Join(common.ETCDCertDir, "ca.pem"))
+		if err != nil {
+			return err
+		}
+		if caCertContext != "" {
+			cert, err := getCaCertInfo(caCertContext, etcdCAName, host.GetName())
+			if err != nil {
+				return err
+			}
+			caCertificates = append(caCertificates, cert)
+		}
+	}
+
+	for _, certPath := range certPaths {
+		certContext, err := readCertFile(runtime, certPath)
+		if err != nil {
+			return err
+		}
+		// the cert does not exist, e.g. the kubelet client cert is not rotated by kubelet.
+		if certContext == "" {
+			continue
+		}
+		cert, err := getCertInfo(certContext, filepath.Base(certPath), host.GetName())
+		if err != nil {
+			return err
+		}
+		certificates = append(certificates, cert)
+	}
+
+	host.GetCache().Set(common.Certificate, certificates)
+	host.GetCache().Set(common.CaCertificate, caCertificates)
+	return nil
+}
+
+// readCertFile returns the content of the cert file, it's empty if the file does not exist.
+func readCertFile(runtime connector.Runtime, path string) (string, error) {
+	certContext, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("if [ -f %[1]s ]; then cat %[1]s; fi", path), false)
+	if err != nil {
+		return "", errors.Wrapf(err, "get cert %s failed", path)
+	}
+	return certContext, nil
+}
+
 func getCertInfo(certContext, certFileName, nodeName string) (*Certificate, error) {
 	certs, err1 := certutil.ParseCertsPEM([]byte(certContext))
 	if err1 != nil {
@@ -147,8 +228,13 @@ func getCertInfo(certContext, certFileName, nodeName string) (*Certificate, erro
 		authorityName = "ca"
 	case "front-proxy-client.crt":
 		authorityName = "front-proxy-ca"
+	case filepath.Base(kubeletClientCert):
+		authorityName = "ca"
 	default:
-		authorityName = ""
+		if strings.HasPrefix(certFileName, "admin-") || strings.HasPrefix(certFileName, "member-") ||
+			strings.HasPrefix(certFileName, "node-") {
+			authorityName = etcdCAName
+		}
 	}
 	cert := Certificate{
 		Name:          certFileName,
@@ -200,10 +286,14 @@ func (d *DisplayForm) Execute(runtime connector.Runtime) error {
 	certificates := make([]*Certificate, 0)
 	caCertificates := make([]*CaCertificate, 0)
 
-	for _, host := range runtime.GetHostsByRole(common.Master) {
+	for _, host := range runtime.GetAllHosts() {
 		certs, ok := host.GetCache().Get(common.Certificate)
 		if !ok {
-			return errors.New("get certificate failed by pipeline cache")
+			// the certs are only listed on the kubernetes and etcd nodes.
+			if host.IsRole(common.Master) {
+				return errors.New("get certificate failed by pipeline cache")
+			}
+			continue
 		}
 		ca, ok := host.GetCache().Get(common.CaCertificate)
 		if !ok {
@@ -252,29 +342,23 @@ type RenewCerts struct {
 }
 
 func (r *RenewCerts) Execute(runtime connector.Runtime) error {
-	var kubeadmAlphaList = []string{
-		"/usr/local/bin/kubeadm alpha certs renew apiserver",
-		"/usr/local/bin/kubeadm alpha certs renew apiserver-kubelet-client",
-		"/usr/local/bin/kubeadm alpha certs renew front-proxy-client",
-		"/usr/local/bin/kubeadm alpha certs renew admin.conf",
-		"/usr/local/bin/kubeadm alpha certs renew controller-manager.conf",
-		"/usr/local/bin/kubeadm alpha certs renew scheduler.conf",
+	renewList := []string{
+		"apiserver",
+		"apiserver-kubelet-client",
+		"front-proxy-client",
+		"admin.conf",
+		"controller-manager.conf",
+		"scheduler.conf",
 	}
-
-	var kubeadmList = []string{
-		"/usr/local/bin/kubeadm certs renew apiserver",
-		"/usr/local/bin/kubeadm certs renew apiserver-kubelet-client",
-		"/usr/local/bin/kubeadm certs renew front-proxy-client",
-		"/usr/local/bin/kubeadm certs renew admin.conf",
-		"/usr/local/bin/kubeadm certs renew controller-manager.conf",
-		"/usr/local/bin/kubeadm certs renew scheduler.conf",
+	// the front-proxy-client cert is renewed by the external signer.
+	if ca := r.KubeConf.Cluster.Certificates.FrontProxy; ca != nil && ca.Signer != nil {
+		renewList = append(renewList[:2], renewList[3:]...)
 	}
 
-	var restartList = []string{
-		"docker ps -af name=k8s_kube-apiserver* -q | xargs --no-run-if-empty docker rm -f",
-		"docker ps -af name=k8s_kube-scheduler* -q | xargs --no-run-if-empty docker rm -f",
-		"docker ps -af name=k8s_kube-controller-manager* -q | xargs --no-run-if-empty docker rm -f",
-		"systemctl restart kubelet",
+	var kubeadmAlphaList, kubeadmList []string
+	for _, name := range renewList {
+		kubeadmAlphaList = append(kubeadmAlphaList, fmt.Sprintf("/usr/local/bin/kubeadm alpha certs renew %s", name))
+		kubeadmList = append(kubeadmList, fmt.Sprintf("/usr/local/bin/kubeadm certs renew %s", name))
 	}
 
 	version, err := runtime.GetRunner().SudoCmd("/usr/local/bin/kubeadm version -o short", true)
@@ -297,11 +381,39 @@ func (r *RenewCerts) Execute(runtime connector.Runtime) error {
 		}
 	}
 
-	_, err = runtime.GetRunner().SudoCmd(strings.Join(restartList, " && "), false)
-	if err != nil {
+	return restartControlPlane(runtime, r.KubeConf.Cluster.Kubernetes.ContainerManager)
+}
+
+// restartControlPlane restarts kube-apiserver, kube-scheduler, kube-controller-manager and kubelet,
+// then waits until kube-apiserver is ready, so that the masters can be restarted one by one.
+func restartControlPlane(runtime connector.Runtime, containerManager string) error {
+	var restartList []string
+	if containerManager == common.Docker {
+		restartList = []string{
+			"docker ps -af name=k8s_kube-apiserver* -q | xargs --no-run-if-empty docker rm -f",
+			"docker ps -af name=k8s_kube-scheduler* -q | xargs --no-run-if-empty docker rm -f",
+			"docker ps -af name=k8s_kube-controller-manager* -q | xargs --no-run-if-empty docker rm -f",
+		}
+	} else {
+		restartList = []string{
+			fmt.Sprintf("%[1]s/crictl pods --namespace kube-system --name 'kube-scheduler-*|kube-controller-manager-*|kube-apiserver-*' -q | xargs --no-run-if-empty %[1]s/crictl rmp -f", common.BinDir),
+		}
+	}
+	restartList = append(restartList, "systemctl restart kubelet")
+
+	if _, err := runtime.GetRunner().SudoCmd(strings.Join(restartList, " && "), false); err != nil {
 		return errors.Wrap(err, "kube-apiserver, kube-schedule, kube-controller-manager or kubelet restart failed")
 	}
-	return nil
+
+	checkCmd := fmt.Sprintf("%s/kubectl --kubeconfig=%s/admin.conf get --raw=/readyz", common.BinDir, common.KubeConfigDir)
+	var err error
+	for i := 0; i < 30; i++ {
+		time.Sleep(5 * time.Second)
+		if _, err = runtime.GetRunner().SudoCmd(checkCmd, false); err == nil {
+			return nil
+		}
+	}
+	return errors.Wrapf(errors.WithStack(err), "kube-apiserver on %s is not ready after restart", runtime.RemoteHost().GetName())
 }
 
 type FetchKubeConfig struct {
@@ -89,6 +89,9 @@ const (
 	ETCDName    = "etcdName"
 	ETCDExist   = "etcdExist"
 
+	// DeleteNodeModule
+	DstNode = "dstNode"
+
 	// KubernetesModule
 	ClusterStatus = "clusterStatus"
 	ClusterExist  = "clusterExist"
@@ -17,6 +17,8 @@
 package common
 
 import (
+	"github.com/pkg/errors"
+
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/prepare"
 )
@@ -50,6 +52,23 @@ func (o *OnlyFirstMaster) PreCheck(runtime connector.Runtime) (bool, error) {
 	return o.Not, nil
 }
 
+// OnlyDeleteNode selects the node to delete, which is set by DstNode in pipeline cache.
+type OnlyDeleteNode struct {
+	KubePrepare
+	Not bool
+}
+
+func (o *OnlyDeleteNode) PreCheck(runtime connector.Runtime) (bool, error) {
+	nodeName, ok := o.PipelineCache.GetMustString(DstNode)
+	if !ok {
+		return false, errors.New("get the node to delete by pipeline cache failed")
+	}
+	if runtime.RemoteHost().GetName() == nodeName {
+		return !o.Not, nil
+	}
+	return o.Not, nil
+}
+
 type IsMaster struct {
 	KubePrepare
 }
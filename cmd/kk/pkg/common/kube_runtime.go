@@ -19,6 +19,7 @@ package common
 import (
 	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/pipeline"
 )
 
 type KubeRuntime struct {
@@ -60,6 +61,12 @@ type Argument struct {
 	EtcdUpgrade         bool
 	WithBuildx          bool
 	OnlyEtcd            bool
+	SnapshotPath        string
+	Resume              bool
+	FromModule          string
+	ToModule            string
+	RotateCA            bool
+	FinishCARotation    bool
 }
 
 func NewKubeRuntime(flag string, arg Argument) (*KubeRuntime, error) {
@@ -100,6 +107,16 @@ func NewKubeRuntime(flag string, arg Argument) (*KubeRuntime, error) {
 	return r, nil
 }
 
+// Checkpoint returns the checkpoint of pipeline by arguments. The completed modules are recorded even if not resume.
+func (k *KubeRuntime) Checkpoint() *pipeline.Checkpoint {
+	return &pipeline.Checkpoint{
+		Resume:     k.Arg.Resume,
+		FromModule: k.Arg.FromModule,
+		ToModule:   k.Arg.ToModule,
+		Config:     k.Cluster,
+	}
+}
+
 // Copy is used to create a copy for Runtime.
 func (k *KubeRuntime) Copy() connector.Runtime {
 	runtime := *k
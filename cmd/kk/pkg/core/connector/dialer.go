@@ -50,6 +50,11 @@ func (d *Dialer) Connect(host Host) (Connection, error) {
 			PrivateKey: host.GetPrivateKey(),
 			KeyFile:    host.GetPrivateKeyPath(),
 			Timeout:    time.Duration(host.GetTimeout()) * time.Second,
+
+			HostKeyChecking:     host.GetHostKeyChecking(),
+			KnownHosts:          host.GetKnownHosts(),
+			HostKeyFingerprints: host.GetHostKeyFingerprints(),
+			Bastions:            host.GetBastions(),
 		}
 		conn, err = NewConnection(opts)
 		if err != nil {
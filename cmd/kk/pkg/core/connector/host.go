@@ -33,6 +33,11 @@ type BaseHost struct {
 	Arch            string `yaml:"arch,omitempty" json:"arch,omitempty"`
 	Timeout         int64  `yaml:"timeout,omitempty" json:"timeout,omitempty"`
 
+	HostKeyChecking     string    `yaml:"hostKeyChecking,omitempty" json:"hostKeyChecking,omitempty"`
+	KnownHosts          string    `yaml:"knownHosts,omitempty" json:"knownHosts,omitempty"`
+	HostKeyFingerprints []string  `yaml:"hostKeyFingerprints,omitempty" json:"hostKeyFingerprints,omitempty"`
+	Bastions            []Bastion `yaml:"bastions,omitempty" json:"bastions,omitempty"`
+
 	Roles     []string        `json:"-"`
 	RoleTable map[string]bool `json:"-"`
 	Cache     *cache.Cache    `json:"-"`
@@ -123,6 +128,38 @@ func (b *BaseHost) SetPrivateKeyPath(path string) {
 	b.PrivateKeyPath = path
 }
 
+func (b *BaseHost) GetHostKeyChecking() string {
+	return b.HostKeyChecking
+}
+
+func (b *BaseHost) SetHostKeyChecking(checking string) {
+	b.HostKeyChecking = checking
+}
+
+func (b *BaseHost) GetKnownHosts() string {
+	return b.KnownHosts
+}
+
+func (b *BaseHost) SetKnownHosts(path string) {
+	b.KnownHosts = path
+}
+
+func (b *BaseHost) GetHostKeyFingerprints() []string {
+	return b.HostKeyFingerprints
+}
+
+func (b *BaseHost) SetHostKeyFingerprints(fingerprints []string) {
+	b.HostKeyFingerprints = fingerprints
+}
+
+func (b *BaseHost) GetBastions() []Bastion {
+	return b.Bastions
+}
+
+func (b *BaseHost) SetBastions(bastions []Bastion) {
+	b.Bastions = bastions
+}
+
 func (b *BaseHost) GetArch() string {
 	return b.Arch
 }
@@ -50,6 +50,7 @@ type ModuleRuntime interface {
 	SetAllHosts([]Host)
 	GetHostsByRole(role string) []Host
 	DeleteHost(host Host)
+	RoleMapDelete(host Host)
 	HostIsDeprecated(host Host) bool
 	InitLogger() error
 }
@@ -83,6 +84,14 @@ type Host interface {
 	SetPrivateKey(privateKey string)
 	GetPrivateKeyPath() string
 	SetPrivateKeyPath(path string)
+	GetHostKeyChecking() string
+	SetHostKeyChecking(checking string)
+	GetKnownHosts() string
+	SetKnownHosts(path string)
+	GetHostKeyFingerprints() []string
+	SetHostKeyFingerprints(fingerprints []string)
+	GetBastions() []Bastion
+	SetBastions(bastions []Bastion)
 	GetArch() string
 	SetArch(arch string)
 	GetTimeout() int64
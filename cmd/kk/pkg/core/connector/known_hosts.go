@@ -0,0 +1,120 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package connector
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/logger"
+)
+
+const (
+	// KnownHostsFile is the default known_hosts file name under the kubekey work dir.
+	KnownHostsFile = "known_hosts"
+
+	// HostKeyCheckingStrict only accepts the host keys recorded in known_hosts. It's the default mode.
+	HostKeyCheckingStrict = "yes"
+	// HostKeyCheckingAcceptNew records the keys of new hosts (trust on first use) and rejects changed keys.
+	// It must be set explicitly.
+	HostKeyCheckingAcceptNew = "accept-new"
+	// HostKeyCheckingNo disables the host key checking.
+	HostKeyCheckingNo = "no"
+)
+
+var knownHostsLock sync.Mutex
+
+func newHostKeyCallback(checking, knownHostsFile string, fingerprints []string) (ssh.HostKeyCallback, error) {
+	if len(fingerprints) > 0 {
+		return func(hostname string, _ net.Addr, key ssh.PublicKey) error {
+			fp := ssh.FingerprintSHA256(key)
+			for _, f := range fingerprints {
+				if f == fp {
+					return nil
+				}
+			}
+			return errors.Errorf("host key fingerprint %s of %s does not match the pinned fingerprints", fp, hostname)
+		}, nil
+	}
+
+	if checking == "" {
+		checking = HostKeyCheckingStrict
+	}
+	switch checking {
+	case HostKeyCheckingStrict, HostKeyCheckingAcceptNew:
+	case HostKeyCheckingNo:
+		return ssh.InsecureIgnoreHostKey(), nil
+	default:
+		return nil, errors.Errorf("unsupported host key checking %q", checking)
+	}
+
+	knownHostsLock.Lock()
+	defer knownHostsLock.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(knownHostsFile), os.ModePerm); err != nil {
+		return nil, errors.Wrapf(err, "Failed to create the dir of known_hosts %q", knownHostsFile)
+	}
+	f, err := os.OpenFile(knownHostsFile, os.O_CREATE|os.O_RDONLY, 0600)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to open known_hosts %q", knownHostsFile)
+	}
+	_ = f.Close()
+
+	callback, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to parse known_hosts %q", knownHostsFile)
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := callback(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if err == nil || !errors.As(err, &keyErr) {
+			return err
+		}
+		if len(keyErr.Want) > 0 {
+			return errors.Wrapf(err, "host key of %s has changed, remove the old key from known_hosts %q if it is expected", hostname, knownHostsFile)
+		}
+		if checking != HostKeyCheckingAcceptNew {
+			return errors.Wrapf(err, "host key of %s is unknown, add it to known_hosts %q or set hostKeyChecking to %q", hostname, knownHostsFile, HostKeyCheckingAcceptNew)
+		}
+		logger.Log.Debugf("add the host key %s of %s to known_hosts %s", ssh.FingerprintSHA256(key), hostname, knownHostsFile)
+		return appendKnownHost(knownHostsFile, hostname, key)
+	}, nil
+}
+
+func appendKnownHost(knownHostsFile, hostname string, key ssh.PublicKey) error {
+	knownHostsLock.Lock()
+	defer knownHostsLock.Unlock()
+
+	f, err := os.OpenFile(knownHostsFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to open known_hosts %q", knownHostsFile)
+	}
+	defer f.Close()
+
+	if _, err := fmt.Fprintln(f, knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key)); err != nil {
+		return errors.Wrapf(err, "Failed to write known_hosts %q", knownHostsFile)
+	}
+	return nil
+}
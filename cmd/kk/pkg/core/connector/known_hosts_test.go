@@ -0,0 +1,141 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package connector
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/logger"
+)
+
+func newTestHostKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// the cases are the same as the host key checking of v4 connector, keep them in sync.
+func TestNewHostKeyCallback(t *testing.T) {
+	logger.Log = logger.NewLogger(t.TempDir(), false)
+
+	hostname := "node1:22"
+	remote := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 22}
+	key := newTestHostKey(t)
+	changedKey := newTestHostKey(t)
+
+	t.Run("pinned fingerprints", func(t *testing.T) {
+		callback, err := newHostKeyCallback(HostKeyCheckingStrict, filepath.Join(t.TempDir(), KnownHostsFile), []string{ssh.FingerprintSHA256(key)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := callback(hostname, remote, key); err != nil {
+			t.Errorf("the pinned key is rejected: %v", err)
+		}
+		if err := callback(hostname, remote, changedKey); err == nil {
+			t.Error("the key which is not pinned should be rejected")
+		}
+	})
+
+	t.Run("unknown host in strict mode", func(t *testing.T) {
+		callback, err := newHostKeyCallback(HostKeyCheckingStrict, filepath.Join(t.TempDir(), KnownHostsFile), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := callback(hostname, remote, key); err == nil {
+			t.Error("the unknown host should be rejected in strict mode")
+		}
+	})
+
+	t.Run("trust on first use", func(t *testing.T) {
+		knownHosts := filepath.Join(t.TempDir(), KnownHostsFile)
+		callback, err := newHostKeyCallback(HostKeyCheckingAcceptNew, knownHosts, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := callback(hostname, remote, key); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(knownHosts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), "node1 ") {
+			t.Errorf("the host key is not recorded in known_hosts: %s", data)
+		}
+
+		// the recorded key is accepted in strict mode, and the changed key is rejected in any mode.
+		callback, err = newHostKeyCallback(HostKeyCheckingStrict, knownHosts, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := callback(hostname, remote, key); err != nil {
+			t.Errorf("the recorded key is rejected: %v", err)
+		}
+		callback, err = newHostKeyCallback(HostKeyCheckingAcceptNew, knownHosts, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := callback(hostname, remote, changedKey); err == nil || !strings.Contains(err.Error(), "has changed") {
+			t.Errorf("got error %v, want the changed key error", err)
+		}
+	})
+
+	t.Run("default mode is strict", func(t *testing.T) {
+		knownHosts := filepath.Join(t.TempDir(), KnownHostsFile)
+		callback, err := newHostKeyCallback("", knownHosts, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := callback(hostname, remote, key); err == nil || !strings.Contains(err.Error(), "is unknown") {
+			t.Errorf("got error %v, want the unknown host error", err)
+		}
+		if data, err := os.ReadFile(knownHosts); err == nil && len(data) != 0 {
+			t.Errorf("the unknown host should not be recorded by default: %s", data)
+		}
+	})
+
+	t.Run("no checking", func(t *testing.T) {
+		callback, err := newHostKeyCallback(HostKeyCheckingNo, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := callback(hostname, remote, key); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("unsupported mode", func(t *testing.T) {
+		if _, err := newHostKeyCallback("unknown", "", nil); err == nil {
+			t.Error("want error for unsupported mode")
+		}
+	})
+}
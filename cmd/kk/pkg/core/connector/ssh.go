@@ -53,16 +53,34 @@ type Cfg struct {
 	Bastion     string
 	BastionPort int
 	BastionUser string
+	// Bastions are the jump hosts to connect the host through, in order.
+	Bastions []Bastion
+
+	HostKeyChecking     string
+	KnownHosts          string
+	HostKeyFingerprints []string
 }
 
 const socketEnvPrefix = "env:"
 
+// Bastion is a jump host. The credentials of the target host are used when it has none.
+type Bastion struct {
+	Address        string
+	Port           int
+	User           string
+	Password       string
+	PrivateKey     string
+	PrivateKeyPath string
+}
+
 type connection struct {
 	mu         sync.Mutex
 	sftpclient *sftp.Client
 	sshclient  *ssh.Client
-	ctx        context.Context
-	cancel     context.CancelFunc
+	// bastionclients are the connections of bastions, in the order of dialing.
+	bastionclients []*ssh.Client
+	ctx            context.Context
+	cancel         context.CancelFunc
 }
 
 func NewConnection(cfg Cfg) (Connection, error) {
@@ -71,25 +89,112 @@ func NewConnection(cfg Cfg) (Connection, error) {
 		return nil, errors.Wrap(err, "Failed to validate ssh connection parameters")
 	}
 
+	authMethods, err := sshAuthMethods(cfg.Password, cfg.PrivateKey, cfg.AgentSocket)
+	if err != nil {
+		return nil, err
+	}
+
+	hostKeyCallback, err := newHostKeyCallback(cfg.HostKeyChecking, cfg.KnownHosts, cfg.HostKeyFingerprints)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create ssh host key callback")
+	}
+
+	sshConfig := &ssh.ClientConfig{
+		User:            cfg.Username,
+		Timeout:         cfg.Timeout,
+		Auth:            authMethods,
+		HostKeyCallback: hostKeyCallback,
+	}
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	sshConn := &connection{
+		ctx:    ctx,
+		cancel: cancelFn,
+	}
+
+	// dial the bastions in order, and tunnel the connection of the next hop through the previous one.
+	// the pinned fingerprints are only for the target host, the bastions are checked by known_hosts.
+	var client *ssh.Client
+	for _, bastion := range cfg.Bastions {
+		bastionKeyCallback, err := newHostKeyCallback(cfg.HostKeyChecking, cfg.KnownHosts, nil)
+		if err != nil {
+			sshConn.Close()
+			return nil, errors.Wrap(err, "Failed to create ssh host key callback")
+		}
+		bastionAuth := authMethods
+		if bastion.Password != "" || bastion.PrivateKey != "" {
+			bastionAuth, err = sshAuthMethods(bastion.Password, bastion.PrivateKey, "")
+			if err != nil {
+				sshConn.Close()
+				return nil, errors.Wrapf(err, "Failed to create auth methods of bastion %s", bastion.Address)
+			}
+		}
+		bastionConfig := *sshConfig
+		bastionConfig.User = bastion.User
+		bastionConfig.Auth = bastionAuth
+		bastionConfig.HostKeyCallback = bastionKeyCallback
+		if client, err = dialSSH(client, net.JoinHostPort(bastion.Address, strconv.Itoa(bastion.Port)), &bastionConfig); err != nil {
+			sshConn.Close()
+			return nil, err
+		}
+		sshConn.bastionclients = append(sshConn.bastionclients, client)
+	}
+
+	if sshConn.sshclient, err = dialSSH(client, net.JoinHostPort(cfg.Address, strconv.Itoa(cfg.Port)), sshConfig); err != nil {
+		sshConn.Close()
+		return nil, err
+	}
+	sftpClient, err := sftp.NewClient(sshConn.sshclient)
+	if err != nil {
+		sshConn.Close()
+		return nil, errors.Wrapf(err, "new sftp client failed: %v", err)
+	}
+	sshConn.sftpclient = sftpClient
+	return sshConn, nil
+}
+
+// dialSSH connects to the endpoint, through the given client if it is not nil.
+func dialSSH(through *ssh.Client, endpoint string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	if through == nil {
+		client, err := ssh.Dial("tcp", endpoint, config)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not establish connection to %s", endpoint)
+		}
+		return client, nil
+	}
+
+	conn, err := through.Dial("tcp", endpoint)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not establish connection to %s", endpoint)
+	}
+	ncc, chans, reqs, err := ssh.NewClientConn(conn, endpoint, config)
+	if err != nil {
+		_ = conn.Close()
+		return nil, errors.Wrapf(err, "could not establish connection to %s", endpoint)
+	}
+	return ssh.NewClient(ncc, chans, reqs), nil
+}
+
+func sshAuthMethods(password, privateKey, agentSocket string) ([]ssh.AuthMethod, error) {
 	authMethods := make([]ssh.AuthMethod, 0)
 
-	if len(cfg.Password) > 0 {
-		authMethods = append(authMethods, ssh.Password(cfg.Password))
+	if len(password) > 0 {
+		authMethods = append(authMethods, ssh.Password(password))
 	}
 
-	if len(cfg.PrivateKey) > 0 {
-		signer, parseErr := ssh.ParsePrivateKey([]byte(cfg.PrivateKey))
+	if len(privateKey) > 0 {
+		signer, parseErr := ssh.ParsePrivateKey([]byte(privateKey))
 		if parseErr != nil {
 			return nil, errors.Wrap(parseErr, "The given SSH key could not be parsed")
 		}
 		authMethods = append(authMethods, ssh.PublicKeys(signer))
 	}
 
-	if len(cfg.AgentSocket) > 0 {
-		addr := cfg.AgentSocket
+	if len(agentSocket) > 0 {
+		addr := agentSocket
 
-		if strings.HasPrefix(cfg.AgentSocket, socketEnvPrefix) {
-			envName := strings.TrimPrefix(cfg.AgentSocket, socketEnvPrefix)
+		if strings.HasPrefix(agentSocket, socketEnvPrefix) {
+			envName := strings.TrimPrefix(agentSocket, socketEnvPrefix)
 
 			if envAddr := os.Getenv(envName); len(envAddr) > 0 {
 				addr = envAddr
@@ -112,65 +217,7 @@ func NewConnection(cfg Cfg) (Connection, error) {
 		authMethods = append(authMethods, ssh.PublicKeys(signers...))
 	}
 
-	sshConfig := &ssh.ClientConfig{
-		User:            cfg.Username,
-		Timeout:         cfg.Timeout,
-		Auth:            authMethods,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-	}
-
-	targetHost := cfg.Address
-	targetPort := strconv.Itoa(cfg.Port)
-
-	if cfg.Bastion != "" {
-		targetHost = cfg.Bastion
-		targetPort = strconv.Itoa(cfg.BastionPort)
-		sshConfig.User = cfg.BastionUser
-	}
-
-	endpoint := net.JoinHostPort(targetHost, targetPort)
-
-	client, err := ssh.Dial("tcp", endpoint, sshConfig)
-	if err != nil {
-		return nil, errors.Wrapf(err, "could not establish connection to %s", endpoint)
-	}
-
-	ctx, cancelFn := context.WithCancel(context.Background())
-	sshConn := &connection{
-		ctx:    ctx,
-		cancel: cancelFn,
-	}
-
-	if cfg.Bastion == "" {
-		sshConn.sshclient = client
-		sftpClient, err := sftp.NewClient(sshConn.sshclient)
-		if err != nil {
-			return nil, errors.Wrapf(err, "new sftp client failed: %v", err)
-		}
-		sshConn.sftpclient = sftpClient
-		return sshConn, nil
-	}
-
-	endpointBehindBastion := net.JoinHostPort(cfg.Address, strconv.Itoa(cfg.Port))
-
-	conn, err := client.Dial("tcp", endpointBehindBastion)
-	if err != nil {
-		return nil, errors.Wrapf(err, "could not establish connection to %s", endpointBehindBastion)
-	}
-
-	sshConfig.User = cfg.Username
-	ncc, chans, reqs, err := ssh.NewClientConn(conn, endpointBehindBastion, sshConfig)
-	if err != nil {
-		return nil, errors.Wrapf(err, "could not establish connection to %s", endpointBehindBastion)
-	}
-
-	sshConn.sshclient = ssh.NewClient(ncc, chans, reqs)
-	sftpClient, err := sftp.NewClient(sshConn.sshclient)
-	if err != nil {
-		return nil, errors.Wrapf(err, "new sftp client failed: %v", err)
-	}
-	sshConn.sftpclient = sftpClient
-	return sshConn, nil
+	return authMethods, nil
 }
 
 func validateOptions(cfg Cfg) (Cfg, error) {
@@ -208,10 +255,38 @@ func validateOptions(cfg Cfg) (Cfg, error) {
 		cfg.BastionUser = cfg.Username
 	}
 
+	if cfg.Bastion != "" {
+		cfg.Bastions = append([]Bastion{{Address: cfg.Bastion, Port: cfg.BastionPort, User: cfg.BastionUser}}, cfg.Bastions...)
+	}
+
+	for i := range cfg.Bastions {
+		if cfg.Bastions[i].Address == "" {
+			return cfg, errors.New("No address specified for SSH bastion")
+		}
+		if cfg.Bastions[i].Port <= 0 {
+			cfg.Bastions[i].Port = 22
+		}
+		if cfg.Bastions[i].User == "" {
+			cfg.Bastions[i].User = cfg.Username
+		}
+		if cfg.Bastions[i].PrivateKey == "" && cfg.Bastions[i].PrivateKeyPath != "" {
+			content, err := os.ReadFile(cfg.Bastions[i].PrivateKeyPath)
+			if err != nil {
+				return cfg, errors.Wrapf(err, "Failed to read keyfile %q of bastion %s", cfg.Bastions[i].PrivateKeyPath, cfg.Bastions[i].Address)
+			}
+			cfg.Bastions[i].PrivateKey = string(content)
+			cfg.Bastions[i].PrivateKeyPath = ""
+		}
+	}
+
 	if cfg.Timeout == 0 {
 		cfg.Timeout = 15 * time.Second
 	}
 
+	if cfg.KnownHosts == "" {
+		cfg.KnownHosts = filepath.Join(common.KubeKey, KnownHostsFile)
+	}
+
 	return cfg, nil
 }
 
@@ -219,7 +294,7 @@ func (c *connection) Close() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if c.sshclient == nil && c.sftpclient == nil {
+	if c.sshclient == nil && c.sftpclient == nil && len(c.bastionclients) == 0 {
 		return
 	}
 	c.cancel()
@@ -232,6 +307,10 @@ func (c *connection) Close() {
 		c.sftpclient.Close()
 		c.sftpclient = nil
 	}
+	for i := len(c.bastionclients) - 1; i >= 0; i-- {
+		c.bastionclients[i].Close()
+	}
+	c.bastionclients = nil
 }
 
 func (c *connection) session() (*ssh.Session, error) {
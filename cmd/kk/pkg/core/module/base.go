@@ -39,6 +39,10 @@ func (b *BaseModule) IsSkip() bool {
 	return b.Skip
 }
 
+func (b *BaseModule) GetName() string {
+	return b.Name
+}
+
 func (b *BaseModule) Default(runtime connector.Runtime, pipelineCache *cache.Cache, moduleCache *cache.Cache) {
 	b.Runtime = runtime
 	b.PipelineCache = pipelineCache
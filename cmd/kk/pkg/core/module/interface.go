@@ -24,6 +24,7 @@ import (
 
 type Module interface {
 	IsSkip() bool
+	GetName() string
 	Default(runtime connector.Runtime, pipelineCache *cache.Cache, moduleCache *cache.Cache)
 	Init()
 	Is() string
@@ -34,3 +35,9 @@ type Module interface {
 	AppendPostHook(h PostHookInterface)
 	CallPostHook(result *ending.ModuleResult) error
 }
+
+// Stateful is implemented by the module which collects states into caches for the following modules, e.g. the status
+// of cluster. It is always run even if it has been completed when the pipeline is resumed from the checkpoint.
+type Stateful interface {
+	IsStateful() bool
+}
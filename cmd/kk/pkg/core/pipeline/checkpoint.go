@@ -0,0 +1,136 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pipeline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/module"
+)
+
+const stateDir = "pipeline-state"
+
+// Checkpoint records the completed modules of a pipeline in a state file under the work dir,
+// so that a failed pipeline can be resumed, or a slice of the pipeline can be run.
+type Checkpoint struct {
+	// Resume skips the modules which have been completed and whose inputs are not changed.
+	Resume bool
+	// FromModule is the name of the first module to run. The first module is used if empty.
+	FromModule string
+	// ToModule is the name of the last module to run, it is searched from FromModule. The last module is used if empty.
+	ToModule string
+	// Config is the input shared by all modules, e.g. the cluster spec. The completed modules are run again if it's changed.
+	Config interface{}
+}
+
+// State is the content of the state file.
+type State struct {
+	Pipeline string         `json:"pipeline"`
+	Modules  []ModuleRecord `json:"modules"`
+}
+
+// ModuleRecord is a completed module of the pipeline.
+type ModuleRecord struct {
+	// Index is the position of the module in the pipeline, a module may be used more than once in a pipeline.
+	Index       int       `json:"index"`
+	Name        string    `json:"name"`
+	Hash        string    `json:"hash"`
+	CompletedAt time.Time `json:"completedAt"`
+}
+
+// StateFile returns the path of state file of the pipeline.
+func StateFile(workDir, pipelineName string) string {
+	return filepath.Join(workDir, stateDir, pipelineName+".json")
+}
+
+// LoadState reads the state file. An empty State is returned if the file does not exist.
+func LoadState(file, pipelineName string) (*State, error) {
+	state := &State{Pipeline: pipelineName}
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "read pipeline state file %s failed", file)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, errors.Wrapf(err, "parse pipeline state file %s failed", file)
+	}
+	return state, nil
+}
+
+// Save writes the state to file.
+func (s *State) Save(file string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "marshal pipeline state failed")
+	}
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return errors.Wrapf(err, "create dir of pipeline state file %s failed", file)
+	}
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return errors.Wrapf(err, "write pipeline state file %s failed", file)
+	}
+	return os.Rename(tmp, file)
+}
+
+// Completed returns whether the module at index has been completed with the same name and hash.
+func (s *State) Completed(index int, name, hash string) bool {
+	for _, r := range s.Modules {
+		if r.Index == index {
+			return r.Name == name && r.Hash == hash
+		}
+	}
+	return false
+}
+
+// Complete records the module at index as completed.
+func (s *State) Complete(index int, name, hash string) {
+	record := ModuleRecord{Index: index, Name: name, Hash: hash, CompletedAt: time.Now()}
+	for i := range s.Modules {
+		if s.Modules[i].Index == index {
+			s.Modules[i] = record
+			return
+		}
+	}
+	s.Modules = append(s.Modules, record)
+}
+
+// moduleHash returns the hash of the config and the fields of module. It must be called before the module is
+// defaulted with runtime and caches.
+func moduleHash(config interface{}, m module.Module) string {
+	h := sha256.New()
+	if data, err := json.Marshal(config); err == nil {
+		h.Write(data)
+	}
+	// the module which can not be marshaled is identified by its type only.
+	if data, err := json.Marshal(m); err == nil {
+		h.Write(data)
+	} else {
+		fmt.Fprintf(h, "%T", m)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,208 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pipeline
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/ending"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/logger"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/module"
+)
+
+func TestState(t *testing.T) {
+	file := StateFile(t.TempDir(), "CreateClusterPipeline")
+	state, err := LoadState(file, "CreateClusterPipeline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Completed(0, "GreetingsModule", "hash") {
+		t.Error("Completed() of empty state should be false")
+	}
+
+	state.Complete(0, "GreetingsModule", "hash")
+	state.Complete(3, "StatusModule", "hash1")
+	state.Complete(3, "StatusModule", "hash2")
+	if err := state.Save(file); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadState(file, "CreateClusterPipeline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Modules) != 2 {
+		t.Fatalf("got %d modules, want 2", len(loaded.Modules))
+	}
+	tests := []struct {
+		index int
+		name  string
+		hash  string
+		want  bool
+	}{
+		{index: 0, name: "GreetingsModule", hash: "hash", want: true},
+		{index: 3, name: "StatusModule", hash: "hash1", want: false},
+		{index: 3, name: "StatusModule", hash: "hash2", want: true},
+		{index: 3, name: "JoinNodesModule", hash: "hash2", want: false},
+		{index: 4, name: "StatusModule", hash: "hash2", want: false},
+	}
+	for _, tt := range tests {
+		if got := loaded.Completed(tt.index, tt.name, tt.hash); got != tt.want {
+			t.Errorf("Completed(%d, %s, %s) = %v, want %v", tt.index, tt.name, tt.hash, got, tt.want)
+		}
+	}
+}
+
+func TestModuleHash(t *testing.T) {
+	config := map[string]string{"version": "v1.23.10"}
+	hash := moduleHash(config, &module.BaseTaskModule{})
+	if hash != moduleHash(map[string]string{"version": "v1.23.10"}, &module.BaseTaskModule{}) {
+		t.Error("moduleHash() should be same with the same inputs")
+	}
+	if hash == moduleHash(map[string]string{"version": "v1.24.0"}, &module.BaseTaskModule{}) {
+		t.Error("moduleHash() should be changed with config")
+	}
+	if hash == moduleHash(config, &module.BaseTaskModule{BaseModule: module.BaseModule{Skip: true}}) {
+		t.Error("moduleHash() should be changed with the fields of module")
+	}
+}
+
+// testRuntime is a runtime without hosts, the pipeline state is saved in workDir.
+type testRuntime struct {
+	connector.Runtime
+	workDir string
+}
+
+func (r *testRuntime) GetWorkDir() string {
+	return r.workDir
+}
+
+func (r *testRuntime) GetAllHosts() []connector.Host {
+	return nil
+}
+
+// testModule records its name into runs when it's run, and fails if its name is in fails.
+type testModule struct {
+	module.BaseModule
+	runs  *[]string
+	fails map[string]bool
+}
+
+func (m *testModule) Run(result *ending.ModuleResult) {
+	*m.runs = append(*m.runs, m.Name)
+	if m.fails[m.Name] {
+		result.LocalErrResult(errors.Errorf("module %s failed", m.Name))
+		return
+	}
+	result.NormalResult()
+}
+
+func TestPipelineStart(t *testing.T) {
+	logger.Log = logger.NewLogger(t.TempDir(), false)
+	names := []string{"GreetingsModule", "PreCheckModule", "InstallModule", "StatusModule"}
+
+	tests := []struct {
+		name string
+		// completed are the modules run before, and the run fails at the module after them if it's not empty.
+		completed  []string
+		checkpoint Checkpoint
+		want       []string
+		wantErr    bool
+	}{
+		{
+			name: "all modules",
+			want: names,
+		},
+		{
+			name:       "from module",
+			checkpoint: Checkpoint{FromModule: "PreCheckModule"},
+			want:       []string{"PreCheckModule", "InstallModule", "StatusModule"},
+		},
+		{
+			name:       "to module",
+			checkpoint: Checkpoint{ToModule: "InstallModule"},
+			want:       []string{"GreetingsModule", "PreCheckModule", "InstallModule"},
+		},
+		{
+			name:       "from module to module",
+			checkpoint: Checkpoint{FromModule: "PreCheckModule", ToModule: "PreCheckModule"},
+			want:       []string{"PreCheckModule"},
+		},
+		{
+			name:       "from module not found",
+			checkpoint: Checkpoint{FromModule: "UnknownModule"},
+			wantErr:    true,
+		},
+		{
+			name:       "to module before from module",
+			checkpoint: Checkpoint{FromModule: "InstallModule", ToModule: "PreCheckModule"},
+			want:       []string{"InstallModule", "StatusModule"},
+			wantErr:    true,
+		},
+		{
+			name:       "resume",
+			completed:  []string{"GreetingsModule", "PreCheckModule"},
+			checkpoint: Checkpoint{Resume: true},
+			want:       []string{"InstallModule", "StatusModule"},
+		},
+		{
+			name:       "resume with changed config",
+			completed:  []string{"GreetingsModule", "PreCheckModule"},
+			checkpoint: Checkpoint{Resume: true, Config: "changed"},
+			want:       names,
+		},
+		{
+			name:       "resume to completed module",
+			completed:  []string{"GreetingsModule", "PreCheckModule"},
+			checkpoint: Checkpoint{Resume: true, ToModule: "PreCheckModule"},
+			want:       nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runtime := &testRuntime{workDir: t.TempDir()}
+			newPipeline := func(checkpoint Checkpoint, runs *[]string, fails map[string]bool) *Pipeline {
+				modules := make([]module.Module, 0, len(names))
+				for _, name := range names {
+					modules = append(modules, &testModule{BaseModule: module.BaseModule{Name: name}, runs: runs, fails: fails})
+				}
+				return &Pipeline{Name: "TestPipeline", Modules: modules, Runtime: runtime, SkipPrintLogo: true, Checkpoint: &checkpoint}
+			}
+
+			if len(tt.completed) > 0 {
+				var runs []string
+				fails := map[string]bool{names[len(tt.completed)]: true}
+				if err := newPipeline(Checkpoint{}, &runs, fails).Start(); err == nil {
+					t.Fatal("the previous run should be failed")
+				}
+			}
+
+			var runs []string
+			err := newPipeline(tt.checkpoint, &runs, nil).Start()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Start() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(runs, tt.want) {
+				t.Errorf("Start() run modules %v, want %v", runs, tt.want)
+			}
+		})
+	}
+}
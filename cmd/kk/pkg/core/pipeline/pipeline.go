@@ -52,6 +52,9 @@ type Pipeline struct {
 	ModuleCachePool sync.Pool
 	ModulePostHooks []module.PostHookInterface
 	SkipPrintLogo   bool
+	// Checkpoint records the completed modules to resume the pipeline. It's disabled if nil.
+	Checkpoint *Checkpoint
+	state      *State
 }
 
 func (p *Pipeline) Init() error {
@@ -60,6 +63,13 @@ func (p *Pipeline) Init() error {
 	}
 	p.PipelineCache = cache.NewCache()
 	p.SpecHosts = len(p.Runtime.GetAllHosts())
+	if p.Checkpoint != nil {
+		state, err := LoadState(p.stateFile(), p.Name)
+		if err != nil {
+			return err
+		}
+		p.state = state
+	}
 	//if err := p.Runtime.GenerateWorkDir(); err != nil {
 	//	return err
 	//}
@@ -73,16 +83,40 @@ func (p *Pipeline) Start() error {
 	if err := p.Init(); err != nil {
 		return errors.Wrapf(err, "Pipeline[%s] execute failed", p.Name)
 	}
+	started := p.Checkpoint == nil || p.Checkpoint.FromModule == ""
+	reachedTo := false
 	for i := range p.Modules {
 		m := p.Modules[i]
 		if m.IsSkip() {
 			continue
 		}
+		// the hash must be calculated before the module is defaulted with runtime.
+		var hash string
+		if p.Checkpoint != nil {
+			hash = moduleHash(p.Checkpoint.Config, m)
+		}
 
 		moduleCache := p.newModuleCache()
 		m.Default(p.Runtime, p.PipelineCache, moduleCache)
 		m.AutoAssert()
 		m.Init()
+
+		if !started && m.GetName() == p.Checkpoint.FromModule {
+			started = true
+		}
+		// ToModule is the last module to run, the pipeline stops after it even if it is skipped by checkpoint.
+		if started && p.Checkpoint != nil && p.Checkpoint.ToModule != "" && m.GetName() == p.Checkpoint.ToModule {
+			reachedTo = true
+		}
+		if p.skipByCheckpoint(m, i, hash, started) {
+			logger.Log.Infof("[%s] skipped by checkpoint", m.GetName())
+			p.releaseModuleCache(moduleCache)
+			if reachedTo {
+				break
+			}
+			continue
+		}
+
 		for j := range p.ModulePostHooks {
 			m.AppendPostHook(p.ModulePostHooks[j])
 		}
@@ -95,7 +129,22 @@ func (p *Pipeline) Start() error {
 		if err != nil {
 			return errors.Wrapf(err, "Pipeline[%s] execute failed", p.Name)
 		}
+		if p.Checkpoint != nil {
+			p.state.Complete(i, m.GetName(), hash)
+			if err := p.state.Save(p.stateFile()); err != nil {
+				return errors.Wrapf(err, "Pipeline[%s] execute failed", p.Name)
+			}
+		}
 		p.releaseModuleCache(moduleCache)
+		if reachedTo {
+			break
+		}
+	}
+	if !started {
+		return errors.Errorf("Pipeline[%s] execute failed: module %s is not found", p.Name, p.Checkpoint.FromModule)
+	}
+	if p.Checkpoint != nil && p.Checkpoint.ToModule != "" && !reachedTo {
+		return errors.Errorf("Pipeline[%s] execute failed: module %s is not found after module %s", p.Name, p.Checkpoint.ToModule, p.Checkpoint.FromModule)
 	}
 	p.releasePipelineCache()
 
@@ -111,6 +160,25 @@ func (p *Pipeline) Start() error {
 	return nil
 }
 
+// skipByCheckpoint returns whether the module is skipped because it's out of the range of FromModule and ToModule,
+// or it has been completed when resume. The stateful module is never skipped.
+func (p *Pipeline) skipByCheckpoint(m module.Module, index int, hash string, started bool) bool {
+	if p.Checkpoint == nil {
+		return false
+	}
+	if s, ok := m.(module.Stateful); ok && s.IsStateful() {
+		return false
+	}
+	if !started {
+		return true
+	}
+	return p.Checkpoint.Resume && p.state.Completed(index, m.GetName(), hash)
+}
+
+func (p *Pipeline) stateFile() string {
+	return StateFile(p.Runtime.GetWorkDir(), p.Name)
+}
+
 func (p *Pipeline) RunModule(m module.Module) *ending.ModuleResult {
 	m.Slogan()
 
@@ -140,18 +140,43 @@ func (f *FetchCerts) Execute(runtime connector.Runtime) error {
 
 type GenerateCerts struct {
 	common.KubeAction
+	// Renew removes the existing certs except the CA, so that they are signed again.
+	Renew bool
 }
 
 func (g *GenerateCerts) Execute(runtime connector.Runtime) error {
 
 	pkiPath := fmt.Sprintf("%s/pki/etcd", runtime.GetWorkDir())
 
+	if g.Renew {
+		if err := removeLeafCerts(pkiPath); err != nil {
+			return err
+		}
+	}
+
 	altName := GenerateAltName(g.KubeConf, &runtime)
 
 	files := []string{"ca.pem", "ca-key.pem"}
 
 	// CA
-	certsList := []*certs.KubekeyCert{KubekeyCertEtcdCA()}
+	caCert := KubekeyCertEtcdCA()
+	if ca := g.KubeConf.Cluster.Certificates.Etcd; ca != nil {
+		if err := ca.Validate("etcd"); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(pkiPath, os.ModePerm); err != nil {
+			return errors.Wrapf(err, "create dir %s failed", pkiPath)
+		}
+		if err := certs.InstallCertificateAuthority(ca, filepath.Join(pkiPath, "ca.pem"), filepath.Join(pkiPath, "ca-key.pem")); err != nil {
+			return err
+		}
+		// the CA key is kept by the external signer.
+		if ca.Signer != nil {
+			caCert.Signer = ca.Signer
+			files = []string{"ca.pem"}
+		}
+	}
+	certsList := []*certs.KubekeyCert{caCert}
 
 	// Certs
 	for _, host := range runtime.GetAllHosts() {
@@ -189,6 +214,24 @@ func (g *GenerateCerts) Execute(runtime connector.Runtime) error {
 	return nil
 }
 
+// removeLeafCerts removes the certs and keys in pkiPath except the CA.
+func removeLeafCerts(pkiPath string) error {
+	entries, err := os.ReadDir(pkiPath)
+	if err != nil {
+		return errors.Wrapf(err, "read dir %s failed", pkiPath)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == "ca.pem" || name == "ca-key.pem" || !strings.HasSuffix(name, ".pem") {
+			continue
+		}
+		if err := os.Remove(filepath.Join(pkiPath, name)); err != nil {
+			return errors.Wrapf(err, "remove cert %s failed", name)
+		}
+	}
+	return nil
+}
+
 func GenerateAltName(k *common.KubeConf, runtime *connector.Runtime) *cert.AltNames {
 	var altName cert.AltNames
 
@@ -18,6 +18,7 @@ package etcd
 
 import (
 	"path/filepath"
+	"time"
 
 	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
@@ -36,6 +37,11 @@ func (p *PreCheckModule) IsSkip() bool {
 	return p.Skip
 }
 
+// IsStateful the etcd status is used by the following modules.
+func (p *PreCheckModule) IsStateful() bool {
+	return true
+}
+
 func (p *PreCheckModule) Init() {
 	p.Name = "ETCDPreCheckModule"
 	p.Desc = "Get ETCD cluster status"
@@ -149,6 +155,11 @@ func (p *InstallETCDBinaryModule) IsSkip() bool {
 	return p.Skip
 }
 
+// IsStateful the access address of etcd is generated for the following modules, the installed etcd is not installed again.
+func (i *InstallETCDBinaryModule) IsStateful() bool {
+	return true
+}
+
 func (i *InstallETCDBinaryModule) Init() {
 	i.Name = "InstallETCDBinaryModule"
 	i.Desc = "Install ETCD cluster"
@@ -436,3 +447,274 @@ func (b *BackupModule) Init() {
 		enable,
 	}
 }
+
+type SnapshotModule struct {
+	common.KubeModule
+	// LocalPath is the file on the operator machine to save the snapshot.
+	LocalPath string
+}
+
+func (s *SnapshotModule) Init() {
+	s.Name = "ETCDSnapshotModule"
+	s.Desc = "Save ETCD snapshot"
+
+	saveSnapshot := &task.RemoteTask{
+		Name:     "SaveETCDSnapshot",
+		Desc:     "Save etcd snapshot and fetch it to local",
+		Hosts:    s.Runtime.GetHostsByRole(common.ETCD),
+		Prepare:  new(FirstETCDNode),
+		Action:   &SaveSnapshot{LocalPath: s.LocalPath},
+		Parallel: false,
+	}
+
+	s.Tasks = []task.Interface{
+		saveSnapshot,
+	}
+}
+
+type RestoreModule struct {
+	common.KubeModule
+	// LocalPath is the snapshot file on the operator machine.
+	LocalPath string
+}
+
+func (r *RestoreModule) Init() {
+	r.Name = "ETCDRestoreModule"
+	r.Desc = "Restore ETCD cluster from snapshot"
+
+	verifySnapshot := &task.LocalTask{
+		Name:   "VerifyETCDSnapshot",
+		Desc:   "Verify local etcd snapshot",
+		Action: &VerifyLocalSnapshot{LocalPath: r.LocalPath},
+	}
+
+	syncSnapshot := &task.RemoteTask{
+		Name:     "SyncETCDSnapshot",
+		Desc:     "Synchronize etcd snapshot to etcd nodes",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Action:   &SyncSnapshot{LocalPath: r.LocalPath},
+		Parallel: true,
+		Retry:    1,
+	}
+
+	stopKubeAPIServer := &task.RemoteTask{
+		Name:     "StopKubeAPIServer",
+		Desc:     "Stop kube-apiserver",
+		Hosts:    r.Runtime.GetHostsByRole(common.Master),
+		Action:   new(StopKubeAPIServer),
+		Parallel: true,
+	}
+
+	waitKubeAPIServerStopped := &task.RemoteTask{
+		Name:     "WaitKubeAPIServerStopped",
+		Desc:     "Wait for kube-apiserver stopped",
+		Hosts:    r.Runtime.GetHostsByRole(common.Master),
+		Action:   new(WaitKubeAPIServerStopped),
+		Parallel: true,
+		Retry:    30,
+		Delay:    2 * time.Second,
+	}
+
+	stopETCD := &task.RemoteTask{
+		Name:     "StopETCD",
+		Desc:     "Stop etcd",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Action:   new(StopETCD),
+		Parallel: true,
+	}
+
+	generateRestoreCluster := &task.LocalTask{
+		Name:   "GenerateRestoreCluster",
+		Desc:   "Generate etcd members to restore",
+		Action: new(GenerateRestoreCluster),
+	}
+
+	restoreSnapshot := &task.RemoteTask{
+		Name:     "RestoreETCDSnapshot",
+		Desc:     "Restore etcd data from snapshot and refresh etcd.env config",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Action:   new(RestoreSnapshot),
+		Parallel: true,
+	}
+
+	startETCD := &task.RemoteTask{
+		Name:     "StartETCD",
+		Desc:     "Start etcd",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Action:   new(StartETCD),
+		Parallel: false,
+	}
+
+	allETCDNodeHealthCheck := &task.RemoteTask{
+		Name:     "AllETCDNodeHealthCheck",
+		Desc:     "Health check on all etcd",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Action:   new(HealthCheck),
+		Parallel: true,
+		Retry:    20,
+	}
+
+	startKubeAPIServer := &task.RemoteTask{
+		Name:     "StartKubeAPIServer",
+		Desc:     "Start kube-apiserver",
+		Hosts:    r.Runtime.GetHostsByRole(common.Master),
+		Action:   new(StartKubeAPIServer),
+		Parallel: true,
+	}
+
+	kubeAPIServerHealthCheck := &task.RemoteTask{
+		Name:     "KubeAPIServerHealthCheck",
+		Desc:     "Health check on kube-apiserver",
+		Hosts:    r.Runtime.GetHostsByRole(common.Master),
+		Action:   new(KubeAPIServerHealthCheck),
+		Parallel: true,
+		Retry:    30,
+	}
+
+	r.Tasks = []task.Interface{
+		verifySnapshot,
+		syncSnapshot,
+		stopKubeAPIServer,
+		waitKubeAPIServerStopped,
+		stopETCD,
+		generateRestoreCluster,
+		restoreSnapshot,
+		startETCD,
+		allETCDNodeHealthCheck,
+		startKubeAPIServer,
+		kubeAPIServerHealthCheck,
+	}
+}
+
+// RemoveMemberModule removes the etcd member of the node to delete, which is set in pipeline cache by common.DstNode.
+type RemoveMemberModule struct {
+	common.KubeModule
+	Skip bool
+}
+
+func (r *RemoveMemberModule) IsSkip() bool {
+	return r.Skip
+}
+
+func (r *RemoveMemberModule) Init() {
+	r.Name = "RemoveETCDMemberModule"
+	r.Desc = "Remove the etcd member of the node to delete"
+
+	generateCluster := &task.LocalTask{
+		Name:   "GenerateRemainingCluster",
+		Desc:   "Generate etcd cluster without the node to delete",
+		Action: new(GenerateRemainingCluster),
+	}
+
+	checkQuorum := &task.RemoteTask{
+		Name:     "CheckRemainingQuorum",
+		Desc:     "Check the remaining etcd members keep quorum",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Prepare:  new(FirstRemainingETCDNode),
+		Action:   new(CheckRemainingQuorum),
+		Parallel: false,
+	}
+
+	removeMember := &task.RemoteTask{
+		Name:     "RemoveETCDMember",
+		Desc:     "Remove the etcd member of the node to delete",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Prepare:  new(FirstRemainingETCDNode),
+		Action:   new(RemoveMember),
+		Parallel: false,
+		Retry:    3,
+	}
+
+	refreshConfig := &task.RemoteTask{
+		Name:     "RefreshETCDConfig",
+		Desc:     "Refresh etcd.env config on the remaining etcd",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Prepare:  &common.OnlyDeleteNode{Not: true},
+		Action:   &RefreshConfig{ToExisting: true},
+		Parallel: false,
+	}
+
+	healthCheck := &task.RemoteTask{
+		Name:     "ETCDHealthCheck",
+		Desc:     "Health check on the remaining etcd",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Prepare:  &common.OnlyDeleteNode{Not: true},
+		Action:   new(HealthCheck),
+		Parallel: true,
+		Retry:    20,
+	}
+
+	r.Tasks = []task.Interface{
+		generateCluster,
+		checkQuorum,
+		removeMember,
+		refreshConfig,
+		healthCheck,
+	}
+}
+
+// RenewCertsModule signs the certs of etcd members, admins and nodes again with the etcd CA,
+// then restarts the etcd members one by one.
+type RenewCertsModule struct {
+	common.KubeModule
+	Skip bool
+}
+
+func (r *RenewCertsModule) IsSkip() bool {
+	return r.Skip
+}
+
+func (r *RenewCertsModule) Init() {
+	r.Name = "RenewETCDCertsModule"
+	r.Desc = "Renew etcd certs"
+
+	fetchCerts := &task.RemoteTask{
+		Name:     "FetchETCDCerts",
+		Desc:     "Fetch etcd certs",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Prepare:  new(FirstETCDNode),
+		Action:   new(FetchCerts),
+		Parallel: false,
+	}
+
+	renewCerts := &task.LocalTask{
+		Name:   "RenewETCDCerts",
+		Desc:   "Renew etcd certs",
+		Action: &GenerateCerts{Renew: true},
+	}
+
+	syncCertsFile := &task.RemoteTask{
+		Name:     "SyncCertsFile",
+		Desc:     "Synchronize certs file",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Action:   new(SyncCertsFile),
+		Parallel: true,
+		Retry:    1,
+	}
+
+	syncCertsToMaster := &task.RemoteTask{
+		Name:     "SyncCertsFileToMaster",
+		Desc:     "Synchronize certs file to master",
+		Hosts:    r.Runtime.GetHostsByRole(common.Master),
+		Prepare:  &common.OnlyETCD{Not: true},
+		Action:   new(SyncCertsFile),
+		Parallel: true,
+		Retry:    1,
+	}
+
+	rollingRestart := &task.RemoteTask{
+		Name:     "RollingRestartETCD",
+		Desc:     "Restart etcd one by one",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Action:   new(RollingRestartETCD),
+		Parallel: false,
+	}
+
+	r.Tasks = []task.Interface{
+		fetchCerts,
+		renewCerts,
+		syncCertsFile,
+		syncCertsToMaster,
+		rollingRestart,
+	}
+}
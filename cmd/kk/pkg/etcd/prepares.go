@@ -75,3 +75,22 @@ func (n *InstallOrUpgradeETCD) PreCheck(runtime connector.Runtime) (bool, error)
 	}
 	return false, errors.New("get etcd node status by host label failed")
 }
+
+// FirstRemainingETCDNode selects the first etcd node except the node to delete.
+type FirstRemainingETCDNode struct {
+	common.KubePrepare
+}
+
+func (f *FirstRemainingETCDNode) PreCheck(runtime connector.Runtime) (bool, error) {
+	nodeName, ok := f.PipelineCache.GetMustString(common.DstNode)
+	if !ok {
+		return false, errors.New("get the node to delete by pipeline cache failed")
+	}
+	for _, host := range runtime.GetHostsByRole(common.ETCD) {
+		if host.GetName() == nodeName {
+			continue
+		}
+		return host.GetName() == runtime.RemoteHost().GetName(), nil
+	}
+	return false, nil
+}
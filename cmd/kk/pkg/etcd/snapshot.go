@@ -0,0 +1,393 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package etcd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/logger"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/utils"
+)
+
+const (
+	// RemoteSnapshot is the path of the snapshot on etcd nodes during snapshot and restore.
+	RemoteSnapshot = common.TmpDir + "/etcd-snapshot.db"
+	// SnapshotChecksumSuffix is the suffix of the file which records the sha256 of snapshot.
+	SnapshotChecksumSuffix = ".sha256"
+	// SnapshotChecksum is the pipeline cache key of the sha256 of the local snapshot.
+	SnapshotChecksum = "etcdSnapshotChecksum"
+
+	DefaultDataDir = "/var/lib/etcd"
+
+	kubeAPIServerManifest       = common.KubeManifestDir + "/kube-apiserver.yaml"
+	kubeAPIServerManifestBackup = common.KubeConfigDir + "/kube-apiserver.yaml.etcd-restore"
+)
+
+// SnapshotStatus is the output of "etcdutl snapshot status -w json".
+type SnapshotStatus struct {
+	Hash      uint32 `json:"hash"`
+	Revision  int64  `json:"revision"`
+	TotalKey  int    `json:"totalKey"`
+	TotalSize int64  `json:"totalSize"`
+}
+
+// DataDir returns the etcd data dir in cluster config.
+func DataDir(kubeConf *common.KubeConf) string {
+	if dir := kubeConf.Cluster.Etcd.DataDir; dir != nil && *dir != "" {
+		return *dir
+	}
+	return DefaultDataDir
+}
+
+// etcdctlEnv returns the env to run etcdctl v3 with the admin certs of host.
+func etcdctlEnv(host connector.Host) string {
+	return fmt.Sprintf("export ETCDCTL_API=3;"+
+		"export ETCDCTL_CERT='%s/admin-%s.pem';"+
+		"export ETCDCTL_KEY='%s/admin-%s-key.pem';"+
+		"export ETCDCTL_CACERT='%s/ca.pem';",
+		common.ETCDCertDir, host.GetName(), common.ETCDCertDir, host.GetName(), common.ETCDCertDir)
+}
+
+// snapshotStatus returns the status of the snapshot file on host. etcdutl is used if it exists,
+// otherwise use etcdctl (etcd < v3.5).
+func snapshotStatus(runtime connector.Runtime, file string) (*SnapshotStatus, error) {
+	cmd := fmt.Sprintf("%s/etcdctl snapshot status %s -w json", common.BinDir, file)
+	if exist, err := runtime.GetRunner().FileExist(filepath.Join(common.BinDir, "etcdutl")); err == nil && exist {
+		cmd = fmt.Sprintf("%s/etcdutl snapshot status %s -w json", common.BinDir, file)
+	}
+	out, err := runtime.GetRunner().SudoCmd("export ETCDCTL_API=3;"+cmd, false)
+	if err != nil {
+		return nil, errors.Wrapf(errors.WithStack(err), "get snapshot %s status failed", file)
+	}
+	// the output may contain the deprecated warning of etcdctl before the json.
+	if i := strings.Index(out, "{"); i > 0 {
+		out = out[i:]
+	}
+	status := &SnapshotStatus{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(out)), status); err != nil {
+		return nil, errors.Wrapf(err, "parse snapshot %s status failed: %s", file, out)
+	}
+	if status.TotalKey == 0 {
+		return nil, errors.Errorf("snapshot %s is empty", file)
+	}
+	return status, nil
+}
+
+// remoteSha256 returns the sha256 of the file on host.
+func remoteSha256(runtime connector.Runtime, file string) (string, error) {
+	out, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("sha256sum %s | cut -d ' ' -f1", file), false)
+	if err != nil {
+		return "", errors.Wrapf(errors.WithStack(err), "get sha256 of %s failed", file)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// LocalSha256 returns the sha256 of the local file.
+func LocalSha256(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// InitialCluster returns the --initial-cluster of etcd members, which is built from the etcd name in host cache
+// and the internal address of etcd hosts.
+func InitialCluster(hosts []connector.Host, peerPort int) (string, error) {
+	members := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		name, ok := host.GetCache().GetMustString(common.ETCDName)
+		if !ok {
+			return "", errors.Errorf("get etcd name of host %s by host cache failed", host.GetName())
+		}
+		members = append(members, fmt.Sprintf("%s=https://%s:%d", name, host.GetInternalIPv4Address(), peerPort))
+	}
+	return strings.Join(members, ","), nil
+}
+
+type SaveSnapshot struct {
+	common.KubeAction
+	// LocalPath is the file on the operator machine to save the snapshot.
+	LocalPath string
+}
+
+func (s *SaveSnapshot) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	if err := utils.ResetTmpDir(runtime); err != nil {
+		return err
+	}
+
+	saveCmd := fmt.Sprintf("%s%s/etcdctl --endpoints=https://%s:%d snapshot save %s",
+		etcdctlEnv(host), common.BinDir, host.GetInternalIPv4Address(), s.KubeConf.Cluster.Etcd.GetPort(), RemoteSnapshot)
+	if _, err := runtime.GetRunner().SudoCmd(saveCmd, false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "save etcd snapshot failed")
+	}
+	defer func() {
+		if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("rm -f %s", RemoteSnapshot), false); err != nil {
+			logger.Log.Warnf("remove etcd snapshot on %s failed: %v", host.GetName(), err)
+		}
+	}()
+
+	status, err := snapshotStatus(runtime, RemoteSnapshot)
+	if err != nil {
+		return err
+	}
+	remoteSum, err := remoteSha256(runtime, RemoteSnapshot)
+	if err != nil {
+		return err
+	}
+
+	if err := runtime.GetRunner().Fetch(s.LocalPath, RemoteSnapshot); err != nil {
+		return errors.Wrap(errors.WithStack(err), "fetch etcd snapshot failed")
+	}
+	// the snapshot contains all secrets of the cluster.
+	if err := os.Chmod(s.LocalPath, 0600); err != nil {
+		return errors.Wrap(err, "chmod local snapshot failed")
+	}
+	localSum, err := LocalSha256(s.LocalPath)
+	if err != nil {
+		return errors.Wrap(err, "get sha256 of local snapshot failed")
+	}
+	if localSum != remoteSum {
+		_ = os.Remove(s.LocalPath)
+		return errors.Errorf("the sha256 of fetched snapshot %s is not equal to the one on %s %s", localSum, host.GetName(), remoteSum)
+	}
+	if err := os.WriteFile(s.LocalPath+SnapshotChecksumSuffix,
+		[]byte(fmt.Sprintf("%s  %s\n", localSum, filepath.Base(s.LocalPath))), 0644); err != nil {
+		return errors.Wrap(err, "write snapshot checksum failed")
+	}
+
+	logger.Log.Messagef(host.GetName(), "etcd snapshot saved to %s (revision: %d, keys: %d, size: %d, sha256: %s)",
+		s.LocalPath, status.Revision, status.TotalKey, status.TotalSize, localSum)
+	return nil
+}
+
+type VerifyLocalSnapshot struct {
+	common.KubeAction
+	LocalPath string
+}
+
+func (v *VerifyLocalSnapshot) Execute(runtime connector.Runtime) error {
+	if _, err := os.Stat(v.LocalPath); err != nil {
+		return errors.Wrapf(err, "snapshot %s is not found", v.LocalPath)
+	}
+	sum, err := LocalSha256(v.LocalPath)
+	if err != nil {
+		return errors.Wrapf(err, "get sha256 of %s failed", v.LocalPath)
+	}
+	// the checksum file is written by "kk etcd snapshot". it's optional for the snapshot from other place.
+	if data, err := os.ReadFile(v.LocalPath + SnapshotChecksumSuffix); err == nil {
+		fields := strings.Fields(string(data))
+		if len(fields) == 0 || fields[0] != sum {
+			return errors.Errorf("the sha256 of snapshot %s is %s, which is not equal to %s", v.LocalPath, sum, v.LocalPath+SnapshotChecksumSuffix)
+		}
+	} else if !os.IsNotExist(err) {
+		return errors.Wrap(err, "read snapshot checksum failed")
+	}
+	v.PipelineCache.Set(SnapshotChecksum, sum)
+	return nil
+}
+
+type SyncSnapshot struct {
+	common.KubeAction
+	LocalPath string
+}
+
+func (s *SyncSnapshot) Execute(runtime connector.Runtime) error {
+	sum, ok := s.PipelineCache.GetMustString(SnapshotChecksum)
+	if !ok {
+		return errors.New("get snapshot checksum by pipeline cache failed")
+	}
+	if err := utils.ResetTmpDir(runtime); err != nil {
+		return err
+	}
+	if err := runtime.GetRunner().Scp(s.LocalPath, RemoteSnapshot); err != nil {
+		return errors.Wrap(errors.WithStack(err), "sync etcd snapshot failed")
+	}
+	remoteSum, err := remoteSha256(runtime, RemoteSnapshot)
+	if err != nil {
+		return err
+	}
+	if remoteSum != sum {
+		return errors.Errorf("the sha256 of synced snapshot %s is not equal to the local one %s", remoteSum, sum)
+	}
+	if _, err := snapshotStatus(runtime, RemoteSnapshot); err != nil {
+		return err
+	}
+	return nil
+}
+
+type StopKubeAPIServer struct {
+	common.KubeAction
+}
+
+func (s *StopKubeAPIServer) Execute(runtime connector.Runtime) error {
+	// kubelet stops the static pod when its manifest is removed.
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("if [ -f %s ]; then mv -f %s %s; fi",
+		kubeAPIServerManifest, kubeAPIServerManifest, kubeAPIServerManifestBackup), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "stop kube-apiserver failed")
+	}
+	return nil
+}
+
+type WaitKubeAPIServerStopped struct {
+	common.KubeAction
+}
+
+func (w *WaitKubeAPIServerStopped) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd("pgrep -x kube-apiserver", false); err == nil {
+		return errors.New("kube-apiserver is still running")
+	}
+	return nil
+}
+
+type StopETCD struct {
+	common.KubeAction
+}
+
+func (s *StopETCD) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd("systemctl stop etcd", false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "stop etcd failed")
+	}
+	return nil
+}
+
+// newEtcdCluster returns the EtcdCluster with hosts as members.
+func newEtcdCluster(hosts []connector.Host, kubeConf *common.KubeConf) (*EtcdCluster, error) {
+	initialCluster, err := InitialCluster(hosts, kubeConf.Cluster.Etcd.GetPeerPort())
+	if err != nil {
+		return nil, err
+	}
+	addrList := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		addrList = append(addrList, fmt.Sprintf("https://%s:%d", host.GetInternalIPv4Address(), kubeConf.Cluster.Etcd.GetPort()))
+	}
+	return &EtcdCluster{
+		clusterExist:    true,
+		accessAddresses: strings.Join(addrList, ","),
+		peerAddresses:   strings.Split(initialCluster, ","),
+	}, nil
+}
+
+type GenerateRestoreCluster struct {
+	common.KubeAction
+}
+
+func (g *GenerateRestoreCluster) Execute(runtime connector.Runtime) error {
+	// all members are restored from the snapshot as a new cluster.
+	cluster, err := newEtcdCluster(runtime.GetHostsByRole(common.ETCD), g.KubeConf)
+	if err != nil {
+		return err
+	}
+	g.PipelineCache.Set(common.ETCDCluster, cluster)
+	return nil
+}
+
+type RestoreSnapshot struct {
+	common.KubeAction
+}
+
+func (r *RestoreSnapshot) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	etcdName, ok := host.GetCache().GetMustString(common.ETCDName)
+	if !ok {
+		return errors.New("get etcd node status by host label failed")
+	}
+	v, ok := r.PipelineCache.Get(common.ETCDCluster)
+	if !ok {
+		return errors.New("get etcd cluster status by pipeline cache failed")
+	}
+	cluster := v.(*EtcdCluster)
+
+	// keep the old data, it can be moved back by hand if the restored cluster is not expected.
+	dataDir := DataDir(r.KubeConf)
+	backupDir := fmt.Sprintf("%s-%s", dataDir, time.Now().Format("20060102150405"))
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("if [ -d %s ]; then mv %s %s; fi", dataDir, dataDir, backupDir), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "backup etcd data dir failed")
+	}
+
+	tool := fmt.Sprintf("export ETCDCTL_API=3;%s/etcdctl", common.BinDir)
+	if exist, err := runtime.GetRunner().FileExist(filepath.Join(common.BinDir, "etcdutl")); err == nil && exist {
+		tool = filepath.Join(common.BinDir, "etcdutl")
+	}
+	restoreCmd := fmt.Sprintf("%s snapshot restore %s --name=%s --initial-cluster=%s --initial-cluster-token=k8s_etcd "+
+		"--initial-advertise-peer-urls=https://%s:%d --data-dir=%s",
+		tool, RemoteSnapshot, etcdName, strings.Join(cluster.peerAddresses, ","),
+		host.GetInternalIPv4Address(), r.KubeConf.Cluster.Etcd.GetPeerPort(), dataDir)
+	if _, err := runtime.GetRunner().SudoCmd(restoreCmd, true); err != nil {
+		return errors.Wrap(errors.WithStack(err), "restore etcd snapshot failed")
+	}
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("chmod 700 %s && rm -f %s", dataDir, RemoteSnapshot), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "clean etcd snapshot failed")
+	}
+
+	// the member is restored with the data dir, the env file is refreshed by the members of snapshot.
+	return refreshConfig(r.KubeConf, runtime, cluster.peerAddresses, ExistCluster, etcdName)
+}
+
+type StartETCD struct {
+	common.KubeAction
+}
+
+func (s *StartETCD) Execute(runtime connector.Runtime) error {
+	// etcd notifies systemd after the quorum is ready, so the members are started without blocking.
+	if _, err := runtime.GetRunner().SudoCmd("systemctl daemon-reload && systemctl enable etcd && systemctl start --no-block etcd", false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "start etcd failed")
+	}
+	return nil
+}
+
+type StartKubeAPIServer struct {
+	common.KubeAction
+}
+
+func (s *StartKubeAPIServer) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("if [ -f %s ]; then mv -f %s %s; fi",
+		kubeAPIServerManifestBackup, kubeAPIServerManifestBackup, kubeAPIServerManifest), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "start kube-apiserver failed")
+	}
+	return nil
+}
+
+type KubeAPIServerHealthCheck struct {
+	common.KubeAction
+}
+
+func (k *KubeAPIServerHealthCheck) Execute(runtime connector.Runtime) error {
+	checkCmd := fmt.Sprintf("%s/kubectl --kubeconfig=%s/admin.conf get --raw=/readyz", common.BinDir, common.KubeConfigDir)
+	if _, err := runtime.GetRunner().SudoCmd(checkCmd, false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "kube-apiserver health check failed")
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package etcd
+
+import (
+	"testing"
+
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
+)
+
+func newEtcdHost(name, address, etcdName string) connector.Host {
+	host := connector.NewHost()
+	host.SetName(name)
+	host.SetInternalAddress(address)
+	if etcdName != "" {
+		host.GetCache().Set(common.ETCDName, etcdName)
+	}
+	return host
+}
+
+func TestInitialCluster(t *testing.T) {
+	tests := []struct {
+		name    string
+		hosts   []connector.Host
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "members with existing and default etcd name",
+			hosts: []connector.Host{
+				newEtcdHost("node1", "192.168.0.2", "etcd-node1"),
+				newEtcdHost("node2", "192.168.0.3", "etcd-old-name"),
+			},
+			want: "etcd-node1=https://192.168.0.2:2380,etcd-old-name=https://192.168.0.3:2380",
+		},
+		{
+			name: "etcd name not found",
+			hosts: []connector.Host{
+				newEtcdHost("node1", "192.168.0.2", ""),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := InitialCluster(tt.hosts, 2380)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("InitialCluster() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("InitialCluster() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
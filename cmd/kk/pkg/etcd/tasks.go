@@ -17,9 +17,11 @@
 package etcd
 
 import (
+	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -27,6 +29,7 @@ import (
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/action"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/logger"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/util"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/etcd/templates"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/files"
@@ -410,6 +413,29 @@ func (r *RestartETCD) Execute(runtime connector.Runtime) error {
 	return nil
 }
 
+// RollingRestartETCD restarts etcd and waits until it's healthy, so that the etcd members are restarted one by one.
+type RollingRestartETCD struct {
+	common.KubeAction
+}
+
+func (r *RollingRestartETCD) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	if _, err := runtime.GetRunner().SudoCmd("systemctl restart etcd", false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "restart etcd failed")
+	}
+
+	checkCmd := fmt.Sprintf("%s%s/etcdctl --endpoints=https://%s:%d endpoint health",
+		etcdctlEnv(host), common.BinDir, host.GetInternalIPv4Address(), r.KubeConf.Cluster.Etcd.GetPort())
+	var err error
+	for i := 0; i < 20; i++ {
+		if _, err = runtime.GetRunner().SudoCmd(checkCmd, false); err == nil {
+			return nil
+		}
+		time.Sleep(5 * time.Second)
+	}
+	return errors.Wrapf(errors.WithStack(err), "etcd on %s is not healthy after restart", host.GetName())
+}
+
 type BackupETCD struct {
 	common.KubeAction
 }
@@ -450,3 +476,131 @@ func (e *EnableBackupETCDService) Execute(runtime connector.Runtime) error {
 	}
 	return nil
 }
+
+// remainingHosts returns the etcd hosts except the node to delete.
+func remainingHosts(runtime connector.Runtime, nodeName string) []connector.Host {
+	var hosts []connector.Host
+	for _, host := range runtime.GetHostsByRole(common.ETCD) {
+		if host.GetName() != nodeName {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+type GenerateRemainingCluster struct {
+	common.KubeAction
+}
+
+func (g *GenerateRemainingCluster) Execute(runtime connector.Runtime) error {
+	nodeName, ok := g.PipelineCache.GetMustString(common.DstNode)
+	if !ok {
+		return errors.New("get the node to delete by pipeline cache failed")
+	}
+	cluster, err := newEtcdCluster(remainingHosts(runtime, nodeName), g.KubeConf)
+	if err != nil {
+		return err
+	}
+	g.PipelineCache.Set(common.ETCDCluster, cluster)
+	return nil
+}
+
+type CheckRemainingQuorum struct {
+	common.KubeAction
+}
+
+func (c *CheckRemainingQuorum) Execute(runtime connector.Runtime) error {
+	nodeName, ok := c.PipelineCache.GetMustString(common.DstNode)
+	if !ok {
+		return errors.New("get the node to delete by pipeline cache failed")
+	}
+	hosts := remainingHosts(runtime, nodeName)
+	healthy := 0
+	for _, host := range hosts {
+		checkCmd := fmt.Sprintf("%s%s/etcdctl --endpoints=https://%s:%d endpoint health",
+			etcdctlEnv(runtime.RemoteHost()), common.BinDir, host.GetInternalIPv4Address(), c.KubeConf.Cluster.Etcd.GetPort())
+		if _, err := runtime.GetRunner().SudoCmd(checkCmd, false); err != nil {
+			logger.Log.Warnf("etcd member on %s is unhealthy: %v", host.GetName(), err)
+			continue
+		}
+		healthy++
+	}
+	if quorum := len(hosts)/2 + 1; healthy < quorum {
+		return errors.Errorf("only %d of the remaining %d etcd members are healthy, removing node %s will drop etcd below quorum %d",
+			healthy, len(hosts), nodeName, quorum)
+	}
+	return nil
+}
+
+type RemoveMember struct {
+	common.KubeAction
+}
+
+func (r *RemoveMember) Execute(runtime connector.Runtime) error {
+	nodeName, ok := r.PipelineCache.GetMustString(common.DstNode)
+	if !ok {
+		return errors.New("get the node to delete by pipeline cache failed")
+	}
+	var node connector.Host
+	for _, host := range runtime.GetHostsByRole(common.ETCD) {
+		if host.GetName() == nodeName {
+			node = host
+		}
+	}
+	if node == nil {
+		return errors.Errorf("etcd node %s is not found", nodeName)
+	}
+
+	host := runtime.RemoteHost()
+	endpoint := fmt.Sprintf("https://%s:%d", host.GetInternalIPv4Address(), r.KubeConf.Cluster.Etcd.GetPort())
+	out, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("%s%s/etcdctl --endpoints=%s member list -w json",
+		etcdctlEnv(host), common.BinDir, endpoint), false)
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "list etcd member failed")
+	}
+	name, hasName := node.GetCache().GetMustString(common.ETCDName)
+	id, ok, err := findMember(out, name, hasName,
+		fmt.Sprintf("https://%s:%d", node.GetInternalIPv4Address(), r.KubeConf.Cluster.Etcd.GetPeerPort()))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// the member has been removed.
+		logger.Log.Messagef(host.GetName(), "etcd member of %s is not found, skip removing", nodeName)
+		return nil
+	}
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("%s%s/etcdctl --endpoints=%s member remove %x",
+		etcdctlEnv(host), common.BinDir, endpoint, id), true); err != nil {
+		return errors.Wrap(errors.WithStack(err), "remove etcd member failed")
+	}
+	return nil
+}
+
+// findMember returns the id of member which matches the name or peer url in the output of "etcdctl member list -w json".
+func findMember(memberList string, name string, hasName bool, peerURL string) (uint64, bool, error) {
+	// the output may contain the deprecated warning of etcdctl before the json.
+	if i := strings.Index(memberList, "{"); i > 0 {
+		memberList = memberList[i:]
+	}
+	var list struct {
+		Members []struct {
+			ID       uint64   `json:"ID"`
+			Name     string   `json:"name"`
+			PeerURLs []string `json:"peerURLs"`
+		} `json:"members"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(memberList)), &list); err != nil {
+		return 0, false, errors.Wrapf(err, "parse etcd member list failed: %s", memberList)
+	}
+	for _, member := range list.Members {
+		if hasName && member.Name == name {
+			return member.ID, true, nil
+		}
+		for _, url := range member.PeerURLs {
+			if url == peerURL {
+				return member.ID, true, nil
+			}
+		}
+	}
+	return 0, false, nil
+}
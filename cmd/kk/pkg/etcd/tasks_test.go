@@ -0,0 +1,53 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package etcd
+
+import "testing"
+
+func TestFindMember(t *testing.T) {
+	memberList := `{"header":{"cluster_id":17237436991929493444,"member_id":9372538179322589801,"raft_term":2},` +
+		`"members":[{"ID":9372538179322589801,"name":"etcd-node1","peerURLs":["https://192.168.0.2:2380"]},` +
+		`{"ID":10501334649042878790,"name":"etcd-node2","peerURLs":["https://192.168.0.3:2380"]}]}`
+
+	tests := []struct {
+		name    string
+		etcd    string
+		hasName bool
+		peerURL string
+		id      uint64
+		found   bool
+	}{
+		{name: "by name", etcd: "etcd-node2", hasName: true, peerURL: "https://192.168.0.9:2380", id: 10501334649042878790, found: true},
+		{name: "by peer url", peerURL: "https://192.168.0.2:2380", id: 9372538179322589801, found: true},
+		{name: "not found", etcd: "etcd-node3", hasName: true, peerURL: "https://192.168.0.4:2380"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, found, err := findMember(memberList, tt.etcd, tt.hasName, tt.peerURL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if id != tt.id || found != tt.found {
+				t.Errorf("findMember() = %d, %v, want %d, %v", id, found, tt.id, tt.found)
+			}
+		})
+	}
+
+	if _, _, err := findMember("not json", "", false, ""); err == nil {
+		t.Error("findMember() with invalid output should return error")
+	}
+}
@@ -30,6 +30,11 @@ type StatusModule struct {
 	common.KubeModule
 }
 
+// IsStateful the cluster status is used by the following modules.
+func (s *StatusModule) IsStateful() bool {
+	return true
+}
+
 func (s *StatusModule) Init() {
 	s.Name = "StatusModule"
 	s.Desc = "Get cluster status"
@@ -0,0 +1,156 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/connector"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/utils/certs"
+)
+
+const frontProxyClient = "front-proxy-client"
+
+// externalCAFiles returns the files of the CAs provided in spec.certificates, which are placed in the cert dir
+// before kubeadm runs. Only the certs signed by external signer are returned when renew.
+func externalCAFiles(c kubekeyapiv1alpha2.Certificates, renew bool) []string {
+	var files []string
+	if c.Kubernetes != nil && !renew {
+		files = append(files, "ca.crt", "ca-chain.crt", "ca.key")
+	}
+	if c.FrontProxy != nil {
+		if !renew {
+			files = append(files, "front-proxy-ca.crt", "front-proxy-ca-chain.crt")
+			if c.FrontProxy.KeyFile != "" {
+				files = append(files, "front-proxy-ca.key")
+			}
+		}
+		// kubeadm uses the front-proxy-client cert on disk when the front-proxy-ca key is absent.
+		if c.FrontProxy.Signer != nil {
+			files = append(files, frontProxyClient+".crt", frontProxyClient+".key")
+		}
+	}
+	return files
+}
+
+func localExternalCADir(runtime connector.Runtime) string {
+	return filepath.Join(runtime.GetWorkDir(), "pki", "kubernetes")
+}
+
+// GenerateExternalCACerts installs the CAs provided in spec.certificates to the local dir,
+// and signs the front-proxy-client cert when the front-proxy CA is used by an external signer.
+type GenerateExternalCACerts struct {
+	common.KubeAction
+	Renew bool
+}
+
+func (g *GenerateExternalCACerts) Execute(runtime connector.Runtime) error {
+	c := g.KubeConf.Cluster.Certificates
+	dir := localExternalCADir(runtime)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return errors.Wrapf(err, "create dir %s failed", dir)
+	}
+
+	if ca := c.Kubernetes; ca != nil {
+		if err := ca.Validate("kubernetes"); err != nil {
+			return err
+		}
+		// kube-controller-manager signs the kubelet certs with the CA key.
+		if ca.Signer != nil {
+			return errors.New("certificates.kubernetes.signer is not supported, the CA key is required by kube-controller-manager")
+		}
+		if err := certs.InstallCertificateAuthority(ca, filepath.Join(dir, "ca.crt"), filepath.Join(dir, "ca.key")); err != nil {
+			return err
+		}
+	}
+
+	ca := c.FrontProxy
+	if ca == nil {
+		return nil
+	}
+	if err := ca.Validate("frontProxy"); err != nil {
+		return err
+	}
+	caCertPath := filepath.Join(dir, "front-proxy-ca.crt")
+	if err := certs.InstallCertificateAuthority(ca, caCertPath, filepath.Join(dir, "front-proxy-ca.key")); err != nil {
+		return err
+	}
+	if ca.Signer == nil {
+		return nil
+	}
+
+	certPath := filepath.Join(dir, frontProxyClient+".crt")
+	if _, err := os.Stat(certPath); err == nil && !g.Renew {
+		return nil
+	}
+	caCerts, err := certutil.CertsFromFile(caCertPath)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't load CA certificate %s", caCertPath)
+	}
+	cert, key, err := certs.NewCertWithSigner(ca.Signer, caCerts[0], &certs.CertConfig{
+		Config: certutil.Config{
+			CommonName: frontProxyClient,
+			Usages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "couldn't sign %s certificate by external signer", frontProxyClient)
+	}
+	keyPEM, err := keyutil.MarshalPrivateKeyToPEM(key)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal private key to PEM")
+	}
+	if err := keyutil.WriteKey(filepath.Join(dir, frontProxyClient+".key"), keyPEM); err != nil {
+		return errors.Wrapf(err, "unable to write %s key", frontProxyClient)
+	}
+	if err := certutil.WriteCert(certPath, certs.EncodeCertPEM(cert)); err != nil {
+		return errors.Wrapf(err, "unable to write %s certificate", frontProxyClient)
+	}
+	return nil
+}
+
+// SyncExternalCACerts synchronizes the CAs provided in spec.certificates to the cert dir of master.
+type SyncExternalCACerts struct {
+	common.KubeAction
+	Renew bool
+}
+
+func (s *SyncExternalCACerts) Execute(runtime connector.Runtime) error {
+	dir := localExternalCADir(runtime)
+	for _, file := range externalCAFiles(s.KubeConf.Cluster.Certificates, s.Renew) {
+		remote := filepath.Join(common.KubeCertDir, file)
+		if err := runtime.GetRunner().SudoScp(filepath.Join(dir, file), remote); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "sync %s failed", file)
+		}
+		if filepath.Ext(file) != ".key" {
+			continue
+		}
+		if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("chmod 600 %s", remote), false); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "chmod %s failed", remote)
+		}
+	}
+	return nil
+}
@@ -39,6 +39,11 @@ type StatusModule struct {
 	common.KubeModule
 }
 
+// IsStateful the cluster status is used by the following modules.
+func (k *StatusModule) IsStateful() bool {
+	return true
+}
+
 func (k *StatusModule) Init() {
 	k.Name = "KubernetesStatusModule"
 	k.Desc = "Get kubernetes cluster status"
@@ -236,6 +241,47 @@ func (i *InitKubernetesModule) Init() {
 	}
 }
 
+// ExternalCAModule places the CAs provided in spec.certificates on the masters before kubeadm runs.
+type ExternalCAModule struct {
+	common.KubeModule
+	Skip bool
+	// Renew only signs the certs of external signer again.
+	Renew bool
+}
+
+func (e *ExternalCAModule) IsSkip() bool {
+	return e.Skip
+}
+
+func (e *ExternalCAModule) Init() {
+	e.Name = "ExternalCAModule"
+	e.Desc = "Use the existing CAs of kubernetes"
+
+	generate := &task.LocalTask{
+		Name:   "GenerateExternalCACerts",
+		Desc:   "Generate certs with the existing CAs",
+		Action: &GenerateExternalCACerts{Renew: e.Renew},
+	}
+
+	sync := &task.RemoteTask{
+		Name:     "SyncExternalCACerts",
+		Desc:     "Synchronize the existing CAs to master",
+		Hosts:    e.Runtime.GetHostsByRole(common.Master),
+		Prepare:  &NodeInCluster{Not: true},
+		Action:   &SyncExternalCACerts{Renew: e.Renew},
+		Parallel: true,
+		Retry:    1,
+	}
+	if e.Renew {
+		sync.Prepare = nil
+	}
+
+	e.Tasks = []task.Interface{
+		generate,
+		sync,
+	}
+}
+
 type JoinNodesModule struct {
 	common.KubeModule
 }
@@ -397,8 +443,7 @@ func (c *CompareConfigAndClusterInfoModule) Init() {
 		Desc:    "Find information about nodes that are expected to be deleted",
 		Hosts:   c.Runtime.GetHostsByRole(common.Master),
 		Prepare: new(common.OnlyFirstMaster),
-		//Action:  new(FindNode),
-		Action: new(FilterFirstMaster),
+		Action:  new(FindDeleteNode),
 	}
 
 	c.Tasks = []task.Interface{
@@ -428,6 +473,11 @@ func (r *RestartKubeletModule) init() {
 
 type DeleteKubeNodeModule struct {
 	common.KubeModule
+	Skip bool
+}
+
+func (d *DeleteKubeNodeModule) IsSkip() bool {
+	return d.Skip
 }
 
 func (d *DeleteKubeNodeModule) Init() {
@@ -452,9 +502,20 @@ func (d *DeleteKubeNodeModule) Init() {
 		Retry:   5,
 	}
 
+	// remove the static pods of control plane on the deleted master.
+	resetControlPlane := &task.RemoteTask{
+		Name:     "ResetControlPlane",
+		Desc:     "Reset the control plane of the deleted node",
+		Hosts:    d.Runtime.GetHostsByRole(common.Master),
+		Prepare:  new(common.OnlyDeleteNode),
+		Action:   new(KubeadmReset),
+		Parallel: true,
+	}
+
 	d.Tasks = []task.Interface{
 		drain,
 		deleteNode,
+		resetControlPlane,
 	}
 }
 
@@ -447,66 +447,50 @@ func (k *KubeadmReset) Execute(runtime connector.Runtime) error {
 	return nil
 }
 
-type FilterFirstMaster struct {
+type FindDeleteNode struct {
 	common.KubeAction
 }
 
-func filterString(filter string, nodes []string) []string {
-	j := 0
-	for _, v := range nodes {
-		if v != filter {
-			nodes[j] = v
-			j++
+func (f *FindDeleteNode) Execute(runtime connector.Runtime) error {
+	nodeName := f.KubeConf.Arg.NodeName
+	var node connector.Host
+	for _, host := range runtime.GetAllHosts() {
+		if host.GetName() == nodeName {
+			node = host
+			break
 		}
 	}
-	resArr := nodes[:j]
-	return resArr
-}
-
-func (f *FilterFirstMaster) Execute(runtime connector.Runtime) error {
-	firstMaster := runtime.GetHostsByRole(common.Master)[0].GetName()
-	//kubectl get node
-	var nodes []string
-	res, err := runtime.GetRunner().Cmd(
-		"sudo -E /usr/local/bin/kubectl get nodes | awk '{print $1}'",
-		true)
-	if err != nil {
-		return errors.Wrap(errors.WithStack(err), "kubectl get nodes failed")
+	if node == nil {
+		return errors.Errorf("node %s is not found in the config file", nodeName)
 	}
 
-	if !strings.Contains(res, "\r\n") {
-		nodes = append(nodes, res)
-	} else {
-		nodes = strings.Split(res, "\r\n")
-	}
-	//nodes filter first master
-	resArr := filterString(firstMaster, nodes)
-	//nodes filter etcd nodes
-	for i := 0; i < len(runtime.GetHostsByRole(common.ETCD)); i++ {
-		etcdName := runtime.GetHostsByRole(common.ETCD)[i].GetName()
-		resArr = filterString(etcdName, resArr)
-	}
-	workerName := make(map[string]struct{})
-	for j := 0; j < len(runtime.GetHostsByRole(common.Worker)); j++ {
-		workerName[runtime.GetHostsByRole(common.Worker)[j].GetName()] = struct{}{}
+	if node.IsRole(common.Master) && len(runtime.GetHostsByRole(common.Master)) <= 1 {
+		return errors.Errorf("node %s is the last control-plane node, it can not be deleted", nodeName)
 	}
-	//make sure node is not the first master and etcd node name
-	var node string
-	for i := 0; i < len(resArr); i++ {
-		if _, ok := workerName[resArr[i]]; ok && resArr[i] == f.KubeConf.Arg.NodeName {
-			node = resArr[i]
-			break
-		}
+	// the quorum of remaining etcd members is checked when the member is removed.
+	if node.IsRole(common.ETCD) && f.KubeConf.Cluster.Etcd.Type == kubekeyv1alpha2.KubeKey &&
+		len(runtime.GetHostsByRole(common.ETCD)) <= 1 {
+		return errors.Errorf("node %s is the last etcd node, it can not be deleted", nodeName)
 	}
 
-	if node == "" {
-		return errors.New("" +
-			"1. check the node name in the config-sample.yaml\n" +
-			"2. check the node name in the Kubernetes cluster\n" +
-			"3. check the node name is the first master and etcd node name\n")
+	if node.IsRole(common.K8s) {
+		res, err := runtime.GetRunner().SudoCmd("/usr/local/bin/kubectl get nodes -o jsonpath='{.items[*].metadata.name}'", false)
+		if err != nil {
+			return errors.Wrap(errors.WithStack(err), "kubectl get nodes failed")
+		}
+		found := false
+		for _, name := range strings.Fields(res) {
+			if name == nodeName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errors.Errorf("node %s is not found in the Kubernetes cluster", nodeName)
+		}
 	}
 
-	f.PipelineCache.Set("dstNode", node)
+	f.PipelineCache.Set(common.DstNode, nodeName)
 	return nil
 }
 
@@ -549,7 +533,7 @@ func (f *FindNode) Execute(runtime connector.Runtime) error {
 			"3. only support to delete a worker\n")
 	}
 
-	f.PipelineCache.Set("dstNode", node)
+	f.PipelineCache.Set(common.DstNode, node)
 	return nil
 }
 
@@ -558,7 +542,7 @@ type DrainNode struct {
 }
 
 func (d *DrainNode) Execute(runtime connector.Runtime) error {
-	nodeName, ok := d.PipelineCache.Get("dstNode")
+	nodeName, ok := d.PipelineCache.Get(common.DstNode)
 	if !ok {
 		return errors.New("get dstNode failed by pipeline cache")
 	}
@@ -575,7 +559,7 @@ type KubectlDeleteNode struct {
 }
 
 func (k *KubectlDeleteNode) Execute(runtime connector.Runtime) error {
-	nodeName, ok := k.PipelineCache.Get("dstNode")
+	nodeName, ok := k.PipelineCache.Get(common.DstNode)
 	if !ok {
 		return errors.New("get dstNode failed by pipeline cache")
 	}
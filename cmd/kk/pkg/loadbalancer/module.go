@@ -347,6 +347,8 @@ func (k *K3sKubevipModule) Init() {
 type DeleteVIPModule struct {
 	common.KubeModule
 	Skip bool
+	// OnlyDeleteNode only deletes the VIP on the node to delete, which is set in pipeline cache by common.DstNode.
+	OnlyDeleteNode bool
 }
 
 func (k *DeleteVIPModule) IsSkip() bool {
@@ -373,8 +375,49 @@ func (k *DeleteVIPModule) Init() {
 		Parallel: true,
 	}
 
+	if k.OnlyDeleteNode {
+		getInterface.Prepare = new(common.OnlyDeleteNode)
+		DeleteVIP.Prepare = new(common.OnlyDeleteNode)
+	}
+
 	k.Tasks = []task.Interface{
 		getInterface,
 		DeleteVIP,
 	}
 }
+
+// RefreshKubevipModule regenerates the kube-vip manifest on all masters, e.g. after a master is deleted.
+type RefreshKubevipModule struct {
+	common.KubeModule
+	Skip bool
+}
+
+func (k *RefreshKubevipModule) IsSkip() bool {
+	return k.Skip
+}
+
+func (k *RefreshKubevipModule) Init() {
+	k.Name = "RefreshKubevipModule"
+	k.Desc = "Refresh kube-vip manifest"
+
+	getInterface := &task.RemoteTask{
+		Name:     "GetNodeInterface",
+		Desc:     "Get Node Interface",
+		Hosts:    k.Runtime.GetHostsByRole(common.Master),
+		Action:   new(GetInterfaceName),
+		Parallel: true,
+	}
+
+	kubevipManifest := &task.RemoteTask{
+		Name:     "GenerateKubevipManifest",
+		Desc:     "Generate kubevip manifest at all master",
+		Hosts:    k.Runtime.GetHostsByRole(common.Master),
+		Action:   new(GenerateKubevipManifest),
+		Parallel: true,
+	}
+
+	k.Tasks = []task.Interface{
+		getInterface,
+		kubevipManifest,
+	}
+}
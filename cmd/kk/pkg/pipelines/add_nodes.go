@@ -43,6 +43,7 @@ import (
 
 func NewAddNodesPipeline(runtime *common.KubeRuntime) error {
 	noArtifact := runtime.Arg.Artifact == ""
+	noExternalCA := runtime.Cluster.Certificates.Kubernetes == nil && runtime.Cluster.Certificates.FrontProxy == nil
 
 	m := []module.Module{
 		&precheck.GreetingsModule{},
@@ -67,6 +68,7 @@ func NewAddNodesPipeline(runtime *common.KubeRuntime) error {
 		&etcd.ConfigureModule{Skip: runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey},
 		&etcd.BackupModule{Skip: runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey},
 		&kubernetes.InstallKubeBinariesModule{},
+		&kubernetes.ExternalCAModule{Skip: noExternalCA},
 		&kubernetes.JoinNodesModule{},
 		&loadbalancer.HaproxyModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabled()},
 		&kubernetes.ConfigureKubernetesModule{},
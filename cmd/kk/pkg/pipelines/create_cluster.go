@@ -46,6 +46,7 @@ import (
 
 func NewCreateClusterPipeline(runtime *common.KubeRuntime) error {
 	noArtifact := runtime.Arg.Artifact == ""
+	noExternalCA := runtime.Cluster.Certificates.Kubernetes == nil && runtime.Cluster.Certificates.FrontProxy == nil
 	skipPushImages := runtime.Arg.SkipPushImages || noArtifact || (!noArtifact && runtime.Cluster.Registry.PrivateRegistry == "")
 	skipLocalStorage := true
 	if runtime.Arg.DeployLocalStorage != nil {
@@ -80,6 +81,7 @@ func NewCreateClusterPipeline(runtime *common.KubeRuntime) error {
 			&kubernetes.InstallKubeBinariesModule{},
 			// init kubeVip on first master
 			&loadbalancer.KubevipModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabledVip()},
+			&kubernetes.ExternalCAModule{Skip: noExternalCA},
 			&kubernetes.InitKubernetesModule{},
 			&dns.ClusterDNSModule{},
 			&kubernetes.StatusModule{},
@@ -104,9 +106,10 @@ func NewCreateClusterPipeline(runtime *common.KubeRuntime) error {
 	}
 
 	p := pipeline.Pipeline{
-		Name:    "CreateClusterPipeline",
-		Modules: m,
-		Runtime: runtime,
+		Name:       "CreateClusterPipeline",
+		Modules:    m,
+		Runtime:    runtime,
+		Checkpoint: runtime.Checkpoint(),
 	}
 	if err := p.Start(); err != nil {
 		return err
@@ -85,9 +85,10 @@ func NewK3sCreateClusterPipeline(runtime *common.KubeRuntime) error {
 	}
 
 	p := pipeline.Pipeline{
-		Name:    "K3sCreateClusterPipeline",
-		Modules: m,
-		Runtime: runtime,
+		Name:       "K3sCreateClusterPipeline",
+		Modules:    m,
+		Runtime:    runtime,
+		Checkpoint: runtime.Checkpoint(),
 	}
 	if err := p.Start(); err != nil {
 		return err
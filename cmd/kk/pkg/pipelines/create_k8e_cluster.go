@@ -85,9 +85,10 @@ func NewK8eCreateClusterPipeline(runtime *common.KubeRuntime) error {
 	}
 
 	p := pipeline.Pipeline{
-		Name:    "K8eCreateClusterPipeline",
-		Modules: m,
-		Runtime: runtime,
+		Name:       "K8eCreateClusterPipeline",
+		Modules:    m,
+		Runtime:    runtime,
+		Checkpoint: runtime.Checkpoint(),
 	}
 	if err := p.Start(); err != nil {
 		return err
@@ -17,24 +17,41 @@
 package pipelines
 
 import (
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/bootstrap/confirm"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/bootstrap/os"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/bootstrap/precheck"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/module"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/pipeline"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/etcd"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/kubernetes"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/loadbalancer"
 )
 
 func DeleteNodePipeline(runtime *common.KubeRuntime) error {
+	var deleteKube, deleteMaster, deleteETCD bool
+	for _, host := range runtime.GetAllHosts() {
+		if host.GetName() == runtime.Arg.NodeName {
+			deleteKube = host.IsRole(common.K8s)
+			deleteMaster = host.IsRole(common.Master)
+			deleteETCD = host.IsRole(common.ETCD) && runtime.Cluster.Etcd.Type == kubekeyapiv1alpha2.KubeKey
+		}
+	}
+	vip := runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabledVip()
+
 	m := []module.Module{
 		&precheck.GreetingsModule{},
 		&confirm.DeleteNodeConfirmModule{Skip: runtime.Arg.SkipConfirmCheck},
 		&kubernetes.CompareConfigAndClusterInfoModule{},
-		&kubernetes.DeleteKubeNodeModule{},
+		&kubernetes.DeleteKubeNodeModule{Skip: !deleteKube},
+		&etcd.PreCheckModule{Skip: !deleteETCD},
+		&etcd.RemoveMemberModule{Skip: !deleteETCD},
+		&loadbalancer.DeleteVIPModule{Skip: !(vip && deleteMaster), OnlyDeleteNode: true},
 		&os.ClearNodeOSModule{},
-		&loadbalancer.DeleteVIPModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabledVip()},
+		// the load balancers on the remaining nodes are refreshed without the deleted master.
+		&loadbalancer.HaproxyModule{Skip: !(deleteMaster && runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabled())},
+		&loadbalancer.RefreshKubevipModule{Skip: !(deleteMaster && vip)},
 	}
 
 	p := pipeline.Pipeline{
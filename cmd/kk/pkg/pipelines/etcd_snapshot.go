@@ -0,0 +1,107 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pipelines
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/bootstrap/confirm"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/bootstrap/precheck"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/module"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/pipeline"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/etcd"
+)
+
+func EtcdSnapshotPipeline(runtime *common.KubeRuntime) error {
+	m := []module.Module{
+		&precheck.GreetingsModule{},
+		&etcd.PreCheckModule{},
+		&etcd.SnapshotModule{LocalPath: runtime.Arg.SnapshotPath},
+	}
+
+	p := pipeline.Pipeline{
+		Name:    "EtcdSnapshotPipeline",
+		Modules: m,
+		Runtime: runtime,
+	}
+	if err := p.Start(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func EtcdRestorePipeline(runtime *common.KubeRuntime) error {
+	m := []module.Module{
+		&precheck.GreetingsModule{},
+		&confirm.ETCDRestoreConfirmModule{Skip: runtime.Arg.SkipConfirmCheck},
+		&etcd.PreCheckModule{},
+		&etcd.RestoreModule{LocalPath: runtime.Arg.SnapshotPath},
+	}
+
+	p := pipeline.Pipeline{
+		Name:    "EtcdRestorePipeline",
+		Modules: m,
+		Runtime: runtime,
+	}
+	if err := p.Start(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func EtcdSnapshot(args common.Argument) error {
+	runtime, err := newEtcdRuntime(args)
+	if err != nil {
+		return err
+	}
+	return EtcdSnapshotPipeline(runtime)
+}
+
+func EtcdRestore(args common.Argument) error {
+	if args.SnapshotPath == "" {
+		return errors.New("the snapshot to restore is required")
+	}
+	runtime, err := newEtcdRuntime(args)
+	if err != nil {
+		return err
+	}
+	return EtcdRestorePipeline(runtime)
+}
+
+// newEtcdRuntime returns the runtime of the cluster whose etcd is installed by KubeKey.
+func newEtcdRuntime(args common.Argument) (*common.KubeRuntime, error) {
+	var loaderType string
+	if args.FilePath != "" {
+		loaderType = common.File
+	} else {
+		loaderType = common.AllInOne
+	}
+
+	runtime, err := common.NewKubeRuntime(loaderType, args)
+	if err != nil {
+		return nil, err
+	}
+	if runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey {
+		return nil, fmt.Errorf("etcd type %s is not supported, only the etcd installed by %s can be snapshot and restored",
+			runtime.Cluster.Etcd.Type, kubekeyapiv1alpha2.KubeKey)
+	}
+	return runtime, nil
+}
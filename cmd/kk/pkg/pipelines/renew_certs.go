@@ -17,17 +17,30 @@
 package pipelines
 
 import (
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/bootstrap/precheck"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/certs"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/module"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/core/pipeline"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/etcd"
+	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/kubernetes"
 )
 
 func RenewCertsPipeline(runtime *common.KubeRuntime) error {
+	etcdKubeKey := runtime.Cluster.Etcd.Type == kubekeyapiv1alpha2.KubeKey
+	rotateCA := runtime.Arg.RotateCA
+	finishCARotation := runtime.Arg.FinishCARotation
+	frontProxySigner := runtime.Cluster.Certificates.FrontProxy != nil && runtime.Cluster.Certificates.FrontProxy.Signer != nil
+
 	m := []module.Module{
 		&precheck.GreetingsModule{},
-		&certs.RenewCertsModule{},
+		&etcd.PreCheckModule{Skip: !etcdKubeKey},
+		&certs.RotateCAModule{Skip: !rotateCA && !finishCARotation, Finish: finishCARotation},
+		&etcd.RenewCertsModule{Skip: !etcdKubeKey || finishCARotation},
+		&kubernetes.ExternalCAModule{Skip: finishCARotation || !frontProxySigner, Renew: true},
+		&certs.RenewCertsModule{Skip: finishCARotation},
+		&certs.RestartSystemWorkloadsModule{Skip: !rotateCA && !finishCARotation},
 		&certs.CheckCertsModule{},
 		&certs.PrintClusterCertsModule{},
 	}
@@ -29,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	certutil "k8s.io/client-go/util/cert"
 
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
 	"github.com/kubesphere/kubekey/v3/cmd/kk/pkg/common"
 )
 
@@ -47,6 +48,8 @@ type KubekeyCert struct {
 	BaseName string
 	CAName   string
 	Config   CertConfig
+	// Signer signs the certs of the CA instead of the CA key.
+	Signer *kubekeyapiv1alpha2.ExternalSigner
 }
 
 // GetConfig returns the definition for the given cert given the provided InitConfiguration
@@ -81,6 +84,22 @@ func (k *KubekeyCert) CreateFromCA(kubeConf *common.KubeConf, pkiPath string, ca
 	return nil
 }
 
+// CreateFromSigner makes and writes a certificate signed by the external signer with the given CA cert.
+func (k *KubekeyCert) CreateFromSigner(kubeConf *common.KubeConf, pkiPath string, caCert *x509.Certificate, signer *kubekeyapiv1alpha2.ExternalSigner) error {
+	cfg, err := k.GetConfig(kubeConf)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't create %q certificate", k.Name)
+	}
+	cert, key, err := NewCertWithSigner(signer, caCert, cfg)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't sign %q certificate by external signer", k.Name)
+	}
+	if err := writeCertificateFilesIfNotExist(pkiPath, k.BaseName, caCert, cert, key, cfg); err != nil {
+		return errors.Wrapf(err, "failed to write or validate certificate %q", k.Name)
+	}
+	return nil
+}
+
 func GenerateCA(ca *KubekeyCert, pkiPath string, kubeConf *common.KubeConf) error {
 
 	if cert, err := TryLoadCertFromDisk(pkiPath, ca.BaseName); err == nil {
@@ -156,6 +175,14 @@ func CreateCertAndKeyFilesWithCA(caCertSpec *KubekeyCert, certSpec *KubekeyCert,
 		return errors.Errorf("expected CAname for %s to be %q, but was %s", certSpec.Name, certSpec.CAName, caCertSpec.Name)
 	}
 
+	if caCertSpec.Signer != nil {
+		caCert, err := TryLoadCertFromDisk(pkiPath, caCertSpec.BaseName)
+		if err != nil {
+			return errors.Wrapf(err, "couldn't load CA certificate %s", caCertSpec.Name)
+		}
+		return certSpec.CreateFromSigner(kubeConf, pkiPath, caCert, caCertSpec.Signer)
+	}
+
 	caCert, caKey, err := LoadCertificateAuthority(pkiPath, caCertSpec.BaseName)
 	if err != nil {
 		return errors.Wrapf(err, "couldn't load CA certificate %s", caCertSpec.Name)
@@ -0,0 +1,258 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package certs
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	cryptorand "crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
+)
+
+const defaultSignerTimeout = 30 * time.Second
+
+// SignRequest is the request to an external signer. It's posted to the HTTP signer, or written to the stdin
+// of the exec signer, in JSON. The format is documented in docs/config-example.md and shared with the gen_cert
+// module of v4 (feature/pkg/modules/gen_cert.go), keep them in sync.
+type SignRequest struct {
+	// CSR is the PEM encoded certificate signing request, which contains the subject and SANs of the cert.
+	CSR string `json:"csr"`
+	// Usages are the extended key usages of the cert, "server auth" or "client auth".
+	Usages []string `json:"usages"`
+	// Duration is the expected validity of the cert, e.g. "87600h0m0s".
+	Duration string `json:"duration"`
+}
+
+// SignResponse is the response of an external signer.
+type SignResponse struct {
+	// Certificate is the PEM encoded cert, optionally followed by the intermediate CAs.
+	Certificate string `json:"certificate"`
+}
+
+var extKeyUsageNames = map[x509.ExtKeyUsage]string{
+	x509.ExtKeyUsageServerAuth: "server auth",
+	x509.ExtKeyUsageClientAuth: "client auth",
+}
+
+// NewCertWithSigner creates a new key and a cert of it which is signed by the external signer with the CA of caCert.
+func NewCertWithSigner(signer *kubekeyapiv1alpha2.ExternalSigner, caCert *x509.Certificate, config *CertConfig) (*x509.Certificate, crypto.Signer, error) {
+	if len(config.Usages) == 0 {
+		return nil, nil, errors.New("must specify at least one ExtKeyUsage")
+	}
+	if len(config.CommonName) == 0 {
+		return nil, nil, errors.New("must specify a CommonName")
+	}
+
+	key, err := NewPrivateKey(config.PublicKeyAlgorithm)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to create private key")
+	}
+
+	RemoveDuplicateAltNames(&config.AltNames)
+	csr, err := x509.CreateCertificateRequest(cryptorand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{
+			CommonName:   config.CommonName,
+			Organization: config.Organization,
+		},
+		DNSNames:    config.AltNames.DNSNames,
+		IPAddresses: config.AltNames.IPs,
+	}, key)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to create certificate signing request")
+	}
+
+	req := SignRequest{
+		CSR:      string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr})),
+		Duration: CertificateValidity.String(),
+	}
+	if config.NotAfter != nil {
+		req.Duration = time.Until(*config.NotAfter).Round(time.Second).String()
+	}
+	for _, usage := range config.Usages {
+		name, ok := extKeyUsageNames[usage]
+		if !ok {
+			return nil, nil, errors.Errorf("unsupported ExtKeyUsage %v of external signer", usage)
+		}
+		req.Usages = append(req.Usages, name)
+	}
+
+	resp, err := sign(signer, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	certs, err := certutil.ParseCertsPEM([]byte(resp.Certificate))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "invalid certificate from external signer")
+	}
+	cert := certs[0]
+	if !KeyMatchesCert(cert, key) {
+		return nil, nil, errors.New("the certificate from external signer does not match the private key")
+	}
+	if err := VerifyCertChain(cert, certs[1:], caCert); err != nil {
+		return nil, nil, errors.Wrapf(err, "the certificate from external signer is not signed by CA %s", caCert.Subject.CommonName)
+	}
+	if err := validateCertificateWithConfig(cert, config.CommonName, config); err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+// sign sends the request to the HTTP or exec signer.
+func sign(signer *kubekeyapiv1alpha2.ExternalSigner, req SignRequest) (*SignResponse, error) {
+	timeout := defaultSignerTimeout
+	if signer.Timeout > 0 {
+		timeout = time.Duration(signer.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal sign request failed")
+	}
+
+	var out []byte
+	if signer.URL != "" {
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, signer.URL, bytes.NewReader(body))
+		if err != nil {
+			return nil, errors.Wrapf(err, "create request to signer %s failed", signer.URL)
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpResp, err := http.DefaultClient.Do(httpReq)
+		if err != nil {
+			return nil, errors.Wrapf(err, "request signer %s failed", signer.URL)
+		}
+		defer httpResp.Body.Close()
+		if out, err = io.ReadAll(httpResp.Body); err != nil {
+			return nil, errors.Wrapf(err, "read response of signer %s failed", signer.URL)
+		}
+		if httpResp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("signer %s returns %s: %s", signer.URL, httpResp.Status, bytes.TrimSpace(out))
+		}
+	} else {
+		if len(signer.Command) == 0 {
+			return nil, errors.New("either url or command of signer should be set")
+		}
+		cmd := exec.CommandContext(ctx, signer.Command[0], signer.Command[1:]...)
+		cmd.Stdin = bytes.NewReader(body)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if out, err = cmd.Output(); err != nil {
+			return nil, errors.Wrapf(err, "run signer %s failed: %s", signer.Command[0], bytes.TrimSpace(stderr.Bytes()))
+		}
+	}
+
+	resp := &SignResponse{}
+	if err := json.Unmarshal(out, resp); err != nil {
+		return nil, errors.Wrap(err, "parse response of signer failed")
+	}
+	if resp.Certificate == "" {
+		return nil, errors.New("no certificate in the response of signer")
+	}
+	return resp, nil
+}
+
+// PathForChain returns the path of the chain of the CA at certPath, e.g. ca-chain.crt for ca.crt.
+func PathForChain(certPath string) string {
+	ext := filepath.Ext(certPath)
+	return strings.TrimSuffix(certPath, ext) + "-chain" + ext
+}
+
+// InstallCertificateAuthority validates the CA provided by user, and copies it to certPath and keyPath.
+// Only the issuing CA is written to certPath, which is the trust anchor of the cluster, so the certs issued by
+// the other CAs under the same root are not trusted. The whole chain is written to PathForChain(certPath) for serving.
+// The key is not copied when the CA is used by an external signer.
+func InstallCertificateAuthority(ca *kubekeyapiv1alpha2.CertificateAuthority, certPath, keyPath string) error {
+	certs, err := certutil.CertsFromFile(ca.CertFile)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't load the CA certificate file %s", ca.CertFile)
+	}
+	caCert := certs[0]
+	if !caCert.IsCA {
+		return errors.Errorf("the first certificate in %s is not a certificate authority", ca.CertFile)
+	}
+	// the chain is ordered from the CA up to the root CA.
+	if len(certs) > 1 {
+		if err := VerifyCertChain(caCert, certs[1:len(certs)-1], certs[len(certs)-1]); err != nil {
+			return errors.Wrapf(err, "the chain in %s is invalid", ca.CertFile)
+		}
+	}
+	CheckCertificatePeriodValidity(ca.CertFile, caCert)
+
+	var keyPEM []byte
+	if ca.KeyFile != "" {
+		if keyPEM, err = os.ReadFile(ca.KeyFile); err != nil {
+			return errors.Wrapf(err, "couldn't read the CA key file %s", ca.KeyFile)
+		}
+		key, err := keyutil.ParsePrivateKeyPEM(keyPEM)
+		if err != nil {
+			return errors.Wrapf(err, "couldn't load the CA key file %s", ca.KeyFile)
+		}
+		if !KeyMatchesCert(caCert, key) {
+			return errors.Errorf("the CA key %s does not match the certificate %s", ca.KeyFile, ca.CertFile)
+		}
+	}
+
+	if err := certutil.WriteCert(certPath, EncodeCertPEM(caCert)); err != nil {
+		return errors.Wrapf(err, "unable to write certificate to file %s", certPath)
+	}
+	var chain []byte
+	for _, cert := range certs {
+		chain = append(chain, EncodeCertPEM(cert)...)
+	}
+	if err := certutil.WriteCert(PathForChain(certPath), chain); err != nil {
+		return errors.Wrapf(err, "unable to write certificate chain to file %s", PathForChain(certPath))
+	}
+	if keyPEM == nil {
+		// remove the key of the CA used before, the certs are signed by the external signer.
+		if err := os.Remove(keyPath); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "unable to remove private key file %s", keyPath)
+		}
+		return nil
+	}
+	if err := keyutil.WriteKey(keyPath, keyPEM); err != nil {
+		return errors.Wrapf(err, "unable to write private key to file %s", keyPath)
+	}
+	return nil
+}
+
+// KeyMatchesCert returns whether the key is the private key of cert.
+func KeyMatchesCert(cert *x509.Certificate, key interface{}) bool {
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return false
+	}
+	pub, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	return ok && pub.Equal(cert.PublicKey)
+}
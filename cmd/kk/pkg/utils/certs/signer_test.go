@@ -0,0 +1,218 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package certs
+
+import (
+	"crypto"
+	cryptorand "crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/v3/cmd/kk/apis/kubekey/v1alpha2"
+)
+
+// the fixtures in testdata/signer are shared with the gen_cert module of v4, see testdata/signer/README.md.
+const fixtures = "testdata/signer"
+
+func loadFixtureCert(t *testing.T, name string) *x509.Certificate {
+	t.Helper()
+	certs, err := certutil.CertsFromFile(filepath.Join(fixtures, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certs[0]
+}
+
+func loadFixtureKey(t *testing.T, name string) crypto.Signer {
+	t.Helper()
+	key, err := keyutil.PrivateKeyFromFile(filepath.Join(fixtures, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key.(crypto.Signer)
+}
+
+// newSignerServer returns a HTTP signer which signs the CSR by the CA of fixtures.
+func newSignerServer(t *testing.T, got *SignRequest) *httptest.Server {
+	t.Helper()
+	ca, caKey := loadFixtureCert(t, "ca.crt"), loadFixtureKey(t, "ca.key")
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(got); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		block, _ := pem.Decode([]byte(got.CSR))
+		if block == nil {
+			http.Error(w, "invalid csr", http.StatusBadRequest)
+			return
+		}
+		csr, err := x509.ParseCertificateRequest(block.Bytes)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		tmpl := &x509.Certificate{
+			Subject:      csr.Subject,
+			DNSNames:     csr.DNSNames,
+			IPAddresses:  csr.IPAddresses,
+			SerialNumber: big.NewInt(100),
+			NotBefore:    ca.NotBefore,
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		}
+		der, err := x509.CreateCertificate(cryptorand.Reader, tmpl, ca, csr.PublicKey, caKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(SignResponse{Certificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))})
+	}))
+}
+
+func TestNewCertWithSigner(t *testing.T) {
+	ca := loadFixtureCert(t, "ca.crt")
+	var got SignRequest
+	server := newSignerServer(t, &got)
+	defer server.Close()
+
+	config := &CertConfig{
+		Config: certutil.Config{
+			CommonName: "member-node1",
+			AltNames:   certutil.AltNames{DNSNames: []string{"node1"}, IPs: []net.IP{net.ParseIP("192.168.0.2")}},
+			Usages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		},
+	}
+	cert, key, err := NewCertWithSigner(&kubekeyapiv1alpha2.ExternalSigner{URL: server.URL}, ca, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !KeyMatchesCert(cert, key) {
+		t.Error("the key does not match the cert")
+	}
+	if cert.Subject.CommonName != "member-node1" {
+		t.Errorf("got CommonName %s, want member-node1", cert.Subject.CommonName)
+	}
+	if len(got.Usages) != 2 || got.Usages[0] != "server auth" || got.Usages[1] != "client auth" {
+		t.Errorf("got usages %v in request, want [server auth client auth]", got.Usages)
+	}
+
+	other, _, err := NewCertificateAuthority(&CertConfig{Config: certutil.Config{CommonName: "other-ca"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := NewCertWithSigner(&kubekeyapiv1alpha2.ExternalSigner{URL: server.URL}, other, config); err == nil {
+		t.Error("the cert is not signed by the given CA, want error")
+	}
+
+	// the exec signer responds a cert of another key.
+	mismatch := &kubekeyapiv1alpha2.ExternalSigner{Command: []string{"cat", filepath.Join(fixtures, "mismatch-response.json")}}
+	if _, _, err := NewCertWithSigner(mismatch, ca, config); err == nil || !strings.Contains(err.Error(), "does not match the private key") {
+		t.Errorf("got error %v, want the key mismatch error", err)
+	}
+}
+
+func TestInstallCertificateAuthority(t *testing.T) {
+	root, ca := loadFixtureCert(t, "root.crt"), loadFixtureCert(t, "ca.crt")
+	dir := t.TempDir()
+
+	certFile := filepath.Join(dir, "bundle.pem")
+	if err := certutil.WriteCert(certFile, append(EncodeCertPEM(ca), EncodeCertPEM(root)...)); err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(fixtures, "ca.key")
+
+	certPath, keyPath := filepath.Join(dir, "pki", "ca.pem"), filepath.Join(dir, "pki", "ca-key.pem")
+	if err := InstallCertificateAuthority(&kubekeyapiv1alpha2.CertificateAuthority{CertFile: certFile, KeyFile: keyFile}, certPath, keyPath); err != nil {
+		t.Fatal(err)
+	}
+	trusted, err := certutil.CertsFromFile(certPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trusted) != 1 || !trusted[0].Equal(ca) {
+		t.Error("only the issuing CA should be installed as the trust anchor")
+	}
+	chain, err := certutil.CertsFromFile(filepath.Join(dir, "pki", "ca-chain.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chain) != 2 || !chain[0].Equal(ca) || !chain[1].Equal(root) {
+		t.Error("the chain should be the CA followed by the root CA")
+	}
+	if _, err := os.Stat(keyPath); err != nil {
+		t.Errorf("the CA key is not installed: %v", err)
+	}
+
+	// the client cert issued by the sibling CA under the same root is not trusted.
+	pool := x509.NewCertPool()
+	for _, cert := range trusted {
+		pool.AddCert(cert)
+	}
+	if _, err := loadFixtureCert(t, "sibling-client.crt").Verify(x509.VerifyOptions{
+		Roots:     pool,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}); err == nil {
+		t.Error("the client cert of sibling CA is trusted by the installed CA")
+	}
+
+	// the key is removed when the CA is switched to an external signer.
+	signer := &kubekeyapiv1alpha2.ExternalSigner{Command: []string{"signer"}}
+	if err := InstallCertificateAuthority(&kubekeyapiv1alpha2.CertificateAuthority{CertFile: certFile, Signer: signer}, certPath, keyPath); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(keyPath); !os.IsNotExist(err) {
+		t.Error("the CA key should be removed when an external signer is used")
+	}
+
+	other, otherKey, err := NewCertificateAuthority(&CertConfig{Config: certutil.Config{CommonName: "other-ca"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPEM, err := keyutil.MarshalPrivateKeyToPEM(otherKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKeyFile := filepath.Join(dir, "other-key.pem")
+	if err := keyutil.WriteKey(otherKeyFile, otherPEM); err != nil {
+		t.Fatal(err)
+	}
+	if err := InstallCertificateAuthority(&kubekeyapiv1alpha2.CertificateAuthority{CertFile: certFile, KeyFile: otherKeyFile}, certPath, keyPath); err == nil {
+		t.Error("the key does not match the CA, want error")
+	}
+
+	brokenChain := filepath.Join(dir, "broken.pem")
+	if err := certutil.WriteCert(brokenChain, append(EncodeCertPEM(ca), EncodeCertPEM(other)...)); err != nil {
+		t.Fatal(err)
+	}
+	if err := InstallCertificateAuthority(&kubekeyapiv1alpha2.CertificateAuthority{CertFile: brokenChain, Signer: signer}, certPath, keyPath); err == nil {
+		t.Error("the chain is broken, want error")
+	}
+}
@@ -61,8 +61,10 @@ func (o *ArtifactExportOptions) Complete(cmd *cobra.Command, args []string) (*kk
 	o.Playbook = args[0]
 
 	pipeline.Spec = kkcorev1.PipelineSpec{
-		Playbook: o.Playbook,
-		Debug:    o.Debug,
+		Playbook:  o.Playbook,
+		Debug:     o.Debug,
+		CheckMode: o.CheckMode,
+		Diff:      o.Diff,
 	}
 
 	config, inventory, err := o.completeRef(pipeline)
@@ -114,9 +116,11 @@ func (o *ArtifactImagesOptions) Complete(cmd *cobra.Command, args []string) (*kk
 	o.Playbook = args[0]
 
 	pipeline.Spec = kkcorev1.PipelineSpec{
-		Playbook: o.Playbook,
-		Debug:    o.Debug,
-		Tags:     []string{"only_image"},
+		Playbook:  o.Playbook,
+		Debug:     o.Debug,
+		CheckMode: o.CheckMode,
+		Diff:      o.Diff,
+		Tags:      []string{"only_image"},
 	}
 
 	config, inventory, err := o.completeRef(pipeline)
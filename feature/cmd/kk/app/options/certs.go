@@ -63,9 +63,11 @@ func (o *CertsRenewOptions) Complete(cmd *cobra.Command, args []string) (*kkcore
 	o.Playbook = args[0]
 
 	pipeline.Spec = kkcorev1.PipelineSpec{
-		Playbook: o.Playbook,
-		Debug:    o.Debug,
-		Tags:     []string{"certs"},
+		Playbook:  o.Playbook,
+		Debug:     o.Debug,
+		CheckMode: o.CheckMode,
+		Diff:      o.Diff,
+		Tags:      []string{"certs"},
 	}
 
 	config, inventory, err := o.completeRef(pipeline)
@@ -70,8 +70,10 @@ func (o *CreateClusterOptions) Complete(cmd *cobra.Command, args []string) (*kkc
 	o.Playbook = args[0]
 
 	pipeline.Spec = kkcorev1.PipelineSpec{
-		Playbook: o.Playbook,
-		Debug:    o.Debug,
+		Playbook:  o.Playbook,
+		Debug:     o.Debug,
+		CheckMode: o.CheckMode,
+		Diff:      o.Diff,
 	}
 
 	config, inventory, err := o.completeRef(pipeline)
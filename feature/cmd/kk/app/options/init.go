@@ -61,8 +61,10 @@ func (o *InitOSOptions) Complete(cmd *cobra.Command, args []string) (*kkcorev1.P
 	o.Playbook = args[0]
 
 	pipeline.Spec = kkcorev1.PipelineSpec{
-		Playbook: o.Playbook,
-		Debug:    o.Debug,
+		Playbook:  o.Playbook,
+		Debug:     o.Debug,
+		CheckMode: o.CheckMode,
+		Diff:      o.Diff,
 	}
 
 	config, inventory, err := o.completeRef(pipeline)
@@ -114,8 +116,10 @@ func (o *InitRegistryOptions) Complete(cmd *cobra.Command, args []string) (*kkco
 	o.Playbook = args[0]
 
 	pipeline.Spec = kkcorev1.PipelineSpec{
-		Playbook: o.Playbook,
-		Debug:    o.Debug,
+		Playbook:  o.Playbook,
+		Debug:     o.Debug,
+		CheckMode: o.CheckMode,
+		Diff:      o.Diff,
 	}
 	config, inventory, err := o.completeRef(pipeline)
 	if err != nil {
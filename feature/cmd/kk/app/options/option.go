@@ -17,11 +17,14 @@ limitations under the License.
 package options
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
@@ -31,6 +34,8 @@ import (
 	"sigs.k8s.io/yaml"
 
 	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+	"github.com/kubesphere/kubekey/v4/pkg/converter"
+	"github.com/kubesphere/kubekey/v4/pkg/variable/vault"
 )
 
 var defaultConfig = &kkcorev1.Config{
@@ -61,8 +66,14 @@ type commonOptions struct {
 	Artifact string
 	// Debug mode, after a successful execution of Pipeline, will retain runtime data, which includes task execution status and parameters.
 	Debug bool
+	// CheckMode only report what would change in hosts, without changing them.
+	CheckMode bool
+	// Diff report the difference of file content which changed in hosts.
+	Diff bool
 	// Namespace for all resources.
 	Namespace string
+	// VaultPasswordFile contains the password to decrypt vault values in config and inventory.
+	VaultPasswordFile string
 }
 
 func newCommonOptions() commonOptions {
@@ -88,8 +99,11 @@ func (o *commonOptions) flags() cliflag.NamedFlagSets {
 	gfs.StringVarP(&o.Artifact, "artifact", "a", "", "Path to a KubeKey artifact")
 	gfs.StringVarP(&o.ConfigFile, "config", "c", o.ConfigFile, "the config file path. support *.yaml ")
 	gfs.StringArrayVar(&o.Set, "set", o.Set, "set value in config. format --set key=val or --set k1=v1,k2=v2")
-	gfs.StringVarP(&o.InventoryFile, "inventory", "i", o.InventoryFile, "the host list file path. support *.ini")
+	gfs.StringVarP(&o.InventoryFile, "inventory", "i", o.InventoryFile, "the host list file path. support kubekey inventory (*.yaml), ansible INI inventory and ansible dynamic inventory script")
 	gfs.BoolVarP(&o.Debug, "debug", "d", o.Debug, "Debug mode, after a successful execution of Pipeline, will retain runtime data, which includes task execution status and parameters.")
+	gfs.BoolVar(&o.CheckMode, "check", o.CheckMode, "Check mode, only report what would change in hosts, without changing them.")
+	gfs.BoolVar(&o.Diff, "diff", o.Diff, "Diff mode, report the difference of file content which changed in hosts. use with --check to preview the changes.")
+	gfs.StringVar(&o.VaultPasswordFile, "vault-password-file", o.VaultPasswordFile, "the file which contains the password to decrypt vault values in config and inventory")
 	gfs.StringVarP(&o.Namespace, "namespace", "n", o.Namespace, "the namespace which pipeline will be executed, all reference resources(pipeline, config, inventory, task) should in the same namespace")
 
 	return fss
@@ -103,6 +117,12 @@ func (o *commonOptions) completeRef(pipeline *kkcorev1.Pipeline) (*kkcorev1.Conf
 		}
 		o.WorkDir = filepath.Join(wd, o.WorkDir)
 	}
+	if o.VaultPasswordFile != "" {
+		// the vault values are decrypted when pipeline run, which get password from env.
+		if err := os.Setenv(vault.EnvPasswordFile, o.VaultPasswordFile); err != nil {
+			return nil, nil, fmt.Errorf("set vault password file error: %w", err)
+		}
+	}
 	// complete config
 	config, err := o.genConfig()
 	if err != nil {
@@ -179,19 +199,14 @@ func (o *commonOptions) genConfig() (*kkcorev1.Config, error) {
 	return config, nil
 }
 
-// genConfig generate config by ConfigFile and set value by command args.
+// genInventory generate inventory by InventoryFile.
 func (o *commonOptions) genInventory() (*kkcorev1.Inventory, error) {
 	inventory := defaultInventory.DeepCopy()
 	if o.InventoryFile != "" {
-		cdata, err := os.ReadFile(o.InventoryFile)
+		var err error
+		inventory, err = readInventory(o.InventoryFile)
 		if err != nil {
-			klog.V(4).ErrorS(err, "read config file error")
-
-			return nil, err
-		}
-		inventory = &kkcorev1.Inventory{}
-		if err := yaml.Unmarshal(cdata, inventory); err != nil {
-			klog.V(4).ErrorS(err, "unmarshal config file error")
+			klog.V(4).ErrorS(err, "read inventory file error", "file", o.InventoryFile)
 
 			return nil, err
 		}
@@ -204,6 +219,82 @@ func (o *commonOptions) genInventory() (*kkcorev1.Inventory, error) {
 	return inventory, nil
 }
 
+// readInventory convert the inventory file to kkcorev1.Inventory. the file can be:
+// an executable file: ansible dynamic inventory script, which output ansible json by "--list".
+// *.yaml, *.yml: kkcorev1.Inventory.
+// *.json: kkcorev1.Inventory or the output of ansible dynamic inventory.
+// *.ini: ansible INI inventory.
+// others: kkcorev1.Inventory if the kind is defined, otherwise ansible INI inventory.
+func readInventory(path string) (*kkcorev1.Inventory, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("stat inventory file error: %w", err)
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	if fi.Mode().IsRegular() && fi.Mode().Perm()&0111 != 0 && !slices.Contains([]string{".yaml", ".yml", ".json", ".ini"}, ext) {
+		output, err := runInventoryScript(path, "--list")
+		if err != nil {
+			return nil, err
+		}
+
+		return converter.ConvertJSONInventory(output, func(host string) (map[string]any, error) {
+			output, err := runInventoryScript(path, "--host", host)
+			if err != nil {
+				return nil, err
+			}
+			vars := make(map[string]any)
+			if err := json.Unmarshal(output, &vars); err != nil {
+				return nil, fmt.Errorf("unmarshal host vars error: %w", err)
+			}
+
+			return vars, nil
+		})
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read inventory file error: %w", err)
+	}
+	switch ext {
+	case ".yaml", ".yml":
+	case ".ini":
+		return converter.ConvertINIInventory(data)
+	case ".json":
+		var meta metav1.TypeMeta
+		if err := json.Unmarshal(data, &meta); err != nil {
+			return nil, fmt.Errorf("unmarshal inventory file error: %w", err)
+		}
+		if meta.Kind == "" {
+			return converter.ConvertJSONInventory(data, nil)
+		}
+	default:
+		// such as ansible "hosts" file. it's kubekey inventory only if the kind is defined.
+		var meta metav1.TypeMeta
+		if err := yaml.Unmarshal(data, &meta); err != nil || meta.Kind == "" {
+			return converter.ConvertINIInventory(data)
+		}
+	}
+	inventory := &kkcorev1.Inventory{}
+	if err := yaml.Unmarshal(data, inventory); err != nil {
+		return nil, fmt.Errorf("unmarshal inventory file error: %w", err)
+	}
+
+	return inventory, nil
+}
+
+// runInventoryScript execute the ansible dynamic inventory script and returns stdout.
+func runInventoryScript(path string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command(path, args...)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("execute inventory script %s error: %w, stderr: %s", path, err, stderr.String())
+	}
+
+	return output, nil
+}
+
 // setValue set key: val in config.
 // If val is json string. convert to map or slice
 // If val is TRUE,YES,Y. convert to bool type true.
@@ -66,9 +66,11 @@ func (o *PreCheckOptions) Complete(cmd *cobra.Command, args []string) (*kkcorev1
 	}
 
 	pipeline.Spec = kkcorev1.PipelineSpec{
-		Playbook: o.Playbook,
-		Debug:    o.Debug,
-		Tags:     tags,
+		Playbook:  o.Playbook,
+		Debug:     o.Debug,
+		CheckMode: o.CheckMode,
+		Diff:      o.Diff,
+		Tags:      tags,
 	}
 	config, inventory, err := o.completeRef(pipeline)
 	if err != nil {
@@ -103,10 +103,12 @@ func (o *KubeKeyRunOptions) Complete(cmd *cobra.Command, args []string) (*kkcore
 			InsecureSkipTLS: o.ProjectInsecureSkipTLS,
 			Token:           o.ProjectToken,
 		},
-		Playbook: o.Playbook,
-		Tags:     o.Tags,
-		SkipTags: o.SkipTags,
-		Debug:    o.Debug,
+		Playbook:  o.Playbook,
+		Tags:      o.Tags,
+		SkipTags:  o.SkipTags,
+		Debug:     o.Debug,
+		CheckMode: o.CheckMode,
+		Diff:      o.Diff,
 	}
 	config, inventory, err := o.completeRef(pipeline)
 	if err != nil {
@@ -0,0 +1,254 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"golang.org/x/term"
+	cliflag "k8s.io/component-base/cli/flag"
+
+	"github.com/kubesphere/kubekey/v4/pkg/variable/vault"
+)
+
+// NewVaultOptions for newVaultCommand
+func NewVaultOptions() *VaultOptions {
+	return &VaultOptions{Keys: vault.DefaultSensitiveKeys}
+}
+
+// VaultOptions for newVaultCommand
+type VaultOptions struct {
+	// PasswordFile contains the vault password. if empty, get password from env or terminal.
+	PasswordFile string
+	// Keys are the parts of key name whose value should be encrypted.
+	Keys []string
+	// String to encrypt, instead of file.
+	String string
+}
+
+// Flags add to newVaultCommand
+func (o *VaultOptions) Flags() cliflag.NamedFlagSets {
+	fss := cliflag.NamedFlagSets{}
+	vfs := fss.FlagSet("vault")
+	vfs.StringVar(&o.PasswordFile, "vault-password-file", o.PasswordFile, fmt.Sprintf("the file which contains vault password. default get password from env %s, %s or terminal", vault.EnvPassword, vault.EnvPasswordFile))
+	vfs.StringSliceVar(&o.Keys, "key", o.Keys, "encrypt the values whose key name contains any of them (case insensitive)")
+	vfs.StringVar(&o.String, "string", o.String, "encrypt the string and print it, instead of encrypting values in file")
+
+	return fss
+}
+
+// vault returns the Vault by password. confirm is whether ask the password twice when read from terminal.
+func (o *VaultOptions) vault(confirm bool) (*vault.Vault, error) {
+	var password []byte
+	var err error
+	if o.PasswordFile != "" {
+		password, err = vault.PasswordFromFile(o.PasswordFile)
+	} else {
+		password, err = vault.PasswordFromEnv()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(password) != 0 {
+		return vault.New(password), nil
+	}
+
+	// read from terminal
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, vault.ErrNoPassword
+	}
+	fmt.Fprint(os.Stderr, "Vault password: ")
+	password, err = term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("read vault password error: %w", err)
+	}
+	if len(password) == 0 {
+		return nil, errors.New("vault password is empty")
+	}
+	if confirm {
+		fmt.Fprint(os.Stderr, "Confirm vault password: ")
+		again, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return nil, fmt.Errorf("read vault password error: %w", err)
+		}
+		if !bytes.Equal(password, again) {
+			return nil, errors.New("vault passwords do not match")
+		}
+	}
+
+	return vault.New(password), nil
+}
+
+// Encrypt the sensitive values in files, or the String.
+func (o *VaultOptions) Encrypt(out io.Writer, files []string) error {
+	if o.String == "" && len(files) == 0 {
+		return errors.New("file or --string is required")
+	}
+	v, err := o.vault(true)
+	if err != nil {
+		return err
+	}
+	if o.String != "" {
+		encrypted, err := v.Encrypt(o.String)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, encrypted)
+
+		return err
+	}
+
+	for _, file := range files {
+		if err := rewriteFile(file, func(data []byte) ([]byte, error) {
+			return v.EncryptYAML(data, o.Keys, nil)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Decrypt the encrypted values in files.
+func (o *VaultOptions) Decrypt(files []string) error {
+	v, err := o.vault(false)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := rewriteFile(file, func(data []byte) ([]byte, error) {
+			result, _, err := v.DecryptYAML(data)
+
+			return result, err
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// View print the files with decrypted values.
+func (o *VaultOptions) View(out io.Writer, files []string) error {
+	v, err := o.vault(false)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("read file %s error: %w", file, err)
+		}
+		result, _, err := v.DecryptYAML(data)
+		if err != nil {
+			return fmt.Errorf("decrypt file %s error: %w", file, err)
+		}
+		if _, err := out.Write(result); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Edit the file with decrypted values by $EDITOR (default vi). after editing, the values which were encrypted
+// and the sensitive values are encrypted again.
+func (o *VaultOptions) Edit(file string) error {
+	v, err := o.vault(false)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("read file %s error: %w", file, err)
+	}
+	decrypted, paths, err := v.DecryptYAML(data)
+	if err != nil {
+		return fmt.Errorf("decrypt file %s error: %w", file, err)
+	}
+
+	// the decrypted content is only readable by owner, and removed after editing.
+	tmp, err := os.CreateTemp("", "kubekey-vault-*"+filepath.Ext(file))
+	if err != nil {
+		return fmt.Errorf("create temp file error: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(decrypted); err != nil {
+		tmp.Close()
+
+		return fmt.Errorf("write temp file error: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write temp file error: %w", err)
+	}
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+	cmd := exec.Command("/bin/sh", "-c", editor+` "$1"`, "sh", tmp.Name())
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("run editor error: %w", err)
+	}
+	edited, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return fmt.Errorf("read temp file error: %w", err)
+	}
+	if bytes.Equal(edited, decrypted) {
+		// not changed, keep the origin file.
+		return nil
+	}
+
+	return rewriteFile(file, func([]byte) ([]byte, error) {
+		return v.EncryptYAML(edited, o.Keys, paths)
+	})
+}
+
+// rewriteFile replace the content of file by fn. the mode of file is kept.
+func rewriteFile(file string, fn func(data []byte) ([]byte, error)) error {
+	fi, err := os.Stat(file)
+	if err != nil {
+		return fmt.Errorf("stat file %s error: %w", file, err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("read file %s error: %w", file, err)
+	}
+	result, err := fn(data)
+	if err != nil {
+		return fmt.Errorf("convert file %s error: %w", file, err)
+	}
+	tmp := filepath.Join(filepath.Dir(file), "."+filepath.Base(file)+".kubekey-vault")
+	if err := os.WriteFile(tmp, result, fi.Mode().Perm()); err != nil {
+		return fmt.Errorf("write file %s error: %w", file, err)
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		os.Remove(tmp)
+
+		return fmt.Errorf("write file %s error: %w", file, err)
+	}
+
+	return nil
+}
@@ -65,6 +65,7 @@ func NewRootCommand() *cobra.Command {
 	cmd.AddCommand(newRunCommand())
 	cmd.AddCommand(newPipelineCommand())
 	cmd.AddCommand(newVersionCommand())
+	cmd.AddCommand(newVaultCommand())
 	// internal command
 	cmd.AddCommand(internalCommand...)
 
@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/kubesphere/kubekey/v4/cmd/kk/app/options"
+)
+
+func newVaultCommand() *cobra.Command {
+	o := options.NewVaultOptions()
+
+	cmd := &cobra.Command{
+		Use:   "vault",
+		Short: "Encrypt and decrypt the sensitive values in config and inventory files",
+		Long: `Encrypt and decrypt the sensitive values in config and inventory files by AES-256-GCM.
+The encrypted values are decrypted when the pipeline runs, with the password from --vault-password-file,
+env KUBEKEY_VAULT_PASSWORD or KUBEKEY_VAULT_PASSWORD_FILE.`,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "encrypt [FILE...]",
+		Short: "Encrypt the sensitive values in yaml files, or the string by --string",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Encrypt(cmd.OutOrStdout(), args)
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "decrypt FILE...",
+		Short: "Decrypt the encrypted values in yaml files",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return o.Decrypt(args)
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "view FILE...",
+		Short: "Print the yaml files with decrypted values",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.View(cmd.OutOrStdout(), args)
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "edit FILE",
+		Short: "Edit the yaml file with decrypted values by $EDITOR, and encrypt them again",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return o.Edit(args[0])
+		},
+	})
+
+	flags := cmd.PersistentFlags()
+	for _, f := range o.Flags().FlagSets {
+		flags.AddFlagSet(f)
+	}
+
+	return cmd
+}
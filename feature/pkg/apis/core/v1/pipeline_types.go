@@ -63,6 +63,12 @@ type PipelineSpec struct {
 	// which includes task execution status and parameters.
 	// +optional
 	Debug bool `json:"debug,omitempty"`
+	// If CheckMode is true, modules only report what they would change, without changing the hosts.
+	// +optional
+	CheckMode bool `json:"checkMode,omitempty"`
+	// If Diff is true, modules which change files report the difference of file content.
+	// +optional
+	Diff bool `json:"diff,omitempty"`
 	// when execute in kubernetes, pipeline will create ob or cornJob to execute.
 	// +optional
 	JobSpec PipelineJobSpec `json:"jobSpec,omitempty"`
@@ -170,6 +176,8 @@ type PipelineTaskResult struct {
 	Failed int `json:"failed,omitempty"`
 	// Ignored number of tasks.
 	Ignored int `json:"ignored,omitempty"`
+	// Changed number of tasks.
+	Changed int `json:"changed,omitempty"`
 }
 
 // PipelineFailedDetail store failed message when pipeline run failed.
@@ -47,14 +47,55 @@ type TaskSpec struct {
 	Name        string   `json:"name,omitempty"`
 	Hosts       []string `json:"hosts,omitempty"`
 	IgnoreError *bool    `json:"ignoreError,omitempty"`
-	Retries     int      `json:"retries,omitempty"`
-
-	When       []string             `json:"when,omitempty"`
-	FailedWhen []string             `json:"failedWhen,omitempty"`
-	Loop       runtime.RawExtension `json:"loop,omitempty"`
+	// Retries is the max retry times of module in each host. retry when module failed or "until" is not satisfied.
+	Retries int `json:"retries,omitempty"`
+	// Delay is the seconds to wait between each retry.
+	Delay int `json:"delay,omitempty"`
+	// Async is the max seconds of module running detached in host. only command module support it.
+	Async int `json:"async,omitempty"`
+	// Poll is the seconds between checking status of async module. 0 means not wait it.
+	Poll *int `json:"poll,omitempty"`
+	// CheckMode overrides the check mode of pipeline. false means the task always changes hosts.
+	CheckMode *bool `json:"checkMode,omitempty"`
+	// Diff overrides the diff mode of pipeline.
+	Diff *bool `json:"diff,omitempty"`
+	// Throttle is the max number of hosts which execute the task concurrently. 0 means no limit.
+	Throttle int `json:"throttle,omitempty"`
+	// Timeout is the max seconds of module executing in each host. 0 means no limit.
+	Timeout int `json:"timeout,omitempty"`
+	// AnyErrorsFatal abort the pipeline when the task failed in any host, whatever the max_fail_percentage of play is.
+	AnyErrorsFatal bool `json:"anyErrorsFatal,omitempty"`
+	// Become is the privilege escalation of module in host.
+	Become Become `json:"become,omitempty"`
+
+	When        []string             `json:"when,omitempty"`
+	FailedWhen  []string             `json:"failedWhen,omitempty"`
+	ChangedWhen []string             `json:"changedWhen,omitempty"`
+	Until       []string             `json:"until,omitempty"`
+	Loop        runtime.RawExtension `json:"loop,omitempty"`
 
 	Module   Module `json:"module,omitempty"`
 	Register string `json:"register,omitempty"`
+	// Notify is the handlers which should be notified when the task changed in host.
+	Notify []string `json:"notify,omitempty"`
+	// DelegateTo is the host which module execute in. it can be template string. the variables are still from the original host.
+	DelegateTo string `json:"delegateTo,omitempty"`
+	// DelegateFacts store the facts (register, set_fact) to the delegated host instead of the original host.
+	DelegateFacts bool `json:"delegateFacts,omitempty"`
+}
+
+// Become is the privilege escalation config of Task.
+type Become struct {
+	// Enable run module as another user.
+	Enable bool `json:"enable,omitempty"`
+	// User to become. default is root.
+	User string `json:"user,omitempty"`
+	// Method of privilege escalation. support sudo and su. default is sudo.
+	Method string `json:"method,omitempty"`
+	// Flags pass to the executable of method.
+	Flags string `json:"flags,omitempty"`
+	// Exe is the executable of method. default is the name of method.
+	Exe string `json:"exe,omitempty"`
 }
 
 // Module of Task
@@ -72,9 +113,12 @@ type TaskStatus struct {
 
 // TaskHostResult each host result for task
 type TaskHostResult struct {
-	Host   string `json:"host,omitempty"`
-	Stdout string `json:"stdout,omitempty"`
-	StdErr string `json:"stdErr,omitempty"`
+	Host    string `json:"host,omitempty"`
+	Stdout  string `json:"stdout,omitempty"`
+	StdErr  string `json:"stdErr,omitempty"`
+	Changed bool   `json:"changed,omitempty"`
+	// Diff is the unified diff of files which changed by task in diff mode.
+	Diff string `json:"diff,omitempty"`
 }
 
 // +genclient
@@ -109,9 +153,20 @@ func (t Task) IsSucceed() bool {
 	return t.Status.Phase == TaskPhaseSuccess || t.Status.Phase == TaskPhaseIgnored
 }
 
-// IsFailed Task.Status.Phase is failed when reach the retries
+// IsFailed Task.Status.Phase is failed. the retries is dealt in each host.
 func (t Task) IsFailed() bool {
-	return t.Status.Phase == TaskPhaseFailed && t.Spec.Retries <= t.Status.RestartCount
+	return t.Status.Phase == TaskPhaseFailed
+}
+
+// IsChanged if any host of Task has changed.
+func (t Task) IsChanged() bool {
+	for _, hr := range t.Status.HostResults {
+		if hr.Changed {
+			return true
+		}
+	}
+
+	return false
 }
 
 func init() {
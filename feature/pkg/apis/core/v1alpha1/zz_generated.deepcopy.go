@@ -24,6 +24,21 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Become) DeepCopyInto(out *Become) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Become.
+func (in *Become) DeepCopy() *Become {
+	if in == nil {
+		return nil
+	}
+	out := new(Become)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Module) DeepCopyInto(out *Module) {
 	*out = *in
@@ -127,6 +142,22 @@ func (in *TaskSpec) DeepCopyInto(out *TaskSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.Poll != nil {
+		in, out := &in.Poll, &out.Poll
+		*out = new(int)
+		**out = **in
+	}
+	if in.CheckMode != nil {
+		in, out := &in.CheckMode, &out.CheckMode
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Diff != nil {
+		in, out := &in.Diff, &out.Diff
+		*out = new(bool)
+		**out = **in
+	}
+	out.Become = in.Become
 	if in.When != nil {
 		in, out := &in.When, &out.When
 		*out = make([]string, len(*in))
@@ -137,8 +168,23 @@ func (in *TaskSpec) DeepCopyInto(out *TaskSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ChangedWhen != nil {
+		in, out := &in.ChangedWhen, &out.ChangedWhen
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Until != nil {
+		in, out := &in.Until, &out.Until
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	in.Loop.DeepCopyInto(&out.Loop)
 	in.Module.DeepCopyInto(&out.Module)
+	if in.Notify != nil {
+		in, out := &in.Notify, &out.Notify
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaskSpec.
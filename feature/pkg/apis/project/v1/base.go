@@ -36,8 +36,8 @@ type Base struct {
 	NoLog          bool                `yaml:"no_log,omitempty"`
 	RunOnce        bool                `yaml:"run_once,omitempty"`
 	IgnoreErrors   *bool               `yaml:"ignore_errors,omitempty"`
-	CheckMode      bool                `yaml:"check_mode,omitempty"`
-	Diff           bool                `yaml:"diff,omitempty"`
+	CheckMode      *bool               `yaml:"check_mode,omitempty"`
+	Diff           *bool               `yaml:"diff,omitempty"`
 	AnyErrorsFatal bool                `yaml:"any_errors_fatal,omitempty"`
 	Throttle       int                 `yaml:"throttle,omitempty"`
 	Timeout        int                 `yaml:"timeout,omitempty"`
@@ -46,7 +46,7 @@ type Base struct {
 	Debugger string `yaml:"debugger,omitempty"`
 
 	// privilege escalation
-	Become       bool   `yaml:"become,omitempty"`
+	Become       *bool  `yaml:"become,omitempty"`
 	BecomeMethod string `yaml:"become_method,omitempty"`
 	BecomeUser   string `yaml:"become_user,omitempty"`
 	BecomeFlags  string `yaml:"become_flags,omitempty"`
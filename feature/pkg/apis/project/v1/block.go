@@ -17,6 +17,7 @@ limitations under the License.
 package v1
 
 import (
+	"errors"
 	"reflect"
 	"strings"
 
@@ -28,11 +29,25 @@ type Block struct {
 	BlockBase
 	// If it has Block, Task should be empty
 	Task
+	// IncludeTasks is loaded when executing. it can be template string.
 	IncludeTasks string `yaml:"include_tasks,omitempty"`
+	// ImportTasks is loaded to Block when loading playbook.
+	ImportTasks string `yaml:"import_tasks,omitempty"`
+	// IncludeRole is loaded when executing. the role name can be template string.
+	IncludeRole *RoleInclude `yaml:"include_role,omitempty"`
+	// ImportRole is loaded to Block when loading playbook.
+	ImportRole *RoleInclude `yaml:"import_role,omitempty"`
 
 	BlockInfo
 }
 
+// RoleInclude defined in project. the argument of "include_role" and "import_role".
+type RoleInclude struct {
+	Name string `yaml:"name"`
+	// TasksFrom is the file under role's tasks dir to load. default is main.
+	TasksFrom string `yaml:"tasks_from,omitempty"`
+}
+
 // BlockBase defined in project.
 type BlockBase struct {
 	Base             `yaml:",inline"`
@@ -58,7 +73,7 @@ type Task struct {
 	FailedWhen  When        `yaml:"failed_when,omitempty"`
 	Loop        any         `yaml:"loop,omitempty"`
 	LoopControl LoopControl `yaml:"loop_control,omitempty"`
-	Poll        int         `yaml:"poll,omitempty"`
+	Poll        *int        `yaml:"poll,omitempty"`
 	Register    string      `yaml:"register,omitempty"`
 	Retries     int         `yaml:"retries,omitempty"`
 	Until       When        `yaml:"until,omitempty"`
@@ -85,11 +100,8 @@ func (b *Block) UnmarshalYAML(unmarshal func(any) error) error {
 		return err
 	}
 
-	if includeTasks, ok := handleIncludeTasks(m); ok {
-		// Set the IncludeTasks field if "include_tasks" exists and is valid.
-		b.IncludeTasks = includeTasks
-
-		return nil
+	if ok, err := handleInclude(b, m, unmarshal); ok || err != nil {
+		return err
 	}
 
 	switch {
@@ -114,16 +126,37 @@ func (b *Block) UnmarshalYAML(unmarshal func(any) error) error {
 	return nil
 }
 
-// handleIncludeTasks checks if the "include_tasks" key exists in the map and is of type string.
-// If so, it returns the string value and true, otherwise it returns an empty string and false.
-func handleIncludeTasks(m map[string]any) (string, bool) {
-	if v, ok := m["include_tasks"]; ok {
-		if it, ok := v.(string); ok {
-			return it, true
+// handleInclude checks if one of "include_tasks", "import_tasks", "include_role" or "import_role" key exists in the map.
+// If so, it sets the field to block and returns true. the "loop" of include is also set to block.
+func handleInclude(b *Block, m map[string]any, unmarshal func(any) error) (bool, error) {
+	var include struct {
+		IncludeTasks string       `yaml:"include_tasks,omitempty"`
+		ImportTasks  string       `yaml:"import_tasks,omitempty"`
+		IncludeRole  *RoleInclude `yaml:"include_role,omitempty"`
+		ImportRole   *RoleInclude `yaml:"import_role,omitempty"`
+		Loop         any          `yaml:"loop,omitempty"`
+	}
+	switch {
+	case m["include_tasks"] != nil, m["import_tasks"] != nil, m["include_role"] != nil, m["import_role"] != nil:
+		if err := unmarshal(&include); err != nil {
+			klog.Errorf("unmarshal data to include error: %v", err)
+
+			return true, err
 		}
+	default:
+		return false, nil
+	}
+
+	if (include.ImportTasks != "" || include.ImportRole != nil) && include.Loop != nil {
+		return true, errors.New("loop is not supported by import_tasks and import_role, use include_tasks or include_role instead")
 	}
+	b.IncludeTasks = include.IncludeTasks
+	b.ImportTasks = include.ImportTasks
+	b.IncludeRole = include.IncludeRole
+	b.ImportRole = include.ImportRole
+	b.Loop = include.Loop
 
-	return "", false
+	return true, nil
 }
 
 // handleBlock attempts to unmarshal the block data into a BlockInfo structure.
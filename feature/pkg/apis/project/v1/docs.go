@@ -24,32 +24,32 @@ Play
 +------+------------------------+------------+
 | Row  |        Keyword         |  Support   |
 +------+------------------------+------------+
-|   1  |   any_errors_fatal     |     ✘      |
-|   2  |   become               |     ✘      |
-|   3  |   become_exe           |     ✘      |
-|   4  |   become_flags         |     ✘      |
-|   5  |   become_method        |     ✘      |
-|   6  |   become_user          |     ✘      |
+|   1  |   any_errors_fatal     |     ✔︎      |
+|   2  |   become               |     ✔︎      |
+|   3  |   become_exe           |     ✔︎      |
+|   4  |   become_flags         |     ✔︎      |
+|   5  |   become_method        |     ✔︎      |
+|   6  |   become_user          |     ✔︎      |
 |   7  |   check_mode           |     ✘      |
 |   8  |   collections          |     ✘      |
 |   9  |   connection           |     ✔︎      |
 |  10  |   debugger             |     ✘      |
 |  11  |   diff                 |     ✘      |
 |  12  |   environment          |     ✘      |
-|  13  |   fact_path            |     ✘      |
-|  14  |   force_handlers       |     ✘      |
+|  13  |   fact_path            |     ✔︎      |
+|  14  |   force_handlers       |     ✔︎      |
 |  15  |   gather_facts         |     ✔︎      |
-|  16  |   gather_subset        |     ✘      |
-|  17  |   gather_timeout       |     ✘      |
-|  18  |   handlers             |     ✘      |
+|  16  |   gather_subset        |     ✔︎      |
+|  17  |   gather_timeout       |     ✔︎      |
+|  18  |   handlers             |     ✔︎      |
 |  19  |   hosts                |     ✔︎      |
 |  20  |   ignore_errors        |     ✔︎      |
 |  21  |   ignore_unreachable   |     ✘      |
-|  22  |   max_fail_percentage  |     ✘      |
+|  22  |   max_fail_percentage  |     ✔︎      |
 |  23  |   module_defaults      |     ✘      |
 |  24  |   name                 |     ✔︎      |
 |  25  |   no_log               |     ✘      |
-|  26  |   order                |     ✘      |
+|  26  |   order                |     ✔︎      |
 |  27  |   port                 |     ✘      |
 |  28  |   post_task            |     ✔︎      |
 |  29  |   pre_tasks            |     ✔︎      |
@@ -57,7 +57,7 @@ Play
 |  31  |   roles                |     ✔︎      |
 |  32  |   run_once             |     ✔︎      |
 |  33  |   serial               |     ✔︎      |
-|  34  |   strategy             |     ✘      |
+|  34  |   strategy             |     ✔︎      |
 |  35  |   tags                 |     ✔︎      |
 |  36  |   tasks                |     ✔︎      |
 |  37  |   throttle             |     ✘      |
@@ -72,11 +72,11 @@ Role
 | Row  |        Keyword         |  Support   |
 +------+------------------------+------------+
 |   1  |   any_errors_fatal     |     ✘      |
-|   2  |   become               |     ✘      |
-|   3  |   become_exe           |     ✘      |
-|   4  |   become_flags         |     ✘      |
-|   5  |   become_method        |     ✘      |
-|   6  |   become_user          |     ✘      |
+|   2  |   become               |     ✔︎      |
+|   3  |   become_exe           |     ✔︎      |
+|   4  |   become_flags         |     ✔︎      |
+|   5  |   become_method        |     ✔︎      |
+|   6  |   become_user          |     ✔︎      |
 |   7  |   check_mode           |     ✘      |
 |   8  |   collections          |     ✘      |
 |   9  |   connection           |     ✘      |
@@ -107,11 +107,11 @@ Block
 +------+------------------------+------------+
 |   1  |   always               |     ✔︎      |
 |   2  |   any_errors_fatal     |     ✘      |
-|   3  |   become               |     ✘      |
-|   4  |   become_exe           |     ✘      |
-|   5  |   become_flags         |     ✘      |
-|   6  |   become_method        |     ✘      |
-|   7  |   become_user          |     ✘      |
+|   3  |   become               |     ✔︎      |
+|   4  |   become_exe           |     ✔︎      |
+|   5  |   become_flags         |     ✔︎      |
+|   6  |   become_method        |     ✔︎      |
+|   7  |   become_user          |     ✔︎      |
 |   8  |   block                |     ✔︎      |
 |   9  |   check_mode           |     ✘      |
 |  10  |   collections          |     ✘      |
@@ -126,7 +126,7 @@ Block
 |  19  |   module_defaults      |     ✘      |
 |  20  |   name                 |     ✔︎      |
 |  21  |   no_log               |     ✘      |
-|  22  |   notify               |     ✘      |
+|  22  |   notify               |     ✔︎      |
 |  23  |   port                 |     ✘      |
 |  24  |   remote_user          |     ✘      |
 |  25  |   rescue               |     ✔︎      |
@@ -144,22 +144,22 @@ Task
 | Row  |        Keyword         |  Support   |
 +------+------------------------+------------+
 |   1  |   action               |     ✔︎      |
-|   2  |   any_errors_fatal     |     ✘      |
+|   2  |   any_errors_fatal     |     ✔︎      |
 |   3  |   args                 |     ✔︎      |
-|   4  |   async                |     ✘      |
-|   5  |   become               |     ✘      |
-|   6  |   become_exe           |     ✘      |
-|   7  |   become_flags         |     ✘      |
-|   8  |   become_method        |     ✘      |
-|   9  |   become_user          |     ✘      |
-|  10  |   changed_when         |     ✘      |
-|  11  |   check_mode           |     ✘      |
+|   4  |   async                |     ✔︎      |
+|   5  |   become               |     ✔︎      |
+|   6  |   become_exe           |     ✔︎      |
+|   7  |   become_flags         |     ✔︎      |
+|   8  |   become_method        |     ✔︎      |
+|   9  |   become_user          |     ✔︎      |
+|  10  |   changed_when         |     ✔︎      |
+|  11  |   check_mode           |     ✔︎      |
 |  12  |   collections          |     ✘      |
 |  13  |   debugger             |     ✘      |
-|  14  |   delay                |     ✘      |
-|  15  |   delegate_facts       |     ✘      |
-|  16  |   delegate_to          |     ✘      |
-|  17  |   diff                 |     ✘      |
+|  14  |   delay                |     ✔︎      |
+|  15  |   delegate_facts       |     ✔︎      |
+|  16  |   delegate_to          |     ✔︎      |
+|  17  |   diff                 |     ✔︎      |
 |  18  |   environment          |     ✘      |
 |  19  |   failed_when          |     ✔︎      |
 |  20  |   ignore_errors        |     ✔︎      |
@@ -170,17 +170,17 @@ Task
 |  25  |   module_defaults      |     ✘      |
 |  26  |   name                 |     ✔︎      |
 |  27  |   no_log               |     ✘      |
-|  28  |   notify               |     ✘      |
-|  29  |   poll                 |     ✘      |
+|  28  |   notify               |     ✔︎      |
+|  29  |   poll                 |     ✔︎      |
 |  30  |   port                 |     ✘      |
 |  31  |   register             |     ✔︎      |
 |  32  |   remote_user          |     ✘      |
-|  33  |   retries              |     ✘      |
+|  33  |   retries              |     ✔︎      |
 |  34  |   run_once             |     ✘      |
 |  35  |   tags                 |     ✔︎      |
-|  36  |   throttle             |     ✘      |
-|  37  |   timeout              |     ✘      |
-|  38  |   until                |     ✘      |
+|  36  |   throttle             |     ✔︎      |
+|  37  |   timeout              |     ✔︎      |
+|  38  |   until                |     ✔︎      |
 |  39  |   vars                 |     ✔︎      |
 |  40  |   when                 |     ✔︎      |
 |  41  |   with_<lookup_plugin> |     ✔︎      |
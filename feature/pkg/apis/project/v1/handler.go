@@ -16,9 +16,52 @@ limitations under the License.
 
 package v1
 
+import (
+	"errors"
+	"slices"
+)
+
 // Handler defined in project.
+// It's a task which only run when it has been notified by other tasks.
 type Handler struct {
-	//Task
+	Block
 
+	// Listen is the topics which handler subscribe to. tasks can notify the handler by its name or listen topics.
 	Listen []string `yaml:"listen,omitempty"`
 }
+
+// UnmarshalYAML yaml string to handler.
+func (h *Handler) UnmarshalYAML(unmarshal func(any) error) error {
+	if err := unmarshal(&h.Block); err != nil {
+		return err
+	}
+	// listen is not a module, should not store in UnknownField.
+	delete(h.UnknownField, "listen")
+
+	var m map[string]any
+	if err := unmarshal(&m); err != nil {
+		return err
+	}
+	switch listen := m["listen"].(type) {
+	case nil:
+	case string:
+		h.Listen = []string{listen}
+	case []any:
+		for _, l := range listen {
+			ls, ok := l.(string)
+			if !ok {
+				return errors.New("unsupported listen type, excepted string or array of strings")
+			}
+			h.Listen = append(h.Listen, ls)
+		}
+	default:
+		return errors.New("unsupported listen type, excepted string or array of strings")
+	}
+
+	return nil
+}
+
+// IsNotified check if the handler match the notify name. which is the name of handler or one of its listen topics.
+func (h Handler) IsNotified(name string) bool {
+	return h.Name == name || slices.Contains(h.Listen, name)
+}
@@ -16,7 +16,35 @@ limitations under the License.
 
 package v1
 
+import (
+	"errors"
+)
+
 // Notifiable defined in project.
 type Notifiable struct {
-	Notify string `yaml:"notify,omitempty"`
+	Notify Notify `yaml:"notify,omitempty"`
+}
+
+// Notify defined in project. the name or listen topic of handlers.
+type Notify struct {
+	Data []string
+}
+
+// UnmarshalYAML yaml string to notify
+func (n *Notify) UnmarshalYAML(unmarshal func(any) error) error {
+	var s string
+	if err := unmarshal(&s); err == nil {
+		n.Data = []string{s}
+
+		return nil
+	}
+
+	var a []string
+	if err := unmarshal(&a); err == nil {
+		n.Data = a
+
+		return nil
+	}
+
+	return errors.New("unsupported type, excepted string or array of strings")
 }
@@ -18,6 +18,7 @@ package v1
 
 import (
 	"errors"
+	"strings"
 )
 
 // Play defined in project.
@@ -32,11 +33,17 @@ type Play struct {
 
 	// Facts
 	GatherFacts bool `yaml:"gather_facts,omitempty"`
-
-	// defaults to be deprecated, should be 'None' in future
-	//GatherSubset []GatherSubset
-	//GatherTimeout int
-	//FactPath string
+	// GatherSubset limit the facts to gather. such as: all, min, network, hardware, security, system, runtime, local.
+	// a subset can be excluded by "!" prefix.
+	GatherSubset PlayGatherSubset `yaml:"gather_subset,omitempty"`
+	// GatherTimeout is the timeout in seconds to gather facts of each host.
+	GatherTimeout int `yaml:"gather_timeout,omitempty"`
+	// GatherConcurrency is the max number of hosts which gather facts concurrently. 0 means the default 20.
+	GatherConcurrency int `yaml:"gather_concurrency,omitempty"`
+	// FactPath is the dir of custom facts in remote host.
+	FactPath string `yaml:"fact_path,omitempty"`
+	// FactCachingTimeout is the seconds which gathered facts are cached in workdir. 0 means not cache.
+	FactCachingTimeout int `yaml:"fact_caching_timeout,omitempty"`
 
 	// Variable Attribute
 	VarsFiles []string `yaml:"vars_files,omitempty"`
@@ -46,14 +53,14 @@ type Play struct {
 	Roles []Role `yaml:"roles,omitempty"`
 
 	// Block (Task) Lists Attributes
-	Handlers  []Block `yaml:"handlers,omitempty"`
-	PreTasks  []Block `yaml:"pre_tasks,omitempty"`
-	PostTasks []Block `yaml:"post_tasks,omitempty"`
-	Tasks     []Block `yaml:"tasks,omitempty"`
+	Handlers  []Handler `yaml:"handlers,omitempty"`
+	PreTasks  []Block   `yaml:"pre_tasks,omitempty"`
+	PostTasks []Block   `yaml:"post_tasks,omitempty"`
+	Tasks     []Block   `yaml:"tasks,omitempty"`
 
 	// Flag/Setting Attributes
 	ForceHandlers     bool       `yaml:"force_handlers,omitempty"`
-	MaxFailPercentage float32    `yaml:"percent,omitempty"`
+	MaxFailPercentage *float32   `yaml:"max_fail_percentage,omitempty"`
 	Serial            PlaySerial `yaml:"serial,omitempty"`
 	Strategy          string     `yaml:"strategy,omitempty"`
 	Order             string     `yaml:"order,omitempty"`
@@ -83,6 +90,34 @@ func (s *PlaySerial) UnmarshalYAML(unmarshal func(any) error) error {
 	return errors.New("unsupported type, excepted any or array")
 }
 
+// PlayGatherSubset defined in project.
+type PlayGatherSubset struct {
+	Subset []string
+}
+
+// UnmarshalYAML yaml string to gather_subset. the string can be separated by comma.
+func (s *PlayGatherSubset) UnmarshalYAML(unmarshal func(any) error) error {
+	var ss []string
+	if err := unmarshal(&ss); err == nil {
+		s.Subset = ss
+
+		return nil
+	}
+
+	var str string
+	if err := unmarshal(&str); err == nil {
+		for _, sub := range strings.Split(str, ",") {
+			if sub = strings.TrimSpace(sub); sub != "" {
+				s.Subset = append(s.Subset, sub)
+			}
+		}
+
+		return nil
+	}
+
+	return errors.New("unsupported type, excepted string or string array")
+}
+
 // PlayHost defined in project.
 type PlayHost struct {
 	Hosts []string
@@ -208,6 +208,95 @@ func TestUnmarshalYaml(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Unmarshal handlers with notify and listen",
+			data: []byte(`---
+- name: test play
+  hosts: localhost
+  tasks:
+    - name: test
+      custom-module: abc
+      notify: restart
+  handlers:
+    - name: restart service
+      custom-module: abc
+      listen: ["restart"]
+`),
+			excepted: []Play{
+				{
+					Base:     Base{Name: "test play"},
+					PlayHost: PlayHost{Hosts: []string{"localhost"}},
+					Tasks: []Block{
+						{
+							BlockBase: BlockBase{Base: Base{Name: "test"}, Notifiable: Notifiable{Notify: Notify{Data: []string{"restart"}}}},
+							Task:      Task{UnknownField: map[string]any{"custom-module": "abc"}},
+						},
+					},
+					Handlers: []Handler{
+						{
+							Block: Block{
+								BlockBase: BlockBase{Base: Base{Name: "restart service"}},
+								Task:      Task{UnknownField: map[string]any{"custom-module": "abc"}},
+							},
+							Listen: []string{"restart"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Unmarshal gather_subset with comma separated string",
+			data: []byte(`---
+- name: test play
+  hosts: localhost
+  gather_facts: true
+  gather_subset: "network, !hardware"
+  gather_timeout: 30
+  gather_concurrency: 5
+`),
+			excepted: []Play{
+				{
+					Base:              Base{Name: "test play"},
+					PlayHost:          PlayHost{Hosts: []string{"localhost"}},
+					GatherFacts:       true,
+					GatherSubset:      PlayGatherSubset{Subset: []string{"network", "!hardware"}},
+					GatherTimeout:     30,
+					GatherConcurrency: 5,
+				},
+			},
+		},
+		{
+			name: "Unmarshal include and import",
+			data: []byte(`---
+- name: test play
+  hosts: localhost
+  tasks:
+    - include_tasks: "{{ .os }}.yaml"
+      loop: [a, b]
+    - import_tasks: import.yaml
+    - include_role:
+        name: role1
+        tasks_from: install
+`),
+			excepted: []Play{
+				{
+					Base:     Base{Name: "test play"},
+					PlayHost: PlayHost{Hosts: []string{"localhost"}},
+					Tasks: []Block{
+						{
+							Task:         Task{Loop: []any{"a", "b"}},
+							IncludeTasks: "{{ .os }}.yaml",
+						},
+						{
+							ImportTasks: "import.yaml",
+						},
+						{
+							IncludeRole: &RoleInclude{Name: "role1", TasksFrom: "install"},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range testcases {
@@ -221,3 +310,13 @@ func TestUnmarshalYaml(t *testing.T) {
 		})
 	}
 }
+
+func TestUnmarshalYaml_ImportLoop(t *testing.T) {
+	for _, data := range []string{
+		`[{"import_tasks": "import.yaml", "loop": ["a", "b"]}]`,
+		`[{"import_role": {"name": "role1"}, "loop": ["a", "b"]}]`,
+	} {
+		var blocks []Block
+		assert.ErrorContains(t, yaml.Unmarshal([]byte(data), &blocks), "loop is not supported", data)
+	}
+}
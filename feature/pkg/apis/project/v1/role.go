@@ -32,6 +32,8 @@ type RoleInfo struct {
 	Role string `yaml:"role,omitempty"`
 
 	Block []Block
+	// Handlers defined in role's handlers/main.yaml
+	Handlers []Handler
 }
 
 // UnmarshalYAML yaml string to role.
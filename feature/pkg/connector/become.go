@@ -0,0 +1,212 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connector
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+const (
+	becomeMethodSudo  = "sudo"
+	becomeMethodSu    = "su"
+	defaultBecomeUser = "root"
+	// suPasswordPrompt is the suffix of password prompt of su. like "Password:".
+	suPasswordPrompt = "assword:"
+	// sudoPasswordPrompt is the password prompt of sudo.
+	sudoPasswordPrompt = "[kubekey-become-password]:"
+	// becomeSuccessMarker is printed before the command is executed by become user.
+	becomeSuccessMarker = "KUBEKEY-BECOME-SUCCESS"
+)
+
+// Become is the privilege escalation for connector.
+type Become struct {
+	// Method of privilege escalation. support sudo and su. default is sudo.
+	Method string
+	// User to become. default is root.
+	User string
+	// Flags pass to the executable of method.
+	Flags string
+	// Exe is the executable of method. default is the name of method.
+	Exe string
+	// Password for privilege escalation.
+	Password string
+}
+
+// method returns the method of privilege escalation. default is sudo.
+func (b Become) method() string {
+	if b.Method == "" {
+		return becomeMethodSudo
+	}
+
+	return b.Method
+}
+
+// Command wrap cmd to execute as become user.
+func (b Become) Command(cmd string) (string, error) {
+	user := b.User
+	if user == "" {
+		user = defaultBecomeUser
+	}
+	exe := b.Exe
+	if exe == "" {
+		exe = b.method()
+	}
+
+	args := []string{exe}
+	if b.Flags != "" {
+		args = append(args, b.Flags)
+	}
+	switch b.method() {
+	case becomeMethodSudo:
+		if b.Flags == "" {
+			// set HOME to become user's.
+			args = append(args, "-H")
+		}
+		if b.Password != "" {
+			// read password from stdin with the prompt. the marker is printed once the command starts,
+			// so the password is only written to stdin when sudo prompts.
+			args = append(args, "-S", "-p", ShellQuote(sudoPasswordPrompt))
+			cmd = "echo " + becomeSuccessMarker + "\n" + cmd
+		} else {
+			// not wait for password.
+			args = append(args, "-n")
+		}
+		args = append(args, "-u", user, "sh", "-c", ShellQuote(cmd))
+	case becomeMethodSu:
+		args = append(args, user, "-c", ShellQuote("sh -c "+ShellQuote(cmd)))
+	default:
+		return "", fmt.Errorf("unsupported become method: %s", b.Method)
+	}
+
+	return strings.Join(args, " "), nil
+}
+
+// promptWriter write the answer to stdin once prompt is found in output. the prompt is removed from output.
+// it's used to input password for su, which reads password from terminal.
+// if marker is set, it's used to input password for sudo: the output before marker is from sudo, and the stdin is closed
+// once the prompt is answered or the marker is found, whether sudo prompts or not.
+type promptWriter struct {
+	sync.Mutex
+	prompt   string
+	answer   string
+	stdin    io.Writer
+	marker   string
+	answered bool
+	started  bool
+	closed   bool
+	buf      bytes.Buffer
+}
+
+// Write output of command.
+func (w *promptWriter) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.marker != "" {
+		return w.writeUntilMarker(p)
+	}
+	if w.answered {
+		if w.buf.Len() == 0 {
+			// the line ending of prompt.
+			w.buf.Write(bytes.TrimLeft(p, " \r\n"))
+		} else {
+			w.buf.Write(p)
+		}
+
+		return len(p), nil
+	}
+	w.buf.Write(p)
+	if i := strings.Index(w.buf.String(), w.prompt); i >= 0 {
+		w.answered = true
+		rest := bytes.Clone(bytes.TrimLeft(w.buf.Bytes()[i+len(w.prompt):], " \r\n"))
+		w.buf.Reset()
+		w.buf.Write(rest)
+		if _, err := io.WriteString(w.stdin, w.answer+"\n"); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+// writeUntilMarker answer the prompt before marker is found. the output before marker is kept except the prompt.
+func (w *promptWriter) writeUntilMarker(p []byte) (int, error) {
+	w.buf.Write(p)
+	if w.started {
+		return len(p), nil
+	}
+	if !w.answered {
+		if i := bytes.Index(w.buf.Bytes(), []byte(w.prompt)); i >= 0 {
+			w.answered = true
+			rest := bytes.Clone(w.buf.Bytes()[i+len(w.prompt):])
+			w.buf.Truncate(i)
+			w.buf.Write(rest)
+			if _, err := io.WriteString(w.stdin, w.answer+"\n"); err != nil {
+				return 0, err
+			}
+			// sudo fails instead of waiting when the password is wrong.
+			if err := w.closeStdin(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if i := bytes.Index(w.buf.Bytes(), []byte(w.marker+"\n")); i >= 0 {
+		w.started = true
+		rest := bytes.Clone(w.buf.Bytes()[i+len(w.marker)+1:])
+		// the line ending of the answered prompt.
+		before := bytes.TrimLeft(bytes.Clone(w.buf.Bytes()[:i]), " \r\n")
+		w.buf.Reset()
+		w.buf.Write(before)
+		w.buf.Write(rest)
+		if err := w.closeStdin(); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+// closeStdin close stdin once.
+func (w *promptWriter) closeStdin() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if c, ok := w.stdin.(io.Closer); ok {
+		return c.Close()
+	}
+
+	return nil
+}
+
+// Bytes is the output of command without prompt. the line ending of terminal is converted to "\n".
+func (w *promptWriter) Bytes() []byte {
+	w.Lock()
+	defer w.Unlock()
+
+	out := w.buf.Bytes()
+	if w.marker != "" && !w.started {
+		// the command is not started, the output is only from sudo. trim the line ending of the answered prompt.
+		out = bytes.TrimLeft(out, " \r\n")
+	}
+
+	return bytes.ReplaceAll(out, []byte("\r\n"), []byte("\n"))
+}
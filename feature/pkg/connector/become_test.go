@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connector
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBecome_Command(t *testing.T) {
+	testcases := []struct {
+		name      string
+		become    Become
+		cmd       string
+		except    string
+		exceptErr bool
+	}{
+		{
+			name:   "sudo without password",
+			become: Become{},
+			cmd:    "echo 'hello'",
+			except: `sudo -H -n -u root sh -c 'echo '"'"'hello'"'"''`,
+		},
+		{
+			name:   "sudo with password",
+			become: Become{User: "admin", Flags: "-E", Password: "123"},
+			cmd:    "whoami",
+			except: "sudo -E -S -p '[kubekey-become-password]:' -u admin sh -c 'echo KUBEKEY-BECOME-SUCCESS\nwhoami'",
+		},
+		{
+			name:   "su",
+			become: Become{Method: "su", Exe: "/bin/su"},
+			cmd:    "whoami",
+			except: `/bin/su root -c 'sh -c '"'"'whoami'"'"''`,
+		},
+		{
+			name:      "unsupported method",
+			become:    Become{Method: "doas"},
+			cmd:       "whoami",
+			exceptErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, err := tc.become.Command(tc.cmd)
+			if tc.exceptErr {
+				assert.Error(t, err)
+
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.except, cmd)
+		})
+	}
+}
+
+func TestPromptWriter(t *testing.T) {
+	stdin := &bytes.Buffer{}
+	w := &promptWriter{prompt: suPasswordPrompt, answer: "123", stdin: stdin}
+	for _, p := range []string{"Pass", "word: ", "\r\nroot\r\n"} {
+		_, err := w.Write([]byte(p))
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, "123\n", stdin.String())
+	assert.Equal(t, "root\n", string(w.Bytes()))
+}
+
+// closeBuffer records whether it's closed.
+type closeBuffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *closeBuffer) Close() error {
+	b.closed = true
+
+	return nil
+}
+
+func TestPromptWriterWithMarker(t *testing.T) {
+	testcases := []struct {
+		name        string
+		outputs     []string
+		exceptStdin string
+		except      string
+	}{
+		{
+			name:        "sudo prompts",
+			outputs:     []string{"[kubekey-become-", "password]:", "\nKUBEKEY-BECOME-SUCCESS\n", "root\n"},
+			exceptStdin: "123\n",
+			except:      "root\n",
+		},
+		{
+			name:    "credentials are cached",
+			outputs: []string{"KUBEKEY-BECOME-SUCCESS\n", "[kubekey-become-password]:\n"},
+			except:  "[kubekey-become-password]:\n",
+		},
+		{
+			name:        "wrong password",
+			outputs:     []string{"[kubekey-become-password]:", "\nSorry, try again.\n", "sudo: no password was provided\n"},
+			exceptStdin: "123\n",
+			except:      "Sorry, try again.\nsudo: no password was provided\n",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			stdin := &closeBuffer{}
+			w := &promptWriter{prompt: sudoPasswordPrompt, answer: "123", stdin: stdin, marker: becomeSuccessMarker}
+			for _, p := range tc.outputs {
+				_, err := w.Write([]byte(p))
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.exceptStdin, stdin.String())
+			assert.True(t, stdin.closed)
+			assert.Equal(t, tc.except, string(w.Bytes()))
+		})
+	}
+}
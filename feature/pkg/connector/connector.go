@@ -17,12 +17,19 @@ limitations under the License.
 package connector
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"io/fs"
 	"net"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
+	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/exec"
 	"k8s.io/utils/ptr"
@@ -36,6 +43,7 @@ const (
 	connectedSSH        = "ssh"
 	connectedLocal      = "local"
 	connectedKubernetes = "kubernetes"
+	connectedContainer  = "container"
 )
 
 // Connector is the interface for connecting to a remote host
@@ -56,6 +64,7 @@ type Connector interface {
 // if set connector to "local", use local connector
 // if set connector to "ssh", use ssh connector
 // if set connector to "kubernetes", use kubernetes connector
+// if set connector to "container", use container connector
 // if connector is not set. when host is localhost, use local connector, else use ssh connector
 // vars contains all inventory for host. It's best to define the connector info in inventory file.
 func NewConnector(host string, connectorVars map[string]any) (Connector, error) {
@@ -71,37 +80,8 @@ func NewConnector(host string, connectorVars map[string]any) (Connector, error)
 			klog.InfoS("get ssh port failed use default port 22", "error", err)
 			hostParam = host
 		}
-		// get port in connector variable. if empty, set default port: 22.
-		portParam, err := variable.IntVar(nil, connectorVars, _const.VariableConnectorPort)
-		if err != nil {
-			klog.V(4).Infof("connector port is empty use: %v", defaultSSHPort)
-			portParam = ptr.To(defaultSSHPort)
-		}
-		// get user in connector variable. if empty, set default user: root.
-		userParam, err := variable.StringVar(nil, connectorVars, _const.VariableConnectorUser)
-		if err != nil {
-			klog.V(4).Infof("connector user is empty use: %s", defaultSSHUser)
-			userParam = defaultSSHUser
-		}
-		// get password in connector variable. if empty, should connector by private key.
-		passwdParam, err := variable.StringVar(nil, connectorVars, _const.VariableConnectorPassword)
-		if err != nil {
-			klog.V(4).InfoS("connector password is empty use public key")
-		}
-		// get private key path in connector variable. if empty, set default path: /root/.ssh/id_rsa.
-		keyParam, err := variable.StringVar(nil, connectorVars, _const.VariableConnectorPrivateKey)
-		if err != nil {
-			klog.V(4).Infof("ssh public key is empty, use: %s", defaultSSHPrivateKey)
-			keyParam = defaultSSHPrivateKey
-		}
 
-		return &sshConnector{
-			Host:       hostParam,
-			Port:       *portParam,
-			User:       userParam,
-			Password:   passwdParam,
-			PrivateKey: keyParam,
-		}, nil
+		return newSSHConnector(hostParam, connectorVars)
 	case connectedKubernetes:
 		kubeconfig, err := variable.StringVar(nil, connectorVars, _const.VariableConnectorKubeconfig)
 		if err != nil && host != _const.VariableLocalHost {
@@ -109,6 +89,8 @@ func NewConnector(host string, connectorVars map[string]any) (Connector, error)
 		}
 
 		return &kubernetesConnector{Cmd: exec.New(), clusterName: host, kubeconfig: kubeconfig}, nil
+	case connectedContainer:
+		return newContainerConnector(host, connectorVars)
 	default:
 		localHost, _ := os.Hostname()
 		// get host in connector variable. if empty, set default host: host_name.
@@ -120,38 +102,142 @@ func NewConnector(host string, connectorVars map[string]any) (Connector, error)
 		if host == _const.VariableLocalHost || host == localHost || isLocalIP(hostParam) {
 			return &localConnector{Cmd: exec.New()}, nil
 		}
-		// get port in connector variable. if empty, set default port: 22.
-		portParam, err := variable.IntVar(nil, connectorVars, _const.VariableConnectorPort)
-		if err != nil {
-			klog.V(4).Infof("connector port is empty use: %v", defaultSSHPort)
-			portParam = ptr.To(defaultSSHPort)
-		}
-		// get user in connector variable. if empty, set default user: root.
-		userParam, err := variable.StringVar(nil, connectorVars, _const.VariableConnectorUser)
-		if err != nil {
-			klog.V(4).Infof("connector user is empty use: %s", defaultSSHUser)
-			userParam = defaultSSHUser
-		}
-		// get password in connector variable. if empty, should connector by private key.
-		passwdParam, err := variable.StringVar(nil, connectorVars, _const.VariableConnectorPassword)
-		if err != nil {
-			klog.V(4).InfoS("connector password is empty use public key")
+
+		return newSSHConnector(hostParam, connectorVars)
+	}
+}
+
+// newSSHConnector creates a ssh connector by connectorVars.
+func newSSHConnector(host string, connectorVars map[string]any) (*sshConnector, error) {
+	// get port in connector variable. if empty, set default port: 22.
+	portParam, err := variable.IntVar(nil, connectorVars, _const.VariableConnectorPort)
+	if err != nil {
+		klog.V(4).Infof("connector port is empty use: %v", defaultSSHPort)
+		portParam = ptr.To(defaultSSHPort)
+	}
+	// get user in connector variable. if empty, set default user: root.
+	userParam, err := variable.StringVar(nil, connectorVars, _const.VariableConnectorUser)
+	if err != nil {
+		klog.V(4).Infof("connector user is empty use: %s", defaultSSHUser)
+		userParam = defaultSSHUser
+	}
+	// get password in connector variable. if empty, should connector by private key.
+	passwdParam, err := variable.StringVar(nil, connectorVars, _const.VariableConnectorPassword)
+	if err != nil {
+		klog.V(4).InfoS("connector password is empty use public key")
+	}
+	// get private key paths in connector variable. if empty, use default paths: ~/.ssh/id_rsa, ~/.ssh/id_ecdsa, ~/.ssh/id_ed25519.
+	keyParam, err := variable.StringSliceVar(nil, connectorVars, _const.VariableConnectorPrivateKey)
+	if err != nil || len(keyParam) == 0 {
+		klog.V(4).Infof("ssh private key is empty, use: %v", defaultSSHPrivateKeys)
+		keyParam = nil
+	}
+	// get passphrase of private key in connector variable. if empty, read it from terminal when the key is encrypted.
+	passphraseParam, _ := variable.StringVar(nil, connectorVars, _const.VariableConnectorPrivateKeyPassphrase)
+	// get ssh-agent socket in connector variable. if empty, set default: env SSH_AUTH_SOCK.
+	agentSocketParam, err := variable.StringVar(nil, connectorVars, _const.VariableConnectorAgentSocket)
+	if err != nil {
+		agentSocketParam = os.Getenv("SSH_AUTH_SOCK")
+	}
+	// get become password in connector variable. it's used when privilege escalation need password.
+	becomePasswdParam, _ := variable.StringVar(nil, connectorVars, _const.VariableConnectorBecomePassword)
+	// get host key checking in connector variable. if empty, only accept the host key in known_hosts.
+	hostKeyCheckingParam, err := variable.StringVar(nil, connectorVars, _const.VariableConnectorHostKeyChecking)
+	if err != nil {
+		hostKeyCheckingParam = hostKeyCheckingStrict
+	}
+	// get known_hosts path in connector variable. if empty, set default path: workdir/known_hosts.
+	knownHostsParam, err := variable.StringVar(nil, connectorVars, _const.VariableConnectorKnownHosts)
+	if err != nil {
+		knownHostsParam = filepath.Join(_const.GetWorkDir(), _const.KnownHostsFile)
+	}
+	// get pinned host key fingerprints in connector variable. known_hosts is not used when it's set.
+	fingerprintsParam, _ := variable.StringSliceVar(nil, connectorVars, _const.VariableConnectorHostKeyFingerprints)
+	// get bastions in connector variable. the user and private key of bastion default to the host's.
+	bastionsParam, err := sshBastions(connectorVars[_const.VariableConnectorBastions], userParam, keyParam, passphraseParam)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sshConnector{
+		Host:                 host,
+		Port:                 *portParam,
+		User:                 userParam,
+		Password:             passwdParam,
+		PrivateKeys:          keyParam,
+		PrivateKeyPassphrase: passphraseParam,
+		AgentSocket:          agentSocketParam,
+		BecomePassword:       becomePasswdParam,
+		HostKeyChecking:      hostKeyCheckingParam,
+		KnownHosts:           knownHostsParam,
+		HostKeyFingerprints:  fingerprintsParam,
+		Bastions:             bastionsParam,
+	}, nil
+}
+
+// sshBastions convert the bastions variable to sshBastion.
+func sshBastions(val any, defaultUser string, defaultKeys []string, defaultPassphrase string) ([]sshBastion, error) {
+	var items []any
+	switch vv := val.(type) {
+	case nil:
+		return nil, nil
+	case []any:
+		items = vv
+	default:
+		items = []any{vv}
+	}
+
+	bastions := make([]sshBastion, 0, len(items))
+	for _, item := range items {
+		bastion := sshBastion{Port: defaultSSHPort, User: defaultUser, PrivateKeys: defaultKeys, PrivateKeyPassphrase: defaultPassphrase}
+		switch iv := item.(type) {
+		case string:
+			// format as "user@host:port"
+			hostPort := iv
+			if user, hp, ok := strings.Cut(iv, "@"); ok {
+				bastion.User = user
+				hostPort = hp
+			}
+			bastion.Host = hostPort
+			if host, port, err := net.SplitHostPort(hostPort); err == nil {
+				p, err := strconv.Atoi(port)
+				if err != nil {
+					return nil, fmt.Errorf("invalid port of bastion %q", iv)
+				}
+				bastion.Host, bastion.Port = host, p
+			}
+		case map[string]any:
+			host, err := variable.StringVar(nil, iv, _const.VariableConnectorHost)
+			if err != nil {
+				return nil, fmt.Errorf("host of bastion is required: %w", err)
+			}
+			bastion.Host = host
+			if port, err := variable.IntVar(nil, iv, _const.VariableConnectorPort); err == nil {
+				bastion.Port = *port
+			}
+			if user, err := variable.StringVar(nil, iv, _const.VariableConnectorUser); err == nil {
+				bastion.User = user
+			}
+			if password, err := variable.StringVar(nil, iv, _const.VariableConnectorPassword); err == nil {
+				bastion.Password = password
+			}
+			if keys, err := variable.StringSliceVar(nil, iv, _const.VariableConnectorPrivateKey); err == nil && len(keys) != 0 {
+				bastion.PrivateKeys = keys
+			}
+			if passphrase, err := variable.StringVar(nil, iv, _const.VariableConnectorPrivateKeyPassphrase); err == nil {
+				bastion.PrivateKeyPassphrase = passphrase
+			}
+			bastion.HostKeyFingerprints, _ = variable.StringSliceVar(nil, iv, _const.VariableConnectorHostKeyFingerprints)
+		default:
+			return nil, fmt.Errorf("unsupported bastion type %T", item)
 		}
-		// get private key path in connector variable. if empty, set default path: /root/.ssh/id_rsa.
-		keyParam, err := variable.StringVar(nil, connectorVars, _const.VariableConnectorPrivateKey)
-		if err != nil {
-			klog.V(4).Infof("ssh public key is empty, use: %s", defaultSSHPrivateKey)
-			keyParam = defaultSSHPrivateKey
+		if bastion.Host == "" {
+			return nil, errors.New("host of bastion is empty")
 		}
-
-		return &sshConnector{
-			Host:       hostParam,
-			Port:       *portParam,
-			User:       userParam,
-			Password:   passwdParam,
-			PrivateKey: keyParam,
-		}, nil
+		bastions = append(bastions, bastion)
 	}
+
+	return bastions, nil
 }
 
 // GatherFacts get host info.
@@ -159,6 +245,80 @@ type GatherFacts interface {
 	HostInfo(ctx context.Context) (map[string]any, error)
 }
 
+// FileStat is the information of a file in host.
+type FileStat struct {
+	// Mode of the file.
+	Mode fs.FileMode
+	// Owner and Group name of the file. empty if unknown.
+	Owner string
+	Group string
+	// Sha256 is the hex encoded sha256 checksum of the file content.
+	Sha256 string
+}
+
+// FileStater get the information of a file in host. It's used to check whether the file should be changed.
+type FileStater interface {
+	// StatFile returns the FileStat of path. If the file is not exist, return an error which satisfy os.IsNotExist.
+	StatFile(ctx context.Context, path string) (*FileStat, error)
+}
+
+// FileOption is the option of file which put to host.
+type FileOption struct {
+	// Mode of the file.
+	Mode fs.FileMode
+	// Owner and Group of the file. if empty, keep the owner and group of the replaced file.
+	Owner string
+	Group string
+	// Sha256 is the expected hex encoded sha256 checksum of the file content. if empty, it's calculated from src.
+	Sha256 string
+}
+
+// FileStreamer put file from reader to host. the content is written to a temp file beside dst,
+// verified by sha256 checksum, and then renamed to dst. so dst is never partially written.
+type FileStreamer interface {
+	// PutFileStream copies the content of src to dst with option.
+	PutFileStream(ctx context.Context, src io.Reader, dst string, option FileOption) error
+}
+
+// PutFileStream put file from reader to host by conn.
+// if conn is not a FileStreamer, the content is read to memory and put by PutFile.
+func PutFileStream(ctx context.Context, conn Connector, src io.Reader, dst string, option FileOption) error {
+	if fsc, ok := conn.(FileStreamer); ok {
+		return fsc.PutFileStream(ctx, src, dst, option)
+	}
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return fmt.Errorf("read content of %s error: %w", dst, err)
+	}
+	if option.Sha256 != "" {
+		sum, err := sha256Sum(bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		if err := checkSha256(dst, option.Sha256, sum); err != nil {
+			return err
+		}
+	}
+	if option.Owner != "" || option.Group != "" {
+		klog.V(4).InfoS("connector does not support owner and group, ignore them", "dst_file", dst)
+	}
+
+	return conn.PutFile(ctx, data, dst, option.Mode)
+}
+
+// RESTConfigGetter is the connector which can access the kubernetes api of host.
+type RESTConfigGetter interface {
+	// RESTConfig returns the rest config to access kubernetes api. kubeconfig is the path of kubeconfig file in host,
+	// it's ignored by the connector which has its own kubeconfig.
+	RESTConfig(ctx context.Context, kubeconfig string) (*rest.Config, error)
+}
+
+// Becomer is the connector which can execute command and put file as another user in host.
+type Becomer interface {
+	// SetBecome set the privilege escalation for the later command and file operations.
+	SetBecome(become Become)
+}
+
 // isLocalIP check if given ipAddr is local network ip
 func isLocalIP(ipAddr string) bool {
 	addrs, err := net.InterfaceAddrs()
@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSHBastions(t *testing.T) {
+	testcases := []struct {
+		name   string
+		val    any
+		except []sshBastion
+		err    bool
+	}{
+		{
+			name: "bastions is empty",
+		},
+		{
+			name: "single bastion in string",
+			val:  "jump.example.com",
+			except: []sshBastion{
+				{Host: "jump.example.com", Port: 22, User: "root", PrivateKeys: []string{"/root/.ssh/id_rsa"}, PrivateKeyPassphrase: "passphrase"},
+			},
+		},
+		{
+			name: "chained bastions",
+			val: []any{
+				"admin@10.0.0.1:2222",
+				map[string]any{
+					"host":                  "10.0.1.1",
+					"port":                  2022,
+					"user":                  "ops",
+					"password":              "123456",
+					"private_key":           "/tmp/id_ed25519",
+					"host_key_fingerprints": []any{"SHA256:abc"},
+				},
+			},
+			except: []sshBastion{
+				{Host: "10.0.0.1", Port: 2222, User: "admin", PrivateKeys: []string{"/root/.ssh/id_rsa"}, PrivateKeyPassphrase: "passphrase"},
+				{Host: "10.0.1.1", Port: 2022, User: "ops", Password: "123456", PrivateKeys: []string{"/tmp/id_ed25519"}, PrivateKeyPassphrase: "passphrase", HostKeyFingerprints: []string{"SHA256:abc"}},
+			},
+		},
+		{
+			name: "bastion without host",
+			val:  []any{map[string]any{"user": "ops"}},
+			err:  true,
+		},
+		{
+			name: "invalid port",
+			val:  "10.0.0.1:ssh",
+			err:  true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			bastions, err := sshBastions(tc.val, "root", []string{"/root/.ssh/id_rsa"}, "passphrase")
+			if tc.err {
+				assert.Error(t, err)
+
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.except, bastions)
+		})
+	}
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connector
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"slices"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/exec"
+
+	_const "github.com/kubesphere/kubekey/v4/pkg/const"
+	"github.com/kubesphere/kubekey/v4/pkg/variable"
+	"github.com/kubesphere/kubekey/v4/pkg/variable/vault"
+)
+
+// container runtime for container connector.
+const (
+	containerRuntimeDocker  = "docker"
+	containerRuntimeNerdctl = "nerdctl"
+	containerRuntimePodman  = "podman"
+	// containerRuntimeChroot run commands in a root dir. such as the rootfs of node image.
+	containerRuntimeChroot = "chroot"
+)
+
+var _ Connector = &containerConnector{}
+var _ GatherFacts = &containerConnector{}
+var _ FileStater = &containerConnector{}
+var _ FileStreamer = &containerConnector{}
+var _ RESTConfigGetter = &containerConnector{}
+
+// containerConnector execute commands and copy files in a container by "<runtime> exec", or in a root dir by "chroot".
+// all operations are done by commands in container, so the container only need "/bin/sh" and coreutils.
+type containerConnector struct {
+	// Runtime is the command line tool of container runtime, or "chroot".
+	Runtime string
+	// Container is the container name or id. it's the root dir when Runtime is "chroot".
+	Container string
+	// User execute commands in container. if empty, use the default user of container.
+	User string
+	Cmd  exec.Interface
+}
+
+// newContainerConnector creates a container connector by connectorVars.
+func newContainerConnector(host string, connectorVars map[string]any) (*containerConnector, error) {
+	runtime, err := variable.StringVar(nil, connectorVars, _const.VariableConnectorRuntime)
+	if err != nil {
+		klog.V(4).Infof("connector runtime is empty use: %s", containerRuntimeDocker)
+		runtime = containerRuntimeDocker
+	}
+	if !slices.Contains([]string{containerRuntimeDocker, containerRuntimeNerdctl, containerRuntimePodman, containerRuntimeChroot}, runtime) {
+		return nil, fmt.Errorf("unsupported container runtime %q", runtime)
+	}
+	// get container in connector variable. if empty, set default container: host_name.
+	container, err := variable.StringVar(nil, connectorVars, _const.VariableConnectorContainer)
+	if err != nil {
+		klog.V(4).Infof("connector container is empty use: %s", host)
+		container = host
+	}
+	user, _ := variable.StringVar(nil, connectorVars, _const.VariableConnectorUser)
+
+	return &containerConnector{Runtime: runtime, Container: container, User: user, Cmd: exec.New()}, nil
+}
+
+// command returns the command which execute cmd by shell in container. stdin should be true if cmd reads from stdin.
+func (c *containerConnector) command(ctx context.Context, cmd string, stdin bool) exec.Cmd {
+	klog.V(5).InfoS("exec container command", "cmd", vault.Redact(cmd), "container", c.Container)
+	if c.Runtime == containerRuntimeChroot {
+		var args []string
+		if c.User != "" {
+			args = append(args, "--userspec="+c.User)
+		}
+
+		return c.Cmd.CommandContext(ctx, containerRuntimeChroot, append(args, c.Container, "/bin/sh", "-c", cmd)...)
+	}
+
+	args := []string{"exec"}
+	if stdin {
+		args = append(args, "-i")
+	}
+	if c.User != "" {
+		args = append(args, "--user", c.User)
+	}
+
+	return c.Cmd.CommandContext(ctx, c.Runtime, append(args, c.Container, "/bin/sh", "-c", cmd)...)
+}
+
+// Init connector, check the container is running or the root dir can be chrooted.
+func (c *containerConnector) Init(ctx context.Context) error {
+	if output, err := c.command(ctx, "true", false).CombinedOutput(); err != nil {
+		return fmt.Errorf("connect to container %s by %s error: %w, output: %s", c.Container, c.Runtime, err, output)
+	}
+
+	return nil
+}
+
+// Close connector, do nothing
+func (c *containerConnector) Close(context.Context) error {
+	return nil
+}
+
+// PutFile copy src to dst file in container.
+func (c *containerConnector) PutFile(ctx context.Context, src []byte, dst string, mode fs.FileMode) error {
+	return c.PutFileStream(ctx, bytes.NewReader(src), dst, FileOption{Mode: mode})
+}
+
+// PutFileStream copy src reader to dst file in container. the content is written to a temp file beside dst by stdin,
+// verified by "sha256sum" in container, and then renamed to dst.
+func (c *containerConnector) PutFileStream(ctx context.Context, src io.Reader, dst string, option FileOption) error {
+	tmp := tempFileName(dst)
+	h := sha256.New()
+	cmd := c.command(ctx, fmt.Sprintf("mkdir -p %s && cat > %s", ShellQuote(filepath.Dir(dst)), ShellQuote(tmp)), true)
+	cmd.SetStdin(io.TeeReader(src, h))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		c.removeFile(ctx, tmp)
+
+		return fmt.Errorf("write content to %s error: %w, output: %s", dst, err, output)
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	if option.Sha256 != "" {
+		if err := checkSha256(dst, option.Sha256, sum); err != nil {
+			c.removeFile(ctx, tmp)
+
+			return err
+		}
+	}
+
+	if output, err := c.ExecuteCommand(ctx, fmt.Sprintf("[ \"$(sha256sum %[1]s | cut -d' ' -f1)\" = %[2]s ] && { %[3]s; } && chmod %[4]o %[1]s && mv -f %[1]s %[5]s",
+		ShellQuote(tmp), sum, chownCommand(tmp, dst, option, ""), option.Mode.Perm(), ShellQuote(dst))); err != nil {
+		c.removeFile(ctx, tmp)
+
+		return fmt.Errorf("move file to %s error: %w, output: %s", dst, err, output)
+	}
+
+	return nil
+}
+
+// removeFile remove the file in container. the error is ignored.
+func (c *containerConnector) removeFile(ctx context.Context, path string) {
+	if output, err := c.ExecuteCommand(ctx, "rm -f "+ShellQuote(path)); err != nil {
+		klog.V(4).ErrorS(err, "Failed to remove file in container", "file", path, "output", string(output))
+	}
+}
+
+// StatFile get the FileStat of file in container.
+func (c *containerConnector) StatFile(ctx context.Context, path string) (*FileStat, error) {
+	return statFileByCommand(ctx, c, path)
+}
+
+// FetchFile copy src file in container to dst writer.
+func (c *containerConnector) FetchFile(ctx context.Context, src string, dst io.Writer) error {
+	var stderr bytes.Buffer
+	cmd := c.command(ctx, "cat "+ShellQuote(src), false)
+	cmd.SetStdout(dst)
+	cmd.SetStderr(&stderr)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("fetch file %s error: %w, output: %s", src, err, stderr.String())
+	}
+
+	return nil
+}
+
+// RESTConfig returns the rest config by the kubeconfig file in container.
+func (c *containerConnector) RESTConfig(ctx context.Context, kubeconfig string) (*rest.Config, error) {
+	return fetchRESTConfig(ctx, c, kubeconfig)
+}
+
+// ExecuteCommand in container
+func (c *containerConnector) ExecuteCommand(ctx context.Context, cmd string) ([]byte, error) {
+	return c.command(ctx, cmd, false).CombinedOutput()
+}
+
+// HostInfo for GatherFacts
+func (c *containerConnector) HostInfo(ctx context.Context) (map[string]any, error) {
+	return hostInfo(ctx, c)
+}
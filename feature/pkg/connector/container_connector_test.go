@@ -0,0 +1,155 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connector
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/exec"
+	testingexec "k8s.io/utils/exec/testing"
+)
+
+func TestNewContainerConnector(t *testing.T) {
+	testcases := []struct {
+		name   string
+		vars   map[string]any
+		except *containerConnector
+		err    bool
+	}{
+		{
+			name:   "default runtime and container",
+			vars:   map[string]any{},
+			except: &containerConnector{Runtime: "docker", Container: "node1"},
+		},
+		{
+			name: "chroot with user",
+			vars: map[string]any{
+				"runtime":   "chroot",
+				"container": "/var/lib/rootfs",
+				"user":      "root",
+			},
+			except: &containerConnector{Runtime: "chroot", Container: "/var/lib/rootfs", User: "root"},
+		},
+		{
+			name: "unsupported runtime",
+			vars: map[string]any{"runtime": "lxc"},
+			err:  true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			conn, err := newContainerConnector("node1", tc.vars)
+			if tc.err {
+				assert.Error(t, err)
+
+				return
+			}
+			assert.NoError(t, err)
+			conn.Cmd = nil
+			assert.Equal(t, tc.except, conn)
+		})
+	}
+}
+
+func TestContainerConnector_ExecuteCommand(t *testing.T) {
+	testcases := []struct {
+		name   string
+		conn   *containerConnector
+		except string
+	}{
+		{
+			name:   "docker",
+			conn:   &containerConnector{Runtime: "docker", Container: "node1"},
+			except: "docker exec node1 /bin/sh -c hostname",
+		},
+		{
+			name:   "podman with user",
+			conn:   &containerConnector{Runtime: "podman", Container: "node1", User: "kube"},
+			except: "podman exec --user kube node1 /bin/sh -c hostname",
+		},
+		{
+			name:   "chroot with user",
+			conn:   &containerConnector{Runtime: "chroot", Container: "/rootfs", User: "kube"},
+			except: "chroot --userspec=kube /rootfs /bin/sh -c hostname",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var actual string
+			tc.conn.Cmd = &testingexec.FakeExec{CommandScript: []testingexec.FakeCommandAction{
+				func(cmd string, args ...string) exec.Cmd {
+					actual = strings.Join(append([]string{cmd}, args...), " ")
+
+					return &testingexec.FakeCmd{
+						CombinedOutputScript: []testingexec.FakeAction{func() ([]byte, []byte, error) {
+							return []byte("node1"), nil, nil
+						}},
+					}
+				},
+			}}
+			output, err := tc.conn.ExecuteCommand(context.Background(), "hostname")
+			assert.NoError(t, err)
+			assert.Equal(t, "node1", string(output))
+			assert.Equal(t, tc.except, actual)
+		})
+	}
+}
+
+func TestContainerConnector_Chroot(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("chroot requires root")
+	}
+	ctx := context.Background()
+	conn := &containerConnector{Runtime: containerRuntimeChroot, Container: "/", Cmd: exec.New()}
+	if err := conn.Init(ctx); err != nil {
+		t.Skipf("chroot is not available: %v", err)
+	}
+	dst := filepath.Join(t.TempDir(), "sub", "test")
+
+	assert.NoError(t, conn.PutFileStream(ctx, strings.NewReader("hello"), dst, FileOption{Mode: 0640}))
+	stat, err := conn.StatFile(ctx, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, os.FileMode(0640), stat.Mode)
+	assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", stat.Sha256)
+	var content bytes.Buffer
+	assert.NoError(t, conn.FetchFile(ctx, dst, &content))
+	assert.Equal(t, "hello", content.String())
+
+	// checksum mismatch does not change dst.
+	assert.Error(t, conn.PutFileStream(ctx, strings.NewReader("world"), dst, FileOption{Mode: 0640, Sha256: "0000"}))
+	content.Reset()
+	assert.NoError(t, conn.FetchFile(ctx, dst, &content))
+	assert.Equal(t, "hello", content.String())
+	tmps, err := filepath.Glob(filepath.Join(filepath.Dir(dst), ".*.kubekey-*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, tmps)
+
+	_, err = conn.StatFile(ctx, filepath.Join(filepath.Dir(dst), "not-exist"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,470 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connector
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	_const "github.com/kubesphere/kubekey/v4/pkg/const"
+)
+
+// fact subsets for gather_subset.
+const (
+	// FactSubsetAll gather all facts. it's the default subset.
+	FactSubsetAll = "all"
+	// FactSubsetMin gather os and process facts. it's always gathered.
+	FactSubsetMin = "min"
+	// FactSubsetNetwork gather network interfaces and default routes.
+	FactSubsetNetwork = "network"
+	// FactSubsetHardware gather disks, mounts and swap.
+	FactSubsetHardware = "hardware"
+	// FactSubsetSecurity gather selinux and apparmor state.
+	FactSubsetSecurity = "security"
+	// FactSubsetSystem gather cgroup version and systemd version.
+	FactSubsetSystem = "system"
+	// FactSubsetRuntime gather installed container runtimes.
+	FactSubsetRuntime = "runtime"
+	// FactSubsetLocal gather custom facts in fact path.
+	FactSubsetLocal = "local"
+)
+
+// DefaultFactPath is the default dir of custom facts in remote host.
+const DefaultFactPath = "/etc/kubekey/facts.d"
+
+// factSubsets is the subsets which gathered by command, in the order of gathering.
+var factSubsets = []string{FactSubsetNetwork, FactSubsetHardware, FactSubsetSecurity, FactSubsetSystem, FactSubsetRuntime, FactSubsetLocal}
+
+// containerRuntimes is the command of container runtimes which gathered in runtime subset.
+var containerRuntimes = []string{"docker", "containerd", "crio", "podman", "nerdctl", "crictl"}
+
+// FactOption is the option to gather facts.
+type FactOption struct {
+	// Subset of facts to gather. empty means all.
+	Subset []string
+	// FactPath is the dir of custom facts. empty means DefaultFactPath.
+	FactPath string
+}
+
+// ParseFactSubset returns the sorted subsets which should be gathered. "min" is always included.
+// a subset prefixed with "!" is excluded, and "!all" excludes all subsets which are not listed.
+func ParseFactSubset(subset []string) ([]string, error) {
+	if len(subset) == 0 {
+		subset = []string{FactSubsetAll}
+	}
+	include := make(map[string]bool)
+	exclude := make(map[string]bool)
+	// all subsets are included when there is no included subset, except "!all" is set.
+	includeAll := true
+	for _, s := range subset {
+		name, excluded := strings.CutPrefix(strings.TrimSpace(s), "!")
+		switch {
+		case name == FactSubsetAll:
+			if excluded {
+				includeAll = false
+			} else {
+				for _, n := range factSubsets {
+					include[n] = true
+				}
+			}
+		case name == FactSubsetMin:
+			// min is always gathered.
+		case slices.Contains(factSubsets, name):
+			if excluded {
+				exclude[name] = true
+			} else {
+				include[name] = true
+			}
+		default:
+			return nil, fmt.Errorf("unsupported gather_subset %q", s)
+		}
+	}
+	if len(include) == 0 && includeAll && !slices.Contains(subset, FactSubsetMin) {
+		for _, n := range factSubsets {
+			include[n] = true
+		}
+	}
+
+	result := []string{FactSubsetMin}
+	for _, n := range factSubsets {
+		if include[n] && !exclude[n] {
+			result = append(result, n)
+		}
+	}
+
+	return result, nil
+}
+
+// GatherHostFacts gathers min facts by GatherFacts.HostInfo, and the other subsets by commands.
+// returns nil if conn not support GatherFacts.
+// the other subsets are best effort: when the command not found in host, the subset is skipped.
+func GatherHostFacts(ctx context.Context, conn Connector, option FactOption) (map[string]any, error) {
+	gf, ok := conn.(GatherFacts)
+	if !ok {
+		return nil, nil
+	}
+	subset, err := ParseFactSubset(option.Subset)
+	if err != nil {
+		return nil, err
+	}
+	facts, err := gf.HostInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := facts[_const.VariableOS]; !ok {
+		// the platform is not supported. only linux has other subsets.
+		return facts, nil
+	}
+	factPath := option.FactPath
+	if factPath == "" {
+		factPath = DefaultFactPath
+	}
+
+	for _, s := range subset {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var key string
+		var value any
+		var err error
+		switch s {
+		case FactSubsetNetwork:
+			key = _const.VariableNetwork
+			value, err = gatherNetwork(ctx, conn)
+		case FactSubsetHardware:
+			key = _const.VariableHardware
+			value, err = gatherHardware(ctx, conn)
+		case FactSubsetSecurity:
+			key = _const.VariableSecurity
+			value, err = gatherSecurity(ctx, conn)
+		case FactSubsetSystem:
+			key = _const.VariableSystem
+			value, err = gatherSystem(ctx, conn)
+		case FactSubsetRuntime:
+			key = _const.VariableContainerRuntimes
+			value, err = gatherContainerRuntimes(ctx, conn)
+		case FactSubsetLocal:
+			key = _const.VariableLocalFacts
+			value, err = gatherLocalFacts(ctx, conn, factPath)
+		default:
+			continue
+		}
+		if err != nil {
+			klog.V(4).ErrorS(err, "Failed to gather facts, skip it", "subset", s)
+
+			continue
+		}
+		facts[key] = value
+	}
+
+	return facts, nil
+}
+
+// gatherNetwork by "ip -j". returns interfaces and default routes of ipv4 and ipv6.
+func gatherNetwork(ctx context.Context, conn Connector) (map[string]any, error) {
+	addr, err := conn.ExecuteCommand(ctx, "ip -j addr show 2>/dev/null")
+	if err != nil {
+		return nil, fmt.Errorf("get ip address error: %w, output: %s", err, addr)
+	}
+	interfaces, err := parseIPAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+	network := map[string]any{"interfaces": interfaces}
+	for _, family := range []string{"ipv4", "ipv6"} {
+		route, err := conn.ExecuteCommand(ctx, fmt.Sprintf("ip -j -%s route show default 2>/dev/null", family[3:]))
+		if err != nil {
+			return nil, fmt.Errorf("get default route error: %w, output: %s", err, route)
+		}
+		defaultRoute, err := parseDefaultRoute(route, interfaces, family)
+		if err != nil {
+			return nil, err
+		}
+		network["default_"+family] = defaultRoute
+	}
+
+	return network, nil
+}
+
+// parseIPAddr convert the output of "ip -j addr show" to interfaces.
+func parseIPAddr(data []byte) ([]map[string]any, error) {
+	var links []struct {
+		Name     string `json:"ifname"`
+		MTU      int    `json:"mtu"`
+		State    string `json:"operstate"`
+		Address  string `json:"address"`
+		AddrInfo []struct {
+			Family    string `json:"family"`
+			Local     string `json:"local"`
+			PrefixLen int    `json:"prefixlen"`
+		} `json:"addr_info"`
+	}
+	if err := json.Unmarshal(data, &links); err != nil {
+		return nil, fmt.Errorf("parse ip address error: %w", err)
+	}
+	interfaces := make([]map[string]any, 0, len(links))
+	for _, l := range links {
+		ipv4, ipv6 := make([]string, 0), make([]string, 0)
+		for _, a := range l.AddrInfo {
+			switch a.Family {
+			case "inet":
+				ipv4 = append(ipv4, fmt.Sprintf("%s/%d", a.Local, a.PrefixLen))
+			case "inet6":
+				ipv6 = append(ipv6, fmt.Sprintf("%s/%d", a.Local, a.PrefixLen))
+			}
+		}
+		interfaces = append(interfaces, map[string]any{
+			"name":  l.Name,
+			"mtu":   l.MTU,
+			"state": l.State,
+			"mac":   l.Address,
+			"ipv4":  ipv4,
+			"ipv6":  ipv6,
+		})
+	}
+
+	return interfaces, nil
+}
+
+// parseDefaultRoute convert the output of "ip -j route show default" to default route.
+// the address is the first address of the interface in family.
+func parseDefaultRoute(data []byte, interfaces []map[string]any, family string) (map[string]any, error) {
+	var routes []struct {
+		Gateway string `json:"gateway"`
+		Dev     string `json:"dev"`
+	}
+	if len(bytes.TrimSpace(data)) != 0 {
+		if err := json.Unmarshal(data, &routes); err != nil {
+			return nil, fmt.Errorf("parse default route error: %w", err)
+		}
+	}
+	if len(routes) == 0 {
+		return map[string]any{}, nil
+	}
+	route := map[string]any{"gateway": routes[0].Gateway, "interface": routes[0].Dev}
+	for _, i := range interfaces {
+		if i["name"] != routes[0].Dev {
+			continue
+		}
+		if addrs, ok := i[family].([]string); ok && len(addrs) > 0 {
+			route["address"] = strings.SplitN(addrs[0], "/", 2)[0]
+		}
+	}
+
+	return route, nil
+}
+
+// gatherHardware returns disks by "lsblk", mounts by "/proc/mounts" and swap by "/proc/swaps".
+func gatherHardware(ctx context.Context, conn Connector) (map[string]any, error) {
+	hardware := make(map[string]any)
+	// lsblk may not exist in minimal host. skip disks only.
+	if output, err := conn.ExecuteCommand(ctx, "lsblk -J -b -d -o NAME,TYPE,SIZE,ROTA,MODEL 2>/dev/null"); err != nil {
+		klog.V(4).ErrorS(err, "Failed to get disks", "output", string(output))
+	} else {
+		disks, err := parseLsblk(output)
+		if err != nil {
+			return nil, err
+		}
+		hardware["disks"] = disks
+	}
+	var mounts bytes.Buffer
+	if err := conn.FetchFile(ctx, "/proc/mounts", &mounts); err != nil {
+		return nil, fmt.Errorf("get mounts error: %w", err)
+	}
+	hardware["mounts"] = parseMounts(mounts.Bytes())
+	var swaps bytes.Buffer
+	if err := conn.FetchFile(ctx, "/proc/swaps", &swaps); err != nil {
+		return nil, fmt.Errorf("get swaps error: %w", err)
+	}
+	devices := parseSwaps(swaps.Bytes())
+	hardware["swap"] = map[string]any{"enabled": len(devices) > 0, "devices": devices}
+
+	return hardware, nil
+}
+
+// parseLsblk convert the output of "lsblk -J" to disks.
+func parseLsblk(data []byte) ([]map[string]any, error) {
+	var blk struct {
+		BlockDevices []map[string]any `json:"blockdevices"`
+	}
+	if err := json.Unmarshal(data, &blk); err != nil {
+		return nil, fmt.Errorf("parse lsblk error: %w", err)
+	}
+	disks := make([]map[string]any, 0)
+	for _, d := range blk.BlockDevices {
+		if d["type"] != "disk" {
+			continue
+		}
+		delete(d, "children")
+		disks = append(disks, d)
+	}
+
+	return disks, nil
+}
+
+// parseMounts convert "/proc/mounts" to mounts. only the mounts of block device are returned.
+func parseMounts(data []byte) []map[string]any {
+	mounts := make([]map[string]any, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "/") {
+			continue
+		}
+		mounts = append(mounts, map[string]any{
+			"device":  fields[0],
+			"mount":   fields[1],
+			"fstype":  fields[2],
+			"options": fields[3],
+		})
+	}
+
+	return mounts
+}
+
+// parseSwaps convert "/proc/swaps" to swap devices. the size and used are in KiB.
+func parseSwaps(data []byte) []map[string]any {
+	devices := make([]map[string]any, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || fields[0] == "Filename" {
+			continue
+		}
+		devices = append(devices, map[string]any{
+			"name": fields[0],
+			"type": fields[1],
+			"size": fields[2],
+			"used": fields[3],
+		})
+	}
+
+	return devices
+}
+
+// gatherSecurity returns the state of selinux and apparmor. the state is "enforcing", "permissive", "enabled" or "disabled".
+func gatherSecurity(ctx context.Context, conn Connector) (map[string]any, error) {
+	output, err := conn.ExecuteCommand(ctx, "{ command -v getenforce >/dev/null 2>&1 && getenforce 2>/dev/null || echo Disabled; } && "+
+		"{ cat /sys/module/apparmor/parameters/enabled 2>/dev/null || echo N; }")
+	if err != nil {
+		return nil, fmt.Errorf("get security state error: %w, output: %s", err, output)
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != 2 {
+		return nil, fmt.Errorf("unexpected security state: %s", output)
+	}
+	apparmor := "disabled"
+	if strings.TrimSpace(lines[1]) == "Y" {
+		apparmor = "enabled"
+	}
+
+	return map[string]any{
+		"selinux":  strings.ToLower(strings.TrimSpace(lines[0])),
+		"apparmor": apparmor,
+	}, nil
+}
+
+// gatherSystem returns cgroup version and systemd version. systemd version is empty if systemd is not installed.
+func gatherSystem(ctx context.Context, conn Connector) (map[string]any, error) {
+	output, err := conn.ExecuteCommand(ctx, "stat -fc %T /sys/fs/cgroup 2>/dev/null && { systemctl --version 2>/dev/null | head -n1; true; }")
+	if err != nil {
+		return nil, fmt.Errorf("get system info error: %w, output: %s", err, output)
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	cgroupVersion := 1
+	if strings.TrimSpace(lines[0]) == "cgroup2fs" {
+		cgroupVersion = 2
+	}
+	var systemdVersion string
+	if len(lines) > 1 {
+		// such as: systemd 249 (249.11-0ubuntu3.12)
+		if fields := strings.Fields(lines[1]); len(fields) > 1 && fields[0] == "systemd" {
+			systemdVersion = fields[1]
+		}
+	}
+
+	return map[string]any{
+		"cgroup_version":  cgroupVersion,
+		"systemd_version": systemdVersion,
+	}, nil
+}
+
+// gatherContainerRuntimes returns the first line of "--version" output for each installed container runtime.
+func gatherContainerRuntimes(ctx context.Context, conn Connector) (map[string]any, error) {
+	output, err := conn.ExecuteCommand(ctx, fmt.Sprintf("for b in %s; do command -v $b >/dev/null 2>&1 && echo \"$b $($b --version 2>/dev/null | head -n1)\"; done; true",
+		strings.Join(containerRuntimes, " ")))
+	if err != nil {
+		return nil, fmt.Errorf("get container runtimes error: %w, output: %s", err, output)
+	}
+	runtimes := make(map[string]any)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		name, version, _ := strings.Cut(scanner.Text(), " ")
+		if slices.Contains(containerRuntimes, name) {
+			runtimes[name] = strings.TrimSpace(version)
+		}
+	}
+
+	return runtimes, nil
+}
+
+// gatherLocalFacts returns custom facts in factPath. the key is the file name without extension.
+// an executable file is executed and the others are read. the content is parsed as json, or kept as string if not json.
+func gatherLocalFacts(ctx context.Context, conn Connector, factPath string) (map[string]any, error) {
+	output, err := conn.ExecuteCommand(ctx, fmt.Sprintf("[ -d %[1]s ] || exit 0; for f in %[1]s/*; do [ -f \"$f\" ] || continue; "+
+		"if [ -x \"$f\" ]; then out=$(\"$f\") || continue; else out=$(cat \"$f\"); fi; "+
+		"echo \"$(basename \"$f\") $(printf '%%s' \"$out\" | base64 | tr -d '\\n')\"; done", ShellQuote(factPath)))
+	if err != nil {
+		return nil, fmt.Errorf("get local facts error: %w, output: %s", err, output)
+	}
+
+	return parseLocalFacts(output)
+}
+
+// parseLocalFacts convert lines of "<file name> <base64 content>" to local facts.
+func parseLocalFacts(data []byte) (map[string]any, error) {
+	facts := make(map[string]any)
+	// the line may be longer than the buffer of bufio.Scanner.
+	for _, line := range strings.Split(string(data), "\n") {
+		name, encoded, _ := strings.Cut(strings.TrimSpace(line), " ")
+		if name == "" {
+			continue
+		}
+		content, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("decode local fact %s error: %w", name, err)
+		}
+		key := strings.TrimSuffix(name, filepath.Ext(name))
+		var value any
+		if err := json.Unmarshal(content, &value); err != nil {
+			klog.V(4).InfoS("local fact is not json, keep it as string", "fact", name)
+			value = string(content)
+		}
+		facts[key] = value
+	}
+
+	return facts, nil
+}
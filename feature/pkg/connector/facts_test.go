@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/exec"
+)
+
+func TestParseFactSubset(t *testing.T) {
+	testcases := []struct {
+		name   string
+		subset []string
+		except []string
+		err    bool
+	}{
+		{
+			name:   "default is all",
+			except: []string{"min", "network", "hardware", "security", "system", "runtime", "local"},
+		},
+		{
+			name:   "only min",
+			subset: []string{"min"},
+			except: []string{"min"},
+		},
+		{
+			name:   "include subset",
+			subset: []string{"runtime", "network"},
+			except: []string{"min", "network", "runtime"},
+		},
+		{
+			name:   "only exclude subset",
+			subset: []string{"!hardware", "!local"},
+			except: []string{"min", "network", "security", "system", "runtime"},
+		},
+		{
+			name:   "exclude all",
+			subset: []string{"!all", "system"},
+			except: []string{"min", "system"},
+		},
+		{
+			name:   "unsupported subset",
+			subset: []string{"virtual"},
+			err:    true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := ParseFactSubset(tc.subset)
+			if tc.err {
+				assert.Error(t, err)
+
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.except, actual)
+		})
+	}
+}
+
+func TestParseNetwork(t *testing.T) {
+	interfaces, err := parseIPAddr([]byte(`[{"ifname":"lo","mtu":65536,"operstate":"UNKNOWN","address":"00:00:00:00:00:00",
+"addr_info":[{"family":"inet","local":"127.0.0.1","prefixlen":8}]},
+{"ifname":"eth0","mtu":1500,"operstate":"UP","address":"52:54:00:12:34:56",
+"addr_info":[{"family":"inet","local":"10.0.0.2","prefixlen":24},{"family":"inet6","local":"fe80::1","prefixlen":64}]}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []map[string]any{
+		{"name": "lo", "mtu": 65536, "state": "UNKNOWN", "mac": "00:00:00:00:00:00", "ipv4": []string{"127.0.0.1/8"}, "ipv6": []string{}},
+		{"name": "eth0", "mtu": 1500, "state": "UP", "mac": "52:54:00:12:34:56", "ipv4": []string{"10.0.0.2/24"}, "ipv6": []string{"fe80::1/64"}},
+	}, interfaces)
+
+	route, err := parseDefaultRoute([]byte(`[{"dst":"default","gateway":"10.0.0.1","dev":"eth0","flags":[]}]`), interfaces, "ipv4")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"gateway": "10.0.0.1", "interface": "eth0", "address": "10.0.0.2"}, route)
+
+	// no default route
+	route, err = parseDefaultRoute([]byte("[]\n"), interfaces, "ipv6")
+	assert.NoError(t, err)
+	assert.Empty(t, route)
+}
+
+func TestParseHardware(t *testing.T) {
+	disks, err := parseLsblk([]byte(`{"blockdevices":[{"name":"sda","type":"disk","size":21474836480,"rota":true,"model":"QEMU"},
+{"name":"sr0","type":"rom","size":1073741312,"rota":true,"model":"QEMU DVD-ROM"}]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]any{{"name": "sda", "type": "disk", "size": float64(21474836480), "rota": true, "model": "QEMU"}}, disks)
+
+	assert.Equal(t, []map[string]any{{"device": "/dev/sda1", "mount": "/", "fstype": "ext4", "options": "rw,relatime"}},
+		parseMounts([]byte("proc /proc proc rw,nosuid 0 0\n/dev/sda1 / ext4 rw,relatime 0 0\n")))
+
+	assert.Equal(t, []map[string]any{{"name": "/swap.img", "type": "file", "size": "2097148", "used": "0"}},
+		parseSwaps([]byte("Filename\t\t\t\tType\t\tSize\t\tUsed\t\tPriority\n/swap.img\tfile\t\t2097148\t\t0\t\t-2\n")))
+	assert.Empty(t, parseSwaps([]byte("Filename\t\t\t\tType\t\tSize\t\tUsed\t\tPriority\n")))
+}
+
+func TestGatherHostFacts_LocalFacts(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("local facts are only gathered in linux")
+	}
+	factPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(factPath, "static.fact"), []byte(`{"a": "b"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(factPath, "script.sh"), []byte("#!/bin/sh\necho '{\"c\": [1, 2]}'\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(factPath, "text"), []byte("plain"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	facts, err := GatherHostFacts(context.Background(), &localConnector{Cmd: exec.New()}, FactOption{
+		Subset:   []string{"local"},
+		FactPath: factPath,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, facts, "os")
+	assert.NotContains(t, facts, "network")
+	assert.Equal(t, map[string]any{
+		"static": map[string]any{"a": "b"},
+		"script": map[string]any{"c": []any{float64(1), float64(2)}},
+		"text":   "plain",
+	}, facts["local_facts"])
+}
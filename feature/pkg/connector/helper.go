@@ -19,7 +19,26 @@ package connector
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+
+	_const "github.com/kubesphere/kubekey/v4/pkg/const"
 )
 
 // convertBytesToMap with split string, only convert line which contain split
@@ -71,3 +90,273 @@ func convertBytesToSlice(bs []byte, split string) []map[string]string {
 
 	return config
 }
+
+// statLocalFile get the FileStat of local file.
+func statLocalFile(path string) (*FileStat, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	sum, err := sha256Sum(file)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, group := localFileOwner(info)
+
+	return &FileStat{Mode: info.Mode(), Owner: owner, Group: group, Sha256: sum}, nil
+}
+
+// sha256Sum returns the hex encoded sha256 checksum of reader.
+func sha256Sum(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// checkSha256 check the sha256 checksum of file is expected.
+func checkSha256(path, expect, actual string) error {
+	if !strings.EqualFold(expect, actual) {
+		return fmt.Errorf("checksum of %s mismatch: expect sha256 %s, but got %s", path, expect, actual)
+	}
+
+	return nil
+}
+
+// tempFileName returns the temp file name beside dst. the temp file is in the same dir, so it can be renamed to dst atomically.
+func tempFileName(dst string) string {
+	return filepath.Join(filepath.Dir(dst), "."+filepath.Base(dst)+".kubekey-"+strconv.FormatInt(time.Now().UnixNano(), 10))
+}
+
+// ShellQuote quote s to a single word in shell.
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
+// chownSpec returns the "owner:group" argument of chown command.
+func chownSpec(owner, group string) string {
+	if group == "" {
+		return owner
+	}
+
+	return owner + ":" + group
+}
+
+// chownCommand returns the shell command which change the owner of tmp file before it's renamed to dst.
+// if owner and group are not set, keep the owner of dst if it's exist, otherwise change to defaultOwner if it's not empty.
+func chownCommand(tmp, dst string, option FileOption, defaultOwner string) string {
+	if option.Owner != "" || option.Group != "" {
+		return "chown " + ShellQuote(chownSpec(option.Owner, option.Group)) + " " + ShellQuote(tmp)
+	}
+	keep := fmt.Sprintf("if [ -e %[1]s ]; then chown \"$(stat -c %%u:%%g %[1]s)\" %[2]s", ShellQuote(dst), ShellQuote(tmp))
+	if defaultOwner != "" {
+		keep += fmt.Sprintf("; else chown %s: %s", ShellQuote(defaultOwner), ShellQuote(tmp))
+	}
+
+	return keep + "; fi"
+}
+
+// putLocalFile write src to a temp file beside dst, verify the checksum, set mode and owner, and then rename it to dst.
+func putLocalFile(src io.Reader, dst string, option FileOption) error {
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		klog.V(4).ErrorS(err, "Failed to create local dir", "dst_file", dst)
+
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".kubekey-*")
+	if err != nil {
+		return fmt.Errorf("create temp file of %s error: %w", dst, err)
+	}
+	if err := writeLocalFile(tmp, src, dst, option); err != nil {
+		if err := os.Remove(tmp.Name()); err != nil {
+			klog.V(4).ErrorS(err, "Failed to remove temp file", "temp_file", tmp.Name())
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// writeLocalFile write src to the temp file and rename it to dst.
+func writeLocalFile(tmp *os.File, src io.Reader, dst string, option FileOption) error {
+	h := sha256.New()
+	_, err := io.Copy(io.MultiWriter(tmp, h), src)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if err := errors.Join(err, tmp.Close()); err != nil {
+		return fmt.Errorf("write content to %s error: %w", dst, err)
+	}
+	if option.Sha256 != "" {
+		if err := checkSha256(dst, option.Sha256, hex.EncodeToString(h.Sum(nil))); err != nil {
+			return err
+		}
+	}
+	// CreateTemp create file with mode 0600.
+	if err := os.Chmod(tmp.Name(), option.Mode.Perm()); err != nil {
+		return err
+	}
+	if err := chownLocalFile(tmp.Name(), dst, option); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), dst)
+}
+
+// chownLocalFile set the owner of file. if owner and group are not set, keep the owner of dst if it's exist.
+func chownLocalFile(path, dst string, option FileOption) error {
+	if option.Owner == "" && option.Group == "" {
+		info, err := os.Stat(dst)
+		if err != nil {
+			// dst is not exist. the file is owned by current user.
+			return nil
+		}
+		stat, ok := info.Sys().(*syscall.Stat_t)
+		if !ok {
+			return nil
+		}
+		if err := os.Lchown(path, int(stat.Uid), int(stat.Gid)); err != nil {
+			// only privileged user can change owner to others.
+			klog.V(4).ErrorS(err, "Failed to keep the owner of file", "dst_file", dst)
+		}
+
+		return nil
+	}
+
+	uid, gid := -1, -1
+	if option.Owner != "" {
+		u, err := user.Lookup(option.Owner)
+		if err != nil {
+			if u, err = user.LookupId(option.Owner); err != nil {
+				return fmt.Errorf("lookup owner %s error: %w", option.Owner, err)
+			}
+		}
+		if uid, err = strconv.Atoi(u.Uid); err != nil {
+			return fmt.Errorf("parse uid of %s error: %w", option.Owner, err)
+		}
+	}
+	if option.Group != "" {
+		g, err := user.LookupGroup(option.Group)
+		if err != nil {
+			if g, err = user.LookupGroupId(option.Group); err != nil {
+				return fmt.Errorf("lookup group %s error: %w", option.Group, err)
+			}
+		}
+		if gid, err = strconv.Atoi(g.Gid); err != nil {
+			return fmt.Errorf("parse gid of %s error: %w", option.Group, err)
+		}
+	}
+	if err := os.Lchown(path, uid, gid); err != nil {
+		return fmt.Errorf("change owner of %s error: %w", dst, err)
+	}
+
+	return nil
+}
+
+// localFileOwner returns the owner and group name of local file. the id is returned if the name is not found.
+func localFileOwner(info fs.FileInfo) (string, string) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", ""
+	}
+	owner := strconv.FormatUint(uint64(stat.Uid), 10)
+	if u, err := user.LookupId(owner); err == nil {
+		owner = u.Username
+	}
+	group := strconv.FormatUint(uint64(stat.Gid), 10)
+	if g, err := user.LookupGroupId(group); err == nil {
+		group = g.Name
+	}
+
+	return owner, group
+}
+
+// fetchRESTConfig get the rest config from the kubeconfig file in host.
+func fetchRESTConfig(ctx context.Context, conn Connector, kubeconfig string) (*rest.Config, error) {
+	data := &bytes.Buffer{}
+	if err := conn.FetchFile(ctx, kubeconfig, data); err != nil {
+		return nil, fmt.Errorf("fetch kubeconfig %s error: %w", kubeconfig, err)
+	}
+	config, err := clientcmd.RESTConfigFromKubeConfig(data.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("load kubeconfig %s error: %w", kubeconfig, err)
+	}
+
+	return config, nil
+}
+
+// statFileByCommand get the FileStat of file by "stat" and "sha256sum" command in host.
+func statFileByCommand(ctx context.Context, conn Connector, path string) (*FileStat, error) {
+	if _, err := conn.ExecuteCommand(ctx, "test -e "+ShellQuote(path)); err != nil {
+		return nil, fmt.Errorf("stat file %s: %w", path, fs.ErrNotExist)
+	}
+	output, err := conn.ExecuteCommand(ctx, "stat -c '%a %U %G' "+ShellQuote(path)+" && sha256sum "+ShellQuote(path))
+	if err != nil {
+		return nil, fmt.Errorf("stat file %s error: %w, output: %s", path, err, output)
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) < 4 {
+		return nil, fmt.Errorf("stat file %s error: unexpected output %q", path, output)
+	}
+	perm, err := strconv.ParseUint(fields[0], 8, 32)
+	if err != nil {
+		return nil, fmt.Errorf("parse mode of file %s error: %w", path, err)
+	}
+
+	return &FileStat{Mode: fs.FileMode(perm), Owner: fields[1], Group: fields[2], Sha256: fields[3]}, nil
+}
+
+// hostInfo gather the os and process information of linux host by conn.
+func hostInfo(ctx context.Context, conn Connector) (map[string]any, error) {
+	// os information
+	osVars := make(map[string]any)
+	var osRelease bytes.Buffer
+	if err := conn.FetchFile(ctx, "/etc/os-release", &osRelease); err != nil {
+		return nil, fmt.Errorf("failed to fetch os-release: %w", err)
+	}
+	osVars[_const.VariableOSRelease] = convertBytesToMap(osRelease.Bytes(), "=")
+	kernel, err := conn.ExecuteCommand(ctx, "uname -r")
+	if err != nil {
+		return nil, fmt.Errorf("get kernel version error: %w", err)
+	}
+	osVars[_const.VariableOSKernelVersion] = string(bytes.TrimSuffix(kernel, []byte("\n")))
+	hn, err := conn.ExecuteCommand(ctx, "hostname")
+	if err != nil {
+		return nil, fmt.Errorf("get hostname error: %w", err)
+	}
+	osVars[_const.VariableOSHostName] = string(bytes.TrimSuffix(hn, []byte("\n")))
+	arch, err := conn.ExecuteCommand(ctx, "arch")
+	if err != nil {
+		return nil, fmt.Errorf("get arch error: %w", err)
+	}
+	osVars[_const.VariableOSArchitecture] = string(bytes.TrimSuffix(arch, []byte("\n")))
+
+	// process information
+	procVars := make(map[string]any)
+	var cpu bytes.Buffer
+	if err := conn.FetchFile(ctx, "/proc/cpuinfo", &cpu); err != nil {
+		return nil, fmt.Errorf("get cpuinfo error: %w", err)
+	}
+	procVars[_const.VariableProcessCPU] = convertBytesToSlice(cpu.Bytes(), ":")
+	var mem bytes.Buffer
+	if err := conn.FetchFile(ctx, "/proc/meminfo", &mem); err != nil {
+		return nil, fmt.Errorf("get meminfo error: %w", err)
+	}
+	procVars[_const.VariableProcessMemory] = convertBytesToMap(mem.Bytes(), ":")
+
+	return map[string]any{
+		_const.VariableOS:      osVars,
+		_const.VariableProcess: procVars,
+	}, nil
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connector
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"k8s.io/klog/v2"
+)
+
+// host key checking mode for ssh connector. the same as "StrictHostKeyChecking" in openssh.
+const (
+	// hostKeyCheckingStrict only accept the host key which recorded in known_hosts. it's the default mode.
+	hostKeyCheckingStrict = "yes"
+	// hostKeyCheckingAcceptNew record the host key of new host to known_hosts (trust on first use),
+	// and reject the host whose key has changed. it should be set explicitly.
+	hostKeyCheckingAcceptNew = "accept-new"
+	// hostKeyCheckingNo do not check the host key.
+	hostKeyCheckingNo = "no"
+)
+
+// knownHostsLock protect the known_hosts file which may be written by hosts concurrently.
+var knownHostsLock sync.Mutex
+
+// hostKeyCallback return the ssh.HostKeyCallback to verify host key.
+// if fingerprints is set, the host key should match one of them, and known_hosts is not used.
+func hostKeyCallback(checking, knownHostsFile string, fingerprints []string) (ssh.HostKeyCallback, error) {
+	if len(fingerprints) != 0 {
+		return func(hostname string, _ net.Addr, key ssh.PublicKey) error {
+			if fp := ssh.FingerprintSHA256(key); !slices.Contains(fingerprints, fp) {
+				return fmt.Errorf("host key fingerprint %s of %s does not match the pinned fingerprints", fp, hostname)
+			}
+
+			return nil
+		}, nil
+	}
+
+	if checking == "" {
+		checking = hostKeyCheckingStrict
+	}
+	switch checking {
+	case hostKeyCheckingStrict, hostKeyCheckingAcceptNew:
+	case hostKeyCheckingNo:
+		return ssh.InsecureIgnoreHostKey(), nil
+	default:
+		return nil, fmt.Errorf("unsupported host key checking %q", checking)
+	}
+
+	// known_hosts file should exist when create callback.
+	if err := os.MkdirAll(filepath.Dir(knownHostsFile), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("create known_hosts dir error: %w", err)
+	}
+	f, err := os.OpenFile(knownHostsFile, os.O_CREATE|os.O_RDONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("open known_hosts %s error: %w", knownHostsFile, err)
+	}
+	f.Close()
+
+	knownHostsLock.Lock()
+	callback, err := knownhosts.New(knownHostsFile)
+	knownHostsLock.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("parse known_hosts %s error: %w", knownHostsFile, err)
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := callback(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if err == nil || !errors.As(err, &keyErr) {
+			return err
+		}
+		if len(keyErr.Want) != 0 {
+			// the host is known, but the key is not match. it may be a man-in-the-middle attack.
+			return fmt.Errorf("host key of %s has changed, remove the old key from known_hosts %s if it's expected: %w",
+				hostname, knownHostsFile, err)
+		}
+		if checking != hostKeyCheckingAcceptNew {
+			return fmt.Errorf("host key of %s is unknown, add it to known_hosts %s or set %q to %q: %w",
+				hostname, knownHostsFile, "host_key_checking", hostKeyCheckingAcceptNew, err)
+		}
+		klog.V(4).InfoS("add new host key to known_hosts", "host", hostname, "fingerprint", ssh.FingerprintSHA256(key), "known_hosts", knownHostsFile)
+
+		return appendKnownHost(knownHostsFile, hostname, key)
+	}, nil
+}
+
+// appendKnownHost record the host key to known_hosts file.
+func appendKnownHost(knownHostsFile, hostname string, key ssh.PublicKey) error {
+	knownHostsLock.Lock()
+	defer knownHostsLock.Unlock()
+
+	f, err := os.OpenFile(knownHostsFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("open known_hosts %s error: %w", knownHostsFile, err)
+	}
+	defer f.Close()
+
+	if _, err := fmt.Fprintln(f, knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key)); err != nil {
+		return fmt.Errorf("write known_hosts %s error: %w", knownHostsFile, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connector
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+func newTestHostKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+func TestHostKeyCallback(t *testing.T) {
+	hostname := "node1:22"
+	remote := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 22}
+	key := newTestHostKey(t)
+	changedKey := newTestHostKey(t)
+
+	t.Run("pinned fingerprints", func(t *testing.T) {
+		callback, err := hostKeyCallback(hostKeyCheckingStrict, filepath.Join(t.TempDir(), "known_hosts"), []string{ssh.FingerprintSHA256(key)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.NoError(t, callback(hostname, remote, key))
+		assert.Error(t, callback(hostname, remote, changedKey))
+	})
+
+	t.Run("unknown host in strict mode", func(t *testing.T) {
+		callback, err := hostKeyCallback(hostKeyCheckingStrict, filepath.Join(t.TempDir(), "known_hosts"), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Error(t, callback(hostname, remote, key))
+	})
+
+	t.Run("trust on first use", func(t *testing.T) {
+		knownHosts := filepath.Join(t.TempDir(), "known_hosts")
+		callback, err := hostKeyCallback(hostKeyCheckingAcceptNew, knownHosts, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := callback(hostname, remote, key); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(knownHosts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Contains(t, string(data), "node1 ")
+
+		// the recorded key is accepted in strict mode, and the changed key is rejected in any mode.
+		callback, err = hostKeyCallback(hostKeyCheckingStrict, knownHosts, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.NoError(t, callback(hostname, remote, key))
+		callback, err = hostKeyCallback(hostKeyCheckingAcceptNew, knownHosts, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.ErrorContains(t, callback(hostname, remote, changedKey), "has changed")
+	})
+
+	t.Run("default mode is strict", func(t *testing.T) {
+		knownHosts := filepath.Join(t.TempDir(), "known_hosts")
+		callback, err := hostKeyCallback("", knownHosts, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.ErrorContains(t, callback(hostname, remote, key), "is unknown")
+		// the unknown host is not recorded.
+		data, err := os.ReadFile(knownHosts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Empty(t, data)
+	})
+
+	t.Run("no checking", func(t *testing.T) {
+		callback, err := hostKeyCallback(hostKeyCheckingNo, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.NoError(t, callback(hostname, remote, key))
+	})
+
+	t.Run("unsupported mode", func(t *testing.T) {
+		_, err := hostKeyCallback("unknown", "", nil)
+		assert.Error(t, err)
+	})
+}
@@ -17,21 +17,28 @@ limitations under the License.
 package connector
 
 import (
+	"bytes"
 	"context"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
 
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/exec"
 
 	_const "github.com/kubesphere/kubekey/v4/pkg/const"
+	"github.com/kubesphere/kubekey/v4/pkg/variable/vault"
 )
 
 const kubeconfigRelPath = ".kube/config"
 
 var _ Connector = &kubernetesConnector{}
+var _ FileStater = &kubernetesConnector{}
+var _ FileStreamer = &kubernetesConnector{}
+var _ RESTConfigGetter = &kubernetesConnector{}
 
 type kubernetesConnector struct {
 	clusterName string
@@ -83,23 +90,34 @@ func (c *kubernetesConnector) Close(_ context.Context) error {
 // PutFile copy src file to dst file. src is the local filename, dst is the local filename.
 // Typically, the configuration file for each cluster may be different,
 // and it may be necessary to keep them in separate directories locally.
-func (c *kubernetesConnector) PutFile(_ context.Context, src []byte, dst string, mode fs.FileMode) error {
-	dst = filepath.Join(c.homeDir, dst)
-	if _, err := os.Stat(filepath.Dir(dst)); err != nil && os.IsNotExist(err) {
-		if err := os.MkdirAll(filepath.Dir(dst), mode); err != nil {
-			klog.V(4).ErrorS(err, "Failed to create local dir", "dst_file", dst)
+func (c *kubernetesConnector) PutFile(ctx context.Context, src []byte, dst string, mode fs.FileMode) error {
+	return c.PutFileStream(ctx, bytes.NewReader(src), dst, FileOption{Mode: mode})
+}
 
-			return err
-		}
+// PutFileStream copy src reader to dst file atomically. dst is relative to the cluster's home dir.
+func (c *kubernetesConnector) PutFileStream(_ context.Context, src io.Reader, dst string, option FileOption) error {
+	return putLocalFile(src, filepath.Join(c.homeDir, dst), option)
+}
+
+// StatFile get the FileStat of local file which is stored in the cluster's home dir.
+func (c *kubernetesConnector) StatFile(_ context.Context, path string) (*FileStat, error) {
+	return statLocalFile(filepath.Join(c.homeDir, path))
+}
+
+// RESTConfig returns the rest config of the cluster. the kubeconfig in host is ignored.
+func (c *kubernetesConnector) RESTConfig(context.Context, string) (*rest.Config, error) {
+	if c.homeDir == "" {
+		// use default kubeconfig.
+		return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{}).ClientConfig()
 	}
 
-	return os.WriteFile(dst, src, mode)
+	return clientcmd.BuildConfigFromFlags("", filepath.Join(c.homeDir, kubeconfigRelPath))
 }
 
 // FetchFile copy src file to dst writer. src is the local filename, dst is the local writer.
 func (c *kubernetesConnector) FetchFile(ctx context.Context, src string, dst io.Writer) error {
 	// add "--kubeconfig" to src command
-	klog.V(5).InfoS("exec local command", "cmd", src)
+	klog.V(5).InfoS("exec local command", "cmd", vault.Redact(src))
 	command := c.Cmd.CommandContext(ctx, "/bin/sh", "-c", src)
 	command.SetDir(c.homeDir)
 	command.SetEnv([]string{"KUBECONFIG=" + filepath.Join(c.homeDir, kubeconfigRelPath)})
@@ -112,10 +130,10 @@ func (c *kubernetesConnector) FetchFile(ctx context.Context, src string, dst io.
 // ExecuteCommand in a kubernetes cluster
 func (c *kubernetesConnector) ExecuteCommand(ctx context.Context, cmd string) ([]byte, error) {
 	// add "--kubeconfig" to src command
-	klog.V(5).InfoS("exec local command", "cmd", cmd)
+	klog.V(5).InfoS("exec local command", "cmd", vault.Redact(cmd))
 	command := c.Cmd.CommandContext(ctx, "/bin/sh", "-c", cmd)
 	command.SetDir(c.homeDir)
 	command.SetEnv([]string{"KUBECONFIG=" + filepath.Join(c.homeDir, kubeconfigRelPath)})
 
-	return command.CombinedOutput()
+	return combinedOutput(ctx, command)
 }
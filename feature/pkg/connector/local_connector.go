@@ -23,17 +23,21 @@ import (
 	"io"
 	"io/fs"
 	"os"
-	"path/filepath"
 	"runtime"
 
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/exec"
 
-	_const "github.com/kubesphere/kubekey/v4/pkg/const"
+	"github.com/kubesphere/kubekey/v4/pkg/variable/vault"
 )
 
 var _ Connector = &localConnector{}
 var _ GatherFacts = &localConnector{}
+var _ FileStater = &localConnector{}
+var _ FileStreamer = &localConnector{}
+var _ RESTConfigGetter = &localConnector{}
 
 type localConnector struct {
 	Cmd exec.Interface
@@ -50,16 +54,23 @@ func (c *localConnector) Close(context.Context) error {
 }
 
 // PutFile copy src file to dst file. src is the local filename, dst is the local filename.
-func (c *localConnector) PutFile(_ context.Context, src []byte, dst string, mode fs.FileMode) error {
-	if _, err := os.Stat(filepath.Dir(dst)); err != nil && os.IsNotExist(err) {
-		if err := os.MkdirAll(filepath.Dir(dst), mode); err != nil {
-			klog.V(4).ErrorS(err, "Failed to create local dir", "dst_file", dst)
+func (c *localConnector) PutFile(ctx context.Context, src []byte, dst string, mode fs.FileMode) error {
+	return c.PutFileStream(ctx, bytes.NewReader(src), dst, FileOption{Mode: mode})
+}
 
-			return err
-		}
-	}
+// PutFileStream copy src reader to dst file atomically. dst is the local filename.
+func (c *localConnector) PutFileStream(_ context.Context, src io.Reader, dst string, option FileOption) error {
+	return putLocalFile(src, dst, option)
+}
+
+// StatFile get the FileStat of local file.
+func (c *localConnector) StatFile(_ context.Context, path string) (*FileStat, error) {
+	return statLocalFile(path)
+}
 
-	return os.WriteFile(dst, src, mode)
+// RESTConfig returns the rest config by the local kubeconfig file.
+func (c *localConnector) RESTConfig(_ context.Context, kubeconfig string) (*rest.Config, error) {
+	return clientcmd.BuildConfigFromFlags("", kubeconfig)
 }
 
 // FetchFile copy src file to dst writer. src is the local filename, dst is the local writer.
@@ -83,55 +94,36 @@ func (c *localConnector) FetchFile(_ context.Context, src string, dst io.Writer)
 
 // ExecuteCommand in local host
 func (c *localConnector) ExecuteCommand(ctx context.Context, cmd string) ([]byte, error) {
-	klog.V(5).InfoS("exec local command", "cmd", cmd)
+	klog.V(5).InfoS("exec local command", "cmd", vault.Redact(cmd))
 
-	return c.Cmd.CommandContext(ctx, "/bin/sh", "-c", cmd).CombinedOutput()
+	return combinedOutput(ctx, c.Cmd.CommandContext(ctx, "/bin/sh", "-c", cmd))
+}
+
+// combinedOutput run the command and return its combined output. the shell is killed when ctx is done, but the
+// processes started by it may still hold the output open. return without waiting for them.
+func combinedOutput(ctx context.Context, command exec.Cmd) ([]byte, error) {
+	type result struct {
+		output []byte
+		err    error
+	}
+	resultCh := make(chan result, 1)
+	go func() {
+		output, err := command.CombinedOutput()
+		resultCh <- result{output: output, err: err}
+	}()
+	select {
+	case r := <-resultCh:
+		return r.output, r.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("execute command stopped: %w", ctx.Err())
+	}
 }
 
 // HostInfo for GatherFacts
 func (c *localConnector) HostInfo(ctx context.Context) (map[string]any, error) {
 	switch runtime.GOOS {
 	case "linux":
-		// os information
-		osVars := make(map[string]any)
-		var osRelease bytes.Buffer
-		if err := c.FetchFile(ctx, "/etc/os-release", &osRelease); err != nil {
-			return nil, fmt.Errorf("failed to fetch os-release: %w", err)
-		}
-		osVars[_const.VariableOSRelease] = convertBytesToMap(osRelease.Bytes(), "=")
-		kernel, err := c.ExecuteCommand(ctx, "uname -r")
-		if err != nil {
-			return nil, fmt.Errorf("get kernel version error: %w", err)
-		}
-		osVars[_const.VariableOSKernelVersion] = string(bytes.TrimSuffix(kernel, []byte("\n")))
-		hn, err := c.ExecuteCommand(ctx, "hostname")
-		if err != nil {
-			return nil, fmt.Errorf("get hostname error: %w", err)
-		}
-		osVars[_const.VariableOSHostName] = string(bytes.TrimSuffix(hn, []byte("\n")))
-		arch, err := c.ExecuteCommand(ctx, "arch")
-		if err != nil {
-			return nil, fmt.Errorf("get arch error: %w", err)
-		}
-		osVars[_const.VariableOSArchitecture] = string(bytes.TrimSuffix(arch, []byte("\n")))
-
-		// process information
-		procVars := make(map[string]any)
-		var cpu bytes.Buffer
-		if err := c.FetchFile(ctx, "/proc/cpuinfo", &cpu); err != nil {
-			return nil, fmt.Errorf("get cpuinfo error: %w", err)
-		}
-		procVars[_const.VariableProcessCPU] = convertBytesToSlice(cpu.Bytes(), ":")
-		var mem bytes.Buffer
-		if err := c.FetchFile(ctx, "/proc/meminfo", &mem); err != nil {
-			return nil, fmt.Errorf("get meminfo error: %w", err)
-		}
-		procVars[_const.VariableProcessMemory] = convertBytesToMap(mem.Bytes(), ":")
-
-		return map[string]any{
-			_const.VariableOS:      osVars,
-			_const.VariableProcess: procVars,
-		}, nil
+		return hostInfo(ctx, c)
 	default:
 		klog.V(4).ErrorS(nil, "Unsupported platform", "platform", runtime.GOOS)
 
@@ -20,6 +20,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -79,3 +82,116 @@ func TestSshConnector_ExecuteCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestLocalConnector_StatFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "test")
+	if err := os.WriteFile(file, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner, group := localFileOwner(info)
+
+	testcases := []struct {
+		name   string
+		path   string
+		except *FileStat
+	}{
+		{
+			name: "file exist",
+			path: file,
+			except: &FileStat{
+				Mode:   0644,
+				Owner:  owner,
+				Group:  group,
+				Sha256: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+			},
+		},
+		{
+			name:   "file not exist",
+			path:   filepath.Join(dir, "not-exist"),
+			except: nil,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			stat, _ := (&localConnector{}).StatFile(context.Background(), tc.path)
+			assert.Equal(t, tc.except, stat)
+		})
+	}
+}
+
+func TestLocalConnector_PutFileStream(t *testing.T) {
+	dir := t.TempDir()
+	exist := filepath.Join(dir, "exist")
+	if err := os.WriteFile(exist, []byte("old"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name    string
+		dst     string
+		option  FileOption
+		content string
+		except  string
+		mode    fs.FileMode
+		err     bool
+	}{
+		{
+			name:    "put new file in not exist dir",
+			dst:     filepath.Join(dir, "sub", "new"),
+			option:  FileOption{Mode: 0640},
+			content: "hello",
+			except:  "hello",
+			mode:    0640,
+		},
+		{
+			name:    "replace exist file with checksum",
+			dst:     exist,
+			option:  FileOption{Mode: 0644, Sha256: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"},
+			content: "hello",
+			except:  "hello",
+			mode:    0644,
+		},
+		{
+			name:    "checksum mismatch keep the exist file",
+			dst:     exist,
+			option:  FileOption{Mode: 0644, Sha256: "0000"},
+			content: "world",
+			except:  "hello",
+			mode:    0644,
+			err:     true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := (&localConnector{}).PutFileStream(context.Background(), strings.NewReader(tc.content), tc.dst, tc.option)
+			if tc.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			data, err := os.ReadFile(tc.dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tc.except, string(data))
+			info, err := os.Stat(tc.dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tc.mode, info.Mode().Perm())
+			// the temp file is renamed or removed.
+			tmps, err := filepath.Glob(filepath.Join(filepath.Dir(tc.dst), ".*.kubekey-*"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Empty(t, tmps)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"k8s.io/klog/v2"
+)
+
+// sharer is the connector whose connection can be shared by the connectors of the same host.
+type sharer interface {
+	Connector
+	// share return a connector which use the same connection. the Close of it does not close the connection.
+	share() Connector
+	// closeShared close the shared connection.
+	closeShared() error
+}
+
+// Pool cache the connection of hosts in a pipeline. it's shared by all tasks, and closed when the pipeline finished.
+type Pool struct {
+	sync.Mutex
+	// connectors is keyed by host and connector variables.
+	connectors map[string]sharer
+}
+
+// NewPool return an empty Pool.
+func NewPool() *Pool {
+	return &Pool{connectors: make(map[string]sharer)}
+}
+
+// Get the initialized connector of host. the connector should be closed after used.
+// if the pool is nil or the connector can not be shared, return a new connector.
+func (p *Pool) Get(ctx context.Context, host string, connectorVars map[string]any) (Connector, error) {
+	conn, err := NewConnector(host, connectorVars)
+	if err != nil {
+		return nil, err
+	}
+	sc, ok := conn.(sharer)
+	if p == nil || !ok {
+		if err := conn.Init(ctx); err != nil {
+			return nil, err
+		}
+
+		return conn, nil
+	}
+
+	// the connection changed if connector variables changed.
+	key := fmt.Sprintf("%s/%v", host, connectorVars)
+	p.Lock()
+	if cached, ok := p.connectors[key]; ok {
+		sc = cached
+	} else {
+		p.connectors[key] = sc
+	}
+	shared := sc.share()
+	p.Unlock()
+
+	// connect to host when it's first used or the connection is broken.
+	if err := shared.Init(ctx); err != nil {
+		return nil, err
+	}
+
+	return shared, nil
+}
+
+// Close all cached connections.
+func (p *Pool) Close() {
+	if p == nil {
+		return
+	}
+	p.Lock()
+	defer p.Unlock()
+
+	for key, sc := range p.connectors {
+		if err := sc.closeShared(); err != nil {
+			klog.V(4).ErrorS(err, "close connection error")
+		}
+		delete(p.connectors, key)
+	}
+}
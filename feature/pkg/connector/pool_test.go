@@ -0,0 +1,191 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connector
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+// testSSHServer is a ssh server which reply "ok" for every command, except "hang" which never returns.
+type testSSHServer struct {
+	sync.Mutex
+	listener net.Listener
+	hostKey  ssh.PublicKey
+	// conns is the accepted connections.
+	conns []net.Conn
+	// hangRequests makes the server never reply the global requests, such as keepalive.
+	hangRequests atomic.Bool
+}
+
+func newTestSSHServer(t *testing.T) *testSSHServer {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{NoClientAuth: true}
+	config.AddHostKey(signer)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &testSSHServer{listener: listener, hostKey: signer.PublicKey()}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.Lock()
+			s.conns = append(s.conns, conn)
+			s.Unlock()
+			go s.serve(conn, config)
+		}
+	}()
+
+	return s
+}
+
+func (s *testSSHServer) serve(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	if s.hangRequests.Load() {
+		go func() {
+			for range reqs {
+			}
+		}()
+	} else {
+		go ssh.DiscardRequests(reqs)
+	}
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go func() {
+			for req := range requests {
+				if req.Type != "exec" {
+					_ = req.Reply(false, nil)
+
+					continue
+				}
+				_ = req.Reply(true, nil)
+				var payload struct{ Command string }
+				if err := ssh.Unmarshal(req.Payload, &payload); err == nil && payload.Command == "hang" {
+					continue
+				}
+				_, _ = channel.Write([]byte("ok"))
+				_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{0}))
+				channel.Close()
+			}
+		}()
+	}
+}
+
+// accepted return the number of accepted connections.
+func (s *testSSHServer) accepted() int {
+	s.Lock()
+	defer s.Unlock()
+
+	return len(s.conns)
+}
+
+// breakConns close all accepted connections.
+func (s *testSSHServer) breakConns() {
+	s.Lock()
+	defer s.Unlock()
+
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+}
+
+func TestPool(t *testing.T) {
+	ctx := context.Background()
+	server := newTestSSHServer(t)
+	addr := server.listener.Addr().(*net.TCPAddr)
+	connectorVars := map[string]any{
+		"type":                  "ssh",
+		"host":                  addr.IP.String(),
+		"port":                  addr.Port,
+		"private_key":           "",
+		"host_key_fingerprints": []any{ssh.FingerprintSHA256(server.hostKey)},
+	}
+	pool := NewPool()
+	exec := func() {
+		conn, err := pool.Get(ctx, "node1", connectorVars)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close(ctx)
+		output, err := conn.ExecuteCommand(ctx, "echo ok")
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", string(output))
+	}
+
+	// the connection is shared by all connectors of the host.
+	exec()
+	exec()
+	assert.Equal(t, 1, server.accepted())
+
+	// reconnect when the connection is broken.
+	server.breakConns()
+	exec()
+	assert.Equal(t, 2, server.accepted())
+
+	// the connection can not be used after the pool closed.
+	conn, err := pool.Get(ctx, "node1", connectorVars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.Close()
+	_, err = conn.ExecuteCommand(ctx, "echo ok")
+	assert.Error(t, err)
+}
+
+func TestPool_NotShared(t *testing.T) {
+	ctx := context.Background()
+	var pool *Pool
+	conn, err := pool.Get(ctx, "localhost", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(ctx)
+	assert.IsType(t, &localConnector{}, conn)
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connector
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/term"
+	"k8s.io/klog/v2"
+
+	_const "github.com/kubesphere/kubekey/v4/pkg/const"
+)
+
+// sshCertSuffix is the suffix of openssh user certificate file. the certificate of "id_ed25519" is "id_ed25519-cert.pub".
+const sshCertSuffix = "-cert.pub"
+
+// maxPassphraseAttempts is the times to prompt the passphrase of private key.
+const maxPassphraseAttempts = 3
+
+// passphrases cache the passphrases which input by prompt. key is the private key file.
+var passphrases = struct {
+	sync.Mutex
+	cache map[string]string
+}{cache: make(map[string]string)}
+
+// sshAuth get the auth methods by password, private key files and ssh-agent.
+// the returned func should be called to close ssh-agent after the connection is established.
+func sshAuth(password string, privateKeys []string, passphrase, agentSocket string) ([]ssh.AuthMethod, func(), error) {
+	var auth []ssh.AuthMethod
+	if password != "" {
+		auth = append(auth, ssh.Password(password))
+	}
+	signers, closeAgent, err := sshSigners(privateKeys, passphrase, agentSocket)
+	if err != nil {
+		return nil, nil, err
+	}
+	// all signers should be in one auth method, the client only try the first method of the same type.
+	if len(signers) != 0 {
+		auth = append(auth, ssh.PublicKeys(signers...))
+	}
+
+	return auth, closeAgent, nil
+}
+
+// sshSigners get signers from private key files in order, and then from ssh-agent.
+// if the certificate of private key is exist, it's used before the private key.
+// if private key files is empty, the default private keys are tried. the default private key which cannot be used
+// (e.g. it's encrypted without passphrase) is skipped, while the configured private key fails the authentication.
+func sshSigners(privateKeys []string, passphrase, agentSocket string) ([]ssh.Signer, func(), error) {
+	keyFiles, isDefault := privateKeys, false
+	if len(keyFiles) == 0 {
+		keyFiles, isDefault = defaultSSHPrivateKeys, true
+	}
+	var signers []ssh.Signer
+	for _, keyFile := range keyFiles {
+		if _, err := os.Stat(keyFile); err != nil {
+			klog.V(4).InfoS("private key is not exist, skip it", "private_key", keyFile)
+
+			continue
+		}
+		keySigners, err := privateKeySigners(keyFile, passphrase)
+		if err != nil {
+			if isDefault {
+				klog.Warningf("skip default private key %s: %v", keyFile, err)
+
+				continue
+			}
+
+			return nil, nil, err
+		}
+		signers = append(signers, keySigners...)
+	}
+
+	closeAgent := func() {}
+	if agentSocket == "" {
+		return signers, closeAgent, nil
+	}
+	conn, err := net.Dial("unix", agentSocket)
+	if err != nil {
+		// ssh-agent is optional. try the other auth methods.
+		klog.V(4).ErrorS(err, "connect to ssh-agent error", "agent_socket", agentSocket)
+
+		return signers, closeAgent, nil
+	}
+	agentSigners, err := agent.NewClient(conn).Signers()
+	if err != nil {
+		conn.Close()
+
+		return nil, nil, fmt.Errorf("get signers from ssh-agent error: %w", err)
+	}
+
+	return append(signers, agentSigners...), func() { conn.Close() }, nil
+}
+
+// privateKeySigners return the signers of private key file. the signer of certificate is before the private key's.
+func privateKeySigners(keyFile, passphrase string) ([]ssh.Signer, error) {
+	key, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("read private key %s error: %w", keyFile, err)
+	}
+	signer, err := parsePrivateKey(keyFile, key, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	certSigner, err := parseCertificate(keyFile+sshCertSuffix, signer)
+	if err != nil {
+		return nil, err
+	}
+	if certSigner != nil {
+		return []ssh.Signer{certSigner, signer}, nil
+	}
+
+	return []ssh.Signer{signer}, nil
+}
+
+// parsePrivateKey parse private key. if the private key is encrypted and passphrase is empty, prompt to input it.
+func parsePrivateKey(keyFile string, key []byte, passphrase string) (ssh.Signer, error) {
+	signer, err := ssh.ParsePrivateKey(key)
+	var missingErr *ssh.PassphraseMissingError
+	if !errors.As(err, &missingErr) {
+		if err != nil {
+			return nil, fmt.Errorf("parse private key %s error: %w", keyFile, err)
+		}
+
+		return signer, nil
+	}
+
+	if passphrase == "" {
+		return promptPrivateKey(keyFile, key)
+	}
+	signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(passphrase))
+	if err != nil {
+		return nil, fmt.Errorf("parse private key %s with passphrase error: %w", keyFile, err)
+	}
+
+	return signer, nil
+}
+
+// parseCertificate parse openssh user certificate, and return the signer of it.
+// if the certificate file is not exist, return nil.
+func parseCertificate(certFile string, signer ssh.Signer) (ssh.Signer, error) {
+	if _, err := os.Stat(certFile); err != nil {
+		return nil, nil
+	}
+	data, err := os.ReadFile(certFile)
+	if err != nil {
+		return nil, fmt.Errorf("read certificate %s error: %w", certFile, err)
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse certificate %s error: %w", certFile, err)
+	}
+	cert, ok := pub.(*ssh.Certificate)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a certificate", certFile)
+	}
+	certSigner, err := ssh.NewCertSigner(cert, signer)
+	if err != nil {
+		return nil, fmt.Errorf("certificate %s does not match the private key: %w", certFile, err)
+	}
+
+	return certSigner, nil
+}
+
+// promptPrivateKey parse the encrypted private key with the passphrase read from terminal. the wrong passphrase
+// is prompted again up to maxPassphraseAttempts times. only the passphrase which decrypts the private key is cached
+// for other hosts.
+func promptPrivateKey(keyFile string, key []byte) (ssh.Signer, error) {
+	passphrases.Lock()
+	defer passphrases.Unlock()
+
+	if passphrase, ok := passphrases.cache[keyFile]; ok {
+		signer, err := ssh.ParsePrivateKeyWithPassphrase(key, []byte(passphrase))
+		if err != nil {
+			return nil, fmt.Errorf("parse private key %s with passphrase error: %w", keyFile, err)
+		}
+
+		return signer, nil
+	}
+	var err error
+	for range maxPassphraseAttempts {
+		var passphrase []byte
+		if passphrase, err = readPassphrase(keyFile); err != nil {
+			return nil, err
+		}
+		var signer ssh.Signer
+		if signer, err = ssh.ParsePrivateKeyWithPassphrase(key, passphrase); err == nil {
+			passphrases.cache[keyFile] = string(passphrase)
+
+			return signer, nil
+		}
+		if !errors.Is(err, x509.IncorrectPasswordError) {
+			break
+		}
+		fmt.Fprintln(os.Stderr, "Bad passphrase, try again.")
+	}
+
+	return nil, fmt.Errorf("parse private key %s with passphrase error: %w", keyFile, err)
+}
+
+// readPassphrase read the passphrase of private key from terminal.
+var readPassphrase = func(keyFile string) ([]byte, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, fmt.Errorf("private key %s is encrypted, set %q in connector variable", keyFile, _const.VariableConnectorPrivateKeyPassphrase)
+	}
+	fmt.Fprintf(os.Stderr, "Enter passphrase for key %s: ", keyFile)
+	passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("read passphrase of private key %s error: %w", keyFile, err)
+	}
+
+	return passphrase, nil
+}
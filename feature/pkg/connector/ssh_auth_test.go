@@ -0,0 +1,261 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connector
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// writeTestPrivateKey generate a private key and write it to dir. encrypt it if passphrase is not empty.
+func writeTestPrivateKey(t *testing.T, dir, name, passphrase string) (string, ed25519.PrivateKey) {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var block *pem.Block
+	if passphrase == "" {
+		block, err = ssh.MarshalPrivateKey(priv, "")
+	} else {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(priv, "", []byte(passphrase))
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(dir, name)
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return keyFile, priv
+}
+
+func TestSSHSigners(t *testing.T) {
+	dir := t.TempDir()
+	plainKey, _ := writeTestPrivateKey(t, dir, "id_plain", "")
+	encryptedKey, _ := writeTestPrivateKey(t, dir, "id_encrypted", "123456")
+
+	testcases := []struct {
+		name        string
+		keys        []string
+		defaultKeys []string
+		passphrase  string
+		except      int
+		err         bool
+	}{
+		{
+			name:   "not exist key is skipped",
+			keys:   []string{filepath.Join(dir, "id_not_exist"), plainKey},
+			except: 1,
+		},
+		{
+			name:       "encrypted key with passphrase",
+			keys:       []string{plainKey, encryptedKey},
+			passphrase: "123456",
+			except:     2,
+		},
+		{
+			name:       "encrypted key with wrong passphrase",
+			keys:       []string{encryptedKey},
+			passphrase: "654321",
+			err:        true,
+		},
+		{
+			name: "encrypted key without passphrase",
+			keys: []string{encryptedKey},
+			err:  true,
+		},
+		{
+			name:        "default encrypted key without passphrase is skipped",
+			defaultKeys: []string{encryptedKey, plainKey},
+			except:      1,
+		},
+		{
+			name:        "configured encrypted key without passphrase is not skipped",
+			keys:        []string{encryptedKey},
+			defaultKeys: []string{plainKey},
+			err:         true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			defaultKeys := defaultSSHPrivateKeys
+			defer func() { defaultSSHPrivateKeys = defaultKeys }()
+			defaultSSHPrivateKeys = tc.defaultKeys
+
+			signers, closeAgent, err := sshSigners(tc.keys, tc.passphrase, "")
+			if tc.err {
+				assert.Error(t, err)
+
+				return
+			}
+			defer closeAgent()
+			assert.NoError(t, err)
+			assert.Len(t, signers, tc.except)
+		})
+	}
+}
+
+func TestSSHSigners_Certificate(t *testing.T) {
+	dir := t.TempDir()
+	keyFile, priv := writeTestPrivateKey(t, dir, "id_ed25519", "")
+	_, caPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caSigner, err := ssh.NewSignerFromKey(caPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := ssh.NewPublicKey(priv.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := &ssh.Certificate{
+		Key:             pub,
+		CertType:        ssh.UserCert,
+		ValidPrincipals: []string{"root"},
+		ValidBefore:     ssh.CertTimeInfinity,
+	}
+	if err := cert.SignCert(rand.Reader, caSigner); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile+sshCertSuffix, ssh.MarshalAuthorizedKey(cert), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	signers, closeAgent, err := sshSigners([]string{keyFile}, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeAgent()
+	// the certificate is tried before the private key.
+	assert.Len(t, signers, 2)
+	assert.IsType(t, &ssh.Certificate{}, signers[0].PublicKey())
+	assert.Equal(t, pub.Marshal(), signers[1].PublicKey().Marshal())
+}
+
+func TestSSHSigners_Agent(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring := agent.NewKeyring()
+	if err := keyring.Add(agent.AddedKey{PrivateKey: priv}); err != nil {
+		t.Fatal(err)
+	}
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_ = agent.ServeAgent(keyring, conn)
+			}()
+		}
+	}()
+
+	signers, closeAgent, err := sshSigners(nil, "", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeAgent()
+	assert.Len(t, signers, 1)
+
+	// ssh-agent is optional.
+	signers, closeAgent, err = sshSigners(nil, "", filepath.Join(t.TempDir(), "not-exist.sock"))
+	assert.NoError(t, err)
+	defer closeAgent()
+	assert.Empty(t, signers)
+}
+
+func TestPromptPrivateKey(t *testing.T) {
+	encryptedKey, _ := writeTestPrivateKey(t, t.TempDir(), "id_encrypted", "123456")
+	key, err := os.ReadFile(encryptedKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name     string
+		inputs   []string
+		attempts int
+		err      bool
+	}{
+		{
+			name:     "re-prompt after wrong passphrase",
+			inputs:   []string{"654321", "123456"},
+			attempts: 2,
+		},
+		{
+			name:     "wrong passphrase is not cached",
+			inputs:   []string{"1", "2", "3", "123456"},
+			attempts: maxPassphraseAttempts,
+			err:      true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			read := readPassphrase
+			defer func() {
+				readPassphrase = read
+				delete(passphrases.cache, encryptedKey)
+			}()
+			var attempts int
+			readPassphrase = func(string) ([]byte, error) {
+				attempts++
+
+				return []byte(tc.inputs[attempts-1]), nil
+			}
+
+			_, err := promptPrivateKey(encryptedKey, key)
+			assert.Equal(t, tc.attempts, attempts)
+			if tc.err {
+				assert.Error(t, err)
+				assert.NotContains(t, passphrases.cache, encryptedKey)
+
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "123456", passphrases.cache[encryptedKey])
+			// the cached passphrase is used without prompt.
+			_, err = promptPrivateKey(encryptedKey, key)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.attempts, attempts)
+		})
+	}
+}
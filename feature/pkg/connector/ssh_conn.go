@@ -0,0 +1,219 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"k8s.io/klog/v2"
+)
+
+var (
+	// sshKeepaliveInterval is the interval to send keepalive request to ssh server.
+	sshKeepaliveInterval = 30 * time.Second
+	// sshKeepaliveTimeout is the max time to wait for the reply of keepalive request.
+	sshKeepaliveTimeout = 15 * time.Second
+)
+
+// sshConn is the ssh connection of host. it's kept alive, and reconnected when it's broken.
+type sshConn struct {
+	sync.Mutex
+	// dial connect to host, return the client of host and the clients of bastions in order of dialing.
+	// it stops when ctx is done.
+	dial func(ctx context.Context) (*ssh.Client, []*ssh.Client, error)
+	// client is nil if not connected or the connection is broken.
+	client         *ssh.Client
+	bastionClients []*ssh.Client
+	// sftpClient is cached for client.
+	sftpClient *sftp.Client
+	closed     bool
+}
+
+// getClient return the ssh client, connect to host if not connected.
+func (s *sshConn) getClient(ctx context.Context) (*ssh.Client, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.clientLocked(ctx)
+}
+
+func (s *sshConn) clientLocked(ctx context.Context) (*ssh.Client, error) {
+	if s.closed {
+		return nil, errors.New("ssh connection is closed")
+	}
+	if s.client != nil {
+		return s.client, nil
+	}
+	client, bastionClients, err := s.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.client, s.bastionClients = client, bastionClients
+	go s.keepalive(client)
+
+	return client, nil
+}
+
+// session create a new session. reconnect once if the connection is broken.
+func (s *sshConn) session(ctx context.Context) (*ssh.Session, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	for retry := 0; ; retry++ {
+		client, err := s.clientLocked(ctx)
+		if err != nil {
+			return nil, err
+		}
+		session, err := client.NewSession()
+		if err == nil || retry > 0 {
+			return session, err
+		}
+		klog.V(4).ErrorS(err, "create ssh session error, reconnect")
+		s.resetLocked(client)
+	}
+}
+
+// sftp return the cached sftp client. reconnect once if the connection is broken.
+func (s *sshConn) sftp(ctx context.Context) (*sftp.Client, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	for retry := 0; ; retry++ {
+		client, err := s.clientLocked(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if s.sftpClient != nil {
+			return s.sftpClient, nil
+		}
+		sftpClient, err := sftp.NewClient(client)
+		if err == nil {
+			s.sftpClient = sftpClient
+
+			return sftpClient, nil
+		}
+		if retry > 0 {
+			return nil, err
+		}
+		klog.V(4).ErrorS(err, "create sftp client error, reconnect")
+		s.resetLocked(client)
+	}
+}
+
+// keepalive send keepalive request to server until the client is closed. reset the connection if the request
+// fails or is not replied in sshKeepaliveTimeout.
+func (s *sshConn) keepalive(client *ssh.Client) {
+	done := make(chan struct{})
+	go func() {
+		_ = client.Wait()
+		close(done)
+	}()
+
+	ticker := time.NewTicker(sshKeepaliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			s.reset(client)
+
+			return
+		case <-ticker.C:
+			if err := sendKeepalive(client); err != nil {
+				klog.V(4).ErrorS(err, "ssh keepalive error, the connection will be reconnected when used")
+				s.reset(client)
+
+				return
+			}
+		}
+	}
+}
+
+// sendKeepalive send a keepalive request and wait for the reply in sshKeepaliveTimeout.
+// SendRequest blocks until the server replies, so the client is closed to unblock it when timeout.
+func sendKeepalive(client *ssh.Client) error {
+	errCh := make(chan error, 1)
+	go func() {
+		_, _, err := client.SendRequest("keepalive@openssh.com", true, nil)
+		errCh <- err
+	}()
+
+	timer := time.NewTimer(sshKeepaliveTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-errCh:
+		return err
+	case <-timer.C:
+		_ = client.Close()
+
+		return fmt.Errorf("no reply of keepalive request in %s", sshKeepaliveTimeout)
+	}
+}
+
+// reset close the broken client. the next use will reconnect.
+func (s *sshConn) reset(client *ssh.Client) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.resetLocked(client)
+}
+
+func (s *sshConn) resetLocked(client *ssh.Client) {
+	// the client has been reset or reconnected.
+	if s.client != client {
+		return
+	}
+	s.closeLocked()
+}
+
+// close the connection. it can not be used after closed.
+func (s *sshConn) close() error {
+	s.Lock()
+	defer s.Unlock()
+
+	s.closed = true
+
+	return s.closeLocked()
+}
+
+func (s *sshConn) closeLocked() error {
+	var errs error
+	if s.sftpClient != nil {
+		errs = errors.Join(errs, s.sftpClient.Close())
+		s.sftpClient = nil
+	}
+	if s.client != nil {
+		if err := s.client.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			errs = errors.Join(errs, err)
+		}
+		s.client = nil
+	}
+	for i := len(s.bastionClients) - 1; i >= 0; i-- {
+		if err := s.bastionClients[i].Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			errs = errors.Join(errs, err)
+		}
+	}
+	s.bastionClients = nil
+
+	return errs
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSSHConn_KeepaliveTimeout(t *testing.T) {
+	interval, timeout := sshKeepaliveInterval, sshKeepaliveTimeout
+	sshKeepaliveInterval, sshKeepaliveTimeout = 50*time.Millisecond, 100*time.Millisecond
+	t.Cleanup(func() { sshKeepaliveInterval, sshKeepaliveTimeout = interval, timeout })
+
+	server := newTestSSHServer(t)
+	server.hangRequests.Store(true)
+	conn := &sshConn{
+		dial: func(context.Context) (*ssh.Client, []*ssh.Client, error) {
+			client, err := ssh.Dial("tcp", server.listener.Addr().String(), &ssh.ClientConfig{
+				HostKeyCallback: ssh.FixedHostKey(server.hostKey),
+			})
+
+			return client, nil, err
+		},
+	}
+	defer conn.close()
+
+	client, err := conn.getClient(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the connection is closed and reset when the keepalive request is not replied.
+	done := make(chan struct{})
+	go func() {
+		_ = client.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("the connection is not closed when keepalive timeout")
+	}
+	assert.Eventually(t, func() bool {
+		conn.Lock()
+		defer conn.Unlock()
+
+		return conn.client == nil
+	}, time.Second, 10*time.Millisecond)
+}
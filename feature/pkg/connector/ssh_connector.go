@@ -19,20 +19,28 @@ package connector
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
+	"net"
 	"os"
 	"os/user"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/sftp"
 	"golang.org/x/crypto/ssh"
+	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 
-	_const "github.com/kubesphere/kubekey/v4/pkg/const"
+	"github.com/kubesphere/kubekey/v4/pkg/variable/vault"
 )
 
 const (
@@ -40,82 +48,217 @@ const (
 	defaultSSHUser = "root"
 )
 
-var defaultSSHPrivateKey string
+// defaultSSHPrivateKeys is tried in order when private key is not set. the not exist or unusable files are skipped.
+var defaultSSHPrivateKeys []string
 
 func init() {
+	homeDir := defaultSSHUser
 	if currentUser, err := user.Current(); err == nil {
-		defaultSSHPrivateKey = filepath.Join(currentUser.HomeDir, ".ssh/id_rsa")
-	} else {
-		defaultSSHPrivateKey = filepath.Join(defaultSSHUser, ".ssh/id_rsa")
+		homeDir = currentUser.HomeDir
+	}
+	for _, key := range []string{"id_rsa", "id_ecdsa", "id_ed25519"} {
+		defaultSSHPrivateKeys = append(defaultSSHPrivateKeys, filepath.Join(homeDir, ".ssh", key))
 	}
 }
 
 var _ Connector = &sshConnector{}
 var _ GatherFacts = &sshConnector{}
+var _ FileStater = &sshConnector{}
+var _ Becomer = &sshConnector{}
+var _ FileStreamer = &sshConnector{}
+var _ RESTConfigGetter = &sshConnector{}
 
 type sshConnector struct {
-	Host       string
-	Port       int
-	User       string
-	Password   string
-	PrivateKey string
-	client     *ssh.Client
+	Host     string
+	Port     int
+	User     string
+	Password string
+	// PrivateKeys is the private key files which tried in order. the certificate "<key>-cert.pub" is used if exist.
+	// defaultSSHPrivateKeys is used if empty.
+	PrivateKeys []string
+	// PrivateKeyPassphrase is the passphrase of encrypted private keys. it's read from terminal if empty.
+	PrivateKeyPassphrase string
+	// AgentSocket is the unix socket of ssh-agent.
+	AgentSocket string
+	// BecomePassword is the password for privilege escalation.
+	BecomePassword string
+	// HostKeyChecking is the mode to verify host key. "yes" (default), "accept-new" or "no".
+	HostKeyChecking string
+	// KnownHosts is the known_hosts file to verify host key.
+	KnownHosts string
+	// HostKeyFingerprints is the pinned sha256 fingerprints of host key. KnownHosts is not used when it's set.
+	HostKeyFingerprints []string
+	// Bastions is the jump hosts to connect the host through, in order.
+	Bastions []sshBastion
+	// conn is the connection of host. it may be shared by the connectors of the same host.
+	conn *sshConn
+	// shared is true if conn is owned by Pool. Close does not close the shared conn.
+	shared bool
+	// become is the privilege escalation. nil means execute as the connected user.
+	become *Become
+}
+
+// sshBastion is a jump host for sshConnector.
+type sshBastion struct {
+	Host                 string
+	Port                 int
+	User                 string
+	Password             string
+	PrivateKeys          []string
+	PrivateKeyPassphrase string
+	// HostKeyFingerprints is the pinned sha256 fingerprints of host key. known_hosts is not used when it's set.
+	HostKeyFingerprints []string
 }
 
-// Init connector, get ssh.Client
-func (c *sshConnector) Init(context.Context) error {
+// Init connector, connect to host.
+func (c *sshConnector) Init(ctx context.Context) error {
 	if c.Host == "" {
 		return errors.New("host is not set")
 	}
-
-	var auth []ssh.AuthMethod
-	if c.Password != "" {
-		auth = append(auth, ssh.Password(c.Password))
+	if c.conn == nil {
+		c.conn = &sshConn{dial: c.connect}
 	}
-	if _, err := os.Stat(c.PrivateKey); err == nil {
-		key, err := os.ReadFile(c.PrivateKey)
+	_, err := c.conn.getClient(ctx)
+
+	return err
+}
+
+// connect to the host through bastions in order. return the client of host and the clients of bastions.
+func (c *sshConnector) connect(ctx context.Context) (*ssh.Client, []*ssh.Client, error) {
+	var client *ssh.Client
+	var bastionClients []*ssh.Client
+	for _, hop := range append(slices.Clone(c.Bastions), sshBastion{
+		Host:                 c.Host,
+		Port:                 c.Port,
+		User:                 c.User,
+		Password:             c.Password,
+		PrivateKeys:          c.PrivateKeys,
+		PrivateKeyPassphrase: c.PrivateKeyPassphrase,
+		HostKeyFingerprints:  c.HostKeyFingerprints,
+	}) {
+		next, err := c.dial(ctx, client, hop)
 		if err != nil {
-			return fmt.Errorf("read private key error: %w", err)
+			for i := len(bastionClients) - 1; i >= 0; i-- {
+				bastionClients[i].Close()
+			}
+
+			return nil, nil, err
 		}
-		privateKey, err := ssh.ParsePrivateKey(key)
-		if err != nil {
-			return fmt.Errorf("parse private key error: %w", err)
+		if client != nil {
+			bastionClients = append(bastionClients, client)
 		}
-		auth = append(auth, ssh.PublicKeys(privateKey))
+		client = next
 	}
 
-	sshClient, err := ssh.Dial("tcp", fmt.Sprintf("%s:%v", c.Host, c.Port), &ssh.ClientConfig{
-		User:            c.User,
+	return client, bastionClients, nil
+}
+
+// dial connect to hop. if through is not nil, the connection is tunneled through it.
+// the connection is closed if ctx is done before the handshake finished.
+func (c *sshConnector) dial(ctx context.Context, through *ssh.Client, hop sshBastion) (*ssh.Client, error) {
+	auth, closeAgent, err := sshAuth(hop.Password, hop.PrivateKeys, hop.PrivateKeyPassphrase, c.AgentSocket)
+	if err != nil {
+		return nil, err
+	}
+	// ssh-agent is only used when authenticating.
+	defer closeAgent()
+	callback, err := hostKeyCallback(c.HostKeyChecking, c.KnownHosts, hop.HostKeyFingerprints)
+	if err != nil {
+		return nil, err
+	}
+	config := &ssh.ClientConfig{
+		User:            hop.User,
 		Auth:            auth,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		HostKeyCallback: callback,
 		Timeout:         30 * time.Second,
-	})
+	}
+	addr := net.JoinHostPort(hop.Host, strconv.Itoa(hop.Port))
+	var conn net.Conn
+	if through == nil {
+		conn, err = (&net.Dialer{Timeout: config.Timeout}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = through.DialContext(ctx, "tcp", addr)
+	}
 	if err != nil {
-		klog.V(4).ErrorS(err, "Dial ssh server failed", "host", c.Host, "port", c.Port)
+		klog.V(4).ErrorS(err, "Dial ssh server failed", "host", hop.Host, "port", hop.Port, "bastion", through != nil)
 
-		return err
+		return nil, err
 	}
-	c.client = sshClient
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	ncc, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if !stop() {
+		if err == nil {
+			ncc.Close()
+		}
 
-	return nil
+		return nil, fmt.Errorf("connect ssh server %s error: %w", addr, ctx.Err())
+	}
+	if err != nil {
+		conn.Close()
+		klog.V(4).ErrorS(err, "Connect ssh server failed", "host", hop.Host, "port", hop.Port, "bastion", through != nil)
+
+		return nil, err
+	}
+
+	return ssh.NewClient(ncc, chans, reqs), nil
 }
 
-// Close connector
+// Close connector. the shared connection is closed by Pool.
 func (c *sshConnector) Close(context.Context) error {
-	return c.client.Close()
+	if c.shared || c.conn == nil {
+		return nil
+	}
+
+	return c.conn.close()
+}
+
+// share return a connector which use the same connection.
+func (c *sshConnector) share() Connector {
+	if c.conn == nil {
+		c.conn = &sshConn{dial: c.connect}
+	}
+	shared := *c
+	shared.shared = true
+	shared.become = nil
+
+	return &shared
+}
+
+// closeShared close the shared connection.
+func (c *sshConnector) closeShared() error {
+	if c.conn == nil {
+		return nil
+	}
+
+	return c.conn.close()
+}
+
+// SetBecome the commands and files in remote node will be executed as become user.
+func (c *sshConnector) SetBecome(become Become) {
+	if become.Password == "" {
+		become.Password = c.BecomePassword
+	}
+	c.become = &become
 }
 
 // PutFile to remote node. src is the file bytes. dst is the remote filename
-func (c *sshConnector) PutFile(_ context.Context, src []byte, dst string, mode fs.FileMode) error {
-	// create sftp client
-	sftpClient, err := sftp.NewClient(c.client)
+func (c *sshConnector) PutFile(ctx context.Context, src []byte, dst string, mode fs.FileMode) error {
+	return c.PutFileStream(ctx, bytes.NewReader(src), dst, FileOption{Mode: mode})
+}
+
+// PutFileStream to remote node. the content of src is uploaded to a temp file beside dst,
+// verified by sha256 checksum in remote host, and then renamed to dst.
+func (c *sshConnector) PutFileStream(ctx context.Context, src io.Reader, dst string, option FileOption) error {
+	sftpClient, err := c.conn.sftp(ctx)
 	if err != nil {
 		klog.V(4).ErrorS(err, "Failed to create sftp client")
 
 		return err
 	}
-	defer sftpClient.Close()
-	// create remote file
+	if c.become != nil {
+		return c.putFileBecome(ctx, sftpClient, src, dst, option)
+	}
+	// create remote dir
 	if _, err := sftpClient.Stat(filepath.Dir(dst)); err != nil && os.IsNotExist(err) {
 		if err := sftpClient.MkdirAll(filepath.Dir(dst)); err != nil {
 			klog.V(4).ErrorS(err, "Failed to create remote dir", "remote_file", dst)
@@ -124,33 +267,187 @@ func (c *sshConnector) PutFile(_ context.Context, src []byte, dst string, mode f
 		}
 	}
 
-	rf, err := sftpClient.Create(dst)
+	tmp := tempFileName(dst)
+	if err := c.putTempFile(ctx, sftpClient, src, tmp, dst, option); err != nil {
+		if err := sftpClient.Remove(tmp); err != nil && !os.IsNotExist(err) {
+			klog.V(4).ErrorS(err, "Failed to remove temp file", "remote_file", tmp)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// putTempFile upload src to tmp, set mode and owner of it, and then rename it to dst.
+func (c *sshConnector) putTempFile(ctx context.Context, sftpClient *sftp.Client, src io.Reader, tmp, dst string, option FileOption) error {
+	if err := c.uploadFile(ctx, sftpClient, src, tmp, dst, option.Sha256); err != nil {
+		return err
+	}
+	if err := sftpClient.Chmod(tmp, option.Mode.Perm()); err != nil {
+		klog.V(4).ErrorS(err, "Failed to change mode of remote file", "remote_file", tmp)
+
+		return err
+	}
+	switch info, err := sftpClient.Stat(dst); {
+	case option.Owner != "" || option.Group != "":
+		if output, err := c.ExecuteCommand(ctx, "chown "+ShellQuote(chownSpec(option.Owner, option.Group))+" "+ShellQuote(tmp)); err != nil {
+			return fmt.Errorf("change owner of %s error: %w, output: %s", dst, err, output)
+		}
+	case err == nil:
+		// keep the owner of replaced file.
+		if stat, ok := info.Sys().(*sftp.FileStat); ok {
+			if err := sftpClient.Chown(tmp, int(stat.UID), int(stat.GID)); err != nil {
+				// only privileged user can change owner to others.
+				klog.V(4).ErrorS(err, "Failed to keep the owner of remote file", "remote_file", dst)
+			}
+		}
+	}
+	if err := sftpClient.PosixRename(tmp, dst); err != nil {
+		// the sftp server may not support posix-rename extension.
+		klog.V(4).ErrorS(err, "Failed to rename remote file by sftp, fallback to mv", "remote_file", dst)
+		if output, err := c.ExecuteCommand(ctx, "mv -f "+ShellQuote(tmp)+" "+ShellQuote(dst)); err != nil {
+			return fmt.Errorf("rename file to %s error: %w, output: %s", dst, err, output)
+		}
+	}
+
+	return nil
+}
+
+// uploadFile upload src to remote path by sftp, and verify the checksum of the remote file.
+// if expect is not empty, the content of src should match it.
+func (c *sshConnector) uploadFile(ctx context.Context, sftpClient *sftp.Client, src io.Reader, path, dst, expect string) error {
+	// the file may contain secrets. create it exclusively, and only the connected user can read it before it's moved to dst.
+	rf, err := sftpClient.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL)
 	if err != nil {
-		klog.V(4).ErrorS(err, "Failed to  create remote file", "remote_file", dst)
+		klog.V(4).ErrorS(err, "Failed to create remote file", "remote_file", path)
 
 		return err
 	}
+	if err := rf.Chmod(0o600); err != nil {
+		klog.V(4).ErrorS(err, "Failed to change mode of remote file", "remote_file", path)
+
+		return errors.Join(err, rf.Close())
+	}
+	h := sha256.New()
+	_, err = rf.ReadFrom(io.TeeReader(src, h))
+	if err := errors.Join(err, rf.Close()); err != nil {
+		klog.V(4).ErrorS(err, "Failed to write content to remote file", "remote_file", path)
+
+		return err
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	if expect != "" {
+		if err := checkSha256(dst, expect, sum); err != nil {
+			return err
+		}
+	}
+	remote, err := c.remoteSha256(ctx, sftpClient, path)
+	if err != nil {
+		return err
+	}
+
+	return checkSha256(dst, sum, remote)
+}
+
+// remoteSha256 calculate the checksum of remote file by "sha256sum", fallback to read the file by sftp.
+func (c *sshConnector) remoteSha256(ctx context.Context, sftpClient *sftp.Client, path string) (string, error) {
+	if output, err := c.ExecuteCommand(ctx, "sha256sum "+ShellQuote(path)); err == nil {
+		if fields := strings.Fields(string(output)); len(fields) > 0 {
+			return fields[0], nil
+		}
+	}
+
+	rf, err := sftpClient.Open(path)
+	if err != nil {
+		klog.V(4).ErrorS(err, "Failed to open file", "remote_file", path)
+
+		return "", err
+	}
 	defer rf.Close()
 
-	if _, err = rf.Write(src); err != nil {
-		klog.V(4).ErrorS(err, "Failed to write content to remote file", "remote_file", dst)
+	return sha256Sum(rf)
+}
 
+// putFileBecome upload file to a temp file by connected user, and copy it beside dst and rename to dst by become user.
+func (c *sshConnector) putFileBecome(ctx context.Context, sftpClient *sftp.Client, src io.Reader, dst string, option FileOption) error {
+	// upload to a private dir, which can not be read or replaced by other users.
+	tmpDir, err := c.mktempDir(ctx)
+	if err != nil {
 		return err
 	}
+	defer func() {
+		if err := sftpClient.RemoveAll(tmpDir); err != nil && !os.IsNotExist(err) {
+			klog.V(4).ErrorS(err, "Failed to remove temp dir", "remote_dir", tmpDir)
+		}
+	}()
+	tmp := tmpDir + "/" + filepath.Base(dst)
+	if err := c.uploadFile(ctx, sftpClient, src, tmp, dst, option.Sha256); err != nil {
+		return err
+	}
+
+	tmpDst := tempFileName(dst)
+	user := c.become.User
+	if user == "" {
+		user = defaultBecomeUser
+	}
+	// keep the owner of replaced file, or owned by become user.
+	chown := chownCommand(tmpDst, dst, option, user)
+	if output, err := c.ExecuteCommand(ctx, fmt.Sprintf("mkdir -p %s && cp %s %s && { %s; } && chmod %o %s && mv -f %s %s",
+		ShellQuote(filepath.Dir(dst)), ShellQuote(tmp), ShellQuote(tmpDst), chown, option.Mode.Perm(), ShellQuote(tmpDst), ShellQuote(tmpDst), ShellQuote(dst))); err != nil {
+		if output, err := c.ExecuteCommand(ctx, "rm -f "+ShellQuote(tmpDst)); err != nil {
+			klog.V(4).ErrorS(err, "Failed to remove temp file", "remote_file", tmpDst, "output", string(output))
+		}
+
+		return fmt.Errorf("move file to %s error: %w, output: %s", dst, err, output)
+	}
 
-	return rf.Chmod(mode)
+	return nil
+}
+
+// mktempDir creates a temp dir by the connected user, which is only accessible by the user.
+func (c *sshConnector) mktempDir(ctx context.Context) (string, error) {
+	session, err := c.conn.session(ctx)
+	if err != nil {
+		klog.V(4).ErrorS(err, "Failed to create ssh session")
+
+		return "", err
+	}
+	defer session.Close()
+	output, err := session.Output("mktemp -d /tmp/.kubekey-XXXXXXXXXX")
+	if err != nil {
+		return "", fmt.Errorf("create temp dir error: %w", err)
+	}
+	dir := strings.TrimSpace(string(output))
+	if !strings.HasPrefix(dir, "/tmp/.kubekey-") {
+		return "", fmt.Errorf("create temp dir error: unexpected output %q", dir)
+	}
+
+	return dir, nil
 }
 
 // FetchFile from remote node. src is the remote filename, dst is the local writer.
-func (c *sshConnector) FetchFile(_ context.Context, src string, dst io.Writer) error {
-	// create sftp client
-	sftpClient, err := sftp.NewClient(c.client)
+func (c *sshConnector) FetchFile(ctx context.Context, src string, dst io.Writer) error {
+	if c.become != nil {
+		// the file may be only readable by become user. read it by command.
+		output, err := c.ExecuteCommand(ctx, "base64 "+ShellQuote(src))
+		if err != nil {
+			return fmt.Errorf("read file %s error: %w, output: %s", src, err, output)
+		}
+		data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(output)), ""))
+		if err != nil {
+			return fmt.Errorf("decode file %s error: %w", src, err)
+		}
+		_, err = dst.Write(data)
+
+		return err
+	}
+	sftpClient, err := c.conn.sftp(ctx)
 	if err != nil {
 		klog.V(4).ErrorS(err, "Failed to create sftp client", "remote_file", src)
 
 		return err
 	}
-	defer sftpClient.Close()
 
 	rf, err := sftpClient.Open(src)
 	if err != nil {
@@ -169,61 +466,134 @@ func (c *sshConnector) FetchFile(_ context.Context, src string, dst io.Writer) e
 	return nil
 }
 
-// ExecuteCommand in remote host
-func (c *sshConnector) ExecuteCommand(_ context.Context, cmd string) ([]byte, error) {
-	klog.V(5).InfoS("exec ssh command", "cmd", cmd, "host", c.Host)
+// StatFile get the FileStat of remote file.
+// the checksum is calculated by "sha256sum" in remote host, fallback to read the file by sftp.
+func (c *sshConnector) StatFile(ctx context.Context, path string) (*FileStat, error) {
+	if c.become != nil {
+		// the file may be only readable by become user.
+		return statFileByCommand(ctx, c, path)
+	}
+	sftpClient, err := c.conn.sftp(ctx)
+	if err != nil {
+		klog.V(4).ErrorS(err, "Failed to create sftp client", "remote_file", path)
+
+		return nil, err
+	}
+
+	info, err := sftpClient.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if output, err := c.ExecuteCommand(ctx, "stat -c '%U %G' "+ShellQuote(path)+" && sha256sum "+ShellQuote(path)); err == nil {
+		if fields := strings.Fields(string(output)); len(fields) > 2 {
+			return &FileStat{Mode: info.Mode(), Owner: fields[0], Group: fields[1], Sha256: fields[2]}, nil
+		}
+	}
+
+	rf, err := sftpClient.Open(path)
+	if err != nil {
+		klog.V(4).ErrorS(err, "Failed to open file", "remote_file", path)
+
+		return nil, err
+	}
+	defer rf.Close()
+
+	sum, err := sha256Sum(rf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileStat{Mode: info.Mode(), Sha256: sum}, nil
+}
+
+// RESTConfig returns the rest config by the kubeconfig file in remote host.
+// the connections to kubernetes api are tunneled by ssh, so the address of api server is resolved in remote host.
+func (c *sshConnector) RESTConfig(ctx context.Context, kubeconfig string) (*rest.Config, error) {
+	config, err := fetchRESTConfig(ctx, c, kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	conn := c.conn
+	config.Dial = func(ctx context.Context, network, address string) (net.Conn, error) {
+		client, err := conn.getClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return client.DialContext(ctx, network, address)
+	}
+
+	return config, nil
+}
+
+// ExecuteCommand in remote host. the session is closed when ctx is done.
+func (c *sshConnector) ExecuteCommand(ctx context.Context, cmd string) ([]byte, error) {
+	klog.V(5).InfoS("exec ssh command", "cmd", vault.Redact(cmd), "host", c.Host)
 	// create ssh session
-	session, err := c.client.NewSession()
+	session, err := c.conn.session(ctx)
 	if err != nil {
 		klog.V(4).ErrorS(err, "Failed to create ssh session")
 
 		return nil, err
 	}
 	defer session.Close()
+	stop := context.AfterFunc(ctx, func() { session.Close() })
+	defer stop()
 
-	return session.CombinedOutput(cmd)
+	var output []byte
+	if c.become != nil {
+		output, err = c.executeBecome(session, cmd)
+	} else {
+		output, err = session.CombinedOutput(cmd)
+	}
+	if err != nil && ctx.Err() != nil {
+		return output, fmt.Errorf("execute command stopped: %w", ctx.Err())
+	}
+
+	return output, err
 }
 
-// HostInfo for GatherFacts
-func (c *sshConnector) HostInfo(ctx context.Context) (map[string]any, error) {
-	// os information
-	osVars := make(map[string]any)
-	var osRelease bytes.Buffer
-	if err := c.FetchFile(ctx, "/etc/os-release", &osRelease); err != nil {
-		return nil, fmt.Errorf("failed to fetch os-release: %w", err)
-	}
-	osVars[_const.VariableOSRelease] = convertBytesToMap(osRelease.Bytes(), "=")
-	kernel, err := c.ExecuteCommand(ctx, "uname -r")
+// executeBecome execute cmd as become user in session.
+func (c *sshConnector) executeBecome(session *ssh.Session, cmd string) ([]byte, error) {
+	becomeCmd, err := c.become.Command(cmd)
 	if err != nil {
-		return nil, fmt.Errorf("get kernel version error: %w", err)
+		return nil, err
 	}
-	osVars[_const.VariableOSKernelVersion] = string(bytes.TrimSuffix(kernel, []byte("\n")))
-	hn, err := c.ExecuteCommand(ctx, "hostname")
-	if err != nil {
-		return nil, fmt.Errorf("get hostname error: %w", err)
+	if c.become.Password == "" {
+		return session.CombinedOutput(becomeCmd)
 	}
-	osVars[_const.VariableOSHostName] = string(bytes.TrimSuffix(hn, []byte("\n")))
-	arch, err := c.ExecuteCommand(ctx, "arch")
-	if err != nil {
-		return nil, fmt.Errorf("get arch error: %w", err)
+	if c.become.method() == becomeMethodSudo {
+		// sudo read password from stdin only when it prompts, the credentials may be cached.
+		// the stdin is closed once the password is answered or the command starts, so the command never reads the password.
+		stdin, err := session.StdinPipe()
+		if err != nil {
+			return nil, fmt.Errorf("get stdin of session error: %w", err)
+		}
+		output := &promptWriter{prompt: sudoPasswordPrompt, answer: c.become.Password, stdin: stdin, marker: becomeSuccessMarker}
+		session.Stdout = output
+		session.Stderr = output
+		err = session.Run(becomeCmd)
+
+		return output.Bytes(), err
 	}
-	osVars[_const.VariableOSArchitecture] = string(bytes.TrimSuffix(arch, []byte("\n")))
 
-	// process information
-	procVars := make(map[string]any)
-	var cpu bytes.Buffer
-	if err := c.FetchFile(ctx, "/proc/cpuinfo", &cpu); err != nil {
-		return nil, fmt.Errorf("get cpuinfo error: %w", err)
+	// su read password from terminal.
+	if err := session.RequestPty("xterm", 80, 200, ssh.TerminalModes{ssh.ECHO: 0}); err != nil {
+		return nil, fmt.Errorf("request pty error: %w", err)
 	}
-	procVars[_const.VariableProcessCPU] = convertBytesToSlice(cpu.Bytes(), ":")
-	var mem bytes.Buffer
-	if err := c.FetchFile(ctx, "/proc/meminfo", &mem); err != nil {
-		return nil, fmt.Errorf("get meminfo error: %w", err)
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("get stdin of session error: %w", err)
 	}
-	procVars[_const.VariableProcessMemory] = convertBytesToMap(mem.Bytes(), ":")
+	output := &promptWriter{prompt: suPasswordPrompt, answer: c.become.Password, stdin: stdin}
+	session.Stdout = output
+	session.Stderr = output
+	err = session.Run(becomeCmd)
+
+	return output.Bytes(), err
+}
 
-	return map[string]any{
-		_const.VariableOS:      osVars,
-		_const.VariableProcess: procVars,
-	}, nil
+// HostInfo for GatherFacts
+func (c *sshConnector) HostInfo(ctx context.Context) (map[string]any, error) {
+	return hostInfo(ctx, c)
 }
@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connector
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSSHConnector_Context(t *testing.T) {
+	t.Run("stop handshake", func(t *testing.T) {
+		// the server accepts the connection, but never handshakes.
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer listener.Close()
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+			}
+		}()
+		addr := listener.Addr().(*net.TCPAddr)
+		conn, err := NewConnector("node1", map[string]any{
+			"type": "ssh", "host": addr.IP.String(), "port": addr.Port, "private_key": "", "host_key_checking": "no",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		assert.ErrorIs(t, conn.Init(ctx), context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("stop command", func(t *testing.T) {
+		server := newTestSSHServer(t)
+		addr := server.listener.Addr().(*net.TCPAddr)
+		conn, err := NewConnector("node1", map[string]any{
+			"type": "ssh", "host": addr.IP.String(), "port": addr.Port, "private_key": "",
+			"host_key_fingerprints": []any{ssh.FingerprintSHA256(server.hostKey)},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := conn.Init(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close(context.Background())
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err = conn.ExecuteCommand(ctx, "hang")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 5*time.Second)
+		// the connection can be used after the command stopped.
+		output, err := conn.ExecuteCommand(context.Background(), "echo ok")
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", string(output))
+	})
+}
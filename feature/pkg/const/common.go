@@ -38,10 +38,30 @@ const ( // === From inventory ===
 	VariableConnectorUser = "user"
 	// VariableConnectorPassword is connected type for VariableConnector.
 	VariableConnectorPassword = "password"
-	// VariableConnectorPrivateKey is connected auth key for VariableConnector.
+	// VariableConnectorPrivateKey is connected auth key for VariableConnector. it can be a list which tried in order.
 	VariableConnectorPrivateKey = "private_key"
+	// VariableConnectorPrivateKeyPassphrase is the passphrase of encrypted VariableConnectorPrivateKey.
+	VariableConnectorPrivateKeyPassphrase = "private_key_passphrase"
+	// VariableConnectorAgentSocket is the ssh-agent socket for VariableConnector. default is env SSH_AUTH_SOCK.
+	VariableConnectorAgentSocket = "agent_socket"
 	// VariableConnectorKubeconfig is connected auth key for VariableConnector.
 	VariableConnectorKubeconfig = "kubeconfig"
+	// VariableConnectorBecomePassword is the password of privilege escalation for VariableConnector.
+	VariableConnectorBecomePassword = "become_password"
+	// VariableConnectorHostKeyChecking is the host key checking mode for VariableConnector. "yes" (default), "accept-new" or "no".
+	VariableConnectorHostKeyChecking = "host_key_checking"
+	// VariableConnectorKnownHosts is the known_hosts file to verify host key for VariableConnector.
+	VariableConnectorKnownHosts = "known_hosts"
+	// VariableConnectorHostKeyFingerprints is the pinned sha256 fingerprints of host key for VariableConnector.
+	VariableConnectorHostKeyFingerprints = "host_key_fingerprints"
+	// VariableConnectorBastions is the jump hosts for VariableConnector. each item is "user@host:port" or a map
+	// which contains VariableConnectorHost, VariableConnectorPort, VariableConnectorUser, VariableConnectorPassword,
+	// VariableConnectorPrivateKey, VariableConnectorPrivateKeyPassphrase and VariableConnectorHostKeyFingerprints.
+	VariableConnectorBastions = "bastions"
+	// VariableConnectorRuntime is the container runtime for container connector. "docker", "nerdctl", "podman" or "chroot".
+	VariableConnectorRuntime = "runtime"
+	// VariableConnectorContainer is the container name or id for container connector. it's the root dir when runtime is "chroot".
+	VariableConnectorContainer = "container"
 )
 
 const ( // === From system generate ===
@@ -73,6 +93,18 @@ const ( // === From GatherFact ===
 	VariableProcessCPU = "cpuInfo"
 	// VariableProcessMemory the value is memory info of VariableProcess.
 	VariableProcessMemory = "memInfo"
+	// VariableNetwork the value is network interfaces and default routes.
+	VariableNetwork = "network"
+	// VariableHardware the value is disks, mounts and swap.
+	VariableHardware = "hardware"
+	// VariableSecurity the value is selinux and apparmor state.
+	VariableSecurity = "security"
+	// VariableSystem the value is cgroup version and systemd version.
+	VariableSystem = "system"
+	// VariableContainerRuntimes the value is the version of installed container runtimes.
+	VariableContainerRuntimes = "container_runtimes"
+	// VariableLocalFacts the value is custom facts in fact_path.
+	VariableLocalFacts = "local_facts"
 )
 
 const ( // === From runtime ===
@@ -27,6 +27,8 @@ workdir/
 |   |   |   |   |   |-- main.yml
 |   |   |   |   |-- defaults/
 |   |   |   |   |   |-- main.yml
+|   |   |   |   |-- handlers/
+|   |   |   |   |   |-- main.yml
 |   |   |   |   |-- templates/
 |   |   |   |   |-- files/
 |   |
@@ -51,6 +53,8 @@ workdir/
 |   |   |   |-- namespace/
 |   |   |   |   |-- inventory.yaml
 |
+|-- known_hosts
+|
 |-- kubekey/
 |-- artifact-path...
 |-- images
@@ -87,6 +91,12 @@ const ProjectRolesDefaultsDir = "defaults"
 // ProjectRolesDefaultsMainFile is a fixed file under defaults. support *.yaml or *yml
 const ProjectRolesDefaultsMainFile = "main"
 
+// ProjectRolesHandlersDir is a fixed directory name under roleName. used to store handlers which tasks in role can notify.
+const ProjectRolesHandlersDir = "handlers"
+
+// ProjectRolesHandlersMainFile is a fixed file under handlers. support *.yaml or *yml
+const ProjectRolesHandlersMainFile = "main"
+
 // ProjectRolesTemplateDir is a fixed directory name under roleName. used to store template which task need.
 const ProjectRolesTemplateDir = "templates"
 
@@ -107,6 +117,9 @@ const RuntimePipelineDir = "pipelines"
 // RuntimePipelineVariableDir is a fixed directory name under runtime, used to store the task execution parameters.
 const RuntimePipelineVariableDir = "variable"
 
+// RuntimeFactsDir is a fixed directory name under runtime, used to store the cached facts of each host.
+const RuntimeFactsDir = "facts"
+
 // RuntimePipelineTaskDir is a fixed directory name under runtime, used to store the task execution status.
 
 // task.yaml is the data of Task resource
@@ -119,6 +132,9 @@ const RuntimePipelineVariableDir = "variable"
 
 // inventory.yaml is the data of Inventory resource
 
+// KnownHostsFile is a fixed file under workdir. used to store the host keys of ssh connector.
+const KnownHostsFile = "known_hosts"
+
 // ArtifactDir is the default directory name under the working directory. It is used to store
 // files required when executing the kubekey command (such as: docker, etcd, image packages, etc.).
 // These files will be downloaded locally and distributed to remote nodes.
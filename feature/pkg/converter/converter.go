@@ -46,13 +46,26 @@ func MarshalBlock(role string, hosts []string, when []string, block kkprojectv1.
 			},
 		},
 		Spec: kkcorev1alpha1.TaskSpec{
-			Name:        block.Name,
-			Hosts:       hosts,
-			IgnoreError: block.IgnoreErrors,
-			Retries:     block.Retries,
-			When:        when,
-			FailedWhen:  block.FailedWhen.Data,
-			Register:    block.Register,
+			Name:           block.Name,
+			Hosts:          hosts,
+			IgnoreError:    block.IgnoreErrors,
+			Retries:        block.Retries,
+			Delay:          block.Delay,
+			Async:          block.AsyncVal,
+			Poll:           block.Poll,
+			CheckMode:      block.CheckMode,
+			Diff:           block.Diff,
+			Throttle:       block.Throttle,
+			Timeout:        block.Timeout,
+			AnyErrorsFatal: block.AnyErrorsFatal,
+			When:           when,
+			FailedWhen:     block.FailedWhen.Data,
+			ChangedWhen:    block.ChangedWhen.Data,
+			Until:          block.Until.Data,
+			Register:       block.Register,
+			Notify:         block.Notify.Data,
+			DelegateTo:     block.DelegateTo,
+			DelegateFacts:  block.DelegateFacts,
 		},
 	}
 
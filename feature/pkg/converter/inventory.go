@@ -0,0 +1,547 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package converter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+	_const "github.com/kubesphere/kubekey/v4/pkg/const"
+)
+
+// ansible implicit groups.
+const (
+	ansibleGroupAll       = "all"
+	ansibleGroupUngrouped = "ungrouped"
+)
+
+// ansibleConnectorVars convert ansible connection variables to the key in connector variable.
+var ansibleConnectorVars = map[string]string{
+	"ansible_host":                 _const.VariableConnectorHost,
+	"ansible_ssh_host":             _const.VariableConnectorHost,
+	"ansible_port":                 _const.VariableConnectorPort,
+	"ansible_ssh_port":             _const.VariableConnectorPort,
+	"ansible_user":                 _const.VariableConnectorUser,
+	"ansible_ssh_user":             _const.VariableConnectorUser,
+	"ansible_password":             _const.VariableConnectorPassword,
+	"ansible_ssh_pass":             _const.VariableConnectorPassword,
+	"ansible_ssh_private_key_file": _const.VariableConnectorPrivateKey,
+	"ansible_private_key_file":     _const.VariableConnectorPrivateKey,
+	"ansible_become_password":      _const.VariableConnectorBecomePassword,
+	"ansible_become_pass":          _const.VariableConnectorBecomePassword,
+}
+
+// ansibleConnections convert "ansible_connection" to the type and runtime of connector.
+var ansibleConnections = map[string][2]string{
+	"ssh":                          {"ssh"},
+	"smart":                        {"ssh"},
+	"paramiko":                     {"ssh"},
+	"local":                        {"local"},
+	"docker":                       {"container", "docker"},
+	"community.docker.docker":      {"container", "docker"},
+	"podman":                       {"container", "podman"},
+	"containers.podman.podman":     {"container", "podman"},
+	"chroot":                       {"container", "chroot"},
+	"community.general.chroot":     {"container", "chroot"},
+	"kubernetes.core.kubectl":      {"kubernetes"},
+	"community.kubernetes.kubectl": {"kubernetes"},
+}
+
+// hostRangeRegexp match the range pattern in host name, such as: node[01:20], node[a:f], node[1:10:2].
+var hostRangeRegexp = regexp.MustCompile(`\[([0-9a-zA-Z]+):([0-9a-zA-Z]+)(?::([0-9]+))?\]`)
+
+// ansibleInventory is the intermediate inventory which convert to kkcorev1.Inventory.
+type ansibleInventory struct {
+	// hosts in order of appearance, with the variables of each host.
+	hosts     []string
+	hostVars  map[string]map[string]any
+	groups    []string
+	groupInfo map[string]*ansibleGroup
+}
+
+type ansibleGroup struct {
+	hosts    []string
+	children []string
+	vars     map[string]any
+}
+
+func newAnsibleInventory() *ansibleInventory {
+	return &ansibleInventory{
+		hostVars:  make(map[string]map[string]any),
+		groupInfo: make(map[string]*ansibleGroup),
+	}
+}
+
+func (i *ansibleInventory) addHost(name string, vars map[string]any) {
+	if _, ok := i.hostVars[name]; !ok {
+		i.hosts = append(i.hosts, name)
+		i.hostVars[name] = make(map[string]any)
+	}
+	for k, v := range vars {
+		i.hostVars[name][k] = v
+	}
+}
+
+func (i *ansibleInventory) group(name string) *ansibleGroup {
+	g, ok := i.groupInfo[name]
+	if !ok {
+		g = &ansibleGroup{vars: make(map[string]any)}
+		i.groupInfo[name] = g
+		i.groups = append(i.groups, name)
+	}
+
+	return g
+}
+
+// inventory convert to kkcorev1.Inventory. the vars of "all" group is the vars of inventory.
+// kubekey only apply group vars to the hosts in the group, so the vars of parent group are merged to the child groups,
+// and the vars of child group take precedence.
+func (i *ansibleInventory) inventory() (*kkcorev1.Inventory, error) {
+	inventory := &kkcorev1.Inventory{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: kkcorev1.SchemeGroupVersion.String(),
+			Kind:       "Inventory",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: kkcorev1.InventorySpec{
+			Hosts:  make(kkcorev1.InventoryHost),
+			Groups: make(map[string]kkcorev1.InventoryGroup),
+		},
+	}
+	for _, h := range i.hosts {
+		raw, err := marshalAnsibleVars(i.hostVars[h])
+		if err != nil {
+			return nil, fmt.Errorf("convert vars of host %s error: %w", h, err)
+		}
+		inventory.Spec.Hosts[h] = raw
+	}
+
+	// the parent vars of each group, in order of parent to child.
+	inherited := make(map[string][]map[string]any)
+	var inherit func(name string, parents []map[string]any, visited []string) error
+	inherit = func(name string, parents []map[string]any, visited []string) error {
+		if slices.Contains(visited, name) {
+			return fmt.Errorf("group %s is a child of itself", name)
+		}
+		g, ok := i.groupInfo[name]
+		if !ok {
+			return nil
+		}
+		inherited[name] = append(inherited[name], parents...)
+		for _, c := range g.children {
+			if err := inherit(c, append(slices.Clone(parents), g.vars), append(visited, name)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+	for _, name := range i.groups {
+		if name == ansibleGroupAll {
+			continue
+		}
+		if err := inherit(name, nil, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, name := range i.groups {
+		g := i.groupInfo[name]
+		if name == ansibleGroupAll {
+			raw, err := marshalAnsibleVars(g.vars)
+			if err != nil {
+				return nil, fmt.Errorf("convert vars of group %s error: %w", name, err)
+			}
+			inventory.Spec.Vars = raw
+
+			continue
+		}
+		vars := make(map[string]any)
+		for _, pv := range append(inherited[name], g.vars) {
+			for k, v := range pv {
+				vars[k] = v
+			}
+		}
+		raw, err := marshalAnsibleVars(vars)
+		if err != nil {
+			return nil, fmt.Errorf("convert vars of group %s error: %w", name, err)
+		}
+		for _, c := range g.children {
+			if _, ok := i.groupInfo[c]; !ok {
+				return nil, fmt.Errorf("child group %s of %s is not defined", c, name)
+			}
+		}
+		inventory.Spec.Groups[name] = kkcorev1.InventoryGroup{
+			Groups: g.children,
+			Hosts:  g.hosts,
+			Vars:   raw,
+		}
+	}
+
+	return inventory, nil
+}
+
+// marshalAnsibleVars convert ansible connection variables to connector variable, and marshal to RawExtension.
+func marshalAnsibleVars(vars map[string]any) (runtime.RawExtension, error) {
+	if len(vars) == 0 {
+		return runtime.RawExtension{}, nil
+	}
+	result := make(map[string]any)
+	connector := make(map[string]any)
+	for k, v := range vars {
+		switch {
+		case ansibleConnectorVars[k] != "":
+			connector[ansibleConnectorVars[k]] = v
+		case k == "ansible_connection":
+			conn, ok := ansibleConnections[fmt.Sprint(v)]
+			if !ok {
+				return runtime.RawExtension{}, fmt.Errorf("unsupported ansible_connection %q", v)
+			}
+			connector[_const.VariableConnectorType] = conn[0]
+			if conn[1] != "" {
+				connector[_const.VariableConnectorRuntime] = conn[1]
+			}
+		default:
+			result[k] = v
+		}
+	}
+	if len(connector) != 0 {
+		if c, ok := result[_const.VariableConnector].(map[string]any); ok {
+			// the connector defined by kubekey variable take precedence.
+			for k, v := range c {
+				connector[k] = v
+			}
+		}
+		result[_const.VariableConnector] = connector
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return runtime.RawExtension{}, err
+	}
+
+	return runtime.RawExtension{Raw: data}, nil
+}
+
+// ConvertINIInventory convert ansible INI inventory to kkcorev1.Inventory.
+// it supports groups, [group:children], [group:vars], host ranges like node[01:20] and inline host vars.
+// ansible connection variables (ansible_host, ansible_user etc.) are converted to connector variable.
+func ConvertINIInventory(data []byte) (*kkcorev1.Inventory, error) {
+	inv := newAnsibleInventory()
+	// the section which is parsed: group name and the kind: hosts, children or vars.
+	group, kind := ansibleGroupUngrouped, "hosts"
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			var hasKind bool
+			group, kind, hasKind = strings.Cut(strings.TrimSpace(line[1:len(line)-1]), ":")
+			if !hasKind {
+				kind = "hosts"
+			}
+			if group == "" || !slices.Contains([]string{"hosts", "children", "vars"}, kind) || hasKind && kind == "hosts" {
+				return nil, fmt.Errorf("line %d: invalid section %s", lineNum, line)
+			}
+			inv.group(group)
+
+			continue
+		}
+
+		switch kind {
+		case "hosts":
+			hosts, vars, err := parseINIHostLine(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			for _, h := range hosts {
+				inv.addHost(h, vars)
+				if group != ansibleGroupUngrouped && group != ansibleGroupAll {
+					g := inv.group(group)
+					if !slices.Contains(g.hosts, h) {
+						g.hosts = append(g.hosts, h)
+					}
+				}
+			}
+		case "children":
+			child := strings.Fields(line)[0]
+			g := inv.group(group)
+			if !slices.Contains(g.children, child) {
+				g.children = append(g.children, child)
+			}
+		case "vars":
+			k, v, ok := strings.Cut(line, "=")
+			if !ok || strings.TrimSpace(k) == "" {
+				return nil, fmt.Errorf("line %d: variable should be key=value", lineNum)
+			}
+			inv.group(group).vars[strings.TrimSpace(k)] = parseINIValue(strings.TrimSpace(v))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	// ungrouped is implicit group, its hosts are only in "all". and the children of all is implicit.
+	delete(inv.groupInfo, ansibleGroupUngrouped)
+	inv.groups = slices.DeleteFunc(inv.groups, func(g string) bool { return g == ansibleGroupUngrouped })
+	if all, ok := inv.groupInfo[ansibleGroupAll]; ok {
+		all.children = nil
+	}
+
+	return inv.inventory()
+}
+
+// parseINIHostLine parse the host line in INI inventory. such as: node[01:03] ansible_host=10.0.0.1 labels="a b"
+func parseINIHostLine(line string) ([]string, map[string]any, error) {
+	fields, err := splitINIFields(line)
+	if err != nil {
+		return nil, nil, err
+	}
+	vars := make(map[string]any)
+	for _, f := range fields[1:] {
+		k, v, ok := strings.Cut(f, "=")
+		if !ok || k == "" {
+			return nil, nil, fmt.Errorf("host variable %q should be key=value", f)
+		}
+		vars[k] = parseINIValue(v)
+	}
+	name := fields[0]
+	// host:port, but not ipv6 address. the ":" in host range is not counted.
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "]") && strings.Count(hostRangeRegexp.ReplaceAllString(name, ""), ":") == 1 {
+		port, err := strconv.Atoi(name[i+1:])
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid port in host %s", name)
+		}
+		name = name[:i]
+		if _, ok := vars["ansible_port"]; !ok {
+			vars["ansible_port"] = port
+		}
+	}
+	hosts, err := expandHostRange(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return hosts, vars, nil
+}
+
+// splitINIFields split line by whitespace. the quoted field is unquoted, and the field start with "#" is comment.
+func splitINIFields(line string) ([]string, error) {
+	var fields []string
+	var field strings.Builder
+	var quote rune
+	inField := false
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				field.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inField = true
+		case r == ' ' || r == '\t':
+			if inField {
+				fields = append(fields, field.String())
+				field.Reset()
+				inField = false
+			}
+		case r == '#' && !inField:
+			// the rest is comment.
+			return fields, nil
+		default:
+			field.WriteRune(r)
+			inField = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in %q", line)
+	}
+	if inField {
+		fields = append(fields, field.String())
+	}
+
+	return fields, nil
+}
+
+// parseINIValue convert the value in INI to bool, number, list or map like yaml.
+// the quoted value, and the value which is not valid yaml, are kept as string.
+func parseINIValue(s string) any {
+	if len(s) >= 2 && (s[0] == '"' && s[len(s)-1] == '"' || s[0] == '\'' && s[len(s)-1] == '\'') {
+		return s[1 : len(s)-1]
+	}
+	var v any
+	if yaml.Unmarshal([]byte(s), &v) != nil || v == nil {
+		return s
+	}
+
+	return v
+}
+
+// expandHostRange expand the ranges in host name. such as: node[01:03] to node01, node02, node03.
+func expandHostRange(name string) ([]string, error) {
+	loc := hostRangeRegexp.FindStringSubmatchIndex(name)
+	if loc == nil {
+		if strings.ContainsAny(name, "[]") {
+			return nil, fmt.Errorf("invalid host range in %s", name)
+		}
+
+		return []string{name}, nil
+	}
+	start, end := name[loc[2]:loc[3]], name[loc[4]:loc[5]]
+	step := 1
+	if loc[6] >= 0 {
+		s, err := strconv.Atoi(name[loc[6]:loc[7]])
+		if err != nil || s <= 0 {
+			return nil, fmt.Errorf("invalid step of host range in %s", name)
+		}
+		step = s
+	}
+
+	var items []string
+	if i, err := strconv.Atoi(start); err == nil {
+		j, err := strconv.Atoi(end)
+		if err != nil || j < i {
+			return nil, fmt.Errorf("invalid host range in %s", name)
+		}
+		format := "%d"
+		if len(start) > 1 && start[0] == '0' {
+			format = fmt.Sprintf("%%0%dd", len(start))
+		}
+		for n := i; n <= j; n += step {
+			items = append(items, fmt.Sprintf(format, n))
+		}
+	} else {
+		if len(start) != 1 || len(end) != 1 || start[0] > end[0] {
+			return nil, fmt.Errorf("invalid host range in %s", name)
+		}
+		for c := int(start[0]); c <= int(end[0]); c += step {
+			items = append(items, string(rune(c)))
+		}
+	}
+
+	var hosts []string
+	for _, item := range items {
+		// the rest of name may contain other ranges.
+		expanded, err := expandHostRange(name[:loc[0]] + item + name[loc[1]:])
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, expanded...)
+	}
+
+	return hosts, nil
+}
+
+// ConvertJSONInventory convert the json output of ansible dynamic inventory ("--list") to kkcorev1.Inventory.
+// hostVars returns the vars of host when "_meta.hostvars" is not in output. it's the output of "--host <name>".
+func ConvertJSONInventory(data []byte, hostVars func(host string) (map[string]any, error)) (*kkcorev1.Inventory, error) {
+	var output map[string]json.RawMessage
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("unmarshal dynamic inventory error: %w", err)
+	}
+	inv := newAnsibleInventory()
+	var meta struct {
+		HostVars map[string]map[string]any `json:"hostvars"`
+	}
+	metaRaw, hasMeta := output["_meta"]
+	if hasMeta {
+		if err := json.Unmarshal(metaRaw, &meta); err != nil {
+			return nil, fmt.Errorf("unmarshal _meta of dynamic inventory error: %w", err)
+		}
+	}
+
+	names := make([]string, 0, len(output))
+	for name := range output {
+		if name != "_meta" {
+			names = append(names, name)
+		}
+	}
+	// the order of json object is lost, sort it to keep the result stable.
+	slices.Sort(names)
+	for _, name := range names {
+		var group struct {
+			Hosts    []string       `json:"hosts"`
+			Children []string       `json:"children"`
+			Vars     map[string]any `json:"vars"`
+		}
+		if err := json.Unmarshal(output[name], &group); err != nil {
+			// the group can be a list of hosts.
+			if err := json.Unmarshal(output[name], &group.Hosts); err != nil {
+				return nil, fmt.Errorf("unmarshal group %s of dynamic inventory error: %w", name, err)
+			}
+		}
+		if name == ansibleGroupUngrouped && len(group.Vars) == 0 && len(group.Children) == 0 {
+			for _, h := range group.Hosts {
+				inv.addHost(h, nil)
+			}
+
+			continue
+		}
+		g := inv.group(name)
+		g.children = group.Children
+		for k, v := range group.Vars {
+			g.vars[k] = v
+		}
+		for _, h := range group.Hosts {
+			inv.addHost(h, nil)
+			if name != ansibleGroupAll && !slices.Contains(g.hosts, h) {
+				g.hosts = append(g.hosts, h)
+			}
+		}
+	}
+	for _, h := range inv.hosts {
+		if hasMeta {
+			inv.addHost(h, meta.HostVars[h])
+
+			continue
+		}
+		if hostVars == nil {
+			continue
+		}
+		vars, err := hostVars(h)
+		if err != nil {
+			return nil, fmt.Errorf("get vars of host %s error: %w", h, err)
+		}
+		inv.addHost(h, vars)
+	}
+	if len(inv.hosts) == 0 {
+		return nil, errors.New("no host in dynamic inventory")
+	}
+	// the children of all is implicit.
+	if all, ok := inv.groupInfo[ansibleGroupAll]; ok {
+		all.children = nil
+	}
+
+	return inv.inventory()
+}
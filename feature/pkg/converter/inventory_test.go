@@ -0,0 +1,198 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package converter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+)
+
+func TestExpandHostRange(t *testing.T) {
+	testcases := []struct {
+		name   string
+		host   string
+		except []string
+		err    bool
+	}{
+		{
+			name:   "no range",
+			host:   "node1",
+			except: []string{"node1"},
+		},
+		{
+			name:   "number range with leading zero",
+			host:   "node[08:11]",
+			except: []string{"node08", "node09", "node10", "node11"},
+		},
+		{
+			name:   "number range with step",
+			host:   "node[1:6:2].example.com",
+			except: []string{"node1.example.com", "node3.example.com", "node5.example.com"},
+		},
+		{
+			name:   "multiple ranges",
+			host:   "rack[a:b]-node[1:2]",
+			except: []string{"racka-node1", "racka-node2", "rackb-node1", "rackb-node2"},
+		},
+		{
+			name: "invalid range",
+			host: "node[3:1]",
+			err:  true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := expandHostRange(tc.host)
+			if tc.err {
+				assert.Error(t, err)
+
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.except, actual)
+		})
+	}
+}
+
+func TestConvertINIInventory(t *testing.T) {
+	testcases := []struct {
+		name   string
+		data   string
+		except kkcorev1.InventorySpec
+		err    bool
+	}{
+		{
+			name: "groups, children, vars and inline host vars",
+			data: `
+# ungrouped host
+localhost ansible_connection=local
+
+[kube_control_plane]
+node1 ansible_host=10.0.0.1 ansible_user=kube labels="a b" # comment
+
+[kube_node]
+node[2:3]:2222
+
+[k8s_cluster:children]
+kube_control_plane
+kube_node
+
+[k8s_cluster:vars]
+kube_version=v1.30.0
+ansible_user=root
+
+[kube_node:vars]
+max_pods=110
+
+[all:vars]
+ntp_enabled=true
+`,
+			except: kkcorev1.InventorySpec{
+				Hosts: kkcorev1.InventoryHost{
+					"localhost": {Raw: []byte(`{"connector":{"type":"local"}}`)},
+					"node1":     {Raw: []byte(`{"connector":{"host":"10.0.0.1","user":"kube"},"labels":"a b"}`)},
+					"node2":     {Raw: []byte(`{"connector":{"port":2222}}`)},
+					"node3":     {Raw: []byte(`{"connector":{"port":2222}}`)},
+				},
+				Vars: runtime.RawExtension{Raw: []byte(`{"ntp_enabled":true}`)},
+				Groups: map[string]kkcorev1.InventoryGroup{
+					"kube_control_plane": {
+						Hosts: []string{"node1"},
+						Vars:  runtime.RawExtension{Raw: []byte(`{"connector":{"user":"root"},"kube_version":"v1.30.0"}`)},
+					},
+					"kube_node": {
+						Hosts: []string{"node2", "node3"},
+						Vars:  runtime.RawExtension{Raw: []byte(`{"connector":{"user":"root"},"kube_version":"v1.30.0","max_pods":110}`)},
+					},
+					"k8s_cluster": {
+						Groups: []string{"kube_control_plane", "kube_node"},
+						Vars:   runtime.RawExtension{Raw: []byte(`{"connector":{"user":"root"},"kube_version":"v1.30.0"}`)},
+					},
+				},
+			},
+		},
+		{
+			name: "undefined child group",
+			data: `
+[k8s_cluster:children]
+kube_node
+`,
+			err: true,
+		},
+		{
+			name: "invalid section",
+			data: `
+[k8s_cluster:hosts]
+node1
+`,
+			err: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			inventory, err := ConvertINIInventory([]byte(tc.data))
+			if tc.err {
+				assert.Error(t, err)
+
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.except, inventory.Spec)
+		})
+	}
+}
+
+func TestConvertJSONInventory(t *testing.T) {
+	data := `{
+  "all": {"vars": {"ntp_enabled": true}, "children": ["k8s_cluster", "ungrouped"]},
+  "k8s_cluster": {"hosts": ["node1", "node2"], "vars": {"ansible_user": "root"}},
+  "etcd": ["node1"],
+  "ungrouped": {"hosts": ["localhost"]},
+  "_meta": {"hostvars": {"node1": {"ansible_host": "10.0.0.1"}}}
+}`
+	inventory, err := ConvertJSONInventory([]byte(data), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, kkcorev1.InventorySpec{
+		Hosts: kkcorev1.InventoryHost{
+			"node1":     {Raw: []byte(`{"connector":{"host":"10.0.0.1"}}`)},
+			"node2":     {},
+			"localhost": {},
+		},
+		Vars: runtime.RawExtension{Raw: []byte(`{"ntp_enabled":true}`)},
+		Groups: map[string]kkcorev1.InventoryGroup{
+			"etcd":        {Hosts: []string{"node1"}},
+			"k8s_cluster": {Hosts: []string{"node1", "node2"}, Vars: runtime.RawExtension{Raw: []byte(`{"connector":{"user":"root"}}`)}},
+		},
+	}, inventory.Spec)
+
+	// without _meta, the host vars are get by hostVars.
+	inventory, err = ConvertJSONInventory([]byte(`{"etcd": {"hosts": ["node1"]}}`), func(host string) (map[string]any, error) {
+		return map[string]any{"ansible_port": 2222}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, kkcorev1.InventoryHost{"node1": {Raw: []byte(`{"connector":{"port":2222}}`)}}, inventory.Spec.Hosts)
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"reflect"
 	"slices"
 
 	"k8s.io/apimachinery/pkg/runtime"
@@ -12,9 +14,11 @@ import (
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
-	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+	kkcorev1alpha1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1alpha1"
 	kkprojectv1 "github.com/kubesphere/kubekey/v4/pkg/apis/project/v1"
+	_const "github.com/kubesphere/kubekey/v4/pkg/const"
 	"github.com/kubesphere/kubekey/v4/pkg/converter"
+	"github.com/kubesphere/kubekey/v4/pkg/converter/tmpl"
 	"github.com/kubesphere/kubekey/v4/pkg/modules"
 	"github.com/kubesphere/kubekey/v4/pkg/variable"
 )
@@ -23,21 +27,37 @@ type blockExecutor struct {
 	*option
 
 	// playbook level config
-	hosts        []string // which hosts will run playbook
-	ignoreErrors *bool    // IgnoreErrors for playbook
+	hosts        []string              // which hosts will run playbook
+	ignoreErrors *bool                 // IgnoreErrors for playbook
+	become       kkcorev1alpha1.Become // privilege escalation for playbook
 	// blocks level config
 	blocks []kkprojectv1.Block
 	role   string   // role name of blocks
 	when   []string // when condition for blocks
 	tags   kkprojectv1.Taggable
+	// rescuable is true when blocks are in a block which has rescue. the failed hosts do not abort the play.
+	rescuable bool
+	// path is the position of blocks in play. it identifies the run_once blocks in "free" strategy.
+	path string
 }
 
 // Exec block. convert block to task and executor it.
 func (e blockExecutor) Exec(ctx context.Context) error {
-	for _, block := range e.blocks {
-		hosts := e.dealRunOnce(block.RunOnce)
+	for i, block := range e.blocks {
+		// stop when the play is aborted by other hosts.
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("execute block %q is canceled: %w", block.Name, err)
+		}
+		be := e
+		be.path = fmt.Sprintf("%s/%d", e.path, i)
+		hosts := be.dealRunOnce(block.RunOnce)
+		if len(hosts) == 0 {
+			// all hosts have failed, or the run_once block has been run by other host.
+			continue
+		}
 		tags := e.dealTags(block.Taggable)
 		ignoreErrors := e.dealIgnoreErrors(block.IgnoreErrors)
+		become := mergeBecome(e.become, block.Base)
 		when := e.dealWhen(block.When)
 
 		// // check tags
@@ -54,16 +74,26 @@ func (e blockExecutor) Exec(ctx context.Context) error {
 		}
 
 		switch {
+		case block.IncludeTasks != "" || block.IncludeRole != nil:
+			if err := be.dealInclude(ctx, hosts, ignoreErrors, become, when, tags, block); err != nil {
+				klog.V(5).ErrorS(err, "deal include error", "block", block.Name, "pipeline", ctrlclient.ObjectKeyFromObject(e.pipeline))
+
+				return err
+			}
 		case len(block.Block) != 0:
-			if err := e.dealBlock(ctx, hosts, ignoreErrors, when, tags, block); err != nil {
+			if block.ImportRole != nil {
+				// the blocks of import_role run in role.
+				be.role = block.ImportRole.Name
+			}
+			if err := be.dealBlock(ctx, hosts, ignoreErrors, become, when, tags, block); err != nil {
 				klog.V(5).ErrorS(err, "deal block error", "block", block.Name, "pipeline", ctrlclient.ObjectKeyFromObject(e.pipeline))
 
 				return err
 			}
-		case block.IncludeTasks != "":
-			// do nothing. include tasks has converted to blocks.
+		case block.ImportTasks != "" || block.ImportRole != nil:
+			// do nothing. the imported file is empty.
 		default:
-			if err := e.dealTask(ctx, hosts, when, block); err != nil {
+			if err := e.dealTask(ctx, hosts, become, when, block); err != nil {
 				klog.V(5).ErrorS(err, "deal task error", "block", block.Name, "pipeline", ctrlclient.ObjectKeyFromObject(e.pipeline))
 
 				return err
@@ -75,13 +105,18 @@ func (e blockExecutor) Exec(ctx context.Context) error {
 }
 
 // dealRunOnce "run_once" argument in block.
-// If RunOnce is true, it's always only run in the first host.
+// If RunOnce is true, it's always only run in the first host which has not failed.
+// In "free" strategy, it's only run by the first host which reaches the block.
 // Otherwise, return hosts which defined in parent block.
 func (e blockExecutor) dealRunOnce(runOnce bool) []string {
 	hosts := e.hosts
 	if runOnce {
 		// runOnce only run in first node
-		hosts = hosts[:1]
+		if hosts = e.failure.filter(hosts); len(hosts) > 0 && e.freeRunOnce.claim(e.path) {
+			hosts = hosts[:1]
+		} else {
+			hosts = nil
+		}
 	}
 
 	return hosts
@@ -97,6 +132,27 @@ func (e blockExecutor) dealIgnoreErrors(ie *bool) *bool {
 	return ie
 }
 
+// mergeBecome "become" arguments in play, role or block. the arguments which not defined inherit parent.
+func mergeBecome(become kkcorev1alpha1.Become, base kkprojectv1.Base) kkcorev1alpha1.Become {
+	if base.Become != nil {
+		become.Enable = *base.Become
+	}
+	if base.BecomeUser != "" {
+		become.User = base.BecomeUser
+	}
+	if base.BecomeMethod != "" {
+		become.Method = base.BecomeMethod
+	}
+	if base.BecomeFlags != "" {
+		become.Flags = base.BecomeFlags
+	}
+	if base.BecomeExe != "" {
+		become.Exe = base.BecomeExe
+	}
+
+	return become
+}
+
 // dealTags "tags" argument in block. block tags inherits parent block
 func (e blockExecutor) dealTags(taggable kkprojectv1.Taggable) kkprojectv1.Taggable {
 	return kkprojectv1.JoinTag(taggable, e.tags)
@@ -115,33 +171,41 @@ func (e blockExecutor) dealWhen(when kkprojectv1.When) []string {
 }
 
 // dealBlock "block" argument has defined in block. execute order is: block -> rescue -> always
-// If rescue is defined, execute it when block execute error.
+// If rescue is defined, execute it in the hosts which failed in block. the hosts are not failed if rescue succeed.
 // If always id defined, execute it.
-func (e blockExecutor) dealBlock(ctx context.Context, hosts []string, ignoreErrors *bool, when []string, tags kkprojectv1.Taggable, block kkprojectv1.Block) error {
-	var errs error
+func (e blockExecutor) dealBlock(ctx context.Context, hosts []string, ignoreErrors *bool, become kkcorev1alpha1.Become, when []string, tags kkprojectv1.Taggable, block kkprojectv1.Block) error {
+	// the hosts which have failed before the block are not rescued.
+	active := e.failure.filter(hosts)
 	// exec block
-	if err := (blockExecutor{
+	errs := blockExecutor{
 		option:       e.option,
 		hosts:        hosts,
 		ignoreErrors: ignoreErrors,
+		become:       become,
 		role:         e.role,
 		blocks:       block.Block,
+		path:         e.path + "/block",
 		when:         when,
 		tags:         tags,
-	}.Exec(ctx)); err != nil {
-		klog.V(5).ErrorS(err, "execute tasks from block error", "block", block.Name, "pipeline", ctrlclient.ObjectKeyFromObject(e.pipeline))
-		errs = errors.Join(errs, err)
+		rescuable:    e.rescuable || len(block.Rescue) != 0,
+	}.Exec(ctx)
+	if errs != nil {
+		klog.V(5).ErrorS(errs, "execute tasks from block error", "block", block.Name, "pipeline", ctrlclient.ObjectKeyFromObject(e.pipeline))
 	}
-	// if block exec failed exec rescue
-	if e.pipeline.Status.Phase == kkcorev1.PipelinePhaseFailed && len(block.Rescue) != 0 {
+	// exec rescue in the hosts which failed in block
+	if failed := e.failure.failed(active); len(failed) != 0 && len(block.Rescue) != 0 {
+		e.failure.remove(failed...)
 		if err := (blockExecutor{
 			option:       e.option,
-			hosts:        hosts,
+			hosts:        failed,
 			ignoreErrors: ignoreErrors,
+			become:       become,
 			blocks:       block.Rescue,
+			path:         e.path + "/rescue",
 			role:         e.role,
 			when:         when,
 			tags:         tags,
+			rescuable:    e.rescuable,
 		}.Exec(ctx)); err != nil {
 			klog.V(5).ErrorS(err, "execute tasks from rescue error", "block", block.Name, "pipeline", ctrlclient.ObjectKeyFromObject(e.pipeline))
 			errs = errors.Join(errs, err)
@@ -153,10 +217,13 @@ func (e blockExecutor) dealBlock(ctx context.Context, hosts []string, ignoreErro
 			option:       e.option,
 			hosts:        hosts,
 			ignoreErrors: ignoreErrors,
+			become:       become,
 			blocks:       block.Always,
+			path:         e.path + "/always",
 			role:         e.role,
 			when:         when,
 			tags:         tags,
+			rescuable:    e.rescuable,
 		}.Exec(ctx)); err != nil {
 			klog.V(5).ErrorS(err, "execute tasks from always error", "block", block.Name, "pipeline", ctrlclient.ObjectKeyFromObject(e.pipeline))
 			errs = errors.Join(errs, err)
@@ -166,9 +233,145 @@ func (e blockExecutor) dealBlock(ctx context.Context, hosts []string, ignoreErro
 	return errs
 }
 
+// dealInclude "include_tasks" or "include_role" argument in block. the file or role is loaded when executing.
+// the "when" and "loop" of include are evaluated in each host, the name of file or role can be template string.
+// the hosts which include the same name with the same loop item (and index) execute the included blocks together.
+func (e blockExecutor) dealInclude(ctx context.Context, hosts []string, ignoreErrors *bool, become kkcorev1alpha1.Become, when []string, tags kkprojectv1.Taggable, block kkprojectv1.Block) error {
+	if e.project == nil {
+		return errors.New("project is not set, cannot load include file")
+	}
+	name := block.IncludeTasks
+	if block.IncludeRole != nil {
+		name = block.IncludeRole.Name
+	}
+
+	type includeGroup struct {
+		name  string
+		index int
+		item  any
+		hosts []string
+	}
+	var groups []*includeGroup
+	for _, h := range hosts {
+		ha, err := e.variable.Get(variable.GetAllVariable(h))
+		if err != nil {
+			return fmt.Errorf("failed to get host %s variable: %w", h, err)
+		}
+		had, ok := ha.(map[string]any)
+		if !ok {
+			return fmt.Errorf("host: %s variable is not a map", h)
+		}
+		// check when condition
+		if len(when) > 0 {
+			ok, err := tmpl.ParseBool(had, when)
+			if err != nil {
+				return fmt.Errorf("parse when condition of include error: %w", err)
+			}
+			if !ok {
+				continue
+			}
+		}
+		// execute include in loop with loop item.
+		items := []any{nil}
+		if block.Loop != nil {
+			data, err := json.Marshal(block.Loop)
+			if err != nil {
+				return fmt.Errorf("marshal loop of include error: %w", err)
+			}
+			items = variable.Extension2Slice(had, runtime.RawExtension{Raw: data})
+		}
+		for i, item := range items {
+			itemVars := maps.Clone(had)
+			itemVars[_const.VariableItem] = item
+			n, err := tmpl.ParseString(itemVars, name)
+			if err != nil {
+				return fmt.Errorf("parse include name %s error: %w", name, err)
+			}
+			idx := slices.IndexFunc(groups, func(g *includeGroup) bool {
+				return g.name == n && g.index == i && reflect.DeepEqual(g.item, item)
+			})
+			if idx == -1 {
+				groups = append(groups, &includeGroup{name: n, index: i, item: item})
+				idx = len(groups) - 1
+			}
+			groups[idx].hosts = append(groups[idx].hosts, h)
+		}
+	}
+
+	for _, g := range groups {
+		if block.Loop != nil {
+			// set item to runtime variable. it's visible for the included tasks which not loop.
+			if err := e.variable.Merge(variable.MergeRuntimeVariable(map[string]any{
+				_const.VariableItem: g.item,
+			}, g.hosts...)); err != nil {
+				return fmt.Errorf("set loop item to variable error: %w", err)
+			}
+		}
+		ie := e
+		ie.path = fmt.Sprintf("%s/%s/%d", e.path, g.name, g.index)
+		if err := ie.execInclude(ctx, g.hosts, ignoreErrors, become, tags, block, g.name); err != nil {
+			return err
+		}
+		if block.Loop != nil {
+			// delete item
+			if err := e.variable.Merge(variable.MergeRuntimeVariable(map[string]any{
+				_const.VariableItem: nil,
+			}, g.hosts...)); err != nil {
+				return fmt.Errorf("clean loop item to variable error: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// execInclude load the include file or role by name, and execute it in hosts.
+func (e blockExecutor) execInclude(ctx context.Context, hosts []string, ignoreErrors *bool, become kkcorev1alpha1.Become, tags kkprojectv1.Taggable, block kkprojectv1.Block, name string) error {
+	var blocks []kkprojectv1.Block
+	role := e.role
+	switch {
+	case block.IncludeRole != nil:
+		r, err := e.project.MarshalRole(kkprojectv1.RoleInclude{Name: name, TasksFrom: block.IncludeRole.TasksFrom})
+		if err != nil {
+			return fmt.Errorf("load include_role %s error: %w", name, err)
+		}
+		// merge role defaults variable. the vars of include and the facts which have been set take precedence.
+		if err := e.variable.Merge(variable.MergeDefaultRuntimeVariable(r.Vars, hosts...)); err != nil {
+			return fmt.Errorf("merge variable error: %w", err)
+		}
+		blocks, role = r.Block, name
+	default:
+		var err error
+		if blocks, err = e.project.MarshalIncludeTasks(e.role, name); err != nil {
+			return fmt.Errorf("load include_tasks %s error: %w", name, err)
+		}
+	}
+
+	// the "when" of include has evaluated. the included blocks only inherit parent's.
+	return blockExecutor{
+		option:       e.option,
+		hosts:        hosts,
+		ignoreErrors: ignoreErrors,
+		become:       become,
+		blocks:       blocks,
+		role:         role,
+		when:         e.when,
+		tags:         tags,
+		rescuable:    e.rescuable,
+		path:         e.path,
+	}.Exec(ctx)
+}
+
 // dealTask "block" argument is not defined in block.
-func (e blockExecutor) dealTask(ctx context.Context, hosts []string, when []string, block kkprojectv1.Block) error {
+func (e blockExecutor) dealTask(ctx context.Context, hosts []string, become kkcorev1alpha1.Become, when []string, block kkprojectv1.Block) error {
+	// the failed hosts are removed from the rest of play.
+	if hosts = e.failure.filter(hosts); len(hosts) == 0 {
+		klog.V(5).InfoS("all hosts have failed, skip task", "block", block.Name, "pipeline", ctrlclient.ObjectKeyFromObject(e.pipeline))
+
+		return nil
+	}
 	task := converter.MarshalBlock(e.role, hosts, when, block)
+	task.Spec.Become = become
 	// complete by pipeline
 	task.GenerateName = e.pipeline.Name + "-"
 	task.Namespace = e.pipeline.Namespace
@@ -198,8 +401,11 @@ func (e blockExecutor) dealTask(ctx context.Context, hosts []string, when []stri
 
 		return fmt.Errorf("no module/action detected in task: %s", task.Name)
 	}
+	if task.Spec.Async > 0 && !modules.SupportAsync(task.Spec.Module.Name) {
+		return fmt.Errorf("async is not supported by module %s in task: %s", task.Spec.Module.Name, task.Spec.Name)
+	}
 
-	if err := (taskExecutor{option: e.option, task: task}.Exec(ctx)); err != nil {
+	if err := (taskExecutor{option: e.option, task: task, rescuable: e.rescuable}.Exec(ctx)); err != nil {
 		klog.V(5).ErrorS(err, "exec task error", "block", block.Name, "pipeline", ctrlclient.ObjectKeyFromObject(e.pipeline))
 
 		return err
@@ -1,18 +1,25 @@
 package executor
 
 import (
+	"context"
+	"fmt"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"k8s.io/utils/ptr"
 
+	kkcorev1alpha1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1alpha1"
 	kkprojectv1 "github.com/kubesphere/kubekey/v4/pkg/apis/project/v1"
+	"github.com/kubesphere/kubekey/v4/pkg/project"
+	"github.com/kubesphere/kubekey/v4/pkg/variable"
 )
 
 func TestBlockExecutor_DealRunOnce(t *testing.T) {
 	testcases := []struct {
 		name    string
 		runOnce bool
+		failed  []string
 		except  []string
 	}{
 		{
@@ -25,12 +32,27 @@ func TestBlockExecutor_DealRunOnce(t *testing.T) {
 			runOnce: true,
 			except:  []string{"node1"},
 		},
+		{
+			name:    "runonce is true and first host has failed",
+			runOnce: true,
+			failed:  []string{"node1"},
+			except:  []string{"node2"},
+		},
+		{
+			name:    "runonce is true and all hosts have failed",
+			runOnce: true,
+			failed:  []string{"node1", "node2", "node3"},
+			except:  []string{},
+		},
 	}
 
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
+			failure := newHostFailure(ptr.To[float32](100), 3)
+			failure.add(tc.failed...)
 			assert.ElementsMatch(t, blockExecutor{
-				hosts: []string{"node1", "node2", "node3"},
+				option: &option{failure: failure},
+				hosts:  []string{"node1", "node2", "node3"},
 			}.dealRunOnce(tc.runOnce), tc.except)
 		})
 	}
@@ -68,6 +90,36 @@ func TestBlockExecutor_DealIgnoreErrors(t *testing.T) {
 	}
 }
 
+func TestMergeBecome(t *testing.T) {
+	testcases := []struct {
+		name   string
+		base   kkprojectv1.Base
+		except kkcorev1alpha1.Become
+	}{
+		{
+			name:   "inherit parent",
+			base:   kkprojectv1.Base{},
+			except: kkcorev1alpha1.Become{Enable: true, User: "admin"},
+		},
+		{
+			name:   "disable become",
+			base:   kkprojectv1.Base{Become: ptr.To(false)},
+			except: kkcorev1alpha1.Become{User: "admin"},
+		},
+		{
+			name:   "override become user and method",
+			base:   kkprojectv1.Base{BecomeUser: "root", BecomeMethod: "su"},
+			except: kkcorev1alpha1.Become{Enable: true, User: "root", Method: "su"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.except, mergeBecome(kkcorev1alpha1.Become{Enable: true, User: "admin"}, tc.base))
+		})
+	}
+}
+
 func TestBlockExecutor_DealTags(t *testing.T) {
 	testcases := []struct {
 		name   string
@@ -131,3 +183,255 @@ func TestBlockExecutor_DealWhen(t *testing.T) {
 		})
 	}
 }
+
+type testProject struct {
+	project.Project
+	tasks map[string][]kkprojectv1.Block
+	roles map[string]*kkprojectv1.Role
+}
+
+func (p testProject) MarshalIncludeTasks(_ string, file string) ([]kkprojectv1.Block, error) {
+	blocks, ok := p.tasks[file]
+	if !ok {
+		return nil, fmt.Errorf("cannot found include_tasks file %s", file)
+	}
+
+	return blocks, nil
+}
+
+func (p testProject) MarshalRole(ri kkprojectv1.RoleInclude) (*kkprojectv1.Role, error) {
+	role, ok := p.roles[ri.Name]
+	if !ok {
+		return nil, fmt.Errorf("cannot found role %s", ri.Name)
+	}
+
+	return role, nil
+}
+
+func TestBlockExecutor_DealInclude(t *testing.T) {
+	debug := kkprojectv1.Block{
+		BlockBase: kkprojectv1.BlockBase{Base: kkprojectv1.Base{Name: "debug"}},
+		Task: kkprojectv1.Task{UnknownField: map[string]any{
+			"debug": map[string]any{"msg": "{{ .item }}"},
+		}},
+	}
+	testcases := []struct {
+		name   string
+		block  kkprojectv1.Block
+		except int
+	}{
+		{
+			name:   "include tasks",
+			block:  kkprojectv1.Block{IncludeTasks: "a.yaml"},
+			except: 1,
+		},
+		{
+			name: "include tasks with loop and template name",
+			block: kkprojectv1.Block{
+				Task:         kkprojectv1.Task{Loop: []any{"a", "b", "a"}},
+				IncludeTasks: "{{ .item }}.yaml",
+			},
+			except: 3,
+		},
+		{
+			name: "include tasks with false when",
+			block: kkprojectv1.Block{
+				BlockBase:    kkprojectv1.BlockBase{Conditional: kkprojectv1.Conditional{When: kkprojectv1.When{Data: []string{"false"}}}},
+				IncludeTasks: "a.yaml",
+			},
+			except: 0,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			o, err := newTestOption()
+			if err != nil {
+				t.Fatal(err)
+			}
+			o.project = testProject{tasks: map[string][]kkprojectv1.Block{
+				"a.yaml": {debug},
+				"b.yaml": {debug},
+			}}
+
+			if err := (blockExecutor{
+				option: o,
+				hosts:  []string{"node1"},
+				blocks: []kkprojectv1.Block{tc.block},
+			}).Exec(context.TODO()); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tc.except, o.pipeline.Status.TaskResult.Total)
+		})
+	}
+}
+
+func TestBlockExecutor_IncludeRoleDefaults(t *testing.T) {
+	o, err := newTestOption()
+	if err != nil {
+		t.Fatal(err)
+	}
+	o.project = testProject{roles: map[string]*kkprojectv1.Role{
+		"role1": {RoleInfo: kkprojectv1.RoleInfo{
+			Base: kkprojectv1.Base{Vars: map[string]any{"a": "default", "b": "default", "c": "default"}},
+			Block: []kkprojectv1.Block{{
+				BlockBase: kkprojectv1.BlockBase{Base: kkprojectv1.Base{Name: "debug"}},
+				Task:      kkprojectv1.Task{UnknownField: map[string]any{"debug": map[string]any{"msg": "{{ .a }}"}}},
+			}},
+		}},
+	}}
+	// the fact which has been set before include.
+	if err := o.variable.Merge(variable.MergeRuntimeVariable(map[string]any{"c": "fact"}, "node1")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := (blockExecutor{
+		option: o,
+		hosts:  []string{"node1"},
+		blocks: []kkprojectv1.Block{{
+			BlockBase:   kkprojectv1.BlockBase{Base: kkprojectv1.Base{Vars: map[string]any{"a": "include"}}},
+			IncludeRole: &kkprojectv1.RoleInclude{Name: "role1"},
+		}},
+	}).Exec(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	v, err := o.variable.Get(variable.GetAllVariable("node1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	vd, ok := v.(map[string]any)
+	if !ok {
+		t.Fatal("variable is not a map")
+	}
+	// the vars of include and the facts override the role defaults.
+	assert.Equal(t, "include", vd["a"])
+	assert.Equal(t, "default", vd["b"])
+	assert.Equal(t, "fact", vd["c"])
+}
+
+func TestBlockExecutor_DealAsync(t *testing.T) {
+	o, err := newTestOption()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.ErrorContains(t, blockExecutor{
+		option: o,
+		hosts:  []string{"node1"},
+		blocks: []kkprojectv1.Block{{
+			BlockBase: kkprojectv1.BlockBase{Base: kkprojectv1.Base{Name: "debug"}},
+			Task: kkprojectv1.Task{
+				AsyncVal:     5,
+				UnknownField: map[string]any{"debug": map[string]any{"msg": "hello"}},
+			},
+		}},
+	}.Exec(context.TODO()), "async is not supported by module debug")
+}
+
+func TestBlockExecutor_DealBlock(t *testing.T) {
+	// the task fails in the hosts whose "fail" variable is true.
+	task := func(name string, failedWhen string) kkprojectv1.Block {
+		return kkprojectv1.Block{
+			BlockBase: kkprojectv1.BlockBase{Base: kkprojectv1.Base{Name: name}},
+			Task: kkprojectv1.Task{
+				FailedWhen:   kkprojectv1.When{Data: []string{failedWhen}},
+				Register:     name,
+				UnknownField: map[string]any{"debug": map[string]any{"msg": name}},
+			},
+		}
+	}
+	testcases := []struct {
+		name              string
+		maxFailPercentage *float32
+		failed            []string
+		rescue            kkprojectv1.Block
+		exceptRescued     []string
+		exceptFailed      []string
+		exceptErr         bool
+	}{
+		{
+			name:              "no host failed",
+			maxFailPercentage: ptr.To[float32](50),
+			rescue:            task("rescue", "false"),
+			exceptRescued:     []string{},
+			exceptFailed:      []string{},
+		},
+		{
+			name:              "rescue the failed host when max_fail_percentage is not exceeded",
+			maxFailPercentage: ptr.To[float32](50),
+			failed:            []string{"node1"},
+			rescue:            task("rescue", "false"),
+			exceptRescued:     []string{"node1"},
+			exceptFailed:      []string{},
+		},
+		{
+			name:          "rescue the failed host when any error is fatal",
+			failed:        []string{"node1"},
+			rescue:        task("rescue", "false"),
+			exceptRescued: []string{"node1"},
+			exceptFailed:  []string{},
+		},
+		{
+			name:              "rescue failed when max_fail_percentage is not exceeded",
+			maxFailPercentage: ptr.To[float32](50),
+			failed:            []string{"node1"},
+			rescue:            task("rescue", "true"),
+			exceptRescued:     []string{"node1"},
+			exceptFailed:      []string{"node1"},
+		},
+		{
+			name:          "rescue failed when any error is fatal",
+			failed:        []string{"node1"},
+			rescue:        task("rescue", "true"),
+			exceptRescued: []string{"node1"},
+			exceptFailed:  []string{"node1"},
+			exceptErr:     true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			o, err := newTestOption()
+			if err != nil {
+				t.Fatal(err)
+			}
+			hosts := []string{"node1", "node2"}
+			o.failure = newHostFailure(tc.maxFailPercentage, len(hosts))
+			for _, h := range hosts {
+				if err := o.variable.Merge(variable.MergeRuntimeVariable(map[string]any{"fail": slices.Contains(tc.failed, h)}, h)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err = blockExecutor{
+				option: o,
+				hosts:  hosts,
+				blocks: []kkprojectv1.Block{{
+					BlockBase: kkprojectv1.BlockBase{Base: kkprojectv1.Base{Name: "block"}},
+					BlockInfo: kkprojectv1.BlockInfo{
+						Block:  []kkprojectv1.Block{task("block", "{{ .fail }}"), task("next", "false")},
+						Rescue: []kkprojectv1.Block{tc.rescue},
+					},
+				}},
+			}.Exec(context.TODO())
+			assert.Equal(t, tc.exceptErr, err != nil)
+
+			rescued := []string{}
+			for _, h := range hosts {
+				v, err := o.variable.Get(variable.GetAllVariable(h))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, ok := v.(map[string]any)["rescue"]; ok {
+					rescued = append(rescued, h)
+				}
+				// the failure of other hosts does not abort the rest of block.
+				if _, ok := v.(map[string]any)["next"]; ok == slices.Contains(tc.failed, h) {
+					t.Errorf("the rest of block in host %s: got run %v, except %v", h, ok, !ok)
+				}
+			}
+			assert.Equal(t, tc.exceptRescued, rescued)
+			assert.Equal(t, tc.exceptFailed, o.failure.failed(hosts))
+		})
+	}
+}
@@ -3,10 +3,14 @@ package executor
 import (
 	"context"
 	"io"
+	"slices"
+	"sync"
 
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+	"github.com/kubesphere/kubekey/v4/pkg/connector"
+	"github.com/kubesphere/kubekey/v4/pkg/project"
 	"github.com/kubesphere/kubekey/v4/pkg/variable"
 )
 
@@ -21,6 +25,157 @@ type option struct {
 
 	pipeline *kkcorev1.Pipeline
 	variable variable.Variable
+	// project is used to load include_tasks and include_role when executing.
+	project project.Project
 	// commandLine log output. default os.stdout
 	logOutput io.Writer
+	// notification store the handlers which notified by tasks. it's reset in each serial batch.
+	notification *notification
+	// connectors cache the connections of hosts. it's closed when the pipeline finished.
+	connectors *connector.Pool
+	// failure store the failed hosts. it's reset in each serial batch.
+	failure *hostFailure
+	// freeRunOnce store the run_once blocks which have run in "free" strategy. it's nil in "linear" strategy.
+	freeRunOnce *freeRunOnce
+	// statusLock protect the pipeline status, which may be changed by hosts concurrently in "free" strategy.
+	statusLock sync.Mutex
+}
+
+// hostFailure store the failed hosts in serial batch. the failed hosts are removed from the rest of play.
+type hostFailure struct {
+	sync.Mutex
+	// maxFailPercentage is the max percentage of failed hosts in serial batch. nil means abort when any host failed.
+	maxFailPercentage *float32
+	// total is the number of hosts in serial batch.
+	total int
+	// hosts is the failed hosts.
+	hosts []string
+}
+
+// newHostFailure return an empty hostFailure
+func newHostFailure(maxFailPercentage *float32, total int) *hostFailure {
+	return &hostFailure{maxFailPercentage: maxFailPercentage, total: total}
+}
+
+// add failed hosts. return true if the play should abort.
+func (f *hostFailure) add(hosts ...string) bool {
+	if f == nil {
+		return true
+	}
+	f.Lock()
+	defer f.Unlock()
+
+	for _, h := range hosts {
+		if !slices.Contains(f.hosts, h) {
+			f.hosts = append(f.hosts, h)
+		}
+	}
+	if f.maxFailPercentage == nil || f.total == 0 {
+		return len(f.hosts) > 0
+	}
+
+	return float32(len(f.hosts))*100/float32(f.total) > *f.maxFailPercentage
+}
+
+// filter return the hosts which have not failed.
+func (f *hostFailure) filter(hosts []string) []string {
+	if f == nil {
+		return hosts
+	}
+	f.Lock()
+	defer f.Unlock()
+
+	return slices.DeleteFunc(slices.Clone(hosts), func(h string) bool {
+		return slices.Contains(f.hosts, h)
+	})
+}
+
+// failed return the hosts which have failed.
+func (f *hostFailure) failed(hosts []string) []string {
+	if f == nil {
+		return nil
+	}
+	f.Lock()
+	defer f.Unlock()
+
+	return slices.DeleteFunc(slices.Clone(hosts), func(h string) bool {
+		return !slices.Contains(f.hosts, h)
+	})
+}
+
+// remove the hosts from failed hosts. it's used when the failed hosts are rescued.
+func (f *hostFailure) remove(hosts ...string) {
+	if f == nil {
+		return
+	}
+	f.Lock()
+	defer f.Unlock()
+
+	f.hosts = slices.DeleteFunc(f.hosts, func(h string) bool {
+		return slices.Contains(hosts, h)
+	})
+}
+
+// freeRunOnce store the run_once blocks which have been run by a host in "free" strategy.
+// the hosts run blocks independently, the block is only run by the first host which reaches it.
+type freeRunOnce struct {
+	sync.Mutex
+	// paths is the position of run_once blocks in play.
+	paths map[string]bool
+}
+
+// newFreeRunOnce return an empty freeRunOnce
+func newFreeRunOnce() *freeRunOnce {
+	return &freeRunOnce{paths: make(map[string]bool)}
+}
+
+// claim the run_once block in path. return false if it has been claimed by other host.
+func (r *freeRunOnce) claim(path string) bool {
+	if r == nil {
+		return true
+	}
+	r.Lock()
+	defer r.Unlock()
+
+	if r.paths[path] {
+		return false
+	}
+	r.paths[path] = true
+
+	return true
+}
+
+// notification store the notified handler names (or listen topics) for each host.
+type notification struct {
+	sync.Mutex
+	// hosts is the notified hosts for each handler name (or listen topic)
+	hosts map[string][]string
+}
+
+// newNotification return an empty notification
+func newNotification() *notification {
+	return &notification{hosts: make(map[string][]string)}
+}
+
+// add hosts which notify the handler name (or listen topic).
+func (n *notification) add(name string, hosts ...string) {
+	n.Lock()
+	defer n.Unlock()
+
+	for _, h := range hosts {
+		if !slices.Contains(n.hosts[name], h) {
+			n.hosts[name] = append(n.hosts[name], h)
+		}
+	}
+}
+
+// flush return all notified handler names (or listen topics) and clean them.
+func (n *notification) flush() map[string][]string {
+	n.Lock()
+	defer n.Unlock()
+
+	hosts := n.hosts
+	n.hosts = make(map[string][]string)
+
+	return hosts
 }
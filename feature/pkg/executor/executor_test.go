@@ -3,10 +3,13 @@ package executor
 import (
 	"context"
 	"os"
+	"testing"
 
+	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
@@ -71,3 +74,46 @@ func newTestOption() (*option, error) {
 
 	return o, nil
 }
+
+func TestHostFailure(t *testing.T) {
+	testcases := []struct {
+		name              string
+		maxFailPercentage *float32
+		failed            [][]string
+		exceptAbort       bool
+		exceptHosts       []string
+	}{
+		{
+			name:        "abort when any host failed",
+			failed:      [][]string{{"node1"}},
+			exceptAbort: true,
+			exceptHosts: []string{"node2", "node3", "node4"},
+		},
+		{
+			name:              "not exceed max_fail_percentage",
+			maxFailPercentage: ptr.To[float32](50),
+			failed:            [][]string{{"node1"}, {"node1", "node2"}},
+			exceptAbort:       false,
+			exceptHosts:       []string{"node3", "node4"},
+		},
+		{
+			name:              "exceed max_fail_percentage",
+			maxFailPercentage: ptr.To[float32](50),
+			failed:            [][]string{{"node1"}, {"node2", "node3"}},
+			exceptAbort:       true,
+			exceptHosts:       []string{"node4"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newHostFailure(tc.maxFailPercentage, 4)
+			var abort bool
+			for _, hosts := range tc.failed {
+				abort = f.add(hosts...)
+			}
+			assert.Equal(t, tc.exceptAbort, abort)
+			assert.Equal(t, tc.exceptHosts, f.filter([]string{"node1", "node2", "node3", "node4"}))
+		})
+	}
+}
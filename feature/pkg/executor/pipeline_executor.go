@@ -17,15 +17,28 @@ limitations under the License.
 package executor
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+	kkcorev1alpha1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1alpha1"
 	kkprojectv1 "github.com/kubesphere/kubekey/v4/pkg/apis/project/v1"
 	"github.com/kubesphere/kubekey/v4/pkg/connector"
 	_const "github.com/kubesphere/kubekey/v4/pkg/const"
@@ -35,6 +48,30 @@ import (
 	"github.com/kubesphere/kubekey/v4/pkg/variable/source"
 )
 
+const (
+	// strategyLinear run each task in all hosts before starting the next task. it's the default strategy.
+	strategyLinear = "linear"
+	// strategyFree run each host through the tasks independently, without waiting for other hosts.
+	strategyFree = "free"
+)
+
+// defaultGatherConcurrency is the max number of hosts which gather facts concurrently
+// when "gather_concurrency" is not set in play.
+const defaultGatherConcurrency = 20
+
+const (
+	// orderInventory keep the hosts order in inventory. it's the default order.
+	orderInventory = "inventory"
+	// orderReverseInventory reverse the hosts order in inventory.
+	orderReverseInventory = "reverse_inventory"
+	// orderSorted sort the hosts by name.
+	orderSorted = "sorted"
+	// orderReverseSorted reverse sort the hosts by name.
+	orderReverseSorted = "reverse_sorted"
+	// orderShuffle shuffle the hosts randomly.
+	orderShuffle = "shuffle"
+)
+
 // NewPipelineExecutor return a new pipelineExecutor
 func NewPipelineExecutor(ctx context.Context, client ctrlclient.Client, pipeline *kkcorev1.Pipeline, logOutput io.Writer) Executor {
 	// get variable
@@ -47,10 +84,12 @@ func NewPipelineExecutor(ctx context.Context, client ctrlclient.Client, pipeline
 
 	return &pipelineExecutor{
 		option: &option{
-			client:    client,
-			pipeline:  pipeline,
-			variable:  v,
-			logOutput: logOutput,
+			client:       client,
+			pipeline:     pipeline,
+			variable:     v,
+			logOutput:    logOutput,
+			notification: newNotification(),
+			connectors:   connector.NewPool(),
 		},
 	}
 }
@@ -67,6 +106,9 @@ func (e pipelineExecutor) Exec(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("deal project error: %w", err)
 	}
+	e.project = pj
+	// the connections are shared by all plays.
+	defer e.connectors.Close()
 
 	// convert to transfer.Playbook struct
 	pb, err := pj.MarshalPlaybook()
@@ -87,8 +129,12 @@ func (e pipelineExecutor) Exec(ctx context.Context) error {
 
 			continue
 		}
+		// sort hosts before batching them in serial.
+		if err := e.dealOrder(play.Order, hosts); err != nil {
+			return fmt.Errorf("deal order argument error: %w", err)
+		}
 		// when gather_fact is set. get host's information from remote.
-		if err := e.dealGatherFacts(ctx, play.GatherFacts, hosts); err != nil {
+		if err := e.dealGatherFacts(ctx, play, hosts); err != nil {
 			return fmt.Errorf("deal gather_facts argument error: %w", err)
 		}
 		// Batch execution, with each batch being a group of hosts run in serial.
@@ -106,7 +152,7 @@ func (e pipelineExecutor) Exec(ctx context.Context) error {
 	return nil
 }
 
-// execBatchHosts executor block in play order by: "pre_tasks" > "roles" > "tasks" > "post_tasks"
+// execBatchHosts executor block in play order by: "pre_tasks" > "roles" > "tasks" > "post_tasks" > "handlers"
 func (e pipelineExecutor) execBatchHosts(ctx context.Context, play kkprojectv1.Play, batchHosts [][]string) any {
 	// generate and execute task.
 	for _, serials := range batchHosts {
@@ -116,62 +162,193 @@ func (e pipelineExecutor) execBatchHosts(ctx context.Context, play kkprojectv1.P
 
 			return errors.New("host is empty")
 		}
+		// handlers are notified in each serial batch.
+		e.notification = newNotification()
+		// abort when any host failed if max_fail_percentage is not set.
+		maxFailPercentage := play.MaxFailPercentage
+		if play.AnyErrorsFatal {
+			maxFailPercentage = nil
+		}
+		e.failure = newHostFailure(maxFailPercentage, len(serials))
+		e.freeRunOnce = nil
+		var err error
+		switch play.Strategy {
+		case "", strategyLinear:
+			err = e.execBatchTasks(ctx, play, serials)
+		case strategyFree:
+			e.freeRunOnce = newFreeRunOnce()
+			err = e.execFreeTasks(ctx, play, serials)
+		default:
+			return fmt.Errorf("unknown strategy %q", play.Strategy)
+		}
+		// handlers run even if the task failed when force_handlers is set.
+		if err == nil || play.ForceHandlers {
+			if herr := e.execHandlers(ctx, play, serials); herr != nil {
+				err = errors.Join(err, fmt.Errorf("execute handlers error: %w", herr))
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
 
-		if err := e.variable.Merge(variable.MergeRuntimeVariable(play.Vars, serials...)); err != nil {
+	return nil
+}
+
+// execBatchTasks executor tasks in a serial batch hosts.
+func (e pipelineExecutor) execBatchTasks(ctx context.Context, play kkprojectv1.Play, serials []string) error {
+	if err := e.variable.Merge(variable.MergeRuntimeVariable(play.Vars, serials...)); err != nil {
+		return fmt.Errorf("merge variable error: %w", err)
+	}
+	become := mergeBecome(kkcorev1alpha1.Become{}, play.Base)
+	// generate task from pre tasks
+	if err := (blockExecutor{
+		option:       e.option,
+		hosts:        serials,
+		ignoreErrors: play.IgnoreErrors,
+		become:       become,
+		blocks:       play.PreTasks,
+		tags:         play.Taggable,
+		path:         "pre_tasks",
+	}.Exec(ctx)); err != nil {
+		return fmt.Errorf("execute pre-tasks from play error: %w", err)
+	}
+	// generate task from role
+	for i, role := range play.Roles {
+		if err := e.variable.Merge(variable.MergeRuntimeVariable(role.Vars, serials...)); err != nil {
 			return fmt.Errorf("merge variable error: %w", err)
 		}
-		// generate task from pre tasks
+		// use the most closely configuration
+		ignoreErrors := role.IgnoreErrors
+		if ignoreErrors == nil {
+			ignoreErrors = play.IgnoreErrors
+		}
+		// role is block.
 		if err := (blockExecutor{
 			option:       e.option,
 			hosts:        serials,
-			ignoreErrors: play.IgnoreErrors,
-			blocks:       play.PreTasks,
-			tags:         play.Taggable,
+			ignoreErrors: ignoreErrors,
+			become:       mergeBecome(become, role.Base),
+			blocks:       role.Block,
+			role:         role.Role,
+			when:         role.When.Data,
+			tags:         kkprojectv1.JoinTag(role.Taggable, play.Taggable),
+			path:         fmt.Sprintf("roles/%d", i),
 		}.Exec(ctx)); err != nil {
-			return fmt.Errorf("execute pre-tasks from play error: %w", err)
+			return fmt.Errorf("execute role-tasks error: %w", err)
 		}
-		// generate task from role
-		for _, role := range play.Roles {
-			if err := e.variable.Merge(variable.MergeRuntimeVariable(role.Vars, serials...)); err != nil {
-				return fmt.Errorf("merge variable error: %w", err)
-			}
-			// use the most closely configuration
-			ignoreErrors := role.IgnoreErrors
-			if ignoreErrors == nil {
-				ignoreErrors = play.IgnoreErrors
+	}
+	// generate task from tasks
+	if err := (blockExecutor{
+		option:       e.option,
+		hosts:        serials,
+		ignoreErrors: play.IgnoreErrors,
+		become:       become,
+		blocks:       play.Tasks,
+		tags:         play.Taggable,
+		path:         "tasks",
+	}.Exec(ctx)); err != nil {
+		return fmt.Errorf("execute tasks error: %w", err)
+	}
+	// generate task from post tasks
+	if err := (blockExecutor{
+		option:       e.option,
+		hosts:        serials,
+		ignoreErrors: play.IgnoreErrors,
+		become:       become,
+		blocks:       play.PostTasks,
+		tags:         play.Taggable,
+		path:         "post_tasks",
+	}.Exec(ctx)); err != nil {
+		return fmt.Errorf("execute post-tasks error: %w", err)
+	}
+
+	return nil
+}
+
+// execFreeTasks executor tasks in each host of serial batch independently. a host does not wait for the other hosts
+// to finish the task before starting the next one. when a host aborts the play, the other hosts are canceled.
+func (e pipelineExecutor) execFreeTasks(ctx context.Context, play kkprojectv1.Play, serials []string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var errs error
+	var mu sync.Mutex
+	wg := &wait.Group{}
+	for _, h := range serials {
+		wg.StartWithContext(ctx, func(ctx context.Context) {
+			if err := e.execBatchTasks(ctx, play, []string{h}); err != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				// the host is canceled by the host which aborted the play.
+				if errs != nil && ctx.Err() != nil {
+					return
+				}
+				errs = errors.Join(errs, fmt.Errorf("execute tasks in host %s error: %w", h, err))
+				cancel()
 			}
-			// role is block.
-			if err := (blockExecutor{
-				option:       e.option,
-				hosts:        serials,
-				ignoreErrors: ignoreErrors,
-				blocks:       role.Block,
-				role:         role.Role,
-				when:         role.When.Data,
-				tags:         kkprojectv1.JoinTag(role.Taggable, play.Taggable),
-			}.Exec(ctx)); err != nil {
-				return fmt.Errorf("execute role-tasks error: %w", err)
+		})
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// execHandlers executor the notified handlers in a serial batch hosts. the handlers defined in roles run before
+// the handlers defined in play, and each handler run at most once in defined order.
+// a handler can notify the handlers which defined after it.
+func (e pipelineExecutor) execHandlers(ctx context.Context, play kkprojectv1.Play, serials []string) error {
+	type roleHandler struct {
+		role string
+		kkprojectv1.Handler
+	}
+	var handlers []roleHandler
+	for _, role := range play.Roles {
+		for _, h := range role.Handlers {
+			handlers = append(handlers, roleHandler{role: role.Role, Handler: h})
+		}
+	}
+	for _, h := range play.Handlers {
+		handlers = append(handlers, roleHandler{Handler: h})
+	}
+
+	become := mergeBecome(kkcorev1alpha1.Become{}, play.Base)
+	notified := make(map[string][]string)
+	for _, h := range handlers {
+		// merge the handlers which notified by previous handlers.
+		for name, hosts := range e.notification.flush() {
+			notified[name] = append(notified[name], hosts...)
+		}
+		var hosts []string
+		for name, hs := range notified {
+			if h.IsNotified(name) {
+				hosts = append(hosts, hs...)
 			}
 		}
-		// generate task from tasks
-		if err := (blockExecutor{
-			option:       e.option,
-			hosts:        serials,
-			ignoreErrors: play.IgnoreErrors,
-			blocks:       play.Tasks,
-			tags:         play.Taggable,
-		}.Exec(ctx)); err != nil {
-			return fmt.Errorf("execute tasks error: %w", err)
+		// keep the order of hosts in serial batch.
+		hosts = slices.DeleteFunc(slices.Clone(serials), func(s string) bool {
+			return !slices.Contains(hosts, s)
+		})
+		if len(hosts) == 0 {
+			continue
+		}
+		// use the most closely configuration
+		ignoreErrors := h.IgnoreErrors
+		if ignoreErrors == nil {
+			ignoreErrors = play.IgnoreErrors
 		}
-		// generate task from post tasks
+		// handlers always run when it has been notified. whatever the tags is.
+		block := h.Block
+		block.Tags = append(slices.Clone(block.Tags), kkprojectv1.AlwaysTag)
 		if err := (blockExecutor{
 			option:       e.option,
-			hosts:        serials,
-			ignoreErrors: play.IgnoreErrors,
-			blocks:       play.Tasks,
-			tags:         play.Taggable,
+			hosts:        hosts,
+			ignoreErrors: ignoreErrors,
+			become:       become,
+			blocks:       []kkprojectv1.Block{block},
+			role:         h.role,
 		}.Exec(ctx)); err != nil {
-			return fmt.Errorf("execute post-tasks error: %w", err)
+			return fmt.Errorf("execute handler %q error: %w", h.Name, err)
 		}
 	}
 
@@ -195,14 +372,48 @@ func (e pipelineExecutor) dealHosts(host kkprojectv1.PlayHost, i *[]string) erro
 	return nil
 }
 
-// dealGatherFacts "gather_facts" argument in playbook. get host remote info and merge to variable
-func (e pipelineExecutor) dealGatherFacts(ctx context.Context, gatherFacts bool, hosts []string) error {
-	if !gatherFacts {
+// dealOrder "order" argument in playbook. sort hosts in place.
+func (e pipelineExecutor) dealOrder(order string, hosts []string) error {
+	switch order {
+	case "", orderInventory:
+	case orderReverseInventory:
+		slices.Reverse(hosts)
+	case orderSorted:
+		slices.Sort(hosts)
+	case orderReverseSorted:
+		slices.Sort(hosts)
+		slices.Reverse(hosts)
+	case orderShuffle:
+		rand.Shuffle(len(hosts), func(i, j int) {
+			hosts[i], hosts[j] = hosts[j], hosts[i]
+		})
+	default:
+		return fmt.Errorf("unknown order %q", order)
+	}
+
+	return nil
+}
+
+// dealGatherFacts "gather_facts" argument in playbook. get host remote info and merge to variable.
+// the facts are gathered in parallel, and cached in workdir when "fact_caching_timeout" is set.
+func (e pipelineExecutor) dealGatherFacts(ctx context.Context, play kkprojectv1.Play, hosts []string) error {
+	if !play.GatherFacts {
 		// skip
 		return nil
 	}
+	subset, err := connector.ParseFactSubset(play.GatherSubset.Subset)
+	if err != nil {
+		return err
+	}
+	option := connector.FactOption{Subset: subset, FactPath: play.FactPath}
+	ttl := time.Duration(play.FactCachingTimeout) * time.Second
+	concurrency := play.GatherConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultGatherConcurrency
+	}
+	cacheDir := filepath.Join(_const.GetRuntimeDir(), _const.RuntimeFactsDir)
 
-	dealGatherFactsInHost := func(hostname string) error {
+	dealGatherFactsInHost := func(ctx context.Context, hostname string) error {
 		v, err := e.variable.Get(variable.GetParamVariable(hostname))
 		if err != nil {
 			klog.V(5).ErrorS(err, "get host variable error", "hostname", hostname)
@@ -216,44 +427,177 @@ func (e pipelineExecutor) dealGatherFacts(ctx context.Context, gatherFacts bool,
 				connectorVars = c2
 			}
 		}
-		// get host connector
-		conn, err := connector.NewConnector(hostname, connectorVars)
+		cacheKey, err := factsCacheKey(option, connectorVars)
 		if err != nil {
-			klog.V(5).ErrorS(err, "new connector error", "hostname", hostname)
-
-			return err
-		}
-		if err := conn.Init(ctx); err != nil {
-			klog.V(5).ErrorS(err, "init connection error", "hostname", hostname)
-
 			return err
 		}
-		defer conn.Close(ctx)
-
-		if gf, ok := conn.(connector.GatherFacts); ok {
-			remoteInfo, err := gf.HostInfo(ctx)
+		remoteInfo := loadFactsCache(cacheDir, hostname, cacheKey, ttl)
+		if remoteInfo == nil {
+			remoteInfo, err = e.gatherFactsWithTimeout(ctx, hostname, connectorVars, option, time.Duration(play.GatherTimeout)*time.Second)
 			if err != nil {
 				klog.V(5).ErrorS(err, "gatherFacts from connector error", "hostname", hostname)
 
 				return err
 			}
-			if err := e.variable.Merge(variable.MergeRemoteVariable(remoteInfo, hostname)); err != nil {
-				klog.V(5).ErrorS(err, "merge gather fact error", "pipeline", ctrlclient.ObjectKeyFromObject(e.pipeline), "host", hostname)
-
-				return fmt.Errorf("merge gather fact error: %w", err)
+			if remoteInfo == nil {
+				// the connector not support gather facts.
+				return nil
+			}
+			if ttl > 0 {
+				saveFactsCache(cacheDir, hostname, cacheKey, remoteInfo)
 			}
 		}
+		if err := e.variable.Merge(variable.MergeRemoteVariable(remoteInfo, hostname)); err != nil {
+			klog.V(5).ErrorS(err, "merge gather fact error", "pipeline", ctrlclient.ObjectKeyFromObject(e.pipeline), "host", hostname)
+
+			return fmt.Errorf("merge gather fact error: %w", err)
+		}
 
 		return nil
 	}
 
+	var errs []error
+	var errsLock sync.Mutex
+	wg := &wait.Group{}
+	// limit the number of hosts which gather facts concurrently.
+	throttle := make(chan struct{}, concurrency)
 	for _, hostname := range hosts {
-		if err := dealGatherFactsInHost(hostname); err != nil {
-			return err
+		wg.StartWithContext(ctx, func(ctx context.Context) {
+			throttle <- struct{}{}
+			defer func() { <-throttle }()
+			if err := dealGatherFactsInHost(ctx, hostname); err != nil {
+				errsLock.Lock()
+				errs = append(errs, fmt.Errorf("host %s: %w", hostname, err))
+				errsLock.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// gatherFactsWithTimeout connect to host and gather facts. when timeout is set, the connection and the commands
+// of gathering are stopped once the timeout is exceeded.
+func (e pipelineExecutor) gatherFactsWithTimeout(ctx context.Context, hostname string, connectorVars map[string]any,
+	option connector.FactOption, timeout time.Duration) (map[string]any, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	facts, err := e.gatherFacts(ctx, hostname, connectorVars, option)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("gather facts timeout after %s: %w", timeout, err)
+	}
+
+	return facts, err
+}
+
+// gatherFacts connect to host and gather facts. it stops when ctx is done.
+func (e pipelineExecutor) gatherFacts(ctx context.Context, hostname string, connectorVars map[string]any, option connector.FactOption) (map[string]any, error) {
+	// get host connector
+	conn, err := e.connectors.Get(ctx, hostname, connectorVars)
+	if err != nil {
+		return nil, fmt.Errorf("init connection error: %w", err)
+	}
+	defer conn.Close(ctx)
+
+	return connector.GatherHostFacts(ctx, conn, option)
+}
+
+// factsCache is the cached facts of a host in workdir.
+type factsCache struct {
+	// Key identify the gather option and connector of the cached facts.
+	Key string `json:"key"`
+	// Time when the facts are gathered.
+	Time  time.Time      `json:"time"`
+	Facts map[string]any `json:"facts"`
+}
+
+// factsCacheKey returns the sha256 of gather option and connector variables. the cache is invalid when they changed.
+func factsCacheKey(option connector.FactOption, connectorVars map[string]any) (string, error) {
+	data, err := json.Marshal(map[string]any{"option": option, "connector": connectorVars})
+	if err != nil {
+		return "", fmt.Errorf("marshal facts cache key error: %w", err)
+	}
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// loadFactsCache returns the cached facts of host in dir. returns nil if the cache is not exist, expired or gathered by other option.
+func loadFactsCache(dir, hostname, key string, ttl time.Duration) map[string]any {
+	if ttl <= 0 {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(dir, hostname+".json"))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			klog.V(4).ErrorS(err, "Failed to read facts cache", "hostname", hostname)
 		}
+
+		return nil
 	}
+	var cache factsCache
+	// decode numbers as json.Number, so that the integer facts keep the same type as gathered.
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&cache); err != nil {
+		klog.V(4).ErrorS(err, "Failed to unmarshal facts cache", "hostname", hostname)
 
-	return nil
+		return nil
+	}
+	if cache.Key != key || time.Since(cache.Time) > ttl {
+		return nil
+	}
+	klog.V(4).InfoS("Use cached facts", "hostname", hostname, "time", cache.Time)
+
+	facts, _ := convertJSONNumber(cache.Facts).(map[string]any)
+
+	return facts
+}
+
+// convertJSONNumber convert the json.Number in v to int, or float64 if it's not an integer.
+func convertJSONNumber(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, item := range val {
+			val[k] = convertJSONNumber(item)
+		}
+	case []any:
+		for i, item := range val {
+			val[i] = convertJSONNumber(item)
+		}
+	case json.Number:
+		if i, err := strconv.Atoi(val.String()); err == nil {
+			return i
+		}
+		f, _ := val.Float64()
+
+		return f
+	}
+
+	return v
+}
+
+// saveFactsCache store the facts of host in dir. the error is ignored, the facts will be gathered again next time.
+func saveFactsCache(dir, hostname, key string, facts map[string]any) {
+	data, err := json.Marshal(factsCache{Key: key, Time: time.Now(), Facts: facts})
+	if err != nil {
+		klog.V(4).ErrorS(err, "Failed to marshal facts cache", "hostname", hostname)
+
+		return
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		klog.V(4).ErrorS(err, "Failed to create facts cache dir", "hostname", hostname)
+
+		return
+	}
+	// the facts may contain sensitive data, only the owner can read it.
+	if err := os.WriteFile(filepath.Join(dir, hostname+".json"), data, 0600); err != nil {
+		klog.V(4).ErrorS(err, "Failed to write facts cache", "hostname", hostname)
+	}
 }
 
 // dealSerial "serial" argument in playbook.
@@ -1,9 +1,16 @@
 package executor
 
 import (
+	"context"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	kkprojectv1 "github.com/kubesphere/kubekey/v4/pkg/apis/project/v1"
+	"github.com/kubesphere/kubekey/v4/pkg/connector"
+	"github.com/kubesphere/kubekey/v4/pkg/variable"
 )
 
 func TestPipelineExecutor_DealRunOnce(t *testing.T) {
@@ -36,3 +43,268 @@ func TestPipelineExecutor_DealRunOnce(t *testing.T) {
 		})
 	}
 }
+
+func TestPipelineExecutor_DealOrder(t *testing.T) {
+	testcases := []struct {
+		name   string
+		order  string
+		hosts  []string
+		except []string
+		err    bool
+	}{
+		{
+			name:   "order is empty",
+			hosts:  []string{"node2", "node1", "node3"},
+			except: []string{"node2", "node1", "node3"},
+		},
+		{
+			name:   "order is inventory",
+			order:  "inventory",
+			hosts:  []string{"node2", "node1", "node3"},
+			except: []string{"node2", "node1", "node3"},
+		},
+		{
+			name:   "order is reverse_inventory",
+			order:  "reverse_inventory",
+			hosts:  []string{"node2", "node1", "node3"},
+			except: []string{"node3", "node1", "node2"},
+		},
+		{
+			name:   "order is sorted",
+			order:  "sorted",
+			hosts:  []string{"node2", "node1", "node3"},
+			except: []string{"node1", "node2", "node3"},
+		},
+		{
+			name:   "order is reverse_sorted",
+			order:  "reverse_sorted",
+			hosts:  []string{"node2", "node1", "node3"},
+			except: []string{"node3", "node2", "node1"},
+		},
+		{
+			name:   "order is unknown",
+			order:  "unknown",
+			hosts:  []string{"node2", "node1", "node3"},
+			except: []string{"node2", "node1", "node3"},
+			err:    true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := pipelineExecutor{}.dealOrder(tc.order, tc.hosts)
+			if tc.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.except, tc.hosts)
+		})
+	}
+
+	t.Run("order is shuffle", func(t *testing.T) {
+		hosts := []string{"node1", "node2", "node3"}
+		assert.NoError(t, pipelineExecutor{}.dealOrder("shuffle", hosts))
+		assert.ElementsMatch(t, []string{"node1", "node2", "node3"}, hosts)
+	})
+}
+
+func TestPipelineExecutor_ExecHandlers(t *testing.T) {
+	testcases := []struct {
+		name     string
+		notify   map[string][]string
+		handlers []kkprojectv1.Handler
+		except   int
+	}{
+		{
+			name:   "handler is not notified",
+			notify: map[string][]string{},
+			handlers: []kkprojectv1.Handler{
+				{Block: kkprojectv1.Block{
+					BlockBase: kkprojectv1.BlockBase{Base: kkprojectv1.Base{Name: "restart"}},
+					Task:      kkprojectv1.Task{UnknownField: map[string]any{"debug": map[string]any{"msg": "restart"}}},
+				}},
+			},
+			except: 0,
+		},
+		{
+			name:   "handler is notified by name",
+			notify: map[string][]string{"restart": {"node1"}},
+			handlers: []kkprojectv1.Handler{
+				{Block: kkprojectv1.Block{
+					BlockBase: kkprojectv1.BlockBase{Base: kkprojectv1.Base{Name: "restart"}},
+					Task:      kkprojectv1.Task{UnknownField: map[string]any{"debug": map[string]any{"msg": "restart"}}},
+				}},
+			},
+			except: 1,
+		},
+		{
+			name:   "handlers are notified by listen topic",
+			notify: map[string][]string{"restart services": {"node1", "node2"}},
+			handlers: []kkprojectv1.Handler{
+				{Block: kkprojectv1.Block{
+					BlockBase: kkprojectv1.BlockBase{Base: kkprojectv1.Base{Name: "restart containerd"}},
+					Task:      kkprojectv1.Task{UnknownField: map[string]any{"debug": map[string]any{"msg": "restart"}}},
+				}, Listen: []string{"restart services"}},
+				{Block: kkprojectv1.Block{
+					BlockBase: kkprojectv1.BlockBase{Base: kkprojectv1.Base{Name: "restart kubelet"}},
+					Task:      kkprojectv1.Task{UnknownField: map[string]any{"debug": map[string]any{"msg": "restart"}}},
+				}, Listen: []string{"restart services"}},
+			},
+			except: 2,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			o, err := newTestOption()
+			if err != nil {
+				t.Fatal(err)
+			}
+			o.notification = newNotification()
+			for name, hosts := range tc.notify {
+				o.notification.add(name, hosts...)
+			}
+
+			if err := (pipelineExecutor{option: o}).execHandlers(context.TODO(), kkprojectv1.Play{Handlers: tc.handlers}, []string{"node1", "node2"}); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tc.except, o.pipeline.Status.TaskResult.Total)
+		})
+	}
+}
+
+func TestFactsCache(t *testing.T) {
+	dir := t.TempDir()
+	key, err := factsCacheKey(connector.FactOption{Subset: []string{"min"}}, map[string]any{"host": "10.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := factsCacheKey(connector.FactOption{Subset: []string{"min"}}, map[string]any{"host": "10.0.0.2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEqual(t, key, otherKey)
+
+	// not cached
+	assert.Nil(t, loadFactsCache(dir, "node1", key, time.Hour))
+	saveFactsCache(dir, "node1", key, map[string]any{"os": map[string]any{"hostname": "node1"}})
+	assert.Equal(t, map[string]any{"os": map[string]any{"hostname": "node1"}}, loadFactsCache(dir, "node1", key, time.Hour))
+	// the numbers keep the type as gathered.
+	saveFactsCache(dir, "node1", key, map[string]any{"process": map[string]any{"cgroup_version": 2}, "load": 0.5})
+	assert.Equal(t, map[string]any{"process": map[string]any{"cgroup_version": 2}, "load": 0.5}, loadFactsCache(dir, "node1", key, time.Hour))
+	// connector changed
+	assert.Nil(t, loadFactsCache(dir, "node1", otherKey, time.Hour))
+	// expired
+	assert.Nil(t, loadFactsCache(dir, "node1", key, time.Nanosecond))
+	// cache is disabled
+	assert.Nil(t, loadFactsCache(dir, "node1", key, 0))
+}
+
+func TestPipelineExecutor_ExecFreeTasks(t *testing.T) {
+	// the task registers its result by name, and fails in the hosts whose "should_fail" variable is true.
+	task := func(name string, base kkprojectv1.Base, t kkprojectv1.Task) kkprojectv1.Block {
+		base.Name = name
+		t.Register = name
+		t.UnknownField = map[string]any{"debug": map[string]any{"msg": name}}
+
+		return kkprojectv1.Block{BlockBase: kkprojectv1.BlockBase{Base: base}, Task: t}
+	}
+	failTask := task("fail", kkprojectv1.Base{}, kkprojectv1.Task{FailedWhen: kkprojectv1.When{Data: []string{"{{ .should_fail }}"}}})
+	testcases := []struct {
+		name   string
+		play   kkprojectv1.Play
+		failed []string
+		// exceptRegistered is the registered task names in each host.
+		exceptRegistered map[string][]string
+		exceptTotal      int
+		exceptErr        bool
+	}{
+		{
+			name: "run_once in block",
+			play: kkprojectv1.Play{Tasks: []kkprojectv1.Block{
+				{BlockInfo: kkprojectv1.BlockInfo{Block: []kkprojectv1.Block{
+					task("once", kkprojectv1.Base{RunOnce: true}, kkprojectv1.Task{}),
+				}}},
+				task("all", kkprojectv1.Base{}, kkprojectv1.Task{}),
+			}},
+			exceptTotal: 3,
+		},
+		{
+			name: "any_errors_fatal cancels the other hosts",
+			play: kkprojectv1.Play{
+				Base: kkprojectv1.Base{AnyErrorsFatal: true},
+				Tasks: []kkprojectv1.Block{
+					failTask,
+					// the task keeps retrying in the hosts which not failed, until it's canceled.
+					task("retry", kkprojectv1.Base{}, kkprojectv1.Task{Until: kkprojectv1.When{Data: []string{"false"}}, Retries: 10, Delay: 1}),
+					task("all", kkprojectv1.Base{}, kkprojectv1.Task{}),
+				},
+			},
+			failed: []string{"node1"},
+			exceptRegistered: map[string][]string{
+				"node1": {"fail"},
+				"node2": {"fail", "retry"},
+			},
+			exceptTotal: 3,
+			exceptErr:   true,
+		},
+		{
+			name: "rescue in the failed host",
+			play: kkprojectv1.Play{Tasks: []kkprojectv1.Block{
+				{BlockInfo: kkprojectv1.BlockInfo{
+					Block:  []kkprojectv1.Block{failTask},
+					Rescue: []kkprojectv1.Block{task("rescue", kkprojectv1.Base{}, kkprojectv1.Task{})},
+				}},
+				task("all", kkprojectv1.Base{}, kkprojectv1.Task{}),
+			}},
+			failed: []string{"node1"},
+			exceptRegistered: map[string][]string{
+				"node1": {"fail", "rescue", "all"},
+				"node2": {"fail", "all"},
+			},
+			exceptTotal: 5,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			o, err := newTestOption()
+			if err != nil {
+				t.Fatal(err)
+			}
+			hosts := []string{"node1", "node2"}
+			for _, h := range hosts {
+				if err := o.variable.Merge(variable.MergeRuntimeVariable(map[string]any{"should_fail": slices.Contains(tc.failed, h)}, h)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			tc.play.Strategy = strategyFree
+			start := time.Now()
+			res := pipelineExecutor{option: o}.execBatchHosts(context.TODO(), tc.play, [][]string{hosts})
+			assert.Equal(t, tc.exceptErr, res != nil)
+			assert.Less(t, time.Since(start), 5*time.Second)
+			assert.Equal(t, tc.exceptTotal, o.pipeline.Status.TaskResult.Total)
+
+			registered := make(map[string][]string)
+			for _, h := range hosts {
+				v, err := o.variable.Get(variable.GetAllVariable(h))
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, name := range []string{"fail", "once", "retry", "rescue", "all"} {
+					if _, ok := v.(map[string]any)[name]; ok {
+						registered[h] = append(registered[h], name)
+					}
+				}
+			}
+			if tc.exceptRegistered != nil {
+				assert.Equal(t, tc.exceptRegistered, registered)
+			} else {
+				// the run_once task is run by one host.
+				assert.ElementsMatch(t, []string{"once", "all", "all"}, append(registered["node1"], registered["node2"]...))
+			}
+		})
+	}
+}
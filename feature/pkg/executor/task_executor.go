@@ -2,6 +2,7 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -19,11 +20,21 @@ import (
 	"github.com/kubesphere/kubekey/v4/pkg/converter/tmpl"
 	"github.com/kubesphere/kubekey/v4/pkg/modules"
 	"github.com/kubesphere/kubekey/v4/pkg/variable"
+	"github.com/kubesphere/kubekey/v4/pkg/variable/vault"
+)
+
+const (
+	// defaultUntilRetries is the retries of module when "until" is set but "retries" is not.
+	defaultUntilRetries = 3
+	// defaultUntilDelay is the delay between retries when "until" is set but "delay" is not.
+	defaultUntilDelay = 5 * time.Second
 )
 
 type taskExecutor struct {
 	*option
 	task *kkcorev1alpha1.Task
+	// rescuable is true when the task is in a block which has rescue. the failed hosts are rescued instead of aborting the play.
+	rescuable bool
 }
 
 // Exec and store Task
@@ -35,6 +46,9 @@ func (e taskExecutor) Exec(ctx context.Context) error {
 		return err
 	}
 	defer func() {
+		e.statusLock.Lock()
+		defer e.statusLock.Unlock()
+
 		e.pipeline.Status.TaskResult.Total++
 		switch e.task.Status.Phase {
 		case kkcorev1alpha1.TaskPhaseSuccess:
@@ -44,45 +58,61 @@ func (e taskExecutor) Exec(ctx context.Context) error {
 		case kkcorev1alpha1.TaskPhaseFailed:
 			e.pipeline.Status.TaskResult.Failed++
 		}
+		if e.task.IsChanged() {
+			e.pipeline.Status.TaskResult.Changed++
+		}
 	}()
 
-	for !e.task.IsComplete() {
-		var roleLog string
-		if e.task.Annotations[kkcorev1alpha1.TaskAnnotationRole] != "" {
-			roleLog = "[" + e.task.Annotations[kkcorev1alpha1.TaskAnnotationRole] + "] "
-		}
-		klog.V(5).InfoS("begin run task", "task", ctrlclient.ObjectKeyFromObject(e.task))
-		fmt.Fprintf(e.logOutput, "%s %s%s\n", time.Now().Format(time.TimeOnly+" MST"), roleLog, e.task.Spec.Name)
-		// exec task
-		e.task.Status.Phase = kkcorev1alpha1.TaskPhaseRunning
-		if err := e.client.Status().Update(ctx, e.task); err != nil {
-			klog.V(5).ErrorS(err, "update task status error", "task", ctrlclient.ObjectKeyFromObject(e.task), "pipeline", ctrlclient.ObjectKeyFromObject(e.pipeline))
-		}
-		e.execTask(ctx)
-		if err := e.client.Status().Update(ctx, e.task); err != nil {
-			klog.V(5).ErrorS(err, "update task status error", "task", ctrlclient.ObjectKeyFromObject(e.task), "pipeline", ctrlclient.ObjectKeyFromObject(e.pipeline))
+	var roleLog string
+	if e.task.Annotations[kkcorev1alpha1.TaskAnnotationRole] != "" {
+		roleLog = "[" + e.task.Annotations[kkcorev1alpha1.TaskAnnotationRole] + "] "
+	}
+	klog.V(5).InfoS("begin run task", "task", ctrlclient.ObjectKeyFromObject(e.task))
+	fmt.Fprintf(e.logOutput, "%s %s%s\n", time.Now().Format(time.TimeOnly+" MST"), roleLog, e.task.Spec.Name)
+	// exec task
+	e.task.Status.Phase = kkcorev1alpha1.TaskPhaseRunning
+	if err := e.client.Status().Update(ctx, e.task); err != nil {
+		klog.V(5).ErrorS(err, "update task status error", "task", ctrlclient.ObjectKeyFromObject(e.task), "pipeline", ctrlclient.ObjectKeyFromObject(e.pipeline))
+	}
+	e.execTask(ctx)
+	// show the changes in diff mode
+	e.dealDiff()
+	if err := e.client.Status().Update(ctx, e.task); err != nil {
+		klog.V(5).ErrorS(err, "update task status error", "task", ctrlclient.ObjectKeyFromObject(e.task), "pipeline", ctrlclient.ObjectKeyFromObject(e.pipeline))
 
-			return err
-		}
+		return err
 	}
 	// exit when task run failed
 	if e.task.IsFailed() {
 		var hostReason []kkcorev1.PipelineFailedDetailHost
+		var failedHosts []string
 		for _, tr := range e.task.Status.HostResults {
 			hostReason = append(hostReason, kkcorev1.PipelineFailedDetailHost{
 				Host:   tr.Host,
 				Stdout: tr.Stdout,
 				StdErr: tr.StdErr,
 			})
+			if tr.StdErr != "" {
+				failedHosts = append(failedHosts, tr.Host)
+			}
 		}
+		e.statusLock.Lock()
 		e.pipeline.Status.FailedDetail = append(e.pipeline.Status.FailedDetail, kkcorev1.PipelineFailedDetail{
 			Task:  e.task.Spec.Name,
 			Hosts: hostReason,
 		})
-		e.pipeline.Status.Phase = kkcorev1.PipelinePhaseFailed
+		// the failed hosts are removed from the rest of play, abort when they exceed "max_fail_percentage".
+		if abort := e.failure.add(failedHosts...); (abort || e.task.Spec.AnyErrorsFatal) && !e.rescuable {
+			e.pipeline.Status.Phase = kkcorev1.PipelinePhaseFailed
+			e.statusLock.Unlock()
 
-		return fmt.Errorf("task %s run failed", e.task.Spec.Name)
+			return fmt.Errorf("task %s run failed", e.task.Spec.Name)
+		}
+		e.statusLock.Unlock()
+		klog.V(4).InfoS("task run failed in hosts, continue with the rest hosts", "hosts", failedHosts, "task", ctrlclient.ObjectKeyFromObject(e.task))
 	}
+	// notify handlers
+	e.dealNotify()
 
 	return nil
 }
@@ -92,8 +122,19 @@ func (e taskExecutor) execTask(ctx context.Context) {
 	// check task host results
 	wg := &wait.Group{}
 	e.task.Status.HostResults = make([]kkcorev1alpha1.TaskHostResult, len(e.task.Spec.Hosts))
+	// limit the number of hosts which execute concurrently.
+	var throttle chan struct{}
+	if e.task.Spec.Throttle > 0 {
+		throttle = make(chan struct{}, e.task.Spec.Throttle)
+	}
 	for i, h := range e.task.Spec.Hosts {
-		wg.StartWithContext(ctx, e.execTaskHost(i, h))
+		wg.StartWithContext(ctx, func(ctx context.Context) {
+			if throttle != nil {
+				throttle <- struct{}{}
+				defer func() { <-throttle }()
+			}
+			e.execTaskHost(i, h)(ctx)
+		})
 	}
 	wg.Wait()
 	// host result for task
@@ -116,10 +157,17 @@ func (e taskExecutor) execTaskHost(i int, h string) func(ctx context.Context) {
 	return func(ctx context.Context) {
 		// task result
 		var stdout, stderr string
+		var changed bool
+		// the unified diff of files which changed in diff mode.
+		var diff strings.Builder
+		// the host which module actually execute in.
+		var delegateTo string
 		defer func() {
-			if err := e.dealRegister(stdout, stderr, h); err != nil {
+			if err := e.dealRegister(stdout, stderr, changed, e.factsHost(h, delegateTo)); err != nil {
 				stderr = err.Error()
 			}
+			// the decrypted vault values should not be logged or stored in task.
+			stdout, stderr = vault.Redact(stdout), vault.Redact(stderr)
 			if stderr != "" && e.task.Spec.IgnoreError != nil && *e.task.Spec.IgnoreError {
 				klog.V(5).ErrorS(nil, "task run failed", "host", h, "stdout", stdout, "stderr", stderr, "task", ctrlclient.ObjectKeyFromObject(e.task))
 			} else if stderr != "" {
@@ -127,13 +175,15 @@ func (e taskExecutor) execTaskHost(i int, h string) func(ctx context.Context) {
 			}
 			// fill result
 			e.task.Status.HostResults[i] = kkcorev1alpha1.TaskHostResult{
-				Host:   h,
-				Stdout: stdout,
-				StdErr: stderr,
+				Host:    h,
+				Stdout:  stdout,
+				StdErr:  stderr,
+				Changed: changed,
+				Diff:    vault.Redact(diff.String()),
 			}
 		}()
 		// task log
-		deferFunc := e.execTaskHostLogs(ctx, h, &stdout, &stderr)
+		deferFunc := e.execTaskHostLogs(ctx, h, &stdout, &stderr, &changed)
 		defer deferFunc()
 		// task execute
 		ha, err := e.variable.Get(variable.GetAllVariable(h))
@@ -152,21 +202,23 @@ func (e taskExecutor) execTaskHost(i int, h string) func(ctx context.Context) {
 			return
 		}
 		// execute module in loop with loop item.
-		// if loop is empty. execute once, and the item is null
+		// if loop is empty. execute once, and the item is not changed (it may be set by include loop).
 		for _, item := range e.dealLoop(had) {
 			// set item to runtime variable
-			if err := e.variable.Merge(variable.MergeRuntimeVariable(map[string]any{
-				_const.VariableItem: item,
-			}, h)); err != nil {
+			if err := e.dealLoopItem(item, h); err != nil {
 				stderr = fmt.Sprintf("set loop item to variable error: %v", err)
 
 				return
 			}
-			e.executeModule(ctx, e.task, h, &stdout, &stderr)
+			// delegate_to may contain item. parse it for each item.
+			if delegateTo, err = e.dealDelegateTo(h); err != nil {
+				stderr = err.Error()
+
+				return
+			}
+			e.executeModule(ctx, e.task, h, delegateTo, &stdout, &stderr, &changed, &diff)
 			// delete item
-			if err := e.variable.Merge(variable.MergeRuntimeVariable(map[string]any{
-				_const.VariableItem: nil,
-			}, h)); err != nil {
+			if err := e.dealLoopItem(nil, h); err != nil {
 				stderr = fmt.Sprintf("clean loop item to variable error: %v", err)
 
 				return
@@ -176,7 +228,7 @@ func (e taskExecutor) execTaskHost(i int, h string) func(ctx context.Context) {
 }
 
 // execTaskHostLogs logs for each host
-func (e taskExecutor) execTaskHostLogs(ctx context.Context, h string, stdout, stderr *string) func() {
+func (e taskExecutor) execTaskHostLogs(ctx context.Context, h string, stdout, stderr *string, changed *bool) func() {
 	// placeholder format task log
 	var placeholder string
 	if hostNameMaxLen, err := e.variable.Get(variable.GetHostMaxLength()); err == nil {
@@ -223,6 +275,8 @@ func (e taskExecutor) execTaskHostLogs(ctx context.Context, h string, stdout, st
 			}
 		case *stdout == modules.StdoutSkip: // skip
 			bar.Describe(fmt.Sprintf("[\033[36m%s\033[0m]%s \033[34mskip   \033[0m", h, placeholder))
+		case *changed: // changed
+			bar.Describe(fmt.Sprintf("[\033[36m%s\033[0m]%s \033[33mchanged\033[0m", h, placeholder))
 		default: //success
 			bar.Describe(fmt.Sprintf("[\033[36m%s\033[0m]%s \033[34msuccess\033[0m", h, placeholder))
 		}
@@ -248,8 +302,69 @@ func (e taskExecutor) dealLoop(ha map[string]any) []any {
 	return items
 }
 
+// dealLoopItem set the loop item to runtime variable. do nothing when loop is not set.
+func (e taskExecutor) dealLoopItem(item any, host string) error {
+	if e.task.Spec.Loop.Raw == nil {
+		return nil
+	}
+
+	return e.variable.Merge(variable.MergeRuntimeVariable(map[string]any{
+		_const.VariableItem: item,
+	}, host))
+}
+
 // executeModule find register module and execute it in a single host.
-func (e taskExecutor) executeModule(ctx context.Context, task *kkcorev1alpha1.Task, host string, stdout, stderr *string) {
+// the module will be retried until "until" condition is true (or module succeed if "until" is not set),
+// at most "retries" times, and wait "delay" seconds between each attempt.
+// the changed and diff are accumulated across loop items. only the diff of the last attempt is kept.
+func (e taskExecutor) executeModule(ctx context.Context, task *kkcorev1alpha1.Task, host, delegateTo string, stdout, stderr *string, changed *bool, diff *strings.Builder) {
+	retries, delay := e.task.Spec.Retries, time.Duration(e.task.Spec.Delay)*time.Second
+	if len(e.task.Spec.Until) > 0 {
+		if retries <= 0 {
+			retries = defaultUntilRetries
+		}
+		if delay <= 0 {
+			delay = defaultUntilDelay
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		var itemChanged bool
+		var itemDiff strings.Builder
+		e.executeModuleOnce(ctx, task, host, delegateTo, stdout, stderr, &itemChanged, &itemDiff)
+		done, err := e.dealUntil(e.factsHost(host, delegateTo), *stdout, *stderr, itemChanged)
+		if err != nil {
+			*stderr = err.Error()
+
+			return
+		}
+		if done {
+			*changed = itemChanged || *changed
+			diff.WriteString(itemDiff.String())
+
+			return
+		}
+		if attempt > retries {
+			if *stderr == "" {
+				*stderr = fmt.Sprintf("until condition is not satisfied after %d attempts", attempt)
+			}
+
+			return
+		}
+		klog.V(4).InfoS("retry task in host", "task", ctrlclient.ObjectKeyFromObject(e.task), "host", host, "attempt", attempt, "stderr", *stderr)
+		select {
+		case <-ctx.Done():
+			*stderr = fmt.Sprintf("task is canceled when retry: %v", ctx.Err())
+
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// executeModuleOnce execute module in a single host once.
+// the changed will be overridden by "changed_when" if set.
+func (e taskExecutor) executeModuleOnce(ctx context.Context, task *kkcorev1alpha1.Task, host, delegateTo string, stdout, stderr *string, changed *bool, diff *strings.Builder) {
 	// get all variable. which contains item.
 	ha, err := e.variable.Get(variable.GetAllVariable(host))
 	if err != nil {
@@ -268,13 +383,45 @@ func (e taskExecutor) executeModule(ctx context.Context, task *kkcorev1alpha1.Ta
 	if skip := e.dealFailedWhen(had, stdout, stderr); skip {
 		return
 	}
-	*stdout, *stderr = modules.FindModule(task.Spec.Module.Name)(ctx, modules.ExecOptions{
-		Args:     e.task.Spec.Module.Args,
-		Host:     host,
-		Variable: e.variable,
-		Task:     *e.task,
-		Pipeline: *e.pipeline,
+	// the pipeline status may be changed by other hosts concurrently in "free" strategy.
+	e.statusLock.Lock()
+	pipeline := *e.pipeline.DeepCopy()
+	e.statusLock.Unlock()
+	*stdout, *stderr, *changed = e.execModule(ctx, host, modules.FindModule(task.Spec.Module.Name), modules.ExecOptions{
+		Args:       e.task.Spec.Module.Args,
+		Host:       host,
+		DelegateTo: delegateTo,
+		Variable:   e.variable,
+		Task:       *e.task,
+		Pipeline:   pipeline,
+		Connectors: e.connectors,
+		DiffOutput: diff,
 	})
+	if *stderr != "" {
+		return
+	}
+	// check changed when condition
+	*changed = e.dealChangedWhen(e.factsHost(host, delegateTo), *stdout, *stderr, *changed)
+}
+
+// execModule execute module. the module and its commands are stopped by the context when "timeout" is reached,
+// and it returns after the module exits.
+func (e taskExecutor) execModule(ctx context.Context, host string, module modules.ModuleExecFunc, opts modules.ExecOptions) (string, string, bool) {
+	if e.task.Spec.Timeout <= 0 {
+		return module(ctx, opts)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(e.task.Spec.Timeout)*time.Second)
+	defer cancel()
+	stdout, stderr, changed := module(ctx, opts)
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return "", fmt.Sprintf("task timeout after %d seconds in host %s", e.task.Spec.Timeout, host), changed
+	case ctx.Err() != nil:
+		return "", fmt.Sprintf("task is canceled: %v", ctx.Err()), changed
+	default:
+		return stdout, stderr, changed
+	}
 }
 
 // dealWhen "when" argument in task.
@@ -318,8 +465,126 @@ func (e taskExecutor) dealFailedWhen(had map[string]any, stdout, stderr *string)
 	return false
 }
 
+// dealChangedWhen "changed_when" argument in task. it's evaluated after module executed, so the
+// registered result of current task can be used in condition.
+func (e taskExecutor) dealChangedWhen(host, stdout, stderr string, changed bool) bool {
+	if len(e.task.Spec.ChangedWhen) == 0 {
+		return changed
+	}
+	ok, err := e.parseResultCondition(host, stdout, stderr, changed, e.task.Spec.ChangedWhen)
+	if err != nil {
+		klog.V(5).ErrorS(err, "validate changed_when condition error", "task", ctrlclient.ObjectKeyFromObject(e.task))
+
+		return changed
+	}
+
+	return ok
+}
+
+// dealUntil "until" argument in task. it's evaluated after each attempt, so the registered result of
+// current attempt can be used in condition. if "until" is not set, the attempt is done when module succeed.
+func (e taskExecutor) dealUntil(host, stdout, stderr string, changed bool) (bool, error) {
+	if len(e.task.Spec.Until) == 0 {
+		return stderr == "", nil
+	}
+	ok, err := e.parseResultCondition(host, stdout, stderr, changed, e.task.Spec.Until)
+	if err != nil {
+		klog.V(5).ErrorS(err, "validate until condition error", "task", ctrlclient.ObjectKeyFromObject(e.task))
+
+		return false, fmt.Errorf("parse until condition error: %w", err)
+	}
+
+	return ok, nil
+}
+
+// parseResultCondition register the module result to host variable, and parse conditions with it.
+func (e taskExecutor) parseResultCondition(host, stdout, stderr string, changed bool, conditions []string) (bool, error) {
+	if err := e.dealRegister(stdout, stderr, changed, host); err != nil {
+		return false, err
+	}
+	ha, err := e.variable.Get(variable.GetAllVariable(host))
+	if err != nil {
+		return false, fmt.Errorf("failed to get host %s variable: %w", host, err)
+	}
+	had, ok := ha.(map[string]any)
+	if !ok {
+		return false, fmt.Errorf("host: %s variable is not a map", host)
+	}
+
+	return tmpl.ParseBool(had, conditions)
+}
+
+// dealDelegateTo "delegate_to" argument in task. parse the delegated host by the original host variables.
+func (e taskExecutor) dealDelegateTo(host string) (string, error) {
+	if e.task.Spec.DelegateTo == "" {
+		return "", nil
+	}
+	ha, err := e.variable.Get(variable.GetAllVariable(host))
+	if err != nil {
+		return "", fmt.Errorf("failed to get host %s variable: %w", host, err)
+	}
+	had, ok := ha.(map[string]any)
+	if !ok {
+		return "", fmt.Errorf("host: %s variable is not a map", host)
+	}
+	delegateTo, err := tmpl.ParseString(had, e.task.Spec.DelegateTo)
+	if err != nil {
+		klog.V(5).ErrorS(err, "parse delegate_to error", "task", ctrlclient.ObjectKeyFromObject(e.task))
+
+		return "", fmt.Errorf("parse delegate_to error: %w", err)
+	}
+
+	return delegateTo, nil
+}
+
+// factsHost is the host which facts (register) of task store to.
+// it's the delegated host when "delegate_facts" is set, otherwise is the original host.
+func (e taskExecutor) factsHost(host, delegateTo string) string {
+	if e.task.Spec.DelegateFacts && delegateTo != "" {
+		return delegateTo
+	}
+
+	return host
+}
+
+// dealDiff "diff" argument in task. print the unified diff of files in changed hosts.
+func (e taskExecutor) dealDiff() {
+	diff := e.pipeline.Spec.Diff
+	if e.task.Spec.Diff != nil {
+		diff = *e.task.Spec.Diff
+	}
+	if !diff {
+		return
+	}
+
+	for _, data := range e.task.Status.HostResults {
+		if !data.Changed || data.Diff == "" {
+			continue
+		}
+		fmt.Fprintf(e.logOutput, "[\033[36m%s\033[0m] diff:\n%s\n", data.Host, data.Diff)
+	}
+}
+
+// dealNotify "notify" argument in task. the hosts which changed by task will notify the handlers.
+func (e taskExecutor) dealNotify() {
+	if len(e.task.Spec.Notify) == 0 || e.notification == nil {
+		return
+	}
+
+	var hosts []string
+	for _, data := range e.task.Status.HostResults {
+		if data.StdErr != "" || !data.Changed {
+			continue
+		}
+		hosts = append(hosts, data.Host)
+	}
+	for _, name := range e.task.Spec.Notify {
+		e.notification.add(name, hosts...)
+	}
+}
+
 // dealRegister "register" argument in task.
-func (e taskExecutor) dealRegister(stdout, stderr, host string) error {
+func (e taskExecutor) dealRegister(stdout, stderr string, changed bool, host string) error {
 	if e.task.Spec.Register != "" {
 		var stdoutResult any = stdout
 		var stderrResult any = stderr
@@ -330,8 +595,9 @@ func (e taskExecutor) dealRegister(stdout, stderr, host string) error {
 		// set variable to parent location
 		if err := e.variable.Merge(variable.MergeRuntimeVariable(map[string]any{
 			e.task.Spec.Register: map[string]any{
-				"stdout": stdoutResult,
-				"stderr": stderrResult,
+				"stdout":  stdoutResult,
+				"stderr":  stderrResult,
+				"changed": changed,
 			},
 		}, host)); err != nil {
 			return fmt.Errorf("register task result to variable error: %w", err)
@@ -3,12 +3,15 @@ package executor
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	kkcorev1alpha1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1alpha1"
+	"github.com/kubesphere/kubekey/v4/pkg/variable"
 )
 
 func TestTaskExecutor(t *testing.T) {
@@ -69,3 +72,270 @@ func TestTaskExecutor(t *testing.T) {
 		})
 	}
 }
+
+func TestTaskExecutor_ChangedWhen(t *testing.T) {
+	testcases := []struct {
+		name        string
+		changedWhen []string
+		except      int
+	}{
+		{
+			name:   "debug module never changed",
+			except: 0,
+		},
+		{
+			name:        "changed_when true",
+			changedWhen: []string{"true"},
+			except:      1,
+		},
+		{
+			name:        "changed_when with register result",
+			changedWhen: []string{`{{ eq .result.stdout "hello" }}`},
+			except:      1,
+		},
+		{
+			name:        "changed_when false",
+			changedWhen: []string{`{{ .result.changed }}`},
+			except:      0,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			o, err := newTestOption()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := (&taskExecutor{
+				option: o,
+				task: &kkcorev1alpha1.Task{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test",
+						Namespace: corev1.NamespaceDefault,
+					},
+					Spec: kkcorev1alpha1.TaskSpec{
+						Hosts:       []string{"node1"},
+						ChangedWhen: tc.changedWhen,
+						Register:    "result",
+						Module: kkcorev1alpha1.Module{
+							Name: "debug",
+							Args: runtime.RawExtension{Raw: []byte(`{"msg":"hello"}`)},
+						},
+					},
+				},
+			}).Exec(context.TODO()); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tc.except, o.pipeline.Status.TaskResult.Changed)
+		})
+	}
+}
+
+func TestTaskExecutor_Until(t *testing.T) {
+	testcases := []struct {
+		name   string
+		until  []string
+		except bool
+	}{
+		{
+			name:   "until satisfied",
+			until:  []string{`{{ eq .result.stdout "hello" }}`},
+			except: true,
+		},
+		{
+			name:   "until not satisfied",
+			until:  []string{`{{ eq .result.stdout "world" }}`},
+			except: false,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			o, err := newTestOption()
+			if err != nil {
+				t.Fatal(err)
+			}
+			task := &kkcorev1alpha1.Task{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: corev1.NamespaceDefault,
+				},
+				Spec: kkcorev1alpha1.TaskSpec{
+					Hosts:    []string{"node1"},
+					Until:    tc.until,
+					Retries:  1,
+					Delay:    1,
+					Register: "result",
+					Module: kkcorev1alpha1.Module{
+						Name: "debug",
+						Args: runtime.RawExtension{Raw: []byte(`{"msg":"hello"}`)},
+					},
+				},
+			}
+
+			_ = (&taskExecutor{
+				option: o,
+				task:   task,
+			}).Exec(context.TODO())
+			assert.Equal(t, tc.except, task.IsSucceed())
+		})
+	}
+}
+
+func TestTaskExecutor_Timeout(t *testing.T) {
+	o, err := newTestOption()
+	if err != nil {
+		t.Fatal(err)
+	}
+	task := &kkcorev1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: corev1.NamespaceDefault,
+		},
+		Spec: kkcorev1alpha1.TaskSpec{
+			Hosts:    []string{"localhost"},
+			Timeout:  1,
+			Throttle: 1,
+			Module: kkcorev1alpha1.Module{
+				Name: "command",
+				Args: runtime.RawExtension{Raw: []byte("sleep 3")},
+			},
+		},
+	}
+
+	start := time.Now()
+	assert.Error(t, (&taskExecutor{
+		option: o,
+		task:   task,
+	}).Exec(context.TODO()))
+	assert.Equal(t, "task timeout after 1 seconds in host localhost", task.Status.HostResults[0].StdErr)
+	// the command is stopped when timeout.
+	assert.Less(t, time.Since(start), 3*time.Second)
+}
+
+func TestTaskExecutor_DelegateTo(t *testing.T) {
+	testcases := []struct {
+		name          string
+		delegateFacts bool
+		exceptHost    string
+	}{
+		{
+			name:       "register to original host",
+			exceptHost: "node1",
+		},
+		{
+			name:          "register to delegated host",
+			delegateFacts: true,
+			exceptHost:    "localhost",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			o, err := newTestOption()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := (&taskExecutor{
+				option: o,
+				task: &kkcorev1alpha1.Task{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test",
+						Namespace: corev1.NamespaceDefault,
+					},
+					Spec: kkcorev1alpha1.TaskSpec{
+						Hosts:         []string{"node1"},
+						DelegateTo:    "localhost",
+						DelegateFacts: tc.delegateFacts,
+						Register:      "result",
+						Module: kkcorev1alpha1.Module{
+							Name: "debug",
+							Args: runtime.RawExtension{Raw: []byte(`{"msg":"hello"}`)},
+						},
+					},
+				},
+			}).Exec(context.TODO()); err != nil {
+				t.Fatal(err)
+			}
+			for _, h := range []string{"node1", "localhost"} {
+				v, err := o.variable.Get(variable.GetAllVariable(h))
+				if err != nil {
+					t.Fatal(err)
+				}
+				vd, ok := v.(map[string]any)
+				if !ok {
+					t.Fatal("variable is not a map")
+				}
+				_, registered := vd["result"]
+				assert.Equal(t, tc.exceptHost == h, registered, "host %s", h)
+			}
+		})
+	}
+}
+
+func TestTaskExecutor_SetFact(t *testing.T) {
+	testcases := []struct {
+		name          string
+		delegateFacts bool
+		exceptHost    string
+	}{
+		{
+			name:       "set fact to original host",
+			exceptHost: "node1",
+		},
+		{
+			name:          "set fact to delegated host",
+			delegateFacts: true,
+			exceptHost:    "node2",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			o, err := newTestOption()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, h := range []string{"node1", "node2", "node3"} {
+				if err := o.variable.Merge(variable.MergeRuntimeVariable(map[string]any{"who": h}, h)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := (&taskExecutor{
+				option: o,
+				task: &kkcorev1alpha1.Task{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test",
+						Namespace: corev1.NamespaceDefault,
+					},
+					Spec: kkcorev1alpha1.TaskSpec{
+						Hosts:         []string{"node1"},
+						DelegateTo:    "node2",
+						DelegateFacts: tc.delegateFacts,
+						Module: kkcorev1alpha1.Module{
+							Name: "set_fact",
+							Args: runtime.RawExtension{Raw: []byte(`{"fact":"{{ .who }}"}`)},
+						},
+					},
+				},
+			}).Exec(context.TODO()); err != nil {
+				t.Fatal(err)
+			}
+			for _, h := range []string{"node1", "node2", "node3"} {
+				v, err := o.variable.Get(variable.GetAllVariable(h))
+				if err != nil {
+					t.Fatal(err)
+				}
+				vd, ok := v.(map[string]any)
+				if !ok {
+					t.Fatal("variable is not a map")
+				}
+				fact, set := vd["fact"]
+				assert.Equal(t, tc.exceptHost == h, set, "host %s", h)
+				if set {
+					// the fact is parsed by the original host.
+					assert.Equal(t, "node1", fact)
+				}
+			}
+		})
+	}
+}
@@ -57,39 +57,39 @@ func newAssertArgs(_ context.Context, raw runtime.RawExtension, vars map[string]
 }
 
 // ModuleAssert deal "assert" module
-func ModuleAssert(ctx context.Context, options ExecOptions) (string, string) {
+func ModuleAssert(ctx context.Context, options ExecOptions) (string, string, bool) {
 	// get host variable
 	ha, err := options.getAllVariables()
 	if err != nil {
-		return "", err.Error()
+		return "", err.Error(), false
 	}
 
 	aa, err := newAssertArgs(ctx, options.Args, ha)
 	if err != nil {
 		klog.V(4).ErrorS(err, "get assert args error", "task", ctrlclient.ObjectKeyFromObject(&options.Task))
 
-		return "", err.Error()
+		return "", err.Error(), false
 	}
 
 	ok, err := tmpl.ParseBool(ha, aa.that)
 	if err != nil {
-		return "", fmt.Sprintf("parse \"that\" error: %v", err)
+		return "", fmt.Sprintf("parse \"that\" error: %v", err), false
 	}
 	// condition is true
 	if ok {
 		r, err := tmpl.ParseString(ha, aa.successMsg)
 		if err == nil {
-			return r, ""
+			return r, "", false
 		}
 		klog.V(4).ErrorS(err, "parse \"success_msg\" error", "task", ctrlclient.ObjectKeyFromObject(&options.Task))
 
-		return StdoutTrue, ""
+		return StdoutTrue, "", false
 	}
 	// condition is false and fail_msg is not empty
 	if aa.failMsg != "" {
 		r, err := tmpl.ParseString(ha, aa.failMsg)
 		if err == nil {
-			return StdoutFalse, r
+			return StdoutFalse, r, false
 		}
 		klog.V(4).ErrorS(err, "parse \"fail_msg\" error", "task", ctrlclient.ObjectKeyFromObject(&options.Task))
 	}
@@ -97,10 +97,10 @@ func ModuleAssert(ctx context.Context, options ExecOptions) (string, string) {
 	if aa.msg != "" {
 		r, err := tmpl.ParseString(ha, aa.msg)
 		if err == nil {
-			return StdoutFalse, r
+			return StdoutFalse, r, false
 		}
 		klog.V(4).ErrorS(err, "parse \"msg\" error", "task", ctrlclient.ObjectKeyFromObject(&options.Task))
 	}
 
-	return StdoutFalse, "False"
+	return StdoutFalse, "False", false
 }
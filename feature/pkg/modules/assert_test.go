@@ -113,7 +113,7 @@ func TestAssert(t *testing.T) {
 			ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 			defer cancel()
 
-			acStdout, acStderr := ModuleAssert(ctx, tc.opt)
+			acStdout, acStderr, _ := ModuleAssert(ctx, tc.opt)
 			assert.Equal(t, tc.exceptStdout, acStdout)
 			assert.Equal(t, tc.exceptStderr, acStderr)
 		})
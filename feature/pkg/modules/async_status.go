@@ -0,0 +1,215 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/klog/v2"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubesphere/kubekey/v4/pkg/connector"
+	"github.com/kubesphere/kubekey/v4/pkg/variable"
+)
+
+// asyncJobDir is the directory in remote host which store the output of async jobs.
+const asyncJobDir = "$HOME/.kubekey_async"
+
+// defaultAsyncPoll is the poll seconds when "async" is set but "poll" is not.
+const defaultAsyncPoll = 10
+
+// asyncModules is the modules which can be run as async job.
+var asyncModules = []string{"command", "shell"}
+
+// SupportAsync check whether the module can be run as async job.
+func SupportAsync(moduleName string) bool {
+	return slices.Contains(asyncModules, moduleName)
+}
+
+// asyncJob is the status of async job. it's the stdout of async module.
+type asyncJob struct {
+	JobID    string `json:"job_id"`
+	Started  bool   `json:"started,omitempty"`
+	Finished bool   `json:"finished"`
+	RC       int    `json:"rc,omitempty"`
+	Stdout   string `json:"stdout,omitempty"`
+	Stderr   string `json:"stderr,omitempty"`
+}
+
+// String json format of asyncJob.
+func (j asyncJob) String() string {
+	data, err := json.Marshal(j)
+	if err != nil {
+		return j.JobID
+	}
+
+	return string(data)
+}
+
+// ModuleAsyncStatus deal "async_status" module. check the status of async job which started with "poll: 0".
+// the job is removed from host once it's reported finished.
+func ModuleAsyncStatus(ctx context.Context, options ExecOptions) (string, string, bool) {
+	// get host variable
+	ha, err := options.getAllVariables()
+	if err != nil {
+		return "", err.Error(), false
+	}
+	args := variable.Extension2Variables(options.Args)
+	jid, err := variable.StringVar(ha, args, "jid")
+	if err != nil {
+		return "", "\"jid\" should be string", false
+	}
+	// get connector
+	conn, err := options.getConnector(ctx)
+	if err != nil {
+		return "", fmt.Sprintf("get connector error: %v", err), false
+	}
+	defer conn.Close(ctx)
+
+	job, err := checkAsyncJob(ctx, conn, jid)
+	if err != nil {
+		return "", err.Error(), false
+	}
+	if job.Finished {
+		cleanAsyncJob(ctx, conn, options, jid)
+	}
+	if job.Finished && job.RC != 0 {
+		return job.String(), asyncJobError(job), true
+	}
+
+	return job.String(), "", job.Finished
+}
+
+// execAsyncCommand start the command detached in host, and wait it finished by polling its status.
+// if poll is 0, return the job id after started, the status can be checked by "async_status" module.
+func execAsyncCommand(ctx context.Context, conn connector.Connector, options ExecOptions, command string) (string, string, bool) {
+	jid, err := startAsyncJob(ctx, conn, command, options.Task.Spec.Async)
+	if err != nil {
+		return "", err.Error(), false
+	}
+	poll := defaultAsyncPoll
+	if options.Task.Spec.Poll != nil {
+		poll = *options.Task.Spec.Poll
+	}
+	if poll <= 0 {
+		return asyncJob{JobID: jid, Started: true}.String(), "", true
+	}
+
+	deadline := time.Now().Add(time.Duration(options.Task.Spec.Async+poll) * time.Second)
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return "", fmt.Sprintf("wait async job %s error: %v", jid, ctx.Err()), false
+		case <-time.After(time.Duration(poll) * time.Second):
+		}
+		// connect for each poll. the job is still running in host when connection dropped.
+		pconn, err := options.getConnector(ctx)
+		if err != nil {
+			klog.V(4).ErrorS(err, "get connector error when poll async job", "job", jid, "task", ctrlclient.ObjectKeyFromObject(&options.Task))
+
+			continue
+		}
+		job, err := checkAsyncJob(ctx, pconn, jid)
+		if err != nil || !job.Finished {
+			pconn.Close(ctx)
+
+			continue
+		}
+		cleanAsyncJob(ctx, pconn, options, jid)
+		pconn.Close(ctx)
+		if job.RC != 0 {
+			return job.Stdout, asyncJobError(job), false
+		}
+
+		return job.Stdout, "", true
+	}
+
+	return "", fmt.Sprintf("async job %s is not finished in %d seconds", jid, options.Task.Spec.Async), false
+}
+
+// startAsyncJob run command detached in host. the command will be killed when reach timeout seconds.
+// return the job id.
+func startAsyncJob(ctx context.Context, conn connector.Connector, command string, timeout int) (string, error) {
+	jid := rand.String(12)
+	dir := asyncJobDir + "/" + jid
+	script := fmt.Sprintf(`mkdir -p %[1]s && cat > %[1]s/cmd <<'KUBEKEY_ASYNC_EOF'
+%[2]s
+KUBEKEY_ASYNC_EOF
+nohup sh -c 'timeout %[3]d sh %[1]s/cmd > %[1]s/stdout 2> %[1]s/stderr; echo $? > %[1]s/rc' > /dev/null 2>&1 &`, dir, command, timeout)
+	if _, err := conn.ExecuteCommand(ctx, script); err != nil {
+		return "", fmt.Errorf("start async job error: %w", err)
+	}
+
+	return jid, nil
+}
+
+// checkAsyncJob get the status of async job in host.
+func checkAsyncJob(ctx context.Context, conn connector.Connector, jid string) (*asyncJob, error) {
+	dir := asyncJobDir + "/" + jid
+	if _, err := conn.ExecuteCommand(ctx, "test -d "+dir); err != nil {
+		return nil, fmt.Errorf("async job %s is not found", jid)
+	}
+
+	job := &asyncJob{JobID: jid}
+	rc, err := conn.ExecuteCommand(ctx, "cat "+dir+"/rc 2>/dev/null || true")
+	if err != nil {
+		return nil, fmt.Errorf("read return code of async job %s error: %w", jid, err)
+	}
+	if strings.TrimSpace(string(rc)) == "" {
+		// rc file is written after the job finished.
+		return job, nil
+	}
+	if job.RC, err = strconv.Atoi(strings.TrimSpace(string(rc))); err != nil {
+		return nil, fmt.Errorf("parse return code of async job %s error: %w", jid, err)
+	}
+	job.Finished = true
+	stdout, err := conn.ExecuteCommand(ctx, "cat "+dir+"/stdout")
+	if err != nil {
+		return nil, fmt.Errorf("read stdout of async job %s error: %w", jid, err)
+	}
+	job.Stdout = strings.TrimSuffix(string(stdout), "\n")
+	stderr, err := conn.ExecuteCommand(ctx, "cat "+dir+"/stderr")
+	if err != nil {
+		return nil, fmt.Errorf("read stderr of async job %s error: %w", jid, err)
+	}
+	job.Stderr = strings.TrimSuffix(string(stderr), "\n")
+
+	return job, nil
+}
+
+// cleanAsyncJob remove the directory of finished async job in host.
+func cleanAsyncJob(ctx context.Context, conn connector.Connector, options ExecOptions, jid string) {
+	if _, err := conn.ExecuteCommand(ctx, "rm -rf "+asyncJobDir+"/"+jid); err != nil {
+		klog.V(4).ErrorS(err, "clean async job error", "job", jid, "task", ctrlclient.ObjectKeyFromObject(&options.Task))
+	}
+}
+
+// asyncJobError is the stderr of failed async job.
+func asyncJobError(job *asyncJob) string {
+	if job.Stderr != "" {
+		return job.Stderr
+	}
+
+	return fmt.Sprintf("async job %s exit with code %d", job.JobID, job.RC)
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+
+	kkcorev1alpha1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1alpha1"
+	"github.com/kubesphere/kubekey/v4/pkg/connector"
+)
+
+func TestAsyncCommand(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	conn, err := connector.NewConnector("localhost", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name         string
+		command      string
+		exceptStdout string
+		exceptStderr string
+	}{
+		{
+			name:         "async command success",
+			command:      "echo hello",
+			exceptStdout: "hello",
+		},
+		{
+			name:         "async command failed",
+			command:      "echo hello >&2; exit 1",
+			exceptStderr: "hello",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), ConnKey, conn), time.Second*10)
+			defer cancel()
+
+			stdout, stderr, _ := ModuleCommand(ctx, ExecOptions{
+				Host:     "localhost",
+				Args:     runtime.RawExtension{Raw: []byte(tc.command)},
+				Variable: &testVariable{},
+				Task: kkcorev1alpha1.Task{Spec: kkcorev1alpha1.TaskSpec{
+					Async: 5,
+					Poll:  ptr.To(1),
+				}},
+			})
+			assert.Equal(t, tc.exceptStdout, stdout)
+			assert.Equal(t, tc.exceptStderr, stderr)
+		})
+	}
+}
+
+func TestAsyncStatus(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	conn, err := connector.NewConnector("localhost", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), ConnKey, conn), time.Second*10)
+	defer cancel()
+
+	// fire and forget
+	stdout, stderr, _ := ModuleCommand(ctx, ExecOptions{
+		Host:     "localhost",
+		Args:     runtime.RawExtension{Raw: []byte("sleep 1; echo hello")},
+		Variable: &testVariable{},
+		Task: kkcorev1alpha1.Task{Spec: kkcorev1alpha1.TaskSpec{
+			Async: 5,
+			Poll:  ptr.To(0),
+		}},
+	})
+	assert.Empty(t, stderr)
+	var job asyncJob
+	if err := json.Unmarshal([]byte(stdout), &job); err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, job.Started)
+
+	testcases := []struct {
+		name           string
+		jid            string
+		wait           time.Duration
+		exceptFinished bool
+		exceptStderr   string
+	}{
+		{
+			name: "job is running",
+			jid:  job.JobID,
+		},
+		{
+			name:           "job is finished",
+			jid:            job.JobID,
+			wait:           2 * time.Second,
+			exceptFinished: true,
+		},
+		{
+			name:         "finished job is removed",
+			jid:          job.JobID,
+			exceptStderr: fmt.Sprintf("async job %s is not found", job.JobID),
+		},
+		{
+			name:         "job is not found",
+			jid:          "not-exist",
+			exceptStderr: "async job not-exist is not found",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			time.Sleep(tc.wait)
+			stdout, stderr, finished := ModuleAsyncStatus(ctx, ExecOptions{
+				Host:     "localhost",
+				Args:     runtime.RawExtension{Raw: []byte(fmt.Sprintf(`{"jid": %q}`, tc.jid))},
+				Variable: &testVariable{},
+			})
+			assert.Equal(t, tc.exceptStderr, stderr)
+			assert.Equal(t, tc.exceptFinished, finished)
+			if finished {
+				assert.Contains(t, stdout, `"stdout":"hello"`)
+			}
+		})
+	}
+}
@@ -17,39 +17,118 @@ limitations under the License.
 package modules
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"strings"
 
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/kubesphere/kubekey/v4/pkg/connector"
 	"github.com/kubesphere/kubekey/v4/pkg/variable"
 )
 
+type commandArgs struct {
+	cmd string
+	// creates skip the command when the path exists in host.
+	creates string
+	// removes skip the command when the path does not exist in host.
+	removes string
+}
+
+// newCommandArgs parse the args of command. it's the command string, or a map with "cmd", "creates" and "removes".
+func newCommandArgs(_ context.Context, raw runtime.RawExtension, vars map[string]any) (*commandArgs, error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(raw.Raw), []byte("{")) {
+		cmd, err := variable.Extension2String(vars, raw)
+		if err != nil {
+			return nil, err
+		}
+
+		return &commandArgs{cmd: cmd}, nil
+	}
+
+	var err error
+	ca := &commandArgs{}
+	args := variable.Extension2Variables(raw)
+	ca.cmd, err = variable.StringVar(vars, args, "cmd")
+	if err != nil {
+		return nil, errors.New("\"cmd\" in args should be string")
+	}
+	ca.creates, _ = variable.StringVar(vars, args, "creates")
+	ca.removes, _ = variable.StringVar(vars, args, "removes")
+
+	return ca, nil
+}
+
+// skip returns true if the command should not be executed because of "creates" or "removes".
+func (ca commandArgs) skip(ctx context.Context, conn connector.Connector) (bool, error) {
+	if ca.creates != "" {
+		exist, err := pathExists(ctx, conn, ca.creates)
+		if err != nil || exist {
+			return exist, err
+		}
+	}
+	if ca.removes != "" {
+		exist, err := pathExists(ctx, conn, ca.removes)
+		if err != nil || !exist {
+			return !exist, err
+		}
+	}
+
+	return false, nil
+}
+
 // ModuleCommand deal "command" module.
-func ModuleCommand(ctx context.Context, options ExecOptions) (string, string) {
+func ModuleCommand(ctx context.Context, options ExecOptions) (string, string, bool) {
 	// get host variable
 	ha, err := options.getAllVariables()
 	if err != nil {
-		return "", err.Error()
+		return "", err.Error(), false
 	}
 	// get connector
-	conn, err := getConnector(ctx, options.Host, ha)
+	conn, err := options.getConnector(ctx)
 	if err != nil {
-		return "", err.Error()
+		return "", err.Error(), false
 	}
 	defer conn.Close(ctx)
-	// command string
-	command, err := variable.Extension2String(ha, options.Args)
+	ca, err := newCommandArgs(ctx, options.Args, ha)
 	if err != nil {
-		return "", err.Error()
+		return "", err.Error(), false
+	}
+	// the command has been executed before. it's checked in check mode too, which does not change the host.
+	if skip, err := ca.skip(ctx, conn); err != nil {
+		return "", err.Error(), false
+	} else if skip {
+		return StdoutSkip, "", false
+	}
+	// the changes of command cannot be predicted. skip it in check mode unless "check_mode: false" is set.
+	if options.checkMode() {
+		return StdoutSkip, "", false
+	}
+	// execute command detached in host
+	if options.Task.Spec.Async > 0 {
+		return execAsyncCommand(ctx, conn, options, ca.cmd)
 	}
 	// execute command
 	var stdout, stderr string
-	data, err := conn.ExecuteCommand(ctx, command)
+	data, err := conn.ExecuteCommand(ctx, ca.cmd)
 	if err != nil {
 		stderr = err.Error()
 	}
 	if data != nil {
 		stdout = strings.TrimSuffix(string(data), "\n")
 	}
+	// command is considered to change the host when it's executed. use "changed_when" to override it.
+	return stdout, stderr, stderr == ""
+}
+
+// pathExists check whether path exists in host.
+func pathExists(ctx context.Context, conn connector.Connector, path string) (bool, error) {
+	output, err := conn.ExecuteCommand(ctx, fmt.Sprintf("if [ -e %s ]; then echo exist; fi", connector.ShellQuote(path)))
+	if err != nil {
+		return false, fmt.Errorf("check path %s error: %w", path, err)
+	}
 
-	return stdout, stderr
+	return strings.TrimSpace(string(output)) == "exist", nil
 }
@@ -18,11 +18,19 @@ package modules
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+
+	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+	kkcorev1alpha1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1alpha1"
+	"github.com/kubesphere/kubekey/v4/pkg/connector"
 )
 
 func TestCommand(t *testing.T) {
@@ -63,6 +71,33 @@ func TestCommand(t *testing.T) {
 			},
 			exceptStderr: "failed",
 		},
+		{
+			name: "skip command in check mode",
+			ctxFunc: func() context.Context {
+				return context.WithValue(context.Background(), ConnKey, successConnector)
+			},
+			opt: ExecOptions{
+				Host:     "test",
+				Args:     runtime.RawExtension{Raw: []byte("echo success")},
+				Variable: &testVariable{},
+				Pipeline: kkcorev1.Pipeline{Spec: kkcorev1.PipelineSpec{CheckMode: true}},
+			},
+			exceptStdout: "skip",
+		},
+		{
+			name: "exec command in check mode with check_mode false",
+			ctxFunc: func() context.Context {
+				return context.WithValue(context.Background(), ConnKey, successConnector)
+			},
+			opt: ExecOptions{
+				Host:     "test",
+				Args:     runtime.RawExtension{Raw: []byte("echo success")},
+				Variable: &testVariable{},
+				Task:     kkcorev1alpha1.Task{Spec: kkcorev1alpha1.TaskSpec{CheckMode: ptr.To(false)}},
+				Pipeline: kkcorev1.Pipeline{Spec: kkcorev1.PipelineSpec{CheckMode: true}},
+			},
+			exceptStdout: "success",
+		},
 	}
 
 	for _, tc := range testcases {
@@ -70,9 +105,77 @@ func TestCommand(t *testing.T) {
 			ctx, cancel := context.WithTimeout(tc.ctxFunc(), time.Second*5)
 			defer cancel()
 
-			acStdout, acStderr := ModuleCommand(ctx, tc.opt)
+			acStdout, acStderr, _ := ModuleCommand(ctx, tc.opt)
 			assert.Equal(t, tc.exceptStdout, acStdout)
 			assert.Equal(t, tc.exceptStderr, acStderr)
 		})
 	}
 }
+
+func TestCommand_CreatesRemoves(t *testing.T) {
+	dir := t.TempDir()
+	exist := filepath.Join(dir, "exist")
+	if err := os.WriteFile(exist, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing")
+	conn, err := connector.NewConnector("localhost", map[string]any{"type": "local"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(context.Background(), ConnKey, conn)
+
+	testcases := []struct {
+		name          string
+		args          string
+		checkMode     bool
+		exceptStdout  string
+		exceptChanged bool
+	}{
+		{
+			name:          "creates exists",
+			args:          fmt.Sprintf(`{"cmd": "echo success", "creates": %q}`, exist),
+			exceptStdout:  "skip",
+			exceptChanged: false,
+		},
+		{
+			name:          "creates not exists",
+			args:          fmt.Sprintf(`{"cmd": "echo success", "creates": %q}`, missing),
+			exceptStdout:  "success",
+			exceptChanged: true,
+		},
+		{
+			name:          "removes exists",
+			args:          fmt.Sprintf(`{"cmd": "echo success", "removes": %q}`, exist),
+			exceptStdout:  "success",
+			exceptChanged: true,
+		},
+		{
+			name:          "removes not exists",
+			args:          fmt.Sprintf(`{"cmd": "echo success", "removes": %q}`, missing),
+			exceptStdout:  "skip",
+			exceptChanged: false,
+		},
+		{
+			name:          "creates exists in check mode",
+			args:          fmt.Sprintf(`{"cmd": "echo success", "creates": %q}`, exist),
+			checkMode:     true,
+			exceptStdout:  "skip",
+			exceptChanged: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			stdout, stderr, changed := ModuleCommand(ctx, ExecOptions{
+				Host:     "localhost",
+				Args:     runtime.RawExtension{Raw: []byte(tc.args)},
+				Variable: &testVariable{},
+				Pipeline: kkcorev1.Pipeline{Spec: kkcorev1.PipelineSpec{CheckMode: tc.checkMode}},
+			})
+			assert.Equal(t, tc.exceptStdout, stdout)
+			assert.Empty(t, stderr)
+			assert.Equal(t, tc.exceptChanged, changed)
+		})
+	}
+}
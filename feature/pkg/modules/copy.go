@@ -41,6 +41,8 @@ type copyArgs struct {
 	content string
 	dest    string
 	mode    *int
+	owner   string
+	group   string
 }
 
 func newCopyArgs(_ context.Context, raw runtime.RawExtension, vars map[string]any) (*copyArgs, error) {
@@ -54,108 +56,118 @@ func newCopyArgs(_ context.Context, raw runtime.RawExtension, vars map[string]an
 		return nil, errors.New("\"dest\" in args should be string")
 	}
 	ca.mode, _ = variable.IntVar(vars, args, "mode")
+	ca.owner, _ = variable.StringVar(vars, args, "owner")
+	ca.group, _ = variable.StringVar(vars, args, "group")
 
 	return ca, nil
 }
 
+// fileOption of dest file. keep the owner of replaced file if owner and group are not set.
+func (ca copyArgs) fileOption(mode fs.FileMode) connector.FileOption {
+	return connector.FileOption{Mode: mode, Owner: ca.owner, Group: ca.group}
+}
+
 // ModuleCopy deal "copy" module
-func ModuleCopy(ctx context.Context, options ExecOptions) (string, string) {
+func ModuleCopy(ctx context.Context, options ExecOptions) (string, string, bool) {
 	// get host variable
 	ha, err := options.getAllVariables()
 	if err != nil {
-		return "", err.Error()
+		return "", err.Error(), false
 	}
 
 	ca, err := newCopyArgs(ctx, options.Args, ha)
 	if err != nil {
 		klog.V(4).ErrorS(err, "get copy args error", "task", ctrlclient.ObjectKeyFromObject(&options.Task))
 
-		return "", err.Error()
+		return "", err.Error(), false
 	}
 
 	// get connector
-	conn, err := getConnector(ctx, options.Host, ha)
+	conn, err := options.getConnector(ctx)
 	if err != nil {
-		return "", fmt.Sprintf("get connector error: %v", err)
+		return "", fmt.Sprintf("get connector error: %v", err), false
 	}
 	defer conn.Close(ctx)
 
+	var stdout, stderr string
+	var changed bool
 	switch {
 	case ca.src != "": // copy local file to remote
-		return ca.copySrc(ctx, options, conn)
+		stdout, stderr, changed = ca.copySrc(ctx, options, conn)
 	case ca.content != "":
-		return ca.copyContent(ctx, os.ModePerm, conn)
+		stdout, stderr, changed = ca.copyContent(ctx, os.ModePerm, conn)
 	default:
-		return "", "either \"src\" or \"content\" must be provided."
+		return "", "either \"src\" or \"content\" must be provided.", false
 	}
+
+	writeDiff(conn, options)
+
+	return stdout, stderr, changed
 }
 
 // copySrc copy src file to dest
-func (ca copyArgs) copySrc(ctx context.Context, options ExecOptions, conn connector.Connector) (string, string) {
+func (ca copyArgs) copySrc(ctx context.Context, options ExecOptions, conn connector.Connector) (string, string, bool) {
+	var changed bool
 	if filepath.IsAbs(ca.src) { // if src is absolute path. find it in local path
 		fileInfo, err := os.Stat(ca.src)
 		if err != nil {
-			return "", fmt.Sprintf(" get src file %s in local path error: %v", ca.src, err)
+			return "", fmt.Sprintf(" get src file %s in local path error: %v", ca.src, err), false
 		}
 
 		if fileInfo.IsDir() { // src is dir
-			if err := ca.absDir(ctx, conn); err != nil {
-				return "", fmt.Sprintf("sync copy absolute dir error %s", err)
+			if changed, err = ca.absDir(ctx, conn); err != nil {
+				return "", fmt.Sprintf("sync copy absolute dir error %s", err), false
 			}
 		} else { // src is file
-			if err := ca.absFile(ctx, fileInfo.Mode(), conn); err != nil {
-				return "", fmt.Sprintf("sync copy absolute dir error %s", err)
+			if changed, err = ca.absFile(ctx, fileInfo.Mode(), conn); err != nil {
+				return "", fmt.Sprintf("sync copy absolute dir error %s", err), false
 			}
 		}
 	} else { // if src is not absolute path. find file in project
 		pj, err := project.New(ctx, options.Pipeline, false)
 		if err != nil {
-			return "", fmt.Sprintf("get project error: %v", err)
+			return "", fmt.Sprintf("get project error: %v", err), false
 		}
 
 		fileInfo, err := pj.Stat(ca.src, project.GetFileOption{IsFile: true, Role: options.Task.Annotations[kkcorev1alpha1.TaskAnnotationRole]})
 		if err != nil {
-			return "", fmt.Sprintf("get file %s from project error %v", ca.src, err)
+			return "", fmt.Sprintf("get file %s from project error %v", ca.src, err), false
 		}
 
 		if fileInfo.IsDir() {
-			if err := ca.relDir(ctx, pj, options.Task.Annotations[kkcorev1alpha1.TaskAnnotationRole], conn); err != nil {
-				return "", fmt.Sprintf("sync copy relative dir error %s", err)
+			if changed, err = ca.relDir(ctx, pj, options.Task.Annotations[kkcorev1alpha1.TaskAnnotationRole], conn); err != nil {
+				return "", fmt.Sprintf("sync copy relative dir error %s", err), false
 			}
 		} else {
-			if err := ca.relFile(ctx, pj, options.Task.Annotations[kkcorev1alpha1.TaskAnnotationRole], fileInfo.Mode(), conn); err != nil {
-				return "", fmt.Sprintf("sync copy relative dir error %s", err)
+			if changed, err = ca.relFile(ctx, pj, options.Task.Annotations[kkcorev1alpha1.TaskAnnotationRole], fileInfo.Mode(), conn); err != nil {
+				return "", fmt.Sprintf("sync copy relative dir error %s", err), false
 			}
 		}
 	}
 
-	return StdoutSuccess, ""
+	return StdoutSuccess, "", changed
 }
 
 // copyContent convert content param and copy to dest
-func (ca copyArgs) copyContent(ctx context.Context, mode fs.FileMode, conn connector.Connector) (string, string) {
+func (ca copyArgs) copyContent(ctx context.Context, mode fs.FileMode, conn connector.Connector) (string, string, bool) {
 	if strings.HasSuffix(ca.dest, "/") {
-		return "", "\"content\" should copy to a file"
+		return "", "\"content\" should copy to a file", false
 	}
 
 	if ca.mode != nil {
 		mode = os.FileMode(*ca.mode)
 	}
 
-	if err := conn.PutFile(ctx, []byte(ca.content), ca.dest, mode); err != nil {
-		return "", fmt.Sprintf("copy file error: %v", err)
+	changed, err := putFile(ctx, conn, []byte(ca.content), ca.dest, ca.fileOption(mode))
+	if err != nil {
+		return "", fmt.Sprintf("copy file error: %v", err), false
 	}
 
-	return StdoutSuccess, ""
+	return StdoutSuccess, "", changed
 }
 
 // relFile when copy.src is relative dir, get all files from project, and copy to remote.
-func (ca copyArgs) relFile(ctx context.Context, pj project.Project, role string, mode fs.FileMode, conn connector.Connector) any {
-	data, err := pj.ReadFile(ca.src, project.GetFileOption{IsFile: true, Role: role})
-	if err != nil {
-		return fmt.Errorf("read file error: %w", err)
-	}
-
+func (ca copyArgs) relFile(ctx context.Context, pj project.Project, role string, mode fs.FileMode, conn connector.Connector) (bool, error) {
 	dest := ca.dest
 	if strings.HasSuffix(ca.dest, "/") {
 		dest = filepath.Join(ca.dest, filepath.Base(ca.src))
@@ -165,15 +177,17 @@ func (ca copyArgs) relFile(ctx context.Context, pj project.Project, role string,
 		mode = os.FileMode(*ca.mode)
 	}
 
-	if err := conn.PutFile(ctx, data, dest, mode); err != nil {
-		return fmt.Errorf("copy file error: %w", err)
+	changed, err := putProjectFile(ctx, conn, pj, ca.src, project.GetFileOption{IsFile: true, Role: role}, dest, ca.fileOption(mode))
+	if err != nil {
+		return false, fmt.Errorf("copy file error: %w", err)
 	}
 
-	return nil
+	return changed, nil
 }
 
 // relDir when copy.src is relative dir, get all files from project, and copy to remote.
-func (ca copyArgs) relDir(ctx context.Context, pj project.Project, role string, conn connector.Connector) error {
+func (ca copyArgs) relDir(ctx context.Context, pj project.Project, role string, conn connector.Connector) (bool, error) {
+	var changed bool
 	if err := pj.WalkDir(ca.src, project.GetFileOption{IsFile: true, Role: role}, func(path string, d fs.DirEntry, err error) error {
 		if d.IsDir() { // only copy file
 			return nil
@@ -192,39 +206,31 @@ func (ca copyArgs) relDir(ctx context.Context, pj project.Project, role string,
 			mode = os.FileMode(*ca.mode)
 		}
 
-		data, err := pj.ReadFile(path, project.GetFileOption{Role: role})
-		if err != nil {
-			return fmt.Errorf("read file error: %w", err)
-		}
-
 		dest := ca.dest
 		if strings.HasSuffix(ca.dest, "/") {
-			rel, err := pj.Rel(ca.src, path, project.GetFileOption{Role: role})
+			rel, err := pj.Rel(ca.src, path, project.GetFileOption{IsFile: true, Role: role})
 			if err != nil {
 				return fmt.Errorf("get relative file path error: %w", err)
 			}
 			dest = filepath.Join(ca.dest, rel)
 		}
 
-		if err := conn.PutFile(ctx, data, dest, mode); err != nil {
+		fileChanged, err := putProjectFile(ctx, conn, pj, path, project.GetFileOption{Role: role}, dest, ca.fileOption(mode))
+		if err != nil {
 			return fmt.Errorf("copy file error: %w", err)
 		}
+		changed = changed || fileChanged
 
 		return nil
 	}); err != nil {
-		return err
+		return false, err
 	}
 
-	return nil
+	return changed, nil
 }
 
 // absFile when copy.src is absolute file, get file from os, and copy to remote.
-func (ca copyArgs) absFile(ctx context.Context, mode fs.FileMode, conn connector.Connector) error {
-	data, err := os.ReadFile(ca.src)
-	if err != nil {
-		return fmt.Errorf("read file error: %w", err)
-	}
-
+func (ca copyArgs) absFile(ctx context.Context, mode fs.FileMode, conn connector.Connector) (bool, error) {
 	dest := ca.dest
 	if strings.HasSuffix(ca.dest, "/") {
 		dest = filepath.Join(ca.dest, filepath.Base(ca.src))
@@ -234,15 +240,17 @@ func (ca copyArgs) absFile(ctx context.Context, mode fs.FileMode, conn connector
 		mode = os.FileMode(*ca.mode)
 	}
 
-	if err := conn.PutFile(ctx, data, dest, mode); err != nil {
-		return fmt.Errorf("copy file error: %w", err)
+	changed, err := putLocalFile(ctx, conn, ca.src, dest, ca.fileOption(mode))
+	if err != nil {
+		return false, fmt.Errorf("copy file error: %w", err)
 	}
 
-	return nil
+	return changed, nil
 }
 
 // absDir when copy.src is absolute dir, get all files from os, and copy to remote.
-func (ca copyArgs) absDir(ctx context.Context, conn connector.Connector) error {
+func (ca copyArgs) absDir(ctx context.Context, conn connector.Connector) (bool, error) {
+	var changed bool
 	if err := filepath.WalkDir(ca.src, func(path string, d fs.DirEntry, err error) error {
 		if d.IsDir() { // only copy file
 			return nil
@@ -261,11 +269,6 @@ func (ca copyArgs) absDir(ctx context.Context, conn connector.Connector) error {
 		if ca.mode != nil {
 			mode = os.FileMode(*ca.mode)
 		}
-		// read file
-		data, err := os.ReadFile(path)
-		if err != nil {
-			return fmt.Errorf("read file error: %w", err)
-		}
 		// copy file to remote
 		dest := ca.dest
 		if strings.HasSuffix(ca.dest, "/") {
@@ -276,14 +279,16 @@ func (ca copyArgs) absDir(ctx context.Context, conn connector.Connector) error {
 			dest = filepath.Join(ca.dest, rel)
 		}
 
-		if err := conn.PutFile(ctx, data, dest, mode); err != nil {
+		fileChanged, err := putLocalFile(ctx, conn, path, dest, ca.fileOption(mode))
+		if err != nil {
 			return fmt.Errorf("copy file error: %w", err)
 		}
+		changed = changed || fileChanged
 
 		return nil
 	}); err != nil {
-		return err
+		return false, err
 	}
 
-	return nil
+	return changed, nil
 }
@@ -18,11 +18,18 @@ package modules
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/runtime"
+
+	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+	"github.com/kubesphere/kubekey/v4/pkg/connector"
 )
 
 func TestCopy(t *testing.T) {
@@ -32,6 +39,7 @@ func TestCopy(t *testing.T) {
 		ctxFunc      func() context.Context
 		exceptStdout string
 		exceptStderr string
+		exceptDiff   string
 	}{
 		{
 			name: "src and content is empty",
@@ -103,6 +111,37 @@ func TestCopy(t *testing.T) {
 			},
 			exceptStderr: "copy file error: failed",
 		},
+		{
+			name: "copy in check mode",
+			opt: ExecOptions{
+				Args: runtime.RawExtension{
+					Raw: []byte(`{"content": "hello world", "dest": "/etc/test.txt"}`),
+				},
+				Host:     "local",
+				Variable: &testVariable{},
+				Pipeline: kkcorev1.Pipeline{Spec: kkcorev1.PipelineSpec{CheckMode: true}},
+			},
+			ctxFunc: func() context.Context {
+				return context.WithValue(context.Background(), ConnKey, failedConnector)
+			},
+			exceptStdout: "success",
+		},
+		{
+			name: "copy in check mode and diff mode",
+			opt: ExecOptions{
+				Args: runtime.RawExtension{
+					Raw: []byte(`{"content": "hello world", "dest": "/etc/test.txt"}`),
+				},
+				Host:     "local",
+				Variable: &testVariable{},
+				Pipeline: kkcorev1.Pipeline{Spec: kkcorev1.PipelineSpec{CheckMode: true, Diff: true}},
+			},
+			ctxFunc: func() context.Context {
+				return context.WithValue(context.Background(), ConnKey, failedConnector)
+			},
+			exceptStdout: "success",
+			exceptDiff:   "--- /dev/null\n+++ /etc/test.txt\n@@ -0,0 +1 @@\n+hello world\n",
+		},
 	}
 
 	for _, tc := range testcases {
@@ -110,9 +149,149 @@ func TestCopy(t *testing.T) {
 			ctx, cancel := context.WithTimeout(tc.ctxFunc(), time.Second*5)
 			defer cancel()
 
-			acStdout, acStderr := ModuleCopy(ctx, tc.opt)
+			diff := &strings.Builder{}
+			tc.opt.DiffOutput = diff
+			acStdout, acStderr, _ := ModuleCopy(ctx, tc.opt)
 			assert.Equal(t, tc.exceptStdout, acStdout)
 			assert.Equal(t, tc.exceptStderr, acStderr)
+			assert.Equal(t, tc.exceptDiff, diff.String())
 		})
 	}
 }
+
+func TestCopy_AbsFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	if err := os.WriteFile(src, []byte("hello world"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dest := filepath.Join(dir, "dest", "test.txt")
+	conn, err := connector.NewConnector("localhost", map[string]any{"type": "local"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(context.Background(), ConnKey, conn)
+	opt := ExecOptions{
+		Args: runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(`{"src": %q, "dest": %q, "mode": 384}`, src, dest)),
+		},
+		Host:     "localhost",
+		Variable: &testVariable{},
+	}
+
+	stdout, stderr, changed := ModuleCopy(ctx, opt)
+	assert.Equal(t, StdoutSuccess, stdout)
+	assert.Empty(t, stderr)
+	assert.True(t, changed)
+	data, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "hello world", string(data))
+	info, err := os.Stat(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// the file is not uploaded again when the checksum and mode are same.
+	_, stderr, changed = ModuleCopy(ctx, opt)
+	assert.Empty(t, stderr)
+	assert.False(t, changed)
+}
+
+func TestCopy_RelFile(t *testing.T) {
+	projectDir := t.TempDir()
+	for file, content := range map[string]string{
+		"playbooks/test.yaml": "- hosts: all",
+		"files/test.txt":      "hello world",
+		"files/dir/a.txt":     "a",
+	} {
+		if err := os.MkdirAll(filepath.Join(projectDir, filepath.Dir(file)), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(projectDir, file), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dest := t.TempDir()
+	conn, err := connector.NewConnector("localhost", map[string]any{"type": "local"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(context.Background(), ConnKey, conn)
+	pipeline := kkcorev1.Pipeline{Spec: kkcorev1.PipelineSpec{
+		Project:  kkcorev1.PipelineProject{Addr: projectDir},
+		Playbook: "playbooks/test.yaml",
+	}}
+
+	testcases := []struct {
+		name string
+		src  string
+		want map[string]string
+	}{
+		{
+			name: "relative file",
+			src:  "test.txt",
+			want: map[string]string{"test.txt": "hello world"},
+		},
+		{
+			name: "relative dir",
+			src:  "dir",
+			want: map[string]string{"a.txt": "a"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			stdout, stderr, changed := ModuleCopy(ctx, ExecOptions{
+				Args: runtime.RawExtension{
+					Raw: []byte(fmt.Sprintf(`{"src": %q, "dest": %q}`, tc.src, dest+"/")),
+				},
+				Host:     "localhost",
+				Variable: &testVariable{},
+				Pipeline: pipeline,
+			})
+			assert.Equal(t, StdoutSuccess, stdout)
+			assert.Empty(t, stderr)
+			assert.True(t, changed)
+			for file, content := range tc.want {
+				data, err := os.ReadFile(filepath.Join(dest, file))
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, content, string(data))
+			}
+		})
+	}
+}
+
+func TestCopy_DiffLargeFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	if err := os.WriteFile(src, make([]byte, maxDiffSize+1), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dest := filepath.Join(dir, "dest")
+	conn, err := connector.NewConnector("localhost", map[string]any{"type": "local"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(context.Background(), ConnKey, conn)
+
+	diff := &strings.Builder{}
+	_, stderr, changed := ModuleCopy(ctx, ExecOptions{
+		Args: runtime.RawExtension{
+			Raw: []byte(fmt.Sprintf(`{"src": %q, "dest": %q}`, src, dest)),
+		},
+		Host:       "localhost",
+		Variable:   &testVariable{},
+		Pipeline:   kkcorev1.Pipeline{Spec: kkcorev1.PipelineSpec{CheckMode: true, Diff: true}},
+		DiffOutput: diff,
+	})
+	assert.Contains(t, diff.String(), "diff is skipped")
+	assert.Empty(t, stderr)
+	assert.True(t, changed)
+	// the file is not put to host in check mode.
+	assert.NoFileExists(t, dest)
+}
@@ -25,11 +25,11 @@ import (
 )
 
 // ModuleDebug deal "debug" module
-func ModuleDebug(_ context.Context, options ExecOptions) (string, string) {
+func ModuleDebug(_ context.Context, options ExecOptions) (string, string, bool) {
 	// get host variable
 	ha, err := options.getAllVariables()
 	if err != nil {
-		return "", err.Error()
+		return "", err.Error(), false
 	}
 
 	args := variable.Extension2Variables(options.Args)
@@ -37,15 +37,15 @@ func ModuleDebug(_ context.Context, options ExecOptions) (string, string) {
 	if varParam, err := variable.StringVar(ha, args, "var"); err == nil {
 		result, err := tmpl.ParseString(ha, fmt.Sprintf("{{ %s }}", varParam))
 		if err != nil {
-			return "", fmt.Sprintf("failed to parse var: %v", err)
+			return "", fmt.Sprintf("failed to parse var: %v", err), false
 		}
 
-		return result, ""
+		return result, "", false
 	}
 	// msg is defined. return the actual msg
 	if msgParam, err := variable.StringVar(ha, args, "msg"); err == nil {
-		return msgParam, ""
+		return msgParam, "", false
 	}
 
-	return "", "unknown args for debug. only support var or msg"
+	return "", "unknown args for debug. only support var or msg", false
 }
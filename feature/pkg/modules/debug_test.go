@@ -78,7 +78,7 @@ func TestDebug(t *testing.T) {
 			ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 			defer cancel()
 
-			acStdout, acStderr := ModuleDebug(ctx, tc.opt)
+			acStdout, acStderr, _ := ModuleDebug(ctx, tc.opt)
 			assert.Equal(t, tc.exceptStdout, acStdout)
 			assert.Equal(t, tc.exceptStderr, acStderr)
 		})
@@ -0,0 +1,169 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modules
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/klog/v2"
+
+	"github.com/kubesphere/kubekey/v4/pkg/connector"
+)
+
+// maxDiffSize is the max size of file content to diff. the larger file is not read to memory for diff.
+const maxDiffSize = 1 << 20
+
+// diffConnector wrap the connector of host in check mode or diff mode.
+// in check mode, the files are not put to host. in diff mode, the unified diff of each changed file is recorded.
+type diffConnector struct {
+	connector.Connector
+
+	check bool
+	diff  bool
+	diffs []string
+}
+
+// PutFile record the difference between src and dst in host, and put src to host if not in check mode.
+func (c *diffConnector) PutFile(ctx context.Context, src []byte, dst string, mode fs.FileMode) error {
+	if c.diff {
+		d, err := c.fileDiff(ctx, src, dst, mode)
+		if err != nil {
+			return err
+		}
+		c.diffs = append(c.diffs, d)
+	}
+	if c.check {
+		return nil
+	}
+
+	return c.Connector.PutFile(ctx, src, dst, mode)
+}
+
+// PutFileStream record the difference between src and dst in host, and put src to host if not in check mode.
+// in diff mode, only the src which is not larger than maxDiffSize is read to memory for diff.
+func (c *diffConnector) PutFileStream(ctx context.Context, src io.Reader, dst string, option connector.FileOption) error {
+	if c.diff {
+		data, err := io.ReadAll(io.LimitReader(src, maxDiffSize+1))
+		if err != nil {
+			return fmt.Errorf("read content of %s error: %w", dst, err)
+		}
+		d, err := c.fileDiff(ctx, data, dst, option.Mode)
+		if err != nil {
+			return err
+		}
+		c.diffs = append(c.diffs, d)
+		src = io.MultiReader(bytes.NewReader(data), src)
+	}
+	if c.check {
+		return nil
+	}
+
+	return connector.PutFileStream(ctx, c.Connector, src, dst, option)
+}
+
+// StatFile stat file by the wrapped connector.
+func (c *diffConnector) StatFile(ctx context.Context, path string) (*connector.FileStat, error) {
+	stater, ok := c.Connector.(connector.FileStater)
+	if !ok {
+		return nil, errors.New("connector does not support stat file")
+	}
+
+	return stater.StatFile(ctx, path)
+}
+
+// fileDiff unified diff from dst in host to src. a missing dst is treated as empty file.
+// the diff is skipped when src or dst is larger than maxDiffSize.
+func (c *diffConnector) fileDiff(ctx context.Context, src []byte, dst string, mode fs.FileMode) (string, error) {
+	if len(src) > maxDiffSize {
+		return fmt.Sprintf("content of %s changed, diff is skipped for file larger than %d bytes\n", dst, maxDiffSize), nil
+	}
+	fromFile := dst
+	old := &limitBuffer{limit: maxDiffSize}
+	if err := c.Connector.FetchFile(ctx, dst, old); err != nil && !old.exceeded {
+		fromFile = "/dev/null"
+		old.Reset()
+	}
+	if old.exceeded {
+		return fmt.Sprintf("content of %s changed, diff is skipped for file larger than %d bytes\n", dst, maxDiffSize), nil
+	}
+
+	d, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(old.String()),
+		B:        splitLines(string(src)),
+		FromFile: fromFile,
+		ToFile:   dst,
+		Context:  3,
+	})
+	if err != nil {
+		return "", fmt.Errorf("diff file %s error: %w", dst, err)
+	}
+	if d == "" {
+		// the content is same. only mode or owner is changed.
+		d = fmt.Sprintf("mode or owner of %s changed, mode: %s\n", dst, mode.Perm())
+	}
+
+	return d, nil
+}
+
+// limitBuffer is a bytes.Buffer which stop writing when the content is larger than limit.
+type limitBuffer struct {
+	bytes.Buffer
+
+	limit    int
+	exceeded bool
+}
+
+// Write append p to buffer. return an error when the content is larger than limit.
+func (b *limitBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.limit {
+		b.exceeded = true
+
+		return 0, fmt.Errorf("content is larger than %d bytes", b.limit)
+	}
+
+	return b.Buffer.Write(p)
+}
+
+// splitLines split content to lines for diff. empty content has no line.
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+
+	return difflib.SplitLines(content)
+}
+
+// writeDiff write the unified diff of changed files to the DiffOutput of modules which put files in diff mode.
+func writeDiff(conn connector.Connector, options ExecOptions) {
+	dc, ok := conn.(*diffConnector)
+	if !ok || options.DiffOutput == nil {
+		return
+	}
+	for _, d := range dc.diffs {
+		if _, err := io.WriteString(options.DiffOutput, d); err != nil {
+			klog.V(4).ErrorS(err, "write diff error", "host", options.Host)
+
+			return
+		}
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package modules
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
@@ -28,48 +29,51 @@ import (
 )
 
 // ModuleFetch deal fetch module
-func ModuleFetch(ctx context.Context, options ExecOptions) (string, string) {
+func ModuleFetch(ctx context.Context, options ExecOptions) (string, string, bool) {
 	// get host variable
 	ha, err := options.getAllVariables()
 	if err != nil {
-		return "", err.Error()
+		return "", err.Error(), false
 	}
 	// check args
 	args := variable.Extension2Variables(options.Args)
 	srcParam, err := variable.StringVar(ha, args, "src")
 	if err != nil {
-		return "", "\"src\" in args should be string"
+		return "", "\"src\" in args should be string", false
 	}
 	destParam, err := variable.StringVar(ha, args, "dest")
 	if err != nil {
-		return "", "\"dest\" in args should be string"
+		return "", "\"dest\" in args should be string", false
 	}
 
 	// get connector
-	conn, err := getConnector(ctx, options.Host, ha)
+	conn, err := options.getConnector(ctx)
 	if err != nil {
-		return "", fmt.Sprintf("get connector error: %v", err)
+		return "", fmt.Sprintf("get connector error: %v", err), false
 	}
 	defer conn.Close(ctx)
 
 	// fetch file
+	var remote bytes.Buffer
+	if err := conn.FetchFile(ctx, srcParam, &remote); err != nil {
+		return "", fmt.Sprintf("failed to fetch file: %v", err), false
+	}
+	// the local file is the same as remote. nothing changed.
+	if local, err := os.ReadFile(destParam); err == nil && bytes.Equal(local, remote.Bytes()) {
+		return StdoutSuccess, "", false
+	}
+
 	if _, err := os.Stat(filepath.Dir(destParam)); os.IsNotExist(err) {
 		if err := os.MkdirAll(filepath.Dir(destParam), os.ModePerm); err != nil {
-			return "", fmt.Sprintf("failed to create dest dir: %v", err)
+			return "", fmt.Sprintf("failed to create dest dir: %v", err), false
 		}
 	}
 
-	destFile, err := os.Create(destParam)
-	if err != nil {
-		klog.V(4).ErrorS(err, "failed to create dest file")
-
-		return "", err.Error()
-	}
-	defer destFile.Close()
+	if err := os.WriteFile(destParam, remote.Bytes(), os.ModePerm); err != nil {
+		klog.V(4).ErrorS(err, "failed to write dest file")
 
-	if err := conn.FetchFile(ctx, srcParam, destFile); err != nil {
-		return "", fmt.Sprintf("failed to fetch file: %v", err)
+		return "", err.Error(), false
 	}
 
-	return StdoutSuccess, ""
+	return StdoutSuccess, "", true
 }
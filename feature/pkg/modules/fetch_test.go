@@ -66,7 +66,7 @@ func TestFetch(t *testing.T) {
 			ctx, cancel := context.WithTimeout(tc.ctxFunc(), time.Second*5)
 			defer cancel()
 
-			acStdout, acStderr := ModuleFetch(ctx, tc.opt)
+			acStdout, acStderr, _ := ModuleFetch(ctx, tc.opt)
 			assert.Equal(t, tc.exceptStdout, acStdout)
 			assert.Equal(t, tc.exceptStderr, acStderr)
 		})
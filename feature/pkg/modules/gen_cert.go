@@ -1,6 +1,7 @@
 package modules
 
 import (
+	"bytes"
 	"context"
 	"crypto"
 	"crypto/ecdsa"
@@ -8,13 +9,17 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/json"
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"io"
 	"math"
 	"math/big"
 	"net"
+	"net/http"
 	"os"
+	"os/exec"
 	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
@@ -40,6 +45,9 @@ const (
 	policyAlways = "Always"
 	// policyIfNotPresent if cert is exist, check it.if not generate new cert.
 	policyIfNotPresent = "IfNotPresent"
+
+	// defaultSignerTimeout defines the default timeout for request to the external signer.
+	defaultSignerTimeout = 30 * time.Second
 )
 
 var defaultAltName = &cgutilcert.AltNames{
@@ -56,17 +64,162 @@ type genCertArgs struct {
 	cn       string
 	outKey   string
 	outCert  string
+	// usages is the extended key usages of the cert. it's required by signer.
+	usages []x509.ExtKeyUsage
+	// signer signs the cert with root_cert instead of root_key.
+	signer *genCertSigner
+	// check only report the cert which would be generated.
+	check bool
 }
 
-// signedCertificate generate certificate signed by root certificate
-func (gca genCertArgs) signedCertificate(cfg *cgutilcert.Config) (string, string) {
-	parentKey, err := TryLoadKeyFromDisk(gca.rootKey)
+// genCertSigner is the external signer, which holds the key of root_cert.
+// the request is posted to url, or written to the stdin of command, in JSON.
+// the wire format is documented in docs/config-example.md of the repository, and it's the same as the signer of
+// cmd/kk/pkg/utils/certs/signer.go. keep the field names and defaults in sync.
+type genCertSigner struct {
+	url     string
+	command []string
+	timeout time.Duration
+}
+
+type signRequest struct {
+	CSR      string   `json:"csr"`
+	Usages   []string `json:"usages"`
+	Duration string   `json:"duration"`
+}
+
+type signResponse struct {
+	Certificate string `json:"certificate"`
+}
+
+var extKeyUsageNames = map[x509.ExtKeyUsage]string{
+	x509.ExtKeyUsageServerAuth: "server auth",
+	x509.ExtKeyUsageClientAuth: "client auth",
+}
+
+// extKeyUsages is the ExtKeyUsage of the names in "usages".
+var extKeyUsages = map[string]x509.ExtKeyUsage{
+	"server auth": x509.ExtKeyUsageServerAuth,
+	"client auth": x509.ExtKeyUsageClientAuth,
+}
+
+// sign the cert of key by the external signer, and verify it's signed with caCert.
+// the usages of request are from cfg.Usages.
+func (s genCertSigner) sign(cfg cgutilcert.Config, after time.Duration, key crypto.Signer, caCert *x509.Certificate) (*x509.Certificate, error) {
+	if len(cfg.Usages) == 0 {
+		return nil, errors.New("must specify at least one ExtKeyUsage")
+	}
+	req := signRequest{}
+	for _, usage := range cfg.Usages {
+		name, ok := extKeyUsageNames[usage]
+		if !ok {
+			return nil, fmt.Errorf("unsupported ExtKeyUsage %v of external signer", usage)
+		}
+		req.Usages = append(req.Usages, name)
+	}
+
+	RemoveDuplicateAltNames(&cfg.AltNames)
+	csr, err := x509.CreateCertificateRequest(cryptorand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{
+			CommonName:   cfg.CommonName,
+			Organization: cfg.Organization,
+		},
+		DNSNames:    cfg.AltNames.DNSNames,
+		IPAddresses: cfg.AltNames.IPs,
+	}, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate request: %w", err)
+	}
+	if after == 0 {
+		after = defaultSignCertAfter
+	}
+	req.CSR = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr}))
+	req.Duration = after.String()
+
+	out, err := s.do(req)
 	if err != nil {
-		return "", fmt.Sprintf("failed to load root key: %v", err)
+		return nil, err
+	}
+	resp := &signResponse{}
+	if err := json.Unmarshal(out, resp); err != nil {
+		return nil, fmt.Errorf("failed to parse response of signer: %w", err)
+	}
+	if resp.Certificate == "" {
+		return nil, errors.New("no certificate in the response of signer")
+	}
+	certs, err := cgutilcert.ParseCertsPEM([]byte(resp.Certificate))
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate from signer: %w", err)
+	}
+	cert := certs[0]
+	if !keyMatchesCert(cert, key) {
+		return nil, errors.New("the certificate from signer does not match the private key")
+	}
+	if err := VerifyCertChain(cert, certs[1:], caCert); err != nil {
+		return nil, fmt.Errorf("the certificate from signer is not signed by CA %s: %w", caCert.Subject.CommonName, err)
+	}
+
+	return cert, nil
+}
+
+// do sends the request to the HTTP or exec signer, and returns the response.
+func (s genCertSigner) do(sr signRequest) ([]byte, error) {
+	body, err := json.Marshal(sr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal sign request: %w", err)
+	}
+
+	timeout := s.timeout
+	if timeout == 0 {
+		timeout = defaultSignerTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var out []byte
+	if s.url != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request to signer %s: %w", s.url, err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to request signer %s: %w", s.url, err)
+		}
+		defer resp.Body.Close()
+		if out, err = io.ReadAll(resp.Body); err != nil {
+			return nil, fmt.Errorf("failed to read response of signer %s: %w", s.url, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("signer %s returns %s: %s", s.url, resp.Status, bytes.TrimSpace(out))
+		}
+	} else {
+		cmd := exec.CommandContext(ctx, s.command[0], s.command[1:]...)
+		cmd.Stdin = bytes.NewReader(body)
+		stderr := bytes.NewBuffer(nil)
+		cmd.Stderr = stderr
+		if out, err = cmd.Output(); err != nil {
+			return nil, fmt.Errorf("failed to run signer %s: %w, stderr: %s", s.command[0], err, bytes.TrimSpace(stderr.Bytes()))
+		}
+	}
+
+	return out, nil
+}
+
+// signedCertificate generate certificate signed by root certificate
+func (gca genCertArgs) signedCertificate(cfg *cgutilcert.Config) (string, string, bool) {
+	var parentKey crypto.Signer
+	if gca.signer == nil {
+		key, err := TryLoadKeyFromDisk(gca.rootKey)
+		if err != nil {
+			return "", fmt.Sprintf("failed to load root key: %v", err), false
+		}
+		parentKey = key
 	}
 	parentCert, _, err := TryLoadCertChainFromDisk(gca.rootCert)
 	if err != nil {
-		return "", fmt.Sprintf("failed to load root certificate: %v", err)
+		return "", fmt.Sprintf("failed to load root certificate: %v", err), false
 	}
 
 	if gca.policy == policyIfNotPresent {
@@ -84,58 +237,91 @@ func (gca genCertArgs) signedCertificate(cfg *cgutilcert.Config) (string, string
 		}
 		// check if the existing key and cert match the root key and cert
 		if err := ValidateCertPeriod(existCert, 0); err != nil {
-			return "", fmt.Sprintf("failed to ValidateCertPeriod: %v", err)
+			return "", fmt.Sprintf("failed to ValidateCertPeriod: %v", err), false
 		}
 		if err := VerifyCertChain(existCert, intermediates, parentCert); err != nil {
-			return "", fmt.Sprintf("failed to VerifyCertChain: %v", err)
+			return "", fmt.Sprintf("failed to VerifyCertChain: %v", err), false
 		}
 		if err := validateCertificateWithConfig(existCert, gca.outCert, cfg); err != nil {
-			return "", fmt.Sprintf("failed to validateCertificateWithConfig: %v", err)
+			return "", fmt.Sprintf("failed to validateCertificateWithConfig: %v", err), false
 		}
 
-		return StdoutSkip, ""
+		return StdoutSkip, "", false
 	}
 NEW:
+	if gca.check {
+		return gca.checkStdout(), "", true
+	}
 	newKey, err := rsa.GenerateKey(cryptorand.Reader, rsaKeySize)
 	if err != nil {
-		return "", fmt.Sprintf("generate rsa key error: %v", err)
+		return "", fmt.Sprintf("generate rsa key error: %v", err), false
 	}
-	newCert, err := NewSignedCert(*cfg, gca.date, newKey, parentCert, parentKey, true)
-	if err != nil {
-		return "", fmt.Sprintf("failed to generate certificate: %v", err)
+	var newCert *x509.Certificate
+	if gca.signer != nil {
+		cert, err := gca.signer.sign(*cfg, gca.date, newKey, parentCert)
+		if err != nil {
+			return "", fmt.Sprintf("failed to sign certificate by signer: %v", err), false
+		}
+		if err := validateCertificateWithConfig(cert, gca.outCert, cfg); err != nil {
+			return "", fmt.Sprintf("failed to validateCertificateWithConfig: %v", err), false
+		}
+		newCert = cert
+	} else {
+		cert, err := NewSignedCert(*cfg, gca.date, newKey, parentCert, parentKey, true)
+		if err != nil {
+			return "", fmt.Sprintf("failed to generate certificate: %v", err), false
+		}
+		newCert = cert
 	}
 
 	// write key and cert to file
 	if err := WriteKey(gca.outKey, newKey, gca.policy); err != nil {
-		return "", fmt.Sprintf("failed to write key: %v", err)
+		return "", fmt.Sprintf("failed to write key: %v", err), false
 	}
 	if err := WriteCert(gca.outCert, newCert, gca.policy); err != nil {
-		return "", fmt.Sprintf("failed to write certificate: %v", err)
+		return "", fmt.Sprintf("failed to write certificate: %v", err), false
 	}
 
-	return StdoutSuccess, ""
+	return StdoutSuccess, "", true
 }
 
 // selfSignedCertificate generate Self-signed certificate
-func (gca genCertArgs) selfSignedCertificate(cfg *cgutilcert.Config) (string, string) {
+func (gca genCertArgs) selfSignedCertificate(cfg *cgutilcert.Config) (string, string, bool) {
+	if gca.policy == policyIfNotPresent {
+		// the exist certificate is still valid. nothing changed.
+		if _, err := TryLoadKeyFromDisk(gca.outKey); err == nil {
+			if existCert, _, err := TryLoadCertChainFromDisk(gca.outCert); err == nil && ValidateCertPeriod(existCert, 0) == nil {
+				return StdoutSuccess, "", false
+			}
+		}
+	}
+	if gca.check {
+		return gca.checkStdout(), "", true
+	}
+
 	newKey, err := rsa.GenerateKey(cryptorand.Reader, rsaKeySize)
 	if err != nil {
-		return "", fmt.Sprintf("generate rsa key error: %v", err)
+		return "", fmt.Sprintf("generate rsa key error: %v", err), false
 	}
 
 	newCert, err := NewSelfSignedCACert(*cfg, gca.date, newKey)
 	if err != nil {
-		return "", fmt.Sprintf("failed to generate self-signed certificate: %v", err)
+		return "", fmt.Sprintf("failed to generate self-signed certificate: %v", err), false
 	}
 	// write key and cert to file
 	if err := WriteKey(gca.outKey, newKey, gca.policy); err != nil {
-		return "", fmt.Sprintf("failed to write key: %v", err)
+		return "", fmt.Sprintf("failed to write key: %v", err), false
 	}
 	if err := WriteCert(gca.outCert, newCert, gca.policy); err != nil {
-		return "", fmt.Sprintf("failed to write certificate: %v", err)
+		return "", fmt.Sprintf("failed to write certificate: %v", err), false
 	}
 
-	return StdoutSuccess, ""
+	return StdoutSuccess, "", true
+}
+
+// checkStdout is the stdout in check mode. the files which would be generated.
+func (gca genCertArgs) checkStdout() string {
+	return fmt.Sprintf("generate key %s and certificate %s", gca.outKey, gca.outCert)
 }
 
 func newGenCertArgs(_ context.Context, raw runtime.RawExtension, vars map[string]any) (*genCertArgs, error) {
@@ -150,6 +336,31 @@ func newGenCertArgs(_ context.Context, raw runtime.RawExtension, vars map[string
 	gca.cn, _ = variable.StringVar(vars, args, "cn")
 	gca.outKey, _ = variable.StringVar(vars, args, "out_key")
 	gca.outCert, _ = variable.StringVar(vars, args, "out_cert")
+	if signerArgs, ok := args["signer"]; ok {
+		signer, ok := signerArgs.(map[string]any)
+		if !ok {
+			return nil, errors.New("\"signer\" should be map")
+		}
+		gcs := &genCertSigner{}
+		gcs.url, _ = variable.StringVar(vars, signer, "url")
+		gcs.command, _ = variable.StringSliceVar(vars, signer, "command")
+		gcs.timeout, _ = variable.DurationVar(vars, signer, "timeout")
+		// the signer is disabled when both url and command are empty.
+		if gcs.url != "" && len(gcs.command) != 0 {
+			return nil, errors.New("only one of \"signer.url\" and \"signer.command\" should be set")
+		}
+		if gcs.url != "" || len(gcs.command) != 0 {
+			gca.signer = gcs
+		}
+	}
+	usages, _ := variable.StringSliceVar(vars, args, "usages")
+	for _, u := range usages {
+		usage, ok := extKeyUsages[u]
+		if !ok {
+			return nil, fmt.Errorf("unsupported usage %q, should be one of [server auth, client auth]", u)
+		}
+		gca.usages = append(gca.usages, usage)
+	}
 	// check args
 	if gca.policy != policyAlways && gca.policy != policyIfNotPresent {
 		return nil, errors.New("\"policy\" should be one of [Always, IfNotPresent]")
@@ -160,31 +371,43 @@ func newGenCertArgs(_ context.Context, raw runtime.RawExtension, vars map[string
 	if gca.cn == "" {
 		return nil, errors.New("\"cn\" in args should be string")
 	}
+	if gca.signer != nil && gca.rootCert == "" {
+		return nil, errors.New("\"root_cert\" should be set when \"signer\" is set")
+	}
+	if gca.signer != nil && len(gca.usages) == 0 {
+		return nil, errors.New("\"usages\" should be set when \"signer\" is set")
+	}
 
 	return gca, nil
 }
 
 // ModuleGenCert generate cert file.
 // if root_key and root_cert is empty, generate Self-signed certificate.
-func ModuleGenCert(ctx context.Context, options ExecOptions) (string, string) {
+// if signer is set, the certificate is signed by the signer with root_cert, root_key is not used.
+// the signer requires root_cert and usages.
+func ModuleGenCert(ctx context.Context, options ExecOptions) (string, string, bool) {
 	// get host variable
 	ha, err := options.getAllVariables()
 	if err != nil {
-		return "", err.Error()
+		return "", err.Error(), false
 	}
 
 	gca, err := newGenCertArgs(ctx, options.Args, ha)
 	if err != nil {
-		return "", err.Error()
+		return "", err.Error(), false
 	}
+	gca.check = options.checkMode()
 
 	cfg := &cgutilcert.Config{
 		CommonName:   gca.cn,
 		Organization: []string{"kubekey"},
 		AltNames:     appendSANsToAltNames(defaultAltName, gca.sans),
+		Usages:       gca.usages,
 	}
 
 	switch {
+	case gca.signer != nil:
+		return gca.signedCertificate(cfg)
 	case gca.rootKey == "" || gca.rootCert == "":
 		return gca.selfSignedCertificate(cfg)
 	default:
@@ -448,6 +671,13 @@ func VerifyCertChain(cert *x509.Certificate, intermediates []*x509.Certificate,
 	return nil
 }
 
+// keyMatchesCert returns whether the key is the private key of cert.
+func keyMatchesCert(cert *x509.Certificate, key crypto.Signer) bool {
+	pub, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool })
+
+	return ok && pub.Equal(cert.PublicKey)
+}
+
 // validateCertificateWithConfig makes sure that a given certificate is valid at
 // least for the SANs defined in the configuration.
 func validateCertificateWithConfig(cert *x509.Certificate, baseName string, cfg *cgutilcert.Config) error {
@@ -18,8 +18,16 @@ package modules
 
 import (
 	"context"
+	cryptorand "crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -64,9 +72,142 @@ func TestModuleGenCert(t *testing.T) {
 
 	for _, testcase := range testcases {
 		t.Run(testcase.name, func(t *testing.T) {
-			stdout, stderr := ModuleGenCert(context.Background(), testcase.opt)
+			stdout, stderr, _ := ModuleGenCert(context.Background(), testcase.opt)
 			assert.Equal(t, testcase.exceptStdout, stdout)
 			assert.Equal(t, testcase.exceptStderr, stderr)
 		})
 	}
 }
+
+func TestModuleGenCertWithSigner(t *testing.T) {
+	// the fixtures in testdata/signer are shared with the signer of cmd/kk, see testdata/signer/README.md.
+	ca, _, err := TryLoadCertChainFromDisk("testdata/signer/ca.crt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	caKey, err := TryLoadKeyFromDisk("testdata/signer/ca.key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got signRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		block, _ := pem.Decode([]byte(got.CSR))
+		if block == nil {
+			http.Error(w, "invalid csr", http.StatusBadRequest)
+			return
+		}
+		csr, err := x509.ParseCertificateRequest(block.Bytes)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(100),
+			Subject:      csr.Subject,
+			DNSNames:     csr.DNSNames,
+			IPAddresses:  csr.IPAddresses,
+			NotBefore:    ca.NotBefore,
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		}
+		der, err := x509.CreateCertificate(cryptorand.Reader, tmpl, ca, csr.PublicKey, caKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(signResponse{Certificate: string(pem.EncodeToMemory(&pem.Block{Type: certificateBlockType, Bytes: der}))})
+	}))
+	defer server.Close()
+
+	if _, err := os.Stat("./test_gen_cert_signer"); os.IsNotExist(err) {
+		if err := os.Mkdir("./test_gen_cert_signer", os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer os.RemoveAll("./test_gen_cert_signer")
+
+	testcases := []struct {
+		name         string
+		signer       string
+		rootCert     string
+		usages       string
+		exceptStdout string
+		exceptStderr string
+	}{
+		{
+			name:         "http signer",
+			signer:       `{"url": "{{ .signer_url }}"}`,
+			rootCert:     "testdata/signer/ca.crt",
+			usages:       `["server auth"]`,
+			exceptStdout: "success",
+		},
+		{
+			name:         "exec signer responds the cert of another key",
+			signer:       `{"command": "[\"cat\", \"testdata/signer/mismatch-response.json\"]"}`,
+			rootCert:     "testdata/signer/ca.crt",
+			usages:       `["server auth", "client auth"]`,
+			exceptStderr: "failed to sign certificate by signer: the certificate from signer does not match the private key",
+		},
+		{
+			name:         "signer without root_cert",
+			signer:       `{"url": "{{ .signer_url }}"}`,
+			usages:       `["server auth"]`,
+			exceptStderr: "\"root_cert\" should be set when \"signer\" is set",
+		},
+		{
+			name:         "signer without usages",
+			signer:       `{"url": "{{ .signer_url }}"}`,
+			rootCert:     "testdata/signer/ca.crt",
+			usages:       `[]`,
+			exceptStderr: "\"usages\" should be set when \"signer\" is set",
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			stdout, stderr, _ := ModuleGenCert(context.Background(), ExecOptions{
+				Args: runtime.RawExtension{
+					Raw: []byte(`{
+"root_cert": "` + testcase.rootCert + `",
+"signer": ` + testcase.signer + `,
+"usages": ` + testcase.usages + `,
+"policy": "Always",
+"sans": "[\"127.0.0.1\"]",
+"cn": "etcd",
+"out_key": "./test_gen_cert_signer/etcd.key",
+"out_cert": "./test_gen_cert_signer/etcd.crt"
+}`),
+				},
+				Host: "local",
+				Variable: &testVariable{
+					value: map[string]any{
+						"signer_url": server.URL,
+					},
+				},
+			})
+			assert.Equal(t, testcase.exceptStdout, stdout)
+			assert.Equal(t, testcase.exceptStderr, stderr)
+		})
+	}
+
+	// the usages of request are from the args.
+	assert.Equal(t, []string{"server auth"}, got.Usages)
+	cert, _, err := TryLoadCertChainFromDisk("./test_gen_cert_signer/etcd.crt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "etcd", cert.Subject.CommonName)
+	assert.NoError(t, VerifyCertChain(cert, nil, ca))
+	// the cert issued by the sibling CA under the same root is not trusted by the issuing CA.
+	sibling, _, err := TryLoadCertChainFromDisk("testdata/signer/sibling-client.crt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Error(t, VerifyCertChain(sibling, nil, ca))
+}
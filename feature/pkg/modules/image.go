@@ -19,7 +19,9 @@ package modules
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -49,17 +51,21 @@ type imageArgs struct {
 }
 
 type imagePullArgs struct {
+	// check only report the images which would be pulled.
+	check         bool
 	manifests     []string
 	skipTLSVerify *bool
 	username      string
 	password      string
 }
 
-func (i imagePullArgs) pull(ctx context.Context) error {
+// pull remote images to local dir. return the images which have been pulled.
+func (i imagePullArgs) pull(ctx context.Context) ([]string, error) {
+	var pulled []string
 	for _, img := range i.manifests {
 		src, err := remote.NewRepository(img)
 		if err != nil {
-			return fmt.Errorf("failed to get remote image: %w", err)
+			return nil, fmt.Errorf("failed to get remote image: %w", err)
 		}
 		src.Client = &auth.Client{
 			Client: &http.Client{
@@ -79,18 +85,28 @@ func (i imagePullArgs) pull(ctx context.Context) error {
 		dst, err := newLocalRepository(filepath.Join(domain, src.Reference.Repository)+":"+src.Reference.Reference,
 			filepath.Join(_const.GetWorkDir(), _const.ArtifactDir, _const.ArtifactImagesDir))
 		if err != nil {
-			return fmt.Errorf("failed to get local image: %w", err)
+			return nil, fmt.Errorf("failed to get local image: %w", err)
+		}
+		// skip the image which has the same digest.
+		if imageDigestEqual(ctx, src, dst) {
+			continue
 		}
 
+		pulled = append(pulled, src.Reference.String())
+		if i.check {
+			continue
+		}
 		if _, err = oras.Copy(ctx, src, src.Reference.Reference, dst, "", oras.DefaultCopyOptions); err != nil {
-			return fmt.Errorf("failed to copy image: %w", err)
+			return nil, fmt.Errorf("failed to copy image: %w", err)
 		}
 	}
 
-	return nil
+	return pulled, nil
 }
 
 type imagePushArgs struct {
+	// check only report the images which would be pushed.
+	check         bool
 	imagesDir     string
 	skipTLSVerify *bool
 	registry      string
@@ -99,18 +115,19 @@ type imagePushArgs struct {
 	namespace     string
 }
 
-// push local dir images to remote registry
-func (i imagePushArgs) push(ctx context.Context) error {
+// push local dir images to remote registry. return the images which have been pushed.
+func (i imagePushArgs) push(ctx context.Context) ([]string, error) {
 	manifests, err := findLocalImageManifests(i.imagesDir)
 	klog.V(5).Info("manifests found", "manifests", manifests)
 	if err != nil {
-		return fmt.Errorf("failed to find local image manifests: %w", err)
+		return nil, fmt.Errorf("failed to find local image manifests: %w", err)
 	}
 
+	var pushed []string
 	for _, img := range manifests {
 		src, err := newLocalRepository(filepath.Join(domain, img), i.imagesDir)
 		if err != nil {
-			return fmt.Errorf("failed to get local image: %w", err)
+			return nil, fmt.Errorf("failed to get local image: %w", err)
 		}
 		repo := src.Reference.Repository
 		if i.namespace != "" {
@@ -119,7 +136,7 @@ func (i imagePushArgs) push(ctx context.Context) error {
 
 		dst, err := remote.NewRepository(filepath.Join(i.registry, repo) + ":" + src.Reference.Reference)
 		if err != nil {
-			return fmt.Errorf("failed to get remote repo: %w", err)
+			return nil, fmt.Errorf("failed to get remote repo: %w", err)
 		}
 		dst.Client = &auth.Client{
 			Client: &http.Client{
@@ -135,13 +152,38 @@ func (i imagePushArgs) push(ctx context.Context) error {
 				Password: i.password,
 			}),
 		}
+		// skip the image which has the same digest.
+		if imageDigestEqual(ctx, src, dst) {
+			continue
+		}
 
+		pushed = append(pushed, dst.Reference.String())
+		if i.check {
+			continue
+		}
 		if _, err = oras.Copy(ctx, src, src.Reference.Reference, dst, "", oras.DefaultCopyOptions); err != nil {
-			return fmt.Errorf("failed to copy image: %w", err)
+			return nil, fmt.Errorf("failed to copy image: %w", err)
 		}
 	}
 
-	return nil
+	return pushed, nil
+}
+
+// imageDigestEqual check if the image manifest in src and dst has the same digest.
+func imageDigestEqual(ctx context.Context, src, dst *remote.Repository) bool {
+	srcDesc, err := src.Resolve(ctx, src.Reference.Reference)
+	if err != nil {
+		klog.V(4).ErrorS(err, "failed to resolve image", "image", src.Reference.String())
+
+		return false
+	}
+	dstDesc, err := dst.Resolve(ctx, dst.Reference.Reference)
+	if err != nil {
+		// image is not exist in dst.
+		return false
+	}
+
+	return srcDesc.Digest == dstDesc.Digest
 }
 
 func newImageArgs(_ context.Context, raw runtime.RawExtension, vars map[string]any) (*imageArgs, error) {
@@ -199,32 +241,50 @@ func newImageArgs(_ context.Context, raw runtime.RawExtension, vars map[string]a
 }
 
 // ModuleImage deal "image" module
-func ModuleImage(ctx context.Context, options ExecOptions) (string, string) {
+func ModuleImage(ctx context.Context, options ExecOptions) (string, string, bool) {
 	// get host variable
 	ha, err := options.getAllVariables()
 	if err != nil {
-		return "", err.Error()
+		return "", err.Error(), false
 	}
 
 	ia, err := newImageArgs(ctx, options.Args, ha)
 	if err != nil {
-		return "", err.Error()
+		return "", err.Error(), false
 	}
 
+	var changed bool
+	var checkStdout []string
 	// pull image manifests to local dir
 	if ia.pull != nil {
-		if err := ia.pull.pull(ctx); err != nil {
-			return "", fmt.Sprintf("failed to pull image: %v", err)
+		ia.pull.check = options.checkMode()
+		pulled, err := ia.pull.pull(ctx)
+		if err != nil {
+			return "", fmt.Sprintf("failed to pull image: %v", err), false
+		}
+		changed = changed || len(pulled) != 0
+		for _, img := range pulled {
+			checkStdout = append(checkStdout, "pull "+img)
 		}
 	}
 	// push image to private registry
 	if ia.push != nil {
-		if err := ia.push.push(ctx); err != nil {
-			return "", fmt.Sprintf("failed to push image: %v", err)
+		ia.push.check = options.checkMode()
+		pushed, err := ia.push.push(ctx)
+		if err != nil {
+			return "", fmt.Sprintf("failed to push image: %v", err), false
 		}
+		changed = changed || len(pushed) != 0
+		for _, img := range pushed {
+			checkStdout = append(checkStdout, "push "+img)
+		}
+	}
+	// report the images which would be copied in check mode.
+	if options.checkMode() && changed {
+		return strings.Join(checkStdout, "\n"), "", changed
 	}
 
-	return StdoutSuccess, ""
+	return StdoutSuccess, "", changed
 }
 
 // findLocalImageManifests get image manifests with whole image's name.
@@ -369,7 +429,8 @@ func (i imageTransport) head(request *http.Request) (*http.Response, error) {
 			Proto:      "Local",
 			StatusCode: http.StatusOK,
 			Header: http.Header{
-				"Content-Type": []string{mediaType},
+				"Content-Type":          []string{mediaType},
+				"Docker-Content-Digest": []string{manifestDigest(file)},
 			},
 			ContentLength: int64(len(file)),
 		}, nil
@@ -378,6 +439,13 @@ func (i imageTransport) head(request *http.Request) (*http.Response, error) {
 	return responseNotAllowed, nil
 }
 
+// manifestDigest returns the digest of manifest content.
+func manifestDigest(manifest []byte) string {
+	sum := sha256.Sum256(manifest)
+
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
 // post method for http.MethodPost, accept request.
 func (i imageTransport) post(request *http.Request) (*http.Response, error) {
 	if strings.HasSuffix(request.URL.Path, "/uploads/") {
@@ -487,7 +555,8 @@ func (i imageTransport) get(request *http.Request) (*http.Response, error) {
 			Proto:      "Local",
 			StatusCode: http.StatusOK,
 			Header: http.Header{
-				"Content-Type": []string{mediaType},
+				"Content-Type":          []string{mediaType},
+				"Docker-Content-Digest": []string{manifestDigest(file)},
 			},
 			ContentLength: int64(len(file)),
 			Body:          io.NopCloser(bytes.NewReader(file)),
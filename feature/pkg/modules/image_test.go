@@ -67,7 +67,7 @@ func TestModuleImage(t *testing.T) {
 
 	for _, testcase := range testcases {
 		t.Run(testcase.name, func(t *testing.T) {
-			stdout, stderr := ModuleImage(context.Background(), testcase.opt)
+			stdout, stderr, _ := ModuleImage(context.Background(), testcase.opt)
 			assert.Equal(t, testcase.exceptStdout, stdout)
 			assert.Equal(t, testcase.exceptStderr, stderr)
 		})
@@ -0,0 +1,527 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modules
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/klog/v2"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kubesphere/kubekey/v4/pkg/connector"
+
+	kkcorev1alpha1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1alpha1"
+	"github.com/kubesphere/kubekey/v4/pkg/converter/tmpl"
+	"github.com/kubesphere/kubekey/v4/pkg/project"
+	"github.com/kubesphere/kubekey/v4/pkg/variable"
+)
+
+// state of k8s module
+const (
+	k8sStatePresent = "present"
+	k8sStateAbsent  = "absent"
+)
+
+const (
+	// k8sDefaultKubeconfig is the kubeconfig in host which created by kubeadm.
+	k8sDefaultKubeconfig = "/etc/kubernetes/admin.conf"
+	// k8sDefaultFieldManager is the field manager of server-side apply.
+	k8sDefaultFieldManager = "kubekey"
+	// k8sDefaultWaitTimeout is the default timeout of waiting for objects.
+	k8sDefaultWaitTimeout = 5 * time.Minute
+	// k8sWaitInterval is the interval to check the state of objects.
+	k8sWaitInterval = 2 * time.Second
+)
+
+type k8sArgs struct {
+	state      string
+	src        string
+	definition string
+	namespace  string
+	kubeconfig string
+	// fieldManager of server-side apply.
+	fieldManager string
+	// forceConflicts take the ownership of fields which managed by others.
+	forceConflicts bool
+	wait           bool
+	waitCondition  *k8sWaitCondition
+	waitTimeout    time.Duration
+}
+
+// k8sWaitCondition is the condition in status of object to wait for.
+type k8sWaitCondition struct {
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
+func newK8sArgs(_ context.Context, raw runtime.RawExtension, vars map[string]any) (*k8sArgs, error) {
+	var err error
+	ka := &k8sArgs{
+		state:          k8sStatePresent,
+		kubeconfig:     k8sDefaultKubeconfig,
+		fieldManager:   k8sDefaultFieldManager,
+		forceConflicts: true,
+		waitTimeout:    k8sDefaultWaitTimeout,
+	}
+	args := variable.Extension2Variables(raw)
+	if state, err := variable.StringVar(vars, args, "state"); err == nil {
+		ka.state = state
+	}
+	if ka.state != k8sStatePresent && ka.state != k8sStateAbsent {
+		return nil, fmt.Errorf("\"state\" should be %q or %q", k8sStatePresent, k8sStateAbsent)
+	}
+	ka.src, _ = variable.StringVar(vars, args, "src")
+	if ka.definition, err = k8sDefinition(vars, args); err != nil {
+		return nil, err
+	}
+	if ka.src == "" && ka.definition == "" {
+		return nil, errors.New("either \"src\" or \"definition\" must be provided")
+	}
+	ka.namespace, _ = variable.StringVar(vars, args, "namespace")
+	if kubeconfig, err := variable.StringVar(vars, args, "kubeconfig"); err == nil {
+		ka.kubeconfig = kubeconfig
+	}
+	if fieldManager, err := variable.StringVar(vars, args, "field_manager"); err == nil {
+		ka.fieldManager = fieldManager
+	}
+	if force, _ := variable.BoolVar(vars, args, "force_conflicts"); force != nil {
+		ka.forceConflicts = *force
+	}
+	if w, _ := variable.BoolVar(vars, args, "wait"); w != nil {
+		ka.wait = *w
+	}
+	if cond, ok := args["wait_condition"].(map[string]any); ok {
+		ka.waitCondition = &k8sWaitCondition{Status: string(metav1.ConditionTrue)}
+		if ka.waitCondition.Type, err = variable.StringVar(vars, cond, "type"); err != nil {
+			return nil, errors.New("\"type\" in wait_condition should be string")
+		}
+		if status, err := variable.StringVar(vars, cond, "status"); err == nil {
+			ka.waitCondition.Status = status
+		}
+	}
+	if timeout, _ := variable.IntVar(vars, args, "wait_timeout"); timeout != nil {
+		ka.waitTimeout = time.Duration(*timeout) * time.Second
+	}
+
+	return ka, nil
+}
+
+// k8sDefinition get the definition in args. it can be a yaml string, an object or a list of objects.
+func k8sDefinition(vars map[string]any, args map[string]any) (string, error) {
+	val, ok := args["definition"]
+	if !ok {
+		return "", nil
+	}
+	if _, ok := val.(string); ok {
+		return variable.StringVar(vars, args, "definition")
+	}
+	data, err := yaml.Marshal(val)
+	if err != nil {
+		return "", fmt.Errorf("\"definition\" should be object: %w", err)
+	}
+
+	return tmpl.ParseString(vars, string(data))
+}
+
+// ModuleK8s deal "k8s" module. create, patch or delete kubernetes objects by server-side apply,
+// and wait for them to be ready. the stdout is the json list of objects.
+func ModuleK8s(ctx context.Context, options ExecOptions) (string, string, bool) {
+	// get host variable
+	ha, err := options.getAllVariables()
+	if err != nil {
+		return "", err.Error(), false
+	}
+
+	ka, err := newK8sArgs(ctx, options.Args, ha)
+	if err != nil {
+		klog.V(4).ErrorS(err, "get k8s args error", "task", ctrlclient.ObjectKeyFromObject(&options.Task))
+
+		return "", err.Error(), false
+	}
+
+	objs, err := ka.objects(ctx, options, ha)
+	if err != nil {
+		return "", err.Error(), false
+	}
+
+	// get connector
+	conn, err := options.getConnector(ctx)
+	if err != nil {
+		return "", fmt.Sprintf("get connector error: %v", err), false
+	}
+	defer conn.Close(ctx)
+	// check mode is handled by dry-run of kubernetes api.
+	if dc, ok := conn.(*diffConnector); ok {
+		conn = dc.Connector
+	}
+	getter, ok := conn.(connector.RESTConfigGetter)
+	if !ok {
+		return "", "connector does not support kubernetes api", false
+	}
+	config, err := getter.RESTConfig(ctx, ka.kubeconfig)
+	if err != nil {
+		return "", fmt.Sprintf("get kubernetes config error: %v", err), false
+	}
+	client, err := newK8sClient(config)
+	if err != nil {
+		return "", fmt.Sprintf("create kubernetes client error: %v", err), false
+	}
+
+	results, changed, err := client.run(ctx, ka, objs, options.checkMode())
+	if err != nil {
+		return "", err.Error(), changed
+	}
+	stdout, err := json.Marshal(results)
+	if err != nil {
+		return "", fmt.Sprintf("marshal objects error: %v", err), changed
+	}
+
+	return string(stdout), "", changed
+}
+
+// objects get the objects from src file and definition. the src file is rendered as template.
+func (ka k8sArgs) objects(ctx context.Context, options ExecOptions, vars map[string]any) ([]*unstructured.Unstructured, error) {
+	manifest := ka.definition
+	if ka.src != "" {
+		var data []byte
+		var err error
+		if filepath.IsAbs(ka.src) {
+			data, err = os.ReadFile(ka.src)
+		} else {
+			var pj project.Project
+			if pj, err = project.New(ctx, options.Pipeline, false); err != nil {
+				return nil, fmt.Errorf("get project error: %w", err)
+			}
+			data, err = pj.ReadFile(ka.src, project.GetFileOption{IsTemplate: true, Role: options.Task.Annotations[kkcorev1alpha1.TaskAnnotationRole]})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read file %s error: %w", ka.src, err)
+		}
+		result, err := tmpl.ParseString(vars, string(data))
+		if err != nil {
+			return nil, fmt.Errorf("parse file %s error: %w", ka.src, err)
+		}
+		manifest = result + "\n---\n" + manifest
+	}
+
+	return parseManifests([]byte(manifest))
+}
+
+// parseManifests decode the multi-document yaml or json to objects. the items of List are expanded.
+func parseManifests(data []byte) ([]*unstructured.Unstructured, error) {
+	var objs []*unstructured.Unstructured
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objs, nil
+			}
+
+			return nil, fmt.Errorf("decode manifest error: %w", err)
+		}
+		if len(obj.Object) == 0 {
+			// empty document
+			continue
+		}
+		if obj.GetKind() == "" || obj.GetAPIVersion() == "" {
+			return nil, fmt.Errorf("manifest %q should have apiVersion and kind", obj.GetName())
+		}
+		if !obj.IsList() {
+			objs = append(objs, obj)
+
+			continue
+		}
+		if err := obj.EachListItem(func(item runtime.Object) error {
+			if u, ok := item.(*unstructured.Unstructured); ok {
+				objs = append(objs, u)
+			}
+
+			return nil
+		}); err != nil {
+			return nil, fmt.Errorf("decode list %q error: %w", obj.GetName(), err)
+		}
+	}
+}
+
+// k8sClient access kubernetes api by dynamic client.
+type k8sClient struct {
+	dynamic dynamic.Interface
+	mapper  meta.ResettableRESTMapper
+}
+
+func newK8sClient(config *rest.Config) (*k8sClient, error) {
+	dc, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	disc, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &k8sClient{dynamic: dc, mapper: restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(disc))}, nil
+}
+
+// run apply or delete objs in order. objects are deleted in reverse order.
+func (c *k8sClient) run(ctx context.Context, ka *k8sArgs, objs []*unstructured.Unstructured, dryRun bool) ([]map[string]any, bool, error) {
+	results := make([]map[string]any, 0, len(objs))
+	var changed bool
+	if ka.state == k8sStateAbsent {
+		objs = slices.Clone(objs)
+		slices.Reverse(objs)
+	}
+	for _, obj := range objs {
+		ri, err := c.resource(ctx, obj, ka.namespace)
+		if err != nil {
+			return results, changed, err
+		}
+		var result *unstructured.Unstructured
+		var objChanged bool
+		if ka.state == k8sStateAbsent {
+			result, objChanged, err = c.delete(ctx, ri, obj, ka, dryRun)
+		} else {
+			result, objChanged, err = c.apply(ctx, ri, obj, ka, dryRun)
+		}
+		changed = changed || objChanged
+		if err != nil {
+			return results, changed, fmt.Errorf("%s %s/%s error: %w", ka.state, obj.GetKind(), obj.GetName(), err)
+		}
+		if result != nil {
+			results = append(results, result.Object)
+		}
+	}
+
+	return results, changed, nil
+}
+
+// resource get the client of obj. the namespace of namespaced object is set to namespace if it's empty.
+func (c *k8sClient) resource(ctx context.Context, obj *unstructured.Unstructured, namespace string) (dynamic.ResourceInterface, error) {
+	gvk := obj.GroupVersionKind()
+	var mapping *meta.RESTMapping
+	// the kind may be defined by the CustomResourceDefinition which just created. reload the mapping until it's found.
+	if err := wait.PollUntilContextTimeout(ctx, k8sWaitInterval, 30*time.Second, true, func(context.Context) (bool, error) {
+		var err error
+		mapping, err = c.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if meta.IsNoMatchError(err) {
+			c.mapper.Reset()
+
+			return false, nil
+		}
+
+		return err == nil, err
+	}); err != nil {
+		return nil, fmt.Errorf("get resource of %s error: %w", gvk, err)
+	}
+
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		obj.SetNamespace("")
+
+		return c.dynamic.Resource(mapping.Resource), nil
+	}
+	if obj.GetNamespace() == "" {
+		if namespace == "" {
+			namespace = metav1.NamespaceDefault
+		}
+		obj.SetNamespace(namespace)
+	}
+
+	return c.dynamic.Resource(mapping.Resource).Namespace(obj.GetNamespace()), nil
+}
+
+// apply obj by server-side apply, and wait for it if needed. changed is true if the object is created or modified.
+func (c *k8sClient) apply(ctx context.Context, ri dynamic.ResourceInterface, obj *unstructured.Unstructured, ka *k8sArgs, dryRun bool) (*unstructured.Unstructured, bool, error) {
+	existing, err := ri.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, false, err
+	}
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return nil, false, err
+	}
+	opts := metav1.PatchOptions{FieldManager: ka.fieldManager, Force: &ka.forceConflicts}
+	if dryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	result, err := ri.Patch(ctx, obj.GetName(), types.ApplyPatchType, data, opts)
+	if err != nil {
+		return nil, false, err
+	}
+	changed := existing == nil || !sameObject(existing, result)
+	if !ka.wait || dryRun {
+		return result, changed, nil
+	}
+
+	result, err = waitObject(ctx, ri, obj.GetName(), ka)
+
+	return result, changed, err
+}
+
+// delete obj, and wait for it to be removed if needed. changed is true if the object is exist.
+func (c *k8sClient) delete(ctx context.Context, ri dynamic.ResourceInterface, obj *unstructured.Unstructured, ka *k8sArgs, dryRun bool) (*unstructured.Unstructured, bool, error) {
+	existing, err := ri.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+	policy := metav1.DeletePropagationBackground
+	opts := metav1.DeleteOptions{PropagationPolicy: &policy}
+	if dryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	if err := ri.Delete(ctx, obj.GetName(), opts); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, false, nil
+		}
+
+		return nil, false, err
+	}
+	if !ka.wait || dryRun {
+		return existing, true, nil
+	}
+
+	return existing, true, wait.PollUntilContextTimeout(ctx, k8sWaitInterval, ka.waitTimeout, true, func(ctx context.Context) (bool, error) {
+		_, err := ri.Get(ctx, obj.GetName(), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+
+		return false, err
+	})
+}
+
+// waitObject wait for the object to meet the wait_condition. if wait_condition is not set, wait for the rollout of workload.
+func waitObject(ctx context.Context, ri dynamic.ResourceInterface, name string, ka *k8sArgs) (*unstructured.Unstructured, error) {
+	var current *unstructured.Unstructured
+	if err := wait.PollUntilContextTimeout(ctx, k8sWaitInterval, ka.waitTimeout, true, func(ctx context.Context) (bool, error) {
+		var err error
+		current, err = ri.Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		if ka.waitCondition != nil {
+			return conditionMet(current, *ka.waitCondition), nil
+		}
+
+		return rolloutComplete(current), nil
+	}); err != nil {
+		return current, fmt.Errorf("wait for %s error: %w", name, err)
+	}
+
+	return current, nil
+}
+
+// conditionMet check whether the condition in status of obj matches cond.
+func conditionMet(obj *unstructured.Unstructured, cond k8sWaitCondition) bool {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		cm, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		if cm["type"] == cond.Type {
+			return cm["status"] == cond.Status
+		}
+	}
+
+	return false
+}
+
+// rolloutComplete check whether all replicas of Deployment, StatefulSet or DaemonSet are updated and available.
+// the other kinds are complete once they exist.
+func rolloutComplete(obj *unstructured.Unstructured) bool {
+	generation := obj.GetGeneration()
+	observed, _, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	status := func(field string) int64 {
+		v, _, _ := unstructured.NestedInt64(obj.Object, "status", field)
+
+		return v
+	}
+	replicas := func() int64 {
+		v, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+		if !found {
+			// default replicas
+			return 1
+		}
+
+		return v
+	}
+
+	switch obj.GroupVersionKind().GroupKind().String() {
+	case "Deployment.apps":
+		return observed >= generation && status("updatedReplicas") == replicas() &&
+			status("replicas") == status("updatedReplicas") && status("availableReplicas") == replicas()
+	case "StatefulSet.apps":
+		current, _, _ := unstructured.NestedString(obj.Object, "status", "currentRevision")
+		update, _, _ := unstructured.NestedString(obj.Object, "status", "updateRevision")
+		strategy, _, _ := unstructured.NestedString(obj.Object, "spec", "updateStrategy", "type")
+		// partitioned rolling update does not update all replicas. only check the ready replicas.
+		if _, partitioned, _ := unstructured.NestedInt64(obj.Object, "spec", "updateStrategy", "rollingUpdate", "partition"); partitioned || strategy == "OnDelete" {
+			return observed >= generation && status("readyReplicas") == replicas()
+		}
+
+		return observed >= generation && status("readyReplicas") == replicas() && status("updatedReplicas") == replicas() && current == update
+	case "DaemonSet.apps":
+		desired := status("desiredNumberScheduled")
+
+		return observed >= generation && status("updatedNumberScheduled") == desired && status("numberAvailable") == desired
+	default:
+		return true
+	}
+}
+
+// sameObject check whether the objects are same except the fields which changed by server.
+func sameObject(a, b *unstructured.Unstructured) bool {
+	strip := func(obj *unstructured.Unstructured) map[string]any {
+		o := obj.DeepCopy()
+		unstructured.RemoveNestedField(o.Object, "metadata", "resourceVersion")
+		unstructured.RemoveNestedField(o.Object, "metadata", "managedFields")
+		unstructured.RemoveNestedField(o.Object, "metadata", "generation")
+		unstructured.RemoveNestedField(o.Object, "status")
+
+		return o.Object
+	}
+
+	return equality.Semantic.DeepEqual(strip(a), strip(b))
+}
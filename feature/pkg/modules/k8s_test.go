@@ -0,0 +1,223 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestParseManifests(t *testing.T) {
+	testcases := []struct {
+		name     string
+		manifest string
+		except   []string
+		err      bool
+	}{
+		{
+			name: "multi documents with empty document",
+			manifest: `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: test
+---
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: test
+  namespace: test
+`,
+			except: []string{"Namespace/test", "ConfigMap/test"},
+		},
+		{
+			name: "list is expanded",
+			manifest: `
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: a
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: b
+`,
+			except: []string{"ConfigMap/a", "ConfigMap/b"},
+		},
+		{
+			name: "missing kind",
+			manifest: `
+apiVersion: v1
+metadata:
+  name: test
+`,
+			err: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			objs, err := parseManifests([]byte(tc.manifest))
+			if tc.err {
+				assert.Error(t, err)
+
+				return
+			}
+			assert.NoError(t, err)
+			var actual []string
+			for _, obj := range objs {
+				actual = append(actual, obj.GetKind()+"/"+obj.GetName())
+			}
+			assert.Equal(t, tc.except, actual)
+		})
+	}
+}
+
+func TestRolloutComplete(t *testing.T) {
+	testcases := []struct {
+		name   string
+		obj    map[string]any
+		except bool
+	}{
+		{
+			name: "deployment complete",
+			obj: map[string]any{
+				"apiVersion": "apps/v1", "kind": "Deployment",
+				"metadata": map[string]any{"name": "test", "generation": int64(2)},
+				"spec":     map[string]any{"replicas": int64(2)},
+				"status": map[string]any{"observedGeneration": int64(2), "replicas": int64(2),
+					"updatedReplicas": int64(2), "availableReplicas": int64(2)},
+			},
+			except: true,
+		},
+		{
+			name: "deployment generation not observed",
+			obj: map[string]any{
+				"apiVersion": "apps/v1", "kind": "Deployment",
+				"metadata": map[string]any{"name": "test", "generation": int64(3)},
+				"spec":     map[string]any{"replicas": int64(2)},
+				"status": map[string]any{"observedGeneration": int64(2), "replicas": int64(2),
+					"updatedReplicas": int64(2), "availableReplicas": int64(2)},
+			},
+			except: false,
+		},
+		{
+			name: "daemonset not available",
+			obj: map[string]any{
+				"apiVersion": "apps/v1", "kind": "DaemonSet",
+				"metadata": map[string]any{"name": "test", "generation": int64(1)},
+				"status": map[string]any{"observedGeneration": int64(1), "desiredNumberScheduled": int64(3),
+					"updatedNumberScheduled": int64(3), "numberAvailable": int64(2)},
+			},
+			except: false,
+		},
+		{
+			name: "statefulset revision not updated",
+			obj: map[string]any{
+				"apiVersion": "apps/v1", "kind": "StatefulSet",
+				"metadata": map[string]any{"name": "test", "generation": int64(1)},
+				"spec":     map[string]any{"replicas": int64(1)},
+				"status": map[string]any{"observedGeneration": int64(1), "readyReplicas": int64(1), "updatedReplicas": int64(1),
+					"currentRevision": "test-1", "updateRevision": "test-2"},
+			},
+			except: false,
+		},
+		{
+			name: "other kind is complete once exist",
+			obj: map[string]any{
+				"apiVersion": "v1", "kind": "ConfigMap",
+				"metadata": map[string]any{"name": "test"},
+			},
+			except: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.except, rolloutComplete(&unstructured.Unstructured{Object: tc.obj}))
+		})
+	}
+}
+
+func TestConditionMet(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"status": map[string]any{
+			"conditions": []any{
+				map[string]any{"type": "Ready", "status": "True"},
+				map[string]any{"type": "Progressing", "status": "False"},
+			},
+		},
+	}}
+
+	assert.True(t, conditionMet(obj, k8sWaitCondition{Type: "Ready", Status: "True"}))
+	assert.False(t, conditionMet(obj, k8sWaitCondition{Type: "Progressing", Status: "True"}))
+	assert.False(t, conditionMet(obj, k8sWaitCondition{Type: "Available", Status: "True"}))
+}
+
+func TestK8sClient_Delete(t *testing.T) {
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	newConfigMap := func(name string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1", "kind": "ConfigMap",
+			"metadata": map[string]any{"name": name, "namespace": "kube-system"},
+		}}
+	}
+	dc := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "ConfigMapList"}, newConfigMap("exist"))
+	client := &k8sClient{dynamic: dc, mapper: meta.MultiRESTMapper{mapper}}
+	ka := &k8sArgs{state: k8sStateAbsent, namespace: "kube-system", wait: true, waitTimeout: 5 * time.Second}
+
+	results, changed, err := client.run(context.Background(), ka, []*unstructured.Unstructured{
+		newConfigMap("exist"), newConfigMap("not-exist"),
+	}, false)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Len(t, results, 1)
+
+	// delete again is not changed.
+	_, changed, err = client.run(context.Background(), ka, []*unstructured.Unstructured{newConfigMap("exist")}, false)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestSameObject(t *testing.T) {
+	newConfigMap := func(resourceVersion, data string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1", "kind": "ConfigMap",
+			"metadata": map[string]any{"name": "test", "resourceVersion": resourceVersion},
+			"data":     map[string]any{"key": data},
+		}}
+	}
+
+	// the fields changed by server are ignored.
+	assert.True(t, sameObject(newConfigMap("1", "a"), newConfigMap("2", "a")))
+	assert.False(t, sameObject(newConfigMap("1", "a"), newConfigMap("2", "b")))
+}
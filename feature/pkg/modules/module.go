@@ -17,8 +17,13 @@ limitations under the License.
 package modules
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"os"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -28,6 +33,7 @@ import (
 	kkcorev1alpha1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1alpha1"
 	"github.com/kubesphere/kubekey/v4/pkg/connector"
 	_const "github.com/kubesphere/kubekey/v4/pkg/const"
+	"github.com/kubesphere/kubekey/v4/pkg/project"
 	"github.com/kubesphere/kubekey/v4/pkg/variable"
 )
 
@@ -43,8 +49,8 @@ const (
 	StdoutFalse = "False"
 )
 
-// ModuleExecFunc exec module
-type ModuleExecFunc func(ctx context.Context, options ExecOptions) (stdout string, stderr string)
+// ModuleExecFunc exec module. changed is whether the module has changed the state of host.
+type ModuleExecFunc func(ctx context.Context, options ExecOptions) (stdout string, stderr string, changed bool)
 
 // ExecOptions for module
 type ExecOptions struct {
@@ -52,12 +58,18 @@ type ExecOptions struct {
 	Args runtime.RawExtension
 	// which Host to execute
 	Host string
+	// DelegateTo is the host which module actually connect to. if empty, connect to Host.
+	DelegateTo string
 	// the variable module need
 	variable.Variable
 	// the task to be executed
 	Task kkcorev1alpha1.Task
 	// the pipeline to be executed
 	Pipeline kkcorev1.Pipeline
+	// Connectors cache the connections of hosts in pipeline. if nil, create a new connection.
+	Connectors *connector.Pool
+	// DiffOutput receive the unified diff of files which changed by module in diff mode. if nil, the diff is dropped.
+	DiffOutput io.Writer
 }
 
 func (o ExecOptions) getAllVariables() (map[string]any, error) {
@@ -74,6 +86,26 @@ func (o ExecOptions) getAllVariables() (map[string]any, error) {
 	return vd, nil
 }
 
+// checkMode returns whether module should only report what it would change, without changing the host.
+// the "check_mode" of task overrides the pipeline's.
+func (o ExecOptions) checkMode() bool {
+	if o.Task.Spec.CheckMode != nil {
+		return *o.Task.Spec.CheckMode
+	}
+
+	return o.Pipeline.Spec.CheckMode
+}
+
+// diffMode returns whether module should report the difference of files it changes.
+// the "diff" of task overrides the pipeline's.
+func (o ExecOptions) diffMode() bool {
+	if o.Task.Spec.Diff != nil {
+		return *o.Task.Spec.Diff
+	}
+
+	return o.Pipeline.Spec.Diff
+}
+
 var module = make(map[string]ModuleExecFunc)
 
 // RegisterModule register module
@@ -103,12 +135,16 @@ func init() {
 	utilruntime.Must(RegisterModule("set_fact", ModuleSetFact))
 	utilruntime.Must(RegisterModule("gen_cert", ModuleGenCert))
 	utilruntime.Must(RegisterModule("image", ModuleImage))
+	utilruntime.Must(RegisterModule("async_status", ModuleAsyncStatus))
+	utilruntime.Must(RegisterModule("k8s", ModuleK8s))
 }
 
 // ConnKey for connector which store in context
 var ConnKey = struct{}{}
 
-func getConnector(ctx context.Context, host string, data map[string]any) (connector.Connector, error) {
+// getConnector get connector of the host which module execute in. it's the delegated host when "delegate_to" is set.
+// the connector variables are taken from the host which connect to.
+func (o ExecOptions) getConnector(ctx context.Context) (connector.Connector, error) {
 	var conn connector.Connector
 	var err error
 
@@ -116,7 +152,21 @@ func getConnector(ctx context.Context, host string, data map[string]any) (connec
 		if vd, ok := v.(connector.Connector); ok {
 			conn = vd
 		}
+		if err = conn.Init(ctx); err != nil {
+			klog.V(4).ErrorS(err, "failed to init connector")
+
+			return conn, err
+		}
 	} else {
+		host := o.Host
+		if o.DelegateTo != "" {
+			host = o.DelegateTo
+		}
+		data, err := ExecOptions{Host: host, Variable: o.Variable}.getAllVariables()
+		if err != nil {
+			return conn, err
+		}
+
 		connectorVars := make(map[string]any)
 
 		if c1, ok := data[_const.VariableConnector]; ok {
@@ -125,17 +175,92 @@ func getConnector(ctx context.Context, host string, data map[string]any) (connec
 			}
 		}
 
-		conn, err = connector.NewConnector(host, connectorVars)
+		conn, err = o.Connectors.Get(ctx, host, connectorVars)
 		if err != nil {
+			klog.V(4).ErrorS(err, "failed to init connector")
+
 			return conn, err
 		}
 	}
 
-	if err = conn.Init(ctx); err != nil {
-		klog.V(4).ErrorS(err, "failed to init connector")
-
-		return conn, err
+	// execute as another user in host. the connector which not support become execute as the connected user.
+	if become := o.Task.Spec.Become; become.Enable {
+		if becomer, ok := conn.(connector.Becomer); ok {
+			becomer.SetBecome(connector.Become{
+				Method: become.Method,
+				User:   become.User,
+				Flags:  become.Flags,
+				Exe:    become.Exe,
+			})
+		} else {
+			klog.V(4).InfoS("connector does not support become, execute as the connected user", "host", o.Host)
+		}
+	}
+	if o.checkMode() || o.diffMode() {
+		return &diffConnector{Connector: conn, check: o.checkMode(), diff: o.diffMode()}, nil
 	}
 
 	return conn, nil
 }
+
+// putFile copy src to dst in host when the content, mode or owner of dst is different from src.
+// return true if dst has changed.
+func putFile(ctx context.Context, conn connector.Connector, src []byte, dst string, option connector.FileOption) (bool, error) {
+	return putFileStream(ctx, conn, func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(src)), nil
+	}, dst, option)
+}
+
+// putLocalFile copy the local file src to dst in host. the file is streamed, not read to memory.
+// return true if dst has changed.
+func putLocalFile(ctx context.Context, conn connector.Connector, src, dst string, option connector.FileOption) (bool, error) {
+	return putFileStream(ctx, conn, func() (io.ReadCloser, error) {
+		return os.Open(src)
+	}, dst, option)
+}
+
+// putProjectFile copy the file src in project to dst in host. the file is streamed, not read to memory.
+// return true if dst has changed.
+func putProjectFile(ctx context.Context, conn connector.Connector, pj project.Project, src string, fileOption project.GetFileOption, dst string, option connector.FileOption) (bool, error) {
+	return putFileStream(ctx, conn, func() (io.ReadCloser, error) {
+		return pj.OpenFile(src, fileOption)
+	}, dst, option)
+}
+
+// putFileStream copy the content opened by open to dst in host when dst is different from it.
+// open is called twice: one for calculating the checksum, and one for uploading.
+func putFileStream(ctx context.Context, conn connector.Connector, open func() (io.ReadCloser, error), dst string, option connector.FileOption) (bool, error) {
+	src, err := open()
+	if err != nil {
+		return false, err
+	}
+	h := sha256.New()
+	_, err = io.Copy(h, src)
+	src.Close()
+	if err != nil {
+		return false, err
+	}
+	option.Sha256 = hex.EncodeToString(h.Sum(nil))
+
+	if stater, ok := conn.(connector.FileStater); ok {
+		if stat, err := stater.StatFile(ctx, dst); err == nil && fileUnchanged(stat, option) {
+			return false, nil
+		}
+	}
+
+	if src, err = open(); err != nil {
+		return false, err
+	}
+	defer src.Close()
+	if err := connector.PutFileStream(ctx, conn, src, dst, option); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// fileUnchanged check whether the file in host is same as option. owner and group are only checked when they are set.
+func fileUnchanged(stat *connector.FileStat, option connector.FileOption) bool {
+	return stat.Sha256 == option.Sha256 && stat.Mode.Perm() == option.Mode.Perm() &&
+		(option.Owner == "" || stat.Owner == option.Owner) && (option.Group == "" || stat.Group == option.Group)
+}
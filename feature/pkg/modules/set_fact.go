@@ -24,13 +24,18 @@ import (
 )
 
 // ModuleSetFact deal "set_fact" module
-func ModuleSetFact(_ context.Context, options ExecOptions) (string, string) {
+func ModuleSetFact(_ context.Context, options ExecOptions) (string, string, bool) {
 	// get host variable
 	args := variable.Extension2Variables(options.Args)
+	// the facts are parsed by the original host, and set to the delegated host when "delegate_facts" is set.
+	host := options.Host
+	if options.Task.Spec.DelegateFacts && options.DelegateTo != "" {
+		host = options.DelegateTo
+	}
 
-	if err := options.Variable.Merge(variable.MergeAllRuntimeVariable(args, options.Host)); err != nil {
-		return "", fmt.Sprintf("set_fact error: %v", err)
+	if err := options.Variable.Merge(variable.MergeHostRuntimeVariable(args, options.Host, host)); err != nil {
+		return "", fmt.Sprintf("set_fact error: %v", err), false
 	}
 
-	return StdoutSuccess, ""
+	return StdoutSuccess, "", false
 }
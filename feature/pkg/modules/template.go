@@ -38,9 +38,11 @@ import (
 )
 
 type templateArgs struct {
-	src  string
-	dest string
-	mode *int
+	src   string
+	dest  string
+	mode  *int
+	owner string
+	group string
 }
 
 func newTemplateArgs(_ context.Context, raw runtime.RawExtension, vars map[string]any) (*templateArgs, error) {
@@ -62,82 +64,92 @@ func newTemplateArgs(_ context.Context, raw runtime.RawExtension, vars map[strin
 	}
 
 	ta.mode, _ = variable.IntVar(vars, args, "mode")
+	ta.owner, _ = variable.StringVar(vars, args, "owner")
+	ta.group, _ = variable.StringVar(vars, args, "group")
 
 	return ta, nil
 }
 
+// fileOption of dest file. keep the owner of replaced file if owner and group are not set.
+func (ta templateArgs) fileOption(mode fs.FileMode) connector.FileOption {
+	return connector.FileOption{Mode: mode, Owner: ta.owner, Group: ta.group}
+}
+
 // ModuleTemplate deal "template" module
-func ModuleTemplate(ctx context.Context, options ExecOptions) (string, string) {
+func ModuleTemplate(ctx context.Context, options ExecOptions) (string, string, bool) {
 	// get host variable
 	ha, err := options.getAllVariables()
 	if err != nil {
-		return "", err.Error()
+		return "", err.Error(), false
 	}
 
 	ta, err := newTemplateArgs(ctx, options.Args, ha)
 	if err != nil {
 		klog.V(4).ErrorS(err, "get template args error", "task", ctrlclient.ObjectKeyFromObject(&options.Task))
 
-		return "", err.Error()
+		return "", err.Error(), false
 	}
 
 	// get connector
-	conn, err := getConnector(ctx, options.Host, ha)
+	conn, err := options.getConnector(ctx)
 	if err != nil {
-		return "", err.Error()
+		return "", err.Error(), false
 	}
 	defer conn.Close(ctx)
 
+	var changed bool
 	if filepath.IsAbs(ta.src) {
 		fileInfo, err := os.Stat(ta.src)
 		if err != nil {
-			return "", fmt.Sprintf(" get src file %s in local path error: %v", ta.src, err)
+			return "", fmt.Sprintf(" get src file %s in local path error: %v", ta.src, err), false
 		}
 
 		if fileInfo.IsDir() { // src is dir
-			if err := ta.absDir(ctx, conn, ha); err != nil {
-				return "", fmt.Sprintf("sync template absolute dir error %s", err)
+			if changed, err = ta.absDir(ctx, conn, ha); err != nil {
+				return "", fmt.Sprintf("sync template absolute dir error %s", err), false
 			}
 		} else { // src is file
-			if err := ta.absFile(ctx, fileInfo.Mode(), conn, ha); err != nil {
-				return "", fmt.Sprintf("sync template absolute file error %s", err)
+			if changed, err = ta.absFile(ctx, fileInfo.Mode(), conn, ha); err != nil {
+				return "", fmt.Sprintf("sync template absolute file error %s", err), false
 			}
 		}
 	} else {
 		pj, err := project.New(ctx, options.Pipeline, false)
 		if err != nil {
-			return "", fmt.Sprintf("get project error: %v", err)
+			return "", fmt.Sprintf("get project error: %v", err), false
 		}
 
 		fileInfo, err := pj.Stat(ta.src, project.GetFileOption{IsTemplate: true, Role: options.Task.Annotations[kkcorev1alpha1.TaskAnnotationRole]})
 		if err != nil {
-			return "", fmt.Sprintf("get file %s from project error: %v", ta.src, err)
+			return "", fmt.Sprintf("get file %s from project error: %v", ta.src, err), false
 		}
 
 		if fileInfo.IsDir() {
-			if err := ta.relDir(ctx, pj, options.Task.Annotations[kkcorev1alpha1.TaskAnnotationRole], conn, ha); err != nil {
-				return "", fmt.Sprintf("sync template relative dir error: %s", err)
+			if changed, err = ta.relDir(ctx, pj, options.Task.Annotations[kkcorev1alpha1.TaskAnnotationRole], conn, ha); err != nil {
+				return "", fmt.Sprintf("sync template relative dir error: %s", err), false
 			}
 		} else {
-			if err := ta.relFile(ctx, pj, options.Task.Annotations[kkcorev1alpha1.TaskAnnotationRole], fileInfo.Mode(), conn, ha); err != nil {
-				return "", fmt.Sprintf("sync template relative dir error: %s", err)
+			if changed, err = ta.relFile(ctx, pj, options.Task.Annotations[kkcorev1alpha1.TaskAnnotationRole], fileInfo.Mode(), conn, ha); err != nil {
+				return "", fmt.Sprintf("sync template relative dir error: %s", err), false
 			}
 		}
 	}
 
-	return StdoutSuccess, ""
+	writeDiff(conn, options)
+
+	return StdoutSuccess, "", changed
 }
 
 // relFile when template.src is relative file, get file from project, parse it, and copy to remote.
-func (ta templateArgs) relFile(ctx context.Context, pj project.Project, role string, mode fs.FileMode, conn connector.Connector, vars map[string]any) any {
+func (ta templateArgs) relFile(ctx context.Context, pj project.Project, role string, mode fs.FileMode, conn connector.Connector, vars map[string]any) (bool, error) {
 	data, err := pj.ReadFile(ta.src, project.GetFileOption{IsTemplate: true, Role: role})
 	if err != nil {
-		return fmt.Errorf("read file error: %w", err)
+		return false, fmt.Errorf("read file error: %w", err)
 	}
 
 	result, err := tmpl.ParseString(vars, string(data))
 	if err != nil {
-		return fmt.Errorf("parse file error: %w", err)
+		return false, fmt.Errorf("parse file error: %w", err)
 	}
 
 	dest := ta.dest
@@ -149,15 +161,17 @@ func (ta templateArgs) relFile(ctx context.Context, pj project.Project, role str
 		mode = os.FileMode(*ta.mode)
 	}
 
-	if err := conn.PutFile(ctx, []byte(result), dest, mode); err != nil {
-		return fmt.Errorf("copy file error: %w", err)
+	changed, err := putFile(ctx, conn, []byte(result), dest, ta.fileOption(mode))
+	if err != nil {
+		return false, fmt.Errorf("copy file error: %w", err)
 	}
 
-	return nil
+	return changed, nil
 }
 
 // relDir when template.src is relative dir, get all files from project, parse it, and copy to remote.
-func (ta templateArgs) relDir(ctx context.Context, pj project.Project, role string, conn connector.Connector, vars map[string]any) error {
+func (ta templateArgs) relDir(ctx context.Context, pj project.Project, role string, conn connector.Connector, vars map[string]any) (bool, error) {
+	var changed bool
 	if err := pj.WalkDir(ta.src, project.GetFileOption{IsTemplate: true, Role: role}, func(path string, d fs.DirEntry, err error) error {
 		if d.IsDir() { // only copy file
 			return nil
@@ -194,28 +208,30 @@ func (ta templateArgs) relDir(ctx context.Context, pj project.Project, role stri
 			dest = filepath.Join(ta.dest, rel)
 		}
 
-		if err := conn.PutFile(ctx, []byte(result), dest, mode); err != nil {
+		fileChanged, err := putFile(ctx, conn, []byte(result), dest, ta.fileOption(mode))
+		if err != nil {
 			return fmt.Errorf("copy file error: %w", err)
 		}
+		changed = changed || fileChanged
 
 		return nil
 	}); err != nil {
-		return err
+		return false, err
 	}
 
-	return nil
+	return changed, nil
 }
 
 // absFile when template.src is absolute file, get file by os, parse it, and copy to remote.
-func (ta templateArgs) absFile(ctx context.Context, mode fs.FileMode, conn connector.Connector, vars map[string]any) error {
+func (ta templateArgs) absFile(ctx context.Context, mode fs.FileMode, conn connector.Connector, vars map[string]any) (bool, error) {
 	data, err := os.ReadFile(ta.src)
 	if err != nil {
-		return fmt.Errorf("read file error: %w", err)
+		return false, fmt.Errorf("read file error: %w", err)
 	}
 
 	result, err := tmpl.ParseString(vars, string(data))
 	if err != nil {
-		return fmt.Errorf("parse file error: %w", err)
+		return false, fmt.Errorf("parse file error: %w", err)
 	}
 
 	dest := ta.dest
@@ -227,15 +243,17 @@ func (ta templateArgs) absFile(ctx context.Context, mode fs.FileMode, conn conne
 		mode = os.FileMode(*ta.mode)
 	}
 
-	if err := conn.PutFile(ctx, []byte(result), dest, mode); err != nil {
-		return fmt.Errorf("copy file error: %w", err)
+	changed, err := putFile(ctx, conn, []byte(result), dest, ta.fileOption(mode))
+	if err != nil {
+		return false, fmt.Errorf("copy file error: %w", err)
 	}
 
-	return nil
+	return changed, nil
 }
 
 // absDir when template.src is absolute dir, get all files by os, parse it, and copy to remote.
-func (ta templateArgs) absDir(ctx context.Context, conn connector.Connector, vars map[string]any) error {
+func (ta templateArgs) absDir(ctx context.Context, conn connector.Connector, vars map[string]any) (bool, error) {
+	var changed bool
 	if err := filepath.WalkDir(ta.src, func(path string, d fs.DirEntry, err error) error {
 		if d.IsDir() { // only copy file
 			return nil
@@ -272,14 +290,16 @@ func (ta templateArgs) absDir(ctx context.Context, conn connector.Connector, var
 			dest = filepath.Join(ta.dest, rel)
 		}
 
-		if err := conn.PutFile(ctx, []byte(result), dest, mode); err != nil {
+		fileChanged, err := putFile(ctx, conn, []byte(result), dest, ta.fileOption(mode))
+		if err != nil {
 			return fmt.Errorf("copy file error: %w", err)
 		}
+		changed = changed || fileChanged
 
 		return nil
 	}); err != nil {
-		return err
+		return false, err
 	}
 
-	return nil
+	return changed, nil
 }
@@ -78,7 +78,7 @@ func TestTemplate(t *testing.T) {
 			ctx, cancel := context.WithTimeout(tc.ctxFunc(), time.Second*5)
 			defer cancel()
 
-			acStdout, acStderr := ModuleTemplate(ctx, tc.opt)
+			acStdout, acStderr, _ := ModuleTemplate(ctx, tc.opt)
 			assert.Equal(t, tc.exceptStdout, acStdout)
 			assert.Equal(t, tc.exceptStderr, acStderr)
 		})
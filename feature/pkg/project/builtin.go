@@ -89,6 +89,16 @@ func (p builtinProject) MarshalPlaybook() (*kkprojectv1.Playbook, error) {
 	return marshalPlaybook(p.FS, p.playbook)
 }
 
+// MarshalIncludeTasks project file to blocks.
+func (p builtinProject) MarshalIncludeTasks(role string, file string) ([]kkprojectv1.Block, error) {
+	return marshalIncludeTasks(p.FS, p.playbook, role, file)
+}
+
+// MarshalRole project role to kkprojectv1.Role.
+func (p builtinProject) MarshalRole(ri kkprojectv1.RoleInclude) (*kkprojectv1.Role, error) {
+	return convertRole(p.FS, p.playbook, ri)
+}
+
 // Stat role/file/template file or dir in project
 func (p builtinProject) Stat(path string, option GetFileOption) (os.FileInfo, error) {
 	return fs.Stat(p.FS, p.getFilePath(path, option))
@@ -104,6 +114,11 @@ func (p builtinProject) ReadFile(path string, option GetFileOption) ([]byte, err
 	return fs.ReadFile(p.FS, p.getFilePath(path, option))
 }
 
+// OpenFile role/file/template file in project
+func (p builtinProject) OpenFile(path string, option GetFileOption) (fs.File, error) {
+	return p.FS.Open(p.getFilePath(path, option))
+}
+
 // Rel path for role/file/template file or dir in project
 func (p builtinProject) Rel(root string, path string, option GetFileOption) (string, error) {
 	return filepath.Rel(p.getFilePath(root, option), path)
@@ -163,6 +163,16 @@ func (p gitProject) MarshalPlaybook() (*kkprojectv1.Playbook, error) {
 	return marshalPlaybook(os.DirFS(p.projectDir), p.Pipeline.Spec.Playbook)
 }
 
+// MarshalIncludeTasks project file to blocks.
+func (p gitProject) MarshalIncludeTasks(role string, file string) ([]kkprojectv1.Block, error) {
+	return marshalIncludeTasks(os.DirFS(p.projectDir), p.Pipeline.Spec.Playbook, role, file)
+}
+
+// MarshalRole project role to kkprojectv1.Role.
+func (p gitProject) MarshalRole(ri kkprojectv1.RoleInclude) (*kkprojectv1.Role, error) {
+	return convertRole(os.DirFS(p.projectDir), p.Pipeline.Spec.Playbook, ri)
+}
+
 // Stat role/file/template file or dir in project
 func (p gitProject) Stat(path string, option GetFileOption) (os.FileInfo, error) {
 	return os.Stat(p.getFilePath(path, option))
@@ -178,6 +188,11 @@ func (p gitProject) ReadFile(path string, option GetFileOption) ([]byte, error)
 	return os.ReadFile(p.getFilePath(path, option))
 }
 
+// OpenFile role/file/template file in project
+func (p gitProject) OpenFile(path string, option GetFileOption) (fs.File, error) {
+	return os.Open(p.getFilePath(path, option))
+}
+
 // Rel path for role/file/template file or dir in project
 func (p gitProject) Rel(root string, path string, option GetFileOption) (string, error) {
 	return filepath.Rel(p.getFilePath(root, option), path)
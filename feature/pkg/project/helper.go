@@ -19,8 +19,10 @@ package project
 import (
 	"fmt"
 	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 
@@ -164,13 +166,17 @@ func convertRoles(baseFS fs.FS, pbPath string, pb *kkprojectv1.Playbook) error {
 			}
 
 			var err error
-			if p.Roles[i].Block, err = convertRoleBlocks(baseFS, pbPath, roleBase); err != nil {
+			if p.Roles[i].Block, err = convertRoleBlocks(baseFS, pbPath, roleBase, _const.ProjectRolesTasksMainFile); err != nil {
 				return fmt.Errorf("convert role %s  tasks failed: %w", r.Role, err)
 			}
 
 			if p.Roles[i].Vars, err = convertRoleVars(baseFS, roleBase, p.Roles[i].Vars); err != nil {
 				return fmt.Errorf("convert role %s defaults failed: %w", r.Role, err)
 			}
+
+			if p.Roles[i].Handlers, err = convertRoleHandlers(baseFS, roleBase); err != nil {
+				return fmt.Errorf("convert role %s handlers failed: %w", r.Role, err)
+			}
 		}
 		pb.Play[i] = p
 	}
@@ -202,9 +208,28 @@ func convertRoleVars(baseFS fs.FS, roleBase string, roleVars map[string]any) (ma
 	return roleVars, nil
 }
 
-// convertRoleBlocks roles/task/main.yaml to []kkprojectv1.Block
-func convertRoleBlocks(baseFS fs.FS, pbPath string, roleBase string) ([]kkprojectv1.Block, error) {
-	mainTask := getYamlFile(baseFS, filepath.Join(roleBase, _const.ProjectRolesTasksDir, _const.ProjectRolesTasksMainFile))
+// convertRoleHandlers roles/handlers/main.yaml to []kkprojectv1.Handler (optional)
+func convertRoleHandlers(baseFS fs.FS, roleBase string) ([]kkprojectv1.Handler, error) {
+	mainHandler := getYamlFile(baseFS, filepath.Join(roleBase, _const.ProjectRolesHandlersDir, _const.ProjectRolesHandlersMainFile))
+	if mainHandler == "" {
+		return nil, nil
+	}
+
+	data, err := fs.ReadFile(baseFS, mainHandler)
+	if err != nil {
+		return nil, fmt.Errorf("read file %s failed: %w", mainHandler, err)
+	}
+	var handlers []kkprojectv1.Handler
+	if err := yaml.Unmarshal(data, &handlers); err != nil {
+		return nil, fmt.Errorf("unmarshal yaml file: %s failed: %w", mainHandler, err)
+	}
+
+	return handlers, nil
+}
+
+// convertRoleBlocks roles/task/main.yaml (or the tasksFrom file) to []kkprojectv1.Block
+func convertRoleBlocks(baseFS fs.FS, pbPath string, roleBase string, tasksFrom string) ([]kkprojectv1.Block, error) {
+	mainTask := getYamlFile(baseFS, filepath.Join(roleBase, _const.ProjectRolesTasksDir, strings.TrimSuffix(strings.TrimSuffix(tasksFrom, ".yaml"), ".yml")))
 	if mainTask == "" {
 		return nil, fmt.Errorf("cannot found main task for Role %s", roleBase)
 	}
@@ -221,25 +246,26 @@ func convertRoleBlocks(baseFS fs.FS, pbPath string, roleBase string) ([]kkprojec
 	return blocks, nil
 }
 
-// convertIncludeTasks from file to blocks
+// convertIncludeTasks from import_tasks and import_role file to blocks.
+// include_tasks and include_role are loaded when executing.
 func convertIncludeTasks(baseFS fs.FS, pbPath string, pb *kkprojectv1.Playbook) error {
 	var pbBase = filepath.Dir(filepath.Dir(pbPath))
 	for _, play := range pb.Play {
-		if err := fileToBlock(baseFS, pbBase, play.PreTasks); err != nil {
+		if err := fileToBlock(baseFS, pbPath, pbBase, play.PreTasks); err != nil {
 			return fmt.Errorf("convert pre_tasks file %s failed: %w", pbPath, err)
 		}
 
-		if err := fileToBlock(baseFS, pbBase, play.Tasks); err != nil {
+		if err := fileToBlock(baseFS, pbPath, pbBase, play.Tasks); err != nil {
 			return fmt.Errorf("convert tasks file %s failed: %w", pbPath, err)
 		}
 
-		if err := fileToBlock(baseFS, pbBase, play.PostTasks); err != nil {
+		if err := fileToBlock(baseFS, pbPath, pbBase, play.PostTasks); err != nil {
 			return fmt.Errorf("convert post_tasks file %s failed: %w", pbPath, err)
 		}
 
 		for _, r := range play.Roles {
 			roleBase := getRoleBaseFromPlaybook(baseFS, pbPath, r.Role)
-			if err := fileToBlock(baseFS, filepath.Join(roleBase, _const.ProjectRolesTasksDir), r.Block); err != nil {
+			if err := fileToBlock(baseFS, pbPath, filepath.Join(roleBase, _const.ProjectRolesTasksDir), r.Block); err != nil {
 				return fmt.Errorf("convert role %s failed: %w", filepath.Join(pbPath, r.Role), err)
 			}
 		}
@@ -248,38 +274,119 @@ func convertIncludeTasks(baseFS fs.FS, pbPath string, pb *kkprojectv1.Playbook)
 	return nil
 }
 
-func fileToBlock(baseFS fs.FS, baseDir string, blocks []kkprojectv1.Block) error {
+// fileToBlock load import_tasks and import_role to block. import_tasks file is relative to baseDir.
+func fileToBlock(baseFS fs.FS, pbPath string, baseDir string, blocks []kkprojectv1.Block) error {
 	for i, b := range blocks {
-		if b.IncludeTasks != "" {
-			data, err := fs.ReadFile(baseFS, filepath.Join(baseDir, b.IncludeTasks))
+		switch {
+		case b.ImportTasks != "":
+			bs, err := convertTasksFile(baseFS, filepath.Join(baseDir, b.ImportTasks))
 			if err != nil {
-				return fmt.Errorf("read includeTask file %s failed: %w", filepath.Join(baseDir, b.IncludeTasks), err)
+				return err
 			}
-			var bs []kkprojectv1.Block
-			if err := yaml.Unmarshal(data, &bs); err != nil {
-				return fmt.Errorf("unmarshal includeTask file %s failed: %w", filepath.Join(baseDir, b.IncludeTasks), err)
+			if err := fileToBlock(baseFS, pbPath, baseDir, bs); err != nil {
+				return fmt.Errorf("convert import_tasks file %s failed: %w", filepath.Join(baseDir, b.ImportTasks), err)
 			}
-
 			b.Block = bs
 			blocks[i] = b
+		case b.ImportRole != nil:
+			role, err := convertRole(baseFS, pbPath, *b.ImportRole)
+			if err != nil {
+				return fmt.Errorf("convert import_role %s failed: %w", b.ImportRole.Name, err)
+			}
+			// the vars defined in block take precedence over role defaults.
+			b.Block = role.Block
+			b.Vars = combineVariables(role.Vars, b.Vars)
+			blocks[i] = b
 		}
 
-		if err := fileToBlock(baseFS, baseDir, b.Block); err != nil {
-			return fmt.Errorf("convert block file %s failed: %w", filepath.Join(baseDir, b.IncludeTasks), err)
+		if err := fileToBlock(baseFS, pbPath, baseDir, b.Block); err != nil {
+			return fmt.Errorf("convert block file %s failed: %w", filepath.Join(baseDir, b.ImportTasks), err)
 		}
 
-		if err := fileToBlock(baseFS, baseDir, b.Rescue); err != nil {
-			return fmt.Errorf("convert rescue file %s failed: %w", filepath.Join(baseDir, b.IncludeTasks), err)
+		if err := fileToBlock(baseFS, pbPath, baseDir, b.Rescue); err != nil {
+			return fmt.Errorf("convert rescue file %s failed: %w", filepath.Join(baseDir, b.ImportTasks), err)
 		}
 
-		if err := fileToBlock(baseFS, baseDir, b.Always); err != nil {
-			return fmt.Errorf("convert always file %s failed: %w", filepath.Join(baseDir, b.IncludeTasks), err)
+		if err := fileToBlock(baseFS, pbPath, baseDir, b.Always); err != nil {
+			return fmt.Errorf("convert always file %s failed: %w", filepath.Join(baseDir, b.ImportTasks), err)
 		}
 	}
 
 	return nil
 }
 
+// convertTasksFile tasks file to []kkprojectv1.Block
+func convertTasksFile(baseFS fs.FS, file string) ([]kkprojectv1.Block, error) {
+	data, err := fs.ReadFile(baseFS, file)
+	if err != nil {
+		return nil, fmt.Errorf("read tasks file %s failed: %w", file, err)
+	}
+	var bs []kkprojectv1.Block
+	if err := yaml.Unmarshal(data, &bs); err != nil {
+		return nil, fmt.Errorf("unmarshal tasks file %s failed: %w", file, err)
+	}
+
+	return bs, nil
+}
+
+// convertRole load role's tasks (main or tasks_from) and defaults to kkprojectv1.Role.
+// the import_tasks and import_role in role's tasks are also loaded.
+func convertRole(baseFS fs.FS, pbPath string, ri kkprojectv1.RoleInclude) (*kkprojectv1.Role, error) {
+	roleBase := getRoleBaseFromPlaybook(baseFS, pbPath, ri.Name)
+	if roleBase == "" {
+		return nil, fmt.Errorf("cannot found Role %s", ri.Name)
+	}
+	tasksFrom := ri.TasksFrom
+	if tasksFrom == "" {
+		tasksFrom = _const.ProjectRolesTasksMainFile
+	}
+
+	role := &kkprojectv1.Role{}
+	role.Role = ri.Name
+	var err error
+	if role.Block, err = convertRoleBlocks(baseFS, pbPath, roleBase, tasksFrom); err != nil {
+		return nil, fmt.Errorf("convert role %s tasks failed: %w", ri.Name, err)
+	}
+	if err := fileToBlock(baseFS, pbPath, filepath.Join(roleBase, _const.ProjectRolesTasksDir), role.Block); err != nil {
+		return nil, fmt.Errorf("convert role %s tasks failed: %w", ri.Name, err)
+	}
+	if role.Vars, err = convertRoleVars(baseFS, roleBase, nil); err != nil {
+		return nil, fmt.Errorf("convert role %s defaults failed: %w", ri.Name, err)
+	}
+
+	return role, nil
+}
+
+// marshalIncludeTasks load the file of include_tasks to blocks.
+// find from role's tasks dir if role is set.
+// find from current_playbook/file.
+// find from project/file.
+func marshalIncludeTasks(baseFS fs.FS, pbPath string, role string, file string) ([]kkprojectv1.Block, error) {
+	var find []string
+	if role != "" {
+		if roleBase := getRoleBaseFromPlaybook(baseFS, pbPath, role); roleBase != "" {
+			find = append(find, filepath.Join(roleBase, _const.ProjectRolesTasksDir, file))
+		}
+	}
+	find = append(find, filepath.Join(filepath.Dir(pbPath), file), filepath.Join(filepath.Dir(filepath.Dir(pbPath)), file))
+	for _, s := range find {
+		if _, err := fs.Stat(baseFS, s); err != nil {
+			continue
+		}
+		blocks, err := convertTasksFile(baseFS, s)
+		if err != nil {
+			return nil, err
+		}
+		if err := fileToBlock(baseFS, pbPath, filepath.Dir(s), blocks); err != nil {
+			return nil, fmt.Errorf("convert include_tasks file %s failed: %w", s, err)
+		}
+
+		return blocks, nil
+	}
+
+	return nil, fmt.Errorf("cannot found include_tasks file %s", file)
+}
+
 // getPlaybookBaseFromPlaybook find import_playbook path base on the current_playbook
 // find from project/playbooks/playbook if exists.
 // find from current_playbook/playbooks/playbook if exists.
@@ -355,6 +462,19 @@ func getYamlFile(baseFS fs.FS, base string) string {
 	return ""
 }
 
+// combineVariables combine v2 map to v1. the value in v2 will override v1.
+func combineVariables(v1, v2 map[string]any) map[string]any {
+	if len(v1) == 0 {
+		return v2
+	}
+
+	mv := make(map[string]any)
+	maps.Copy(mv, v1)
+	maps.Copy(mv, v2)
+
+	return mv
+}
+
 // combine v2 map to v1 if not repeat.
 func combineMaps(v1, v2 map[string]any) (map[string]any, error) {
 	if len(v1) == 0 {
@@ -235,6 +235,106 @@ func TestMarshalPlaybook(t *testing.T) {
 	}
 }
 
+func TestMarshalIncludeTasks(t *testing.T) {
+	testcases := []struct {
+		name   string
+		role   string
+		file   string
+		except []kkprojectv1.Block
+		err    bool
+	}{
+		{
+			name: "find from role's tasks dir",
+			role: "role2",
+			file: "include.yaml",
+			except: []kkprojectv1.Block{
+				{
+					BlockBase: kkprojectv1.BlockBase{Base: kkprojectv1.Base{Name: "role2 | include1"}},
+					Task: kkprojectv1.Task{UnknownField: map[string]any{
+						"debug": map[string]any{
+							"msg": "echo \"hello world\"",
+						},
+					}},
+				},
+			},
+		},
+		{
+			name: "find from current_playbook",
+			file: "include.yaml",
+			except: []kkprojectv1.Block{
+				{
+					BlockBase: kkprojectv1.BlockBase{Base: kkprojectv1.Base{Name: "playbook | include1"}},
+					Task: kkprojectv1.Task{UnknownField: map[string]any{
+						"debug": map[string]any{
+							"msg": "echo \"hello world\"",
+						},
+					}},
+				},
+			},
+		},
+		{
+			name: "cannot find",
+			file: "include2.yaml",
+			err:  true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			blocks, err := marshalIncludeTasks(os.DirFS("testdata"), filepath.Join("playbooks", "playbook1.yaml"), tc.role, tc.file)
+			if tc.err {
+				assert.Error(t, err)
+			} else {
+				assert.Equal(t, tc.except, blocks)
+			}
+		})
+	}
+}
+
+func TestConvertRole(t *testing.T) {
+	testcases := []struct {
+		name   string
+		role   kkprojectv1.RoleInclude
+		except *kkprojectv1.Role
+		err    bool
+	}{
+		{
+			name: "role with tasks_from",
+			role: kkprojectv1.RoleInclude{Name: "role2", TasksFrom: "include"},
+			except: &kkprojectv1.Role{RoleInfo: kkprojectv1.RoleInfo{
+				Base: kkprojectv1.Base{Vars: map[string]any{"a": "b"}},
+				Role: "role2",
+				Block: []kkprojectv1.Block{
+					{
+						BlockBase: kkprojectv1.BlockBase{Base: kkprojectv1.Base{Name: "role2 | include1"}},
+						Task: kkprojectv1.Task{UnknownField: map[string]any{
+							"debug": map[string]any{
+								"msg": "echo \"hello world\"",
+							},
+						}},
+					},
+				},
+			}},
+		},
+		{
+			name: "cannot find role",
+			role: kkprojectv1.RoleInclude{Name: "role3"},
+			err:  true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			role, err := convertRole(os.DirFS("testdata"), filepath.Join("playbooks", "playbook1.yaml"), tc.role)
+			if tc.err {
+				assert.Error(t, err)
+			} else {
+				assert.Equal(t, tc.except, role)
+			}
+		})
+	}
+}
+
 func TestCombineMaps(t *testing.T) {
 	testcases := []struct {
 		name   string
@@ -105,6 +105,16 @@ func (p localProject) MarshalPlaybook() (*kkprojectv1.Playbook, error) {
 	return marshalPlaybook(os.DirFS(p.projectDir), p.playbook)
 }
 
+// MarshalIncludeTasks project file to blocks.
+func (p localProject) MarshalIncludeTasks(role string, file string) ([]kkprojectv1.Block, error) {
+	return marshalIncludeTasks(os.DirFS(p.projectDir), p.playbook, role, file)
+}
+
+// MarshalRole project role to kkprojectv1.Role.
+func (p localProject) MarshalRole(ri kkprojectv1.RoleInclude) (*kkprojectv1.Role, error) {
+	return convertRole(os.DirFS(p.projectDir), p.playbook, ri)
+}
+
 // Stat role/file/template file or dir in project
 func (p localProject) Stat(path string, option GetFileOption) (os.FileInfo, error) {
 	return os.Stat(p.getFilePath(path, option))
@@ -120,6 +130,11 @@ func (p localProject) ReadFile(path string, option GetFileOption) ([]byte, error
 	return os.ReadFile(p.getFilePath(path, option))
 }
 
+// OpenFile role/file/template file in project
+func (p localProject) OpenFile(path string, option GetFileOption) (fs.File, error) {
+	return os.Open(p.getFilePath(path, option))
+}
+
 // Rel path for role/file/template file or dir in project
 func (p localProject) Rel(root string, path string, option GetFileOption) (string, error) {
 	return filepath.Rel(p.getFilePath(root, option), path)
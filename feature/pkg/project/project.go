@@ -32,9 +32,15 @@ var builtinProjectFunc func(kkcorev1.Pipeline) (Project, error)
 // get project file should base on it
 type Project interface {
 	MarshalPlaybook() (*kkprojectv1.Playbook, error)
+	// MarshalIncludeTasks load the file of include_tasks to blocks. the file is relative to role's tasks dir or playbook.
+	MarshalIncludeTasks(role string, file string) ([]kkprojectv1.Block, error)
+	// MarshalRole load the role of include_role to kkprojectv1.Role.
+	MarshalRole(ri kkprojectv1.RoleInclude) (*kkprojectv1.Role, error)
 	Stat(path string, option GetFileOption) (os.FileInfo, error)
 	WalkDir(path string, option GetFileOption, f fs.WalkDirFunc) error
 	ReadFile(path string, option GetFileOption) ([]byte, error)
+	// OpenFile open the file for reading, so that the large file can be streamed rather than read to memory.
+	OpenFile(path string, option GetFileOption) (fs.File, error)
 	Rel(root string, path string, option GetFileOption) (string, error)
 }
 
@@ -17,6 +17,7 @@ limitations under the License.
 package variable
 
 import (
+	"bytes"
 	"fmt"
 	"net"
 	"reflect"
@@ -33,6 +34,7 @@ import (
 	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
 	_const "github.com/kubesphere/kubekey/v4/pkg/const"
 	"github.com/kubesphere/kubekey/v4/pkg/converter/tmpl"
+	"github.com/kubesphere/kubekey/v4/pkg/variable/vault"
 )
 
 // combineVariables merge multiple variables into one variable
@@ -467,3 +469,66 @@ func Extension2String(d map[string]any, ext runtime.RawExtension) (string, error
 
 	return result, nil
 }
+
+// newVault returns the vault with the password from env.
+func newVault() (*vault.Vault, error) {
+	password, err := vault.PasswordFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	return vault.New(password), nil
+}
+
+// decryptVault decrypt the vault values in config and inventory. the vault password is only required when
+// there are encrypted values. the returned vault is nil if there is no encrypted value.
+func decryptVault(config *kkcorev1.Config, inventory *kkcorev1.Inventory) (*vault.Vault, error) {
+	var v *vault.Vault
+	decrypt := func(raw runtime.RawExtension) (runtime.RawExtension, error) {
+		if !bytes.Contains(raw.Raw, []byte(vault.Header)) {
+			return raw, nil
+		}
+		if v == nil {
+			nv, err := newVault()
+			if err != nil {
+				return raw, err
+			}
+			v = nv
+		}
+		var val any
+		if err := json.Unmarshal(raw.Raw, &val); err != nil {
+			return raw, fmt.Errorf("unmarshal variable error: %w", err)
+		}
+		val, err := v.DecryptValues(val)
+		if err != nil {
+			return raw, fmt.Errorf("decrypt vault value error: %w", err)
+		}
+		data, err := json.Marshal(val)
+		if err != nil {
+			return raw, fmt.Errorf("marshal variable error: %w", err)
+		}
+
+		return runtime.RawExtension{Raw: data}, nil
+	}
+
+	var err error
+	if config.Spec, err = decrypt(config.Spec); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	if inventory.Spec.Vars, err = decrypt(inventory.Spec.Vars); err != nil {
+		return nil, fmt.Errorf("inventory vars: %w", err)
+	}
+	for hn, hv := range inventory.Spec.Hosts {
+		if inventory.Spec.Hosts[hn], err = decrypt(hv); err != nil {
+			return nil, fmt.Errorf("inventory host %s: %w", hn, err)
+		}
+	}
+	for gn, gv := range inventory.Spec.Groups {
+		if gv.Vars, err = decrypt(gv.Vars); err != nil {
+			return nil, fmt.Errorf("inventory group %s: %w", gn, err)
+		}
+		inventory.Spec.Groups[gn] = gv
+	}
+
+	return v, nil
+}
@@ -20,9 +20,11 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
 	"github.com/kubesphere/kubekey/v4/pkg/converter/tmpl"
+	"github.com/kubesphere/kubekey/v4/pkg/variable/vault"
 )
 
 func TestMergeVariable(t *testing.T) {
@@ -356,3 +358,42 @@ func TestHostsInGroup(t *testing.T) {
 		})
 	}
 }
+
+func TestDecryptVault(t *testing.T) {
+	encrypted, err := vault.New([]byte("pass")).Encrypt("123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newInventory := func() *kkcorev1.Inventory {
+		return &kkcorev1.Inventory{Spec: kkcorev1.InventorySpec{
+			Hosts: map[string]runtime.RawExtension{
+				"node1": {Raw: []byte(`{"connector":{"password":"` + encrypted + `"}}`)},
+			},
+			Groups: map[string]kkcorev1.InventoryGroup{
+				"k8s": {Vars: runtime.RawExtension{Raw: []byte(`{"token":"` + encrypted + `"}`)}},
+			},
+		}}
+	}
+
+	t.Run("without password", func(t *testing.T) {
+		t.Setenv(vault.EnvPassword, "")
+		t.Setenv(vault.EnvPasswordFile, "")
+		_, err := decryptVault(&kkcorev1.Config{}, newInventory())
+		assert.ErrorIs(t, err, vault.ErrNoPassword)
+	})
+
+	t.Run("with password", func(t *testing.T) {
+		t.Setenv(vault.EnvPassword, "pass")
+		config := &kkcorev1.Config{Spec: runtime.RawExtension{Raw: []byte(`{"kube_version":"v1.30.0"}`)}}
+		inventory := newInventory()
+		v, err := decryptVault(config, inventory)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.NotNil(t, v)
+		assert.Equal(t, `{"kube_version":"v1.30.0"}`, string(config.Spec.Raw))
+		assert.JSONEq(t, `{"connector":{"password":"123456"}}`, string(inventory.Spec.Hosts["node1"].Raw))
+		assert.JSONEq(t, `{"token":"123456"}`, string(inventory.Spec.Groups["k8s"].Vars.Raw))
+		assert.Equal(t, "password: "+vault.Redacted, vault.Redact("password: 123456"))
+	})
+}
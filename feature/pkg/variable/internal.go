@@ -34,6 +34,7 @@ import (
 	_const "github.com/kubesphere/kubekey/v4/pkg/const"
 	"github.com/kubesphere/kubekey/v4/pkg/converter/tmpl"
 	"github.com/kubesphere/kubekey/v4/pkg/variable/source"
+	"github.com/kubesphere/kubekey/v4/pkg/variable/vault"
 )
 
 type variable struct {
@@ -43,8 +44,10 @@ type variable struct {
 	source source.Source
 	// value is the data of the variable, which store in memory
 	value *value
+	// vault decrypts the values in config and inventory. it's nil if there is no encrypted value.
+	vault *vault.Vault
 	// lock is the lock for value
-	sync.Mutex
+	sync.RWMutex
 }
 
 // value is the specific data contained in the variable
@@ -123,6 +126,9 @@ type host struct {
 
 // Get vars
 func (v *variable) Get(f GetFunc) (any, error) {
+	v.RLock()
+	defer v.RUnlock()
+
 	return f(v)
 }
 
@@ -147,6 +153,13 @@ func (v *variable) syncSource(old value) error {
 			// nothing change skip.
 			continue
 		}
+		// the values derived from vault should not be written to disk in plain.
+		hv, err := v.encryptHost(hv)
+		if err != nil {
+			klog.ErrorS(err, "encrypt host data error", "hostname", hn)
+
+			return err
+		}
 		// write to source
 		data, err := json.MarshalIndent(hv, "", "  ")
 		if err != nil {
@@ -163,6 +176,44 @@ func (v *variable) syncSource(old value) error {
 	return nil
 }
 
+// encryptHost returns a copy of h, in which the values derived from vault are encrypted.
+func (v *variable) encryptHost(h host) (host, error) {
+	if v.vault == nil {
+		return h, nil
+	}
+	remote, err := v.vault.EncryptSecrets(h.RemoteVars)
+	if err != nil {
+		return h, err
+	}
+	rt, err := v.vault.EncryptSecrets(h.RuntimeVars)
+	if err != nil {
+		return h, err
+	}
+	remoteVars, _ := remote.(map[string]any)
+	runtimeVars, _ := rt.(map[string]any)
+
+	return host{RemoteVars: remoteVars, RuntimeVars: runtimeVars}, nil
+}
+
+// decryptHost decrypt the values in h which are encrypted by encryptHost.
+func (v *variable) decryptHost(h *host) error {
+	if v.vault == nil {
+		vlt, err := newVault()
+		if err != nil {
+			return err
+		}
+		v.vault = vlt
+	}
+	if _, err := v.vault.DecryptValues(h.RemoteVars); err != nil {
+		return fmt.Errorf("remote: %w", err)
+	}
+	if _, err := v.vault.DecryptValues(h.RuntimeVars); err != nil {
+		return fmt.Errorf("runtime: %w", err)
+	}
+
+	return nil
+}
+
 // ***************************** GetFunc ***************************** //
 
 // GetHostnames get all hostnames from a group or host
@@ -307,6 +358,16 @@ var MergeRemoteVariable = func(data map[string]any, hostname string) MergeFunc {
 
 // MergeRuntimeVariable parse variable by specific host and merge to the host.
 var MergeRuntimeVariable = func(data map[string]any, hosts ...string) MergeFunc {
+	return mergeRuntimeVariable(data, false, hosts...)
+}
+
+// MergeDefaultRuntimeVariable parse variable by specific host and merge to the host as default value.
+// the variable which has been set in runtime (such as block vars and set_fact) is not overridden.
+var MergeDefaultRuntimeVariable = func(data map[string]any, hosts ...string) MergeFunc {
+	return mergeRuntimeVariable(data, true, hosts...)
+}
+
+func mergeRuntimeVariable(data map[string]any, asDefault bool, hosts ...string) MergeFunc {
 	if len(data) == 0 || len(hosts) == 0 {
 		// skip
 		return emptyMergeFunc
@@ -318,8 +379,8 @@ var MergeRuntimeVariable = func(data map[string]any, hosts ...string) MergeFunc
 			if !ok {
 				return errors.New("variable type error")
 			}
-			// merge to specify host
-			curVariable, err := v.Get(GetAllVariable(hostName))
+			// merge to specify host. the variable has been locked by Merge, get it without lock.
+			curVariable, err := GetAllVariable(hostName)(v)
 			if err != nil {
 				return err
 			}
@@ -336,11 +397,12 @@ var MergeRuntimeVariable = func(data map[string]any, hosts ...string) MergeFunc
 				return err
 			}
 
-			if _, ok := v.(*variable); !ok {
-				return errors.New("variable type error")
-			}
 			hv := vv.value.Hosts[hostName]
-			hv.RuntimeVars = combineVariables(hv.RuntimeVars, data)
+			if asDefault {
+				hv.RuntimeVars = combineVariables(data, hv.RuntimeVars)
+			} else {
+				hv.RuntimeVars = combineVariables(hv.RuntimeVars, data)
+			}
 			vv.value.Hosts[hostName] = hv
 		}
 
@@ -348,15 +410,20 @@ var MergeRuntimeVariable = func(data map[string]any, hosts ...string) MergeFunc
 	}
 }
 
-// MergeAllRuntimeVariable parse variable by specific host and merge to all hosts.
-var MergeAllRuntimeVariable = func(data map[string]any, hostName string) MergeFunc {
+// MergeHostRuntimeVariable parse variable by specific host and merge to the given hosts.
+var MergeHostRuntimeVariable = func(data map[string]any, hostName string, hosts ...string) MergeFunc {
+	if len(data) == 0 || len(hosts) == 0 {
+		// skip
+		return emptyMergeFunc
+	}
+
 	return func(v Variable) error {
 		vv, ok := v.(*variable)
 		if !ok {
 			return errors.New("variable type error")
 		}
-		// merge to specify host
-		curVariable, err := v.Get(GetAllVariable(hostName))
+		// parse by the variable of hostName. the variable has been locked by Merge, get it without lock.
+		curVariable, err := GetAllVariable(hostName)(v)
 		if err != nil {
 			return err
 		}
@@ -373,10 +440,7 @@ var MergeAllRuntimeVariable = func(data map[string]any, hostName string) MergeFu
 			return err
 		}
 
-		for h := range vv.value.Hosts {
-			if _, ok := v.(*variable); !ok {
-				return errors.New("variable type error")
-			}
+		for _, h := range hosts {
 			hv := vv.value.Hosts[h]
 			hv.RuntimeVars = combineVariables(hv.RuntimeVars, data)
 			vv.value.Hosts[h] = hv
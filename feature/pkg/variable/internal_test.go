@@ -17,12 +17,16 @@ limitations under the License.
 package variable
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+	"github.com/kubesphere/kubekey/v4/pkg/variable/source"
+	"github.com/kubesphere/kubekey/v4/pkg/variable/vault"
 )
 
 func TestGetAllVariable(t *testing.T) {
@@ -93,3 +97,55 @@ func TestGetAllVariable(t *testing.T) {
 		})
 	}
 }
+
+func TestSyncSourceWithVault(t *testing.T) {
+	t.Setenv(vault.EnvPassword, "pass")
+	vlt := vault.New([]byte("pass"))
+	encrypted, err := vlt.Encrypt("123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// decrypt to add the value to secrets.
+	if _, err := vlt.DecryptValues(encrypted); err != nil {
+		t.Fatal(err)
+	}
+
+	s := source.NewMemorySource()
+	v := &variable{source: s, vault: vlt, value: &value{Hosts: map[string]host{
+		"node1": {
+			RemoteVars: map[string]any{"mtu": 1234567},
+			RuntimeVars: map[string]any{
+				"token":   "token=123456",
+				"123456":  "key",
+				"options": []any{"--password", "123456"},
+			},
+		},
+	}}}
+	if err := v.syncSource(value{}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := s.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, strings.Contains(string(data["node1.json"]), "token=123456"))
+	h := host{}
+	if err := json.Unmarshal(data["node1.json"], &h); err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, vault.IsEncrypted(h.RuntimeVars["options"].([]any)[1].(string)))
+	assert.Equal(t, "key", h.RuntimeVars["123456"])
+
+	// the stored values are decrypted when they are read back.
+	if err := (&variable{}).decryptHost(&h); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, map[string]any{"mtu": float64(1234567)}, h.RemoteVars)
+	assert.Equal(t, map[string]any{
+		"token":   "token=123456",
+		"123456":  "key",
+		"options": []any{"--password", "123456"},
+	}, h.RuntimeVars)
+	// the value in memory is not changed.
+	assert.Equal(t, "token=123456", v.value.Hosts["node1"].RuntimeVars["token"])
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package variable
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -31,6 +32,7 @@ import (
 	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
 	_const "github.com/kubesphere/kubekey/v4/pkg/const"
 	"github.com/kubesphere/kubekey/v4/pkg/variable/source"
+	"github.com/kubesphere/kubekey/v4/pkg/variable/vault"
 )
 
 var (
@@ -94,9 +96,18 @@ func New(ctx context.Context, client ctrlclient.Client, pipeline kkcorev1.Pipeli
 		}
 	}
 
+	// decrypt the vault values in config and inventory. they are only decrypted in memory.
+	vlt, err := decryptVault(config, inventory)
+	if err != nil {
+		klog.V(4).ErrorS(err, "decrypt vault values error", "pipeline", ctrlclient.ObjectKeyFromObject(&pipeline))
+
+		return nil, err
+	}
+
 	v := &variable{
 		key:    string(pipeline.UID),
 		source: s,
+		vault:  vlt,
 		value: &value{
 			Config:    *config,
 			Inventory: *inventory,
@@ -129,6 +140,14 @@ func New(ctx context.Context, client ctrlclient.Client, pipeline kkcorev1.Pipeli
 
 			return nil, err
 		}
+		// the values derived from vault are stored encrypted.
+		if bytes.Contains(d, []byte(vault.Header)) {
+			if err := v.decryptHost(&h); err != nil {
+				klog.V(4).ErrorS(err, "decrypt host error", "pipeline", ctrlclient.ObjectKeyFromObject(&pipeline))
+
+				return nil, err
+			}
+		}
 
 		v.value.Hosts[strings.TrimSuffix(k, ".json")] = h
 	}
@@ -0,0 +1,337 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package vault encrypt and decrypt the values in config and inventory by AES-256-GCM.
+// the key is derived from password by scrypt with a random salt.
+// an encrypted value is a string: "$KUBEKEY_VAULT;1.0;AES256-GCM;" + base64(salt + nonce + ciphertext).
+package vault
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Header is the prefix of encrypted value.
+const Header = "$KUBEKEY_VAULT;1.0;AES256-GCM;"
+
+const (
+	// EnvPassword is the environment variable of vault password.
+	EnvPassword = "KUBEKEY_VAULT_PASSWORD"
+	// EnvPasswordFile is the environment variable of the file which contains vault password.
+	EnvPasswordFile = "KUBEKEY_VAULT_PASSWORD_FILE"
+)
+
+// Redacted replace the decrypted values when they are written to disk or logged.
+const Redacted = "********"
+
+const (
+	saltSize = 16
+	keySize  = 32
+	// scrypt parameters recommended for interactive use.
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// ErrNoPassword is returned when decrypt value without vault password.
+var ErrNoPassword = fmt.Errorf("vault password is required, set it by env %s or %s", EnvPassword, EnvPasswordFile)
+
+// IsEncrypted returns whether the value is encrypted by vault.
+func IsEncrypted(s string) bool {
+	return strings.HasPrefix(s, Header)
+}
+
+// PasswordFromEnv returns vault password from EnvPassword, or the content of EnvPasswordFile without trailing newline.
+// returns nil if both are not set.
+func PasswordFromEnv() ([]byte, error) {
+	if password := os.Getenv(EnvPassword); password != "" {
+		return []byte(password), nil
+	}
+	if file := os.Getenv(EnvPasswordFile); file != "" {
+		return PasswordFromFile(file)
+	}
+
+	return nil, nil
+}
+
+// PasswordFromFile returns the content of file without trailing newline.
+func PasswordFromFile(file string) ([]byte, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read vault password file error: %w", err)
+	}
+	password := bytes.TrimRight(data, "\r\n")
+	if len(password) == 0 {
+		return nil, fmt.Errorf("vault password file %s is empty", file)
+	}
+
+	return password, nil
+}
+
+// Vault encrypt and decrypt values by password.
+type Vault struct {
+	password []byte
+
+	sync.Mutex
+	// salt for encrypt. all values encrypted by the same Vault share the salt, so the key is derived once.
+	salt []byte
+	// keys derived by salt.
+	keys map[string][]byte
+}
+
+// New returns a Vault with password.
+func New(password []byte) *Vault {
+	return &Vault{password: password, keys: make(map[string][]byte)}
+}
+
+// key returns the key derived by salt.
+func (v *Vault) key(salt []byte) ([]byte, error) {
+	v.Lock()
+	defer v.Unlock()
+	if key, ok := v.keys[string(salt)]; ok {
+		return key, nil
+	}
+	key, err := scrypt.Key(v.password, salt, scryptN, scryptR, scryptP, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("derive vault key error: %w", err)
+	}
+	v.keys[string(salt)] = key
+
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// Encrypt the plain value. the encrypted value is returned as it is.
+func (v *Vault) Encrypt(plain string) (string, error) {
+	if IsEncrypted(plain) {
+		return plain, nil
+	}
+	v.Lock()
+	if v.salt == nil {
+		v.salt = make([]byte, saltSize)
+		if _, err := rand.Read(v.salt); err != nil {
+			v.Unlock()
+
+			return "", fmt.Errorf("generate salt error: %w", err)
+		}
+	}
+	salt := v.salt
+	v.Unlock()
+	key, err := v.key(salt)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generate nonce error: %w", err)
+	}
+	data := append(append(slices.Clone(salt), nonce...), gcm.Seal(nil, nonce, []byte(plain), []byte(Header))...)
+
+	return Header + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// Decrypt the encrypted value. the value which is not encrypted is returned as it is.
+func (v *Vault) Decrypt(s string) (string, error) {
+	if !IsEncrypted(s) {
+		return s, nil
+	}
+	if len(v.password) == 0 {
+		return "", ErrNoPassword
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(strings.TrimPrefix(s, Header)))
+	if err != nil {
+		return "", fmt.Errorf("decode vault value error: %w", err)
+	}
+	if len(data) < saltSize {
+		return "", errors.New("vault value is too short")
+	}
+	key, err := v.key(data[:saltSize])
+	if err != nil {
+		return "", err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	data = data[saltSize:]
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("vault value is too short")
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], []byte(Header))
+	if err != nil {
+		return "", errors.New("decrypt vault value error: wrong password or the value is corrupted")
+	}
+
+	return string(plain), nil
+}
+
+// DecryptValues decrypt the encrypted strings in val recursively. the decrypted strings are added to redaction.
+func (v *Vault) DecryptValues(val any) (any, error) {
+	switch tv := val.(type) {
+	case string:
+		if !IsEncrypted(tv) {
+			return tv, nil
+		}
+		plain, err := v.Decrypt(tv)
+		if err != nil {
+			return nil, err
+		}
+		AddSecret(plain)
+
+		return plain, nil
+	case map[string]any:
+		for k, e := range tv {
+			d, err := v.DecryptValues(e)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", k, err)
+			}
+			tv[k] = d
+		}
+
+		return tv, nil
+	case []any:
+		for i, e := range tv {
+			d, err := v.DecryptValues(e)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			tv[i] = d
+		}
+
+		return tv, nil
+	default:
+		return val, nil
+	}
+}
+
+// EncryptSecrets returns a copy of val, in which the strings containing the decrypted values are encrypted.
+// it's used to store the values derived from vault, which are decrypted by DecryptValues when they are read back.
+func (v *Vault) EncryptSecrets(val any) (any, error) {
+	switch tv := val.(type) {
+	case string:
+		if IsEncrypted(tv) || !ContainsSecret(tv) {
+			return tv, nil
+		}
+
+		return v.Encrypt(tv)
+	case map[string]any:
+		if tv == nil {
+			return tv, nil
+		}
+		m := make(map[string]any, len(tv))
+		for k, e := range tv {
+			d, err := v.EncryptSecrets(e)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", k, err)
+			}
+			m[k] = d
+		}
+
+		return m, nil
+	case []any:
+		if tv == nil {
+			return tv, nil
+		}
+		l := make([]any, len(tv))
+		for i, e := range tv {
+			d, err := v.EncryptSecrets(e)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			l[i] = d
+		}
+
+		return l, nil
+	default:
+		return val, nil
+	}
+}
+
+// secrets are the decrypted values in process. they are redacted by Redact.
+var secrets = struct {
+	sync.RWMutex
+	values []string
+}{}
+
+// AddSecret add the value to redaction. the empty value is ignored.
+func AddSecret(s string) {
+	if s == "" {
+		return
+	}
+	secrets.Lock()
+	defer secrets.Unlock()
+	values := []string{s}
+	// the value may be written in json, such as the variable file in runtime dir.
+	if data, err := json.Marshal(s); err == nil && string(data[1:len(data)-1]) != s {
+		values = append(values, string(data[1:len(data)-1]))
+	}
+	for _, v := range values {
+		if !slices.Contains(secrets.values, v) {
+			secrets.values = append(secrets.values, v)
+		}
+	}
+	// replace the longer value first, the value may contain another.
+	sort.Slice(secrets.values, func(i, j int) bool { return len(secrets.values[i]) > len(secrets.values[j]) })
+}
+
+// ContainsSecret returns whether s contains any decrypted value.
+func ContainsSecret(s string) bool {
+	secrets.RLock()
+	defer secrets.RUnlock()
+	for _, v := range secrets.values {
+		if strings.Contains(s, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Redact replace the decrypted values in s by Redacted.
+func Redact(s string) string {
+	secrets.RLock()
+	defer secrets.RUnlock()
+	for _, v := range secrets.values {
+		s = strings.ReplaceAll(s, v, Redacted)
+	}
+
+	return s
+}
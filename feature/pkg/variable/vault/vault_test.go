@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVault_EncryptDecrypt(t *testing.T) {
+	v := New([]byte("pass"))
+	encrypted, err := v.Encrypt("secret value")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, IsEncrypted(encrypted))
+	assert.NotContains(t, encrypted, "secret value")
+	// encrypt the encrypted value is not changed.
+	again, err := v.Encrypt(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, encrypted, again)
+
+	// decrypt by a new vault with the same password.
+	plain, err := New([]byte("pass")).Decrypt(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "secret value", plain)
+
+	_, err = New([]byte("wrong")).Decrypt(encrypted)
+	assert.Error(t, err)
+	_, err = New(nil).Decrypt(encrypted)
+	assert.ErrorIs(t, err, ErrNoPassword)
+	// the value not encrypted is returned as it is.
+	plain, err = New(nil).Decrypt("plain")
+	assert.NoError(t, err)
+	assert.Equal(t, "plain", plain)
+}
+
+func TestVault_YAML(t *testing.T) {
+	v := New([]byte("pass"))
+	data := `# inventory
+spec:
+  hosts:
+    node1:
+      connector:
+        user: root
+        password: "123456" # root password
+  vars:
+    registry:
+      auth:
+        - username: admin
+          registry_password: "abc\"d"
+    kube_version: v1.30.0
+`
+	encrypted, err := v.EncryptYAML([]byte(data), DefaultSensitiveKeys, []string{"spec.vars.kube_version"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, string(encrypted), "# root password")
+	assert.Contains(t, string(encrypted), "user: root")
+	assert.NotContains(t, string(encrypted), "123456")
+	assert.NotContains(t, string(encrypted), "v1.30.0")
+	assert.Equal(t, 3, strings.Count(string(encrypted), Header))
+
+	decrypted, paths, err := New([]byte("pass")).DecryptYAML(encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"spec.hosts.node1.connector.password", "spec.vars.registry.auth.0.registry_password", "spec.vars.kube_version"}, paths)
+	// the number like string is quoted.
+	assert.Contains(t, string(decrypted), `password: "123456" # root password`)
+	assert.Contains(t, string(decrypted), `registry_password: abc"d`)
+	assert.Contains(t, string(decrypted), "kube_version: v1.30.0")
+
+	_, _, err = New([]byte("wrong")).DecryptYAML(encrypted)
+	assert.Error(t, err)
+}
+
+func TestRedact(t *testing.T) {
+	v := New([]byte("pass"))
+	encrypted, err := v.Encrypt(`p@ss"word`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	val, err := v.DecryptValues(map[string]any{"connector": map[string]any{"password": encrypted}, "list": []any{"a"}})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"connector": map[string]any{"password": `p@ss"word`}, "list": []any{"a"}}, val)
+
+	assert.Equal(t, "login with "+Redacted, Redact(`login with p@ss"word`))
+	// the value in json is redacted too.
+	assert.Equal(t, `{"password":"`+Redacted+`"}`, Redact(`{"password":"p@ss\"word"}`))
+}
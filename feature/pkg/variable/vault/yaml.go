@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultSensitiveKeys are the parts of key name whose value is encrypted by EncryptYAML.
+var DefaultSensitiveKeys = []string{"password", "passwd", "passphrase", "token", "secret", "credential"}
+
+// EncryptYAML encrypt the string values in yaml documents. a value is encrypted when its key name contains any of keys
+// (case insensitive), or its path is in paths. the comments and order in yaml are kept.
+func (v *Vault) EncryptYAML(data []byte, keys []string, paths []string) ([]byte, error) {
+	return walkYAML(data, func(path, key string, node *yaml.Node) error {
+		if IsEncrypted(node.Value) || (!sensitiveKey(key, keys) && !slices.Contains(paths, path)) {
+			return nil
+		}
+		encrypted, err := v.Encrypt(node.Value)
+		if err != nil {
+			return err
+		}
+		node.Value, node.Tag, node.Style = encrypted, "!!str", 0
+
+		return nil
+	})
+}
+
+// DecryptYAML decrypt the encrypted values in yaml documents. returns the paths of decrypted values,
+// which can be used to encrypt the values again by EncryptYAML.
+func (v *Vault) DecryptYAML(data []byte) ([]byte, []string, error) {
+	var paths []string
+	result, err := walkYAML(data, func(path, _ string, node *yaml.Node) error {
+		if !IsEncrypted(node.Value) {
+			return nil
+		}
+		plain, err := v.Decrypt(node.Value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		node.Value, node.Tag, node.Style = plain, "!!str", 0
+		if strings.Contains(plain, "\n") {
+			node.Style = yaml.LiteralStyle
+		}
+		paths = append(paths, path)
+
+		return nil
+	})
+
+	return result, paths, err
+}
+
+// sensitiveKey returns whether the key name contains any of keys.
+func sensitiveKey(key string, keys []string) bool {
+	key = strings.ToLower(key)
+	for _, k := range keys {
+		if k != "" && strings.Contains(key, strings.ToLower(k)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// walkYAML call fn with each scalar value in yaml documents, and returns the encoded documents.
+// path is the keys and indexes from root, joined by ".". key is the mapping key of the value, empty if it's in sequence.
+func walkYAML(data []byte, fn func(path, key string, node *yaml.Node) error) ([]byte, error) {
+	var walk func(node *yaml.Node, path, key string) error
+	walk = func(node *yaml.Node, path, key string) error {
+		switch node.Kind {
+		case yaml.DocumentNode:
+			for _, n := range node.Content {
+				if err := walk(n, path, key); err != nil {
+					return err
+				}
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				k := node.Content[i].Value
+				if err := walk(node.Content[i+1], joinPath(path, k), k); err != nil {
+					return err
+				}
+			}
+		case yaml.SequenceNode:
+			for i, n := range node.Content {
+				if err := walk(n, joinPath(path, strconv.Itoa(i)), key); err != nil {
+					return err
+				}
+			}
+		case yaml.ScalarNode:
+			if node.Tag == "!!null" {
+				return nil
+			}
+
+			return fn(path, key, node)
+		}
+
+		return nil
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	for i := 0; ; i++ {
+		var doc yaml.Node
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, fmt.Errorf("decode yaml error: %w", err)
+		}
+		path := ""
+		if i > 0 {
+			// the path of documents except the first one is prefixed by the index.
+			path = strconv.Itoa(i)
+		}
+		if err := walk(&doc, path, ""); err != nil {
+			return nil, err
+		}
+		if err := encoder.Encode(&doc); err != nil {
+			return nil, fmt.Errorf("encode yaml error: %w", err)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("encode yaml error: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}